@@ -0,0 +1,163 @@
+package avatars
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/keybase/client/go/logger"
+)
+
+// diskCache is a local, quota-bounded cache of downloaded avatar image
+// bytes, keyed by the URL they were fetched from. It exists so that
+// re-rendering a user's avatar (e.g. scrolling a chat, switching
+// profile tabs) doesn't re-download it from the server every time.
+//
+// This mirrors chat.DiskAssetCache's content-addressed, LRU-evicted
+// design (see go/chat/attachment_cache.go), reimplemented here rather
+// than shared because the two caches serve unrelated features with
+// different lifetimes and keying schemes.
+type diskCache struct {
+	sync.Mutex
+	dir   string
+	quota int64
+	log   logger.Logger
+
+	order *list.List               // LRU at the front, MRU at the back
+	elems map[string]*list.Element // key -> its node in order
+	sizes map[string]int64         // key -> file size on disk
+}
+
+// newDiskCache creates a diskCache rooted at dir, created on first Put.
+// quota is the total number of bytes the cache may occupy on disk; a
+// non-positive quota disables eviction.
+func newDiskCache(log logger.Logger, dir string, quota int64) *diskCache {
+	return &diskCache{
+		dir:   dir,
+		quota: quota,
+		log:   log,
+		order: list.New(),
+		elems: make(map[string]*list.Element),
+		sizes: make(map[string]int64),
+	}
+}
+
+func cacheKey(url string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(url)))
+}
+
+func (d *diskCache) path(key string) string {
+	return filepath.Join(d.dir, key)
+}
+
+// GetPath returns the local path previously cached for url, if the file
+// is still present on disk. The second return value is false on a
+// cache miss.
+func (d *diskCache) GetPath(url string) (string, bool) {
+	key := cacheKey(url)
+
+	d.Lock()
+	_, found := d.elems[key]
+	d.Unlock()
+	if !found {
+		return "", false
+	}
+
+	path := d.path(key)
+	if _, err := os.Stat(path); err != nil {
+		d.log.Debug("avatars.diskCache: GetPath(%s) missing on disk, forgetting entry: %s", key, err)
+		d.Lock()
+		d.removeLocked(key)
+		d.Unlock()
+		return "", false
+	}
+
+	d.Lock()
+	d.touchLocked(key)
+	d.Unlock()
+	return path, true
+}
+
+// Put stores data (downloaded from url) in the cache and returns the
+// local path it was written to, evicting least-recently-used entries
+// as necessary to stay under quota.
+func (d *diskCache) Put(url string, data []byte) (string, error) {
+	if err := os.MkdirAll(d.dir, 0700); err != nil {
+		return "", err
+	}
+
+	key := cacheKey(url)
+	path := d.path(key)
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		return "", err
+	}
+
+	d.Lock()
+	evict := d.recordPutLocked(key, int64(len(data)))
+	d.Unlock()
+
+	for _, victim := range evict {
+		if err := os.Remove(d.path(victim)); err != nil && !os.IsNotExist(err) {
+			d.log.Debug("avatars.diskCache: failed to evict %s over quota: %s", victim, err)
+		}
+	}
+	return path, nil
+}
+
+// Remove drops any cached entry for url, e.g. on an avatar-changed
+// invalidation.
+func (d *diskCache) Remove(url string) {
+	key := cacheKey(url)
+	d.Lock()
+	d.removeLocked(key)
+	d.Unlock()
+	if err := os.Remove(d.path(key)); err != nil && !os.IsNotExist(err) {
+		d.log.Debug("avatars.diskCache: failed to remove %s: %s", key, err)
+	}
+}
+
+func (d *diskCache) touchLocked(key string) {
+	if elem, ok := d.elems[key]; ok {
+		d.order.MoveToBack(elem)
+	}
+}
+
+func (d *diskCache) recordPutLocked(key string, size int64) (evict []string) {
+	d.removeLocked(key)
+	d.sizes[key] = size
+	d.elems[key] = d.order.PushBack(key)
+
+	if d.quota <= 0 {
+		return nil
+	}
+	for d.totalLocked() > d.quota && d.order.Len() > 1 {
+		front := d.order.Front()
+		victim := front.Value.(string)
+		if victim == key {
+			break
+		}
+		evict = append(evict, victim)
+		d.removeLocked(victim)
+	}
+	return evict
+}
+
+func (d *diskCache) removeLocked(key string) {
+	if elem, ok := d.elems[key]; ok {
+		d.order.Remove(elem)
+		delete(d.elems, key)
+	}
+	delete(d.sizes, key)
+}
+
+func (d *diskCache) totalLocked() int64 {
+	var total int64
+	for _, size := range d.sizes {
+		total += size
+	}
+	return total
+}
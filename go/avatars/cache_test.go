@@ -0,0 +1,68 @@
+package avatars
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/keybase/client/go/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDiskCache(t *testing.T, quota int64) (*diskCache, func()) {
+	dir, err := ioutil.TempDir(os.TempDir(), "avatars_cache_test")
+	require.NoError(t, err)
+	cache := newDiskCache(logger.NewTestLogger(t), dir, quota)
+	return cache, func() { os.RemoveAll(dir) }
+}
+
+func TestDiskCacheGetPutRemove(t *testing.T) {
+	cache, cleanup := newTestDiskCache(t, 0)
+	defer cleanup()
+
+	url := "https://example.com/avatar.png"
+	if _, found := cache.GetPath(url); found {
+		t.Fatal("expected miss before Put")
+	}
+
+	path, err := cache.Put(url, []byte("some image bytes"))
+	require.NoError(t, err)
+
+	got, found := cache.GetPath(url)
+	require.True(t, found)
+	require.Equal(t, path, got)
+
+	cache.Remove(url)
+	if _, found := cache.GetPath(url); found {
+		t.Fatal("expected miss after Remove")
+	}
+}
+
+func TestDiskCacheEvictsLRU(t *testing.T) {
+	cache, cleanup := newTestDiskCache(t, 25)
+	defer cleanup()
+
+	a, b, c := "https://example.com/a", "https://example.com/b", "https://example.com/c"
+	_, err := cache.Put(a, make([]byte, 10))
+	require.NoError(t, err)
+	_, err = cache.Put(b, make([]byte, 10))
+	require.NoError(t, err)
+
+	// touch a, making b the least-recently-used entry
+	_, found := cache.GetPath(a)
+	require.True(t, found)
+
+	// total is now 30, over the quota of 25: b should be evicted, not a
+	_, err = cache.Put(c, make([]byte, 10))
+	require.NoError(t, err)
+
+	if _, found := cache.GetPath(b); found {
+		t.Fatal("expected b to have been evicted")
+	}
+	if _, found := cache.GetPath(a); !found {
+		t.Fatal("expected a to still be cached")
+	}
+	if _, found := cache.GetPath(c); !found {
+		t.Fatal("expected c to still be cached")
+	}
+}
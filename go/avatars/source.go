@@ -0,0 +1,262 @@
+// Copyright 2017 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package avatars
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/keybase/client/go/libkb"
+	keybase1 "github.com/keybase/client/go/protocol/keybase1"
+)
+
+// urlCacheTTL bounds how long a fetched (name, format) -> URL mapping
+// is trusted before Source re-hits the server for it. This is much
+// shorter than the disk image cache's lifetime: URLs are cheap to
+// re-fetch and can change (e.g. a new avatar upload), while the actual
+// image bytes for an unchanged URL are worth caching much longer.
+const urlCacheTTL = 10 * time.Minute
+
+// defaultDiskCacheQuotaBytes bounds the on-disk avatar image cache. It's
+// a fixed constant rather than a user-configurable setting, since
+// avatars are small and the failure mode of a slightly-too-small quota
+// (more frequent re-downloads) is mild.
+const defaultDiskCacheQuotaBytes = 50 * 1024 * 1024
+
+// downloadTimeout bounds a single avatar image download.
+const downloadTimeout = 10 * time.Second
+
+type urlCacheEntry struct {
+	urls      map[keybase1.AvatarFormat]string
+	fetchedAt time.Time
+}
+
+// Source implements libkb.AvatarLoader. It looks up avatar URLs from
+// the API server (caching them briefly in memory), downloads whichever
+// images aren't already on disk, and hands back local file paths.
+type Source struct {
+	libkb.Contextified
+
+	urlCacheMu sync.Mutex
+	userURLs   map[string]urlCacheEntry
+	teamURLs   map[string]urlCacheEntry
+
+	images     *diskCache
+	httpClient *http.Client
+}
+
+var _ libkb.AvatarLoader = (*Source)(nil)
+
+// NewSourceAndInstall creates a new avatar Source and installs it into g.
+func NewSourceAndInstall(g *libkb.GlobalContext) libkb.AvatarLoader {
+	s := &Source{
+		Contextified: libkb.NewContextified(g),
+		userURLs:     make(map[string]urlCacheEntry),
+		teamURLs:     make(map[string]urlCacheEntry),
+		images:       newDiskCache(g.Log, filepath.Join(g.Env.GetCacheDir(), "avatars"), defaultDiskCacheQuotaBytes),
+		httpClient:   &http.Client{Timeout: downloadTimeout},
+	}
+	g.SetAvatarLoader(s)
+	return s
+}
+
+func (s *Source) LoadUsers(ctx context.Context, usernames []string, formats []keybase1.AvatarFormat) (keybase1.LoadAvatarsRes, error) {
+	return s.load(ctx, "image/username_pic_urls", usernames, formats, s.userURLs)
+}
+
+func (s *Source) LoadTeams(ctx context.Context, teams []string, formats []keybase1.AvatarFormat) (keybase1.LoadAvatarsRes, error) {
+	return s.load(ctx, "image/team_pic_urls", teams, formats, s.teamURLs)
+}
+
+// OnCacheInvalidate drops the cached URL mapping and any cached image
+// bytes for name, so the next Load call re-fetches it from scratch.
+// Called in response to an avatar-changed gregor out-of-band message.
+func (s *Source) OnCacheInvalidate(ctx context.Context, name string) error {
+	s.urlCacheMu.Lock()
+	entry, ok := s.userURLs[name]
+	delete(s.userURLs, name)
+	if !ok {
+		entry, ok = s.teamURLs[name]
+		delete(s.teamURLs, name)
+	}
+	s.urlCacheMu.Unlock()
+
+	if ok {
+		for _, url := range entry.urls {
+			s.images.Remove(url)
+		}
+	}
+	s.G().Log.CDebugf(ctx, "avatars.Source: invalidated cache for %s", name)
+	return nil
+}
+
+func (s *Source) load(ctx context.Context, endpoint string, names []string, formats []keybase1.AvatarFormat,
+	urlCache map[string]urlCacheEntry) (res keybase1.LoadAvatarsRes, err error) {
+	defer s.G().CTrace(ctx, fmt.Sprintf("avatars.Source#load(%s)", endpoint), func() error { return err })()
+
+	urlsByName, err := s.urlsForNames(ctx, endpoint, names, formats, urlCache)
+	if err != nil {
+		return res, err
+	}
+
+	for _, name := range names {
+		res.Picmap = append(res.Picmap, s.buildNameEntry(ctx, name, formats, urlsByName[name]))
+	}
+	return res, nil
+}
+
+// buildNameEntry resolves each requested format's URL into a
+// locally-cached path. A format with no URL, or one whose
+// download/cache-write failed, is reported with an empty path rather
+// than failing the whole batch.
+func (s *Source) buildNameEntry(ctx context.Context, name string, formats []keybase1.AvatarFormat,
+	urls map[keybase1.AvatarFormat]string) keybase1.NameWithFormats {
+	entry := keybase1.NameWithFormats{Name: name}
+	for _, format := range formats {
+		var path string
+		if url, ok := urls[format]; ok {
+			var err error
+			if path, err = s.fetchImage(ctx, url); err != nil {
+				s.G().Log.CDebugf(ctx, "avatars.Source: failed to fetch %s (%s): %s", name, format, err)
+				path = ""
+			}
+		}
+		entry.Formats = append(entry.Formats, keybase1.AvatarFormatPath{Format: format, Path: path})
+	}
+	return entry
+}
+
+// urlsForNames returns, for each of names, the URL for each requested
+// format, using urlCache to skip a server round trip for names whose
+// mapping was fetched recently. Whatever's left is fetched from the
+// server in one batch call.
+func (s *Source) urlsForNames(ctx context.Context, endpoint string, names []string, formats []keybase1.AvatarFormat,
+	urlCache map[string]urlCacheEntry) (map[string]map[keybase1.AvatarFormat]string, error) {
+
+	ret := make(map[string]map[keybase1.AvatarFormat]string)
+
+	var toFetch []string
+	s.urlCacheMu.Lock()
+	for _, name := range names {
+		entry, ok := urlCache[name]
+		if ok && time.Since(entry.fetchedAt) < urlCacheTTL && hasAllFormats(entry.urls, formats) {
+			ret[name] = entry.urls
+			continue
+		}
+		toFetch = append(toFetch, name)
+	}
+	s.urlCacheMu.Unlock()
+
+	if len(toFetch) == 0 {
+		return ret, nil
+	}
+
+	fetched, err := s.fetchURLs(ctx, endpoint, toFetch, formats)
+	if err != nil {
+		return nil, err
+	}
+
+	s.urlCacheMu.Lock()
+	for name, urls := range fetched {
+		urlCache[name] = urlCacheEntry{urls: urls, fetchedAt: time.Now()}
+		ret[name] = urls
+	}
+	s.urlCacheMu.Unlock()
+
+	return ret, nil
+}
+
+func hasAllFormats(have map[keybase1.AvatarFormat]string, want []keybase1.AvatarFormat) bool {
+	for _, f := range want {
+		if _, ok := have[f]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// picURLRes is the shape of the API server's image/*_pic_urls response:
+// a map from name to a map from format name to URL.
+type picURLRes struct {
+	Status   libkb.AppStatus              `json:"status"`
+	Pictures map[string]map[string]string `json:"pictures"`
+}
+
+func (r *picURLRes) GetAppStatus() *libkb.AppStatus {
+	return &r.Status
+}
+
+func (s *Source) fetchURLs(ctx context.Context, endpoint string, names []string, formats []keybase1.AvatarFormat) (map[string]map[keybase1.AvatarFormat]string, error) {
+	formatStrs := make([]string, len(formats))
+	for i, f := range formats {
+		formatStrs[i] = f.String()
+	}
+
+	arg := libkb.APIArg{
+		Endpoint: endpoint,
+		Args: libkb.HTTPArgs{
+			"names":   libkb.S{Val: strings.Join(names, ",")},
+			"formats": libkb.S{Val: strings.Join(formatStrs, ",")},
+		},
+		NetContext: ctx,
+	}
+
+	var res picURLRes
+	if err := s.G().API.GetDecode(arg, &res); err != nil {
+		return nil, err
+	}
+
+	ret := make(map[string]map[keybase1.AvatarFormat]string)
+	for name, byFormat := range res.Pictures {
+		m := make(map[keybase1.AvatarFormat]string)
+		for formatStr, url := range byFormat {
+			if f, ok := keybase1.AvatarFormatMap[formatStr]; ok {
+				m[f] = url
+			}
+		}
+		ret[name] = m
+	}
+	return ret, nil
+}
+
+// fetchImage downloads and disk-caches the image at url, returning the
+// local path it was written to. A cache hit skips the download. The
+// server-hosted image itself isn't a keybase API endpoint, so this goes
+// out over a plain HTTP client rather than libkb.API/ExternalAPI, which
+// only know how to speak JSON/HTML/text.
+func (s *Source) fetchImage(ctx context.Context, url string) (string, error) {
+	if path, found := s.images.GetPath(url); found {
+		return path, nil
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return "", err
+	}
+	req = req.WithContext(ctx)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("avatars.Source: got status %d fetching %s", resp.StatusCode, url)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	return s.images.Put(url, body)
+}
@@ -12,6 +12,16 @@ import (
 	"github.com/keybase/client/go/protocol/keybase1"
 )
 
+// badgeStateDiskVersion is bumped to invalidate persisted badge state
+// entries whenever the on-disk representation changes shape.
+const badgeStateDiskVersion = 1
+
+type badgeStateDisk struct {
+	DBVersion int
+	InboxVers chat1.InboxVers
+	State     keybase1.BadgeState
+}
+
 // Badger keeps a BadgeState up to date and broadcasts it to electron.
 // This is the client-specific glue.
 // The state is kept up to date by subscribing to:
@@ -67,12 +77,44 @@ func (b *Badger) Resync(ctx context.Context, remoteClient *chat1.RemoteClient) e
 
 func (b *Badger) Clear(ctx context.Context) {
 	b.badgeState.Clear()
+	if db := b.G().LocalDb; db != nil {
+		if err := db.Delete(b.dbKey()); err != nil {
+			b.G().Log.Debug("Badger clear (db) failed: %v", err)
+		}
+	}
 	err := b.Send()
 	if err != nil {
 		b.G().Log.Warning("Badger send (clear) failed: %v", err)
 	}
 }
 
+// Restore loads the last persisted badge state for the current user from
+// local disk and broadcasts it immediately, so consumers have something to
+// show on startup instead of an empty badge state while waiting for the
+// first full gregor/chat sync to complete.
+func (b *Badger) Restore(ctx context.Context) {
+	uid := b.G().Env.GetUID()
+	db := b.G().LocalDb
+	if uid.IsNil() || db == nil {
+		return
+	}
+
+	var disk badgeStateDisk
+	found, err := db.GetInto(&disk, b.dbKey())
+	if err != nil {
+		b.G().Log.Debug("Badger restore: error reading from db: %v", err)
+		return
+	}
+	if !found || disk.DBVersion != badgeStateDiskVersion {
+		return
+	}
+
+	b.badgeState.Import(disk.InboxVers, disk.State)
+	if err := b.Send(); err != nil {
+		b.G().Log.Warning("Badger send (restore) failed: %v", err)
+	}
+}
+
 // Send the badgestate to electron
 func (b *Badger) Send() error {
 	state, err := b.badgeState.Export()
@@ -81,9 +123,33 @@ func (b *Badger) Send() error {
 	}
 	b.log(state)
 	b.G().NotifyRouter.HandleBadgeState(state)
+	b.persist(state)
 	return nil
 }
 
+// persist writes state to local disk so it survives a service restart.
+// Best-effort: a failure here just means the next restart starts from an
+// empty badge state, the same as before this cache existed.
+func (b *Badger) persist(state keybase1.BadgeState) {
+	uid := b.G().Env.GetUID()
+	db := b.G().LocalDb
+	if uid.IsNil() || db == nil {
+		return
+	}
+	disk := badgeStateDisk{
+		DBVersion: badgeStateDiskVersion,
+		InboxVers: b.badgeState.InboxVers(),
+		State:     state,
+	}
+	if err := db.PutObj(b.dbKey(), nil, disk); err != nil {
+		b.G().Log.Debug("Badger persist failed: %v", err)
+	}
+}
+
+func (b *Badger) dbKey() libkb.DbKey {
+	return libkb.DbKeyUID(libkb.DBBadgeState, b.G().Env.GetUID())
+}
+
 func (b *Badger) State() *BadgeState {
 	return b.badgeState
 }
@@ -36,6 +36,15 @@ func NewBadgeState(log logger.Logger) *BadgeState {
 	}
 }
 
+// InboxVers returns the chat inbox version this badge state was last
+// updated with.
+func (b *BadgeState) InboxVers() chat1.InboxVers {
+	b.Lock()
+	defer b.Unlock()
+
+	return b.inboxVers
+}
+
 // Exports the state summary
 func (b *BadgeState) Export() (keybase1.BadgeState, error) {
 	b.Lock()
@@ -128,6 +137,22 @@ func (b *BadgeState) UpdateWithChatFull(update chat1.UnreadUpdateFull) {
 	b.inboxVers = update.InboxVers
 }
 
+// Import replaces the in-memory state with a previously exported one, e.g.
+// one just loaded from disk. Used to give badges a value to show
+// immediately on startup, before the first live gregor/chat update
+// arrives.
+func (b *BadgeState) Import(inboxVers chat1.InboxVers, state keybase1.BadgeState) {
+	b.Lock()
+	defer b.Unlock()
+
+	b.inboxVers = inboxVers
+	b.state = state
+	b.chatUnreadMap = make(map[string]keybase1.BadgeConversationInfo)
+	for _, info := range state.Conversations {
+		b.chatUnreadMap[chat1.ConversationID(info.ConvID).String()] = info
+	}
+}
+
 func (b *BadgeState) Clear() {
 	b.state = keybase1.BadgeState{}
 	b.inboxVers = chat1.InboxVers(0)
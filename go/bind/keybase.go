@@ -47,6 +47,7 @@ func Init(homeDir string, logFile string, runModeStr string, accessGroupOverride
 	kbCtx.Init()
 	kbCtx.SetServices(externals.GetServices())
 	pvlsource.NewPvlSourceAndInstall(kbCtx)
+	externals.WatchPvlKitServices(kbCtx)
 	usage := libkb.Usage{
 		Config:    true,
 		API:       true,
@@ -182,3 +183,16 @@ func Reset() error {
 func Version() string {
 	return libkb.VersionString()
 }
+
+// NetworkChanged should be called by the mobile OS layer whenever it
+// detects a network change (Wi-Fi/cellular handoff, VPN up/down, airplane
+// mode, ...), so gregor/chat/the API client find out immediately instead
+// of waiting for the periodic reachability poll. It's the mobile
+// counterpart of the desktop route-change monitor in
+// go/service/reachability_linux.go.
+func NetworkChanged() {
+	if kbCtx == nil || kbCtx.ConnectivityMonitor == nil {
+		return
+	}
+	kbCtx.ConnectivityMonitor.ForceCheck()
+}
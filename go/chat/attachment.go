@@ -1,11 +1,14 @@
 package chat
 
 import (
+	"bytes"
 	"crypto/hmac"
 	"crypto/sha256"
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"net/http"
 	"os"
 	"path/filepath"
 
@@ -58,6 +61,7 @@ type AttachmentStore struct {
 	s3signer s3.Signer
 	s3c      s3.Root
 	stash    AttachmentStash
+	cache    *DiskAssetCache
 
 	// testing hooks
 	testing    bool                        // true if we're in a test
@@ -67,12 +71,18 @@ type AttachmentStore struct {
 }
 
 // NewAttachmentStore creates a standard AttachmentStore that uses a real
-// S3 connection.
-func NewAttachmentStore(log logger.Logger, runtimeDir string) *AttachmentStore {
+// S3 connection. cacheQuotaBytes bounds the size of the on-disk cache of
+// decrypted attachments kept under runtimeDir (see DiskAssetCache); a
+// non-positive value disables eviction. httpClient, if non-nil, is used for
+// all S3 requests -- pass a proxy-routed client to make attachment
+// transfers honor the "attachment" ProxyPolicy (see
+// libkb.Env.GetProxyPolicy); nil uses S3's usual default client.
+func NewAttachmentStore(log logger.Logger, runtimeDir string, cacheQuotaBytes int64, httpClient *http.Client) *AttachmentStore {
 	return &AttachmentStore{
 		log:   log,
-		s3c:   &s3.AWS{},
+		s3c:   &s3.AWS{Client: httpClient},
 		stash: NewFileStash(runtimeDir),
+		cache: NewDiskAssetCache(log, filepath.Join(runtimeDir, "chat_attachment_cache"), cacheQuotaBytes),
 	}
 }
 
@@ -81,10 +91,12 @@ func NewAttachmentStore(log logger.Logger, runtimeDir string) *AttachmentStore {
 // It uses an in-memory s3 interface, reports enc/sig keys, and allows limiting
 // the number of blocks uploaded.
 func newAttachmentStoreTesting(log logger.Logger, kt func(enc, sig []byte)) *AttachmentStore {
+	tempdir, _ := ioutil.TempDir(os.TempDir(), "attachment_store_test")
 	return &AttachmentStore{
 		log:       log,
 		s3c:       &s3.Mem{},
 		stash:     NewFileStash(os.TempDir()),
+		cache:     NewDiskAssetCache(log, filepath.Join(tempdir, "chat_attachment_cache"), 0),
 		keyTester: kt,
 		testing:   true,
 	}
@@ -201,6 +213,17 @@ func (a *AttachmentStore) DownloadAsset(ctx context.Context, params chat1.S3Para
 	if asset.Key == nil || asset.VerifyKey == nil || asset.EncHash == nil {
 		return fmt.Errorf("unencrypted attachments not supported")
 	}
+
+	if data, found := a.cache.Get(asset.EncHash); found {
+		a.log.Debug("DownloadAsset: serving %s from local cache", asset.Path)
+		progWriter := newProgressWriter(progress, asset.Size)
+		if _, err := io.Copy(w, io.TeeReader(bytes.NewReader(data), progWriter)); err != nil {
+			return err
+		}
+		progWriter.Finish()
+		return nil
+	}
+
 	region := a.regionFromAsset(asset)
 	b := a.s3Conn(signer, region, params.AccessKey).Bucket(asset.Bucket)
 
@@ -234,7 +257,8 @@ func (a *AttachmentStore) DownloadAsset(ctx context.Context, params chat1.S3Para
 		decBody = dec.Decrypt(tee, asset.Key, asset.VerifyKey)
 	}
 
-	n, err := io.Copy(w, decBody)
+	var plaintext bytes.Buffer
+	n, err := io.Copy(io.MultiWriter(w, &plaintext), decBody)
 	if err != nil {
 		return err
 	}
@@ -248,6 +272,10 @@ func (a *AttachmentStore) DownloadAsset(ctx context.Context, params chat1.S3Para
 	}
 	a.log.Debug("attachment content hash is valid")
 
+	if err := a.cache.Put(asset.EncHash, plaintext.Bytes()); err != nil {
+		a.log.Debug("DownloadAsset: failed to cache %s: %s", asset.Path, err)
+	}
+
 	return nil
 }
 
@@ -0,0 +1,160 @@
+package chat
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/keybase/client/go/logger"
+)
+
+// DiskAssetCache is a local, quota-bounded cache of decrypted chat
+// attachment bytes (full-size images/videos as well as their previews),
+// keyed by the asset's ciphertext hash. It exists so that re-viewing an
+// attachment (e.g. scrolling back over a thread, or re-opening a
+// preview thumbnail) doesn't require re-downloading and re-decrypting it
+// from S3 every time.
+//
+// Like AttachmentStash, entries live in a plain directory rather than in
+// JSONLocalDb: attachments are arbitrarily large binary blobs, which
+// doesn't fit LocalDb's small-JSON-document usage pattern. Eviction
+// tracking mirrors libkb's dbUsageTracker (see go/libkb/db.go): an
+// in-memory LRU list bounds total bytes on disk to a configurable quota,
+// evicting the least-recently-used assets first. The tracker only knows
+// about entries it has seen since the cache was constructed, so a quota
+// change takes effect gradually as new assets are stored, not
+// retroactively.
+type DiskAssetCache struct {
+	sync.Mutex
+	dir   string
+	quota int64
+	log   logger.Logger
+
+	order *list.List               // LRU at the front, MRU at the back
+	elems map[string]*list.Element // key -> its node in order
+	sizes map[string]int64         // key -> file size on disk
+}
+
+// NewDiskAssetCache creates a DiskAssetCache rooted at dir, which is
+// created if it does not already exist. quota is the total number of
+// bytes the cache may occupy on disk; a non-positive quota disables
+// eviction.
+func NewDiskAssetCache(log logger.Logger, dir string, quota int64) *DiskAssetCache {
+	return &DiskAssetCache{
+		dir:   dir,
+		quota: quota,
+		log:   log,
+		order: list.New(),
+		elems: make(map[string]*list.Element),
+		sizes: make(map[string]int64),
+	}
+}
+
+// cacheKey turns an asset's ciphertext hash into a cache-safe filename.
+func cacheKey(encHash []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(encHash))
+}
+
+func (d *DiskAssetCache) path(key string) string {
+	return filepath.Join(d.dir, key)
+}
+
+// Get returns the decrypted contents previously stored for encHash, if
+// present. The second return value is false on a cache miss.
+func (d *DiskAssetCache) Get(encHash []byte) ([]byte, bool) {
+	key := cacheKey(encHash)
+
+	d.Lock()
+	_, found := d.elems[key]
+	d.Unlock()
+	if !found {
+		return nil, false
+	}
+
+	data, err := ioutil.ReadFile(d.path(key))
+	if err != nil {
+		d.log.Debug("DiskAssetCache: Get(%s) failed to read, forgetting entry: %s", key, err)
+		d.Lock()
+		d.removeLocked(key)
+		d.Unlock()
+		return nil, false
+	}
+
+	d.Lock()
+	d.touchLocked(key)
+	d.Unlock()
+	return data, true
+}
+
+// Put stores decrypted attachment bytes under encHash, evicting
+// least-recently-used entries as necessary to stay under quota.
+func (d *DiskAssetCache) Put(encHash []byte, data []byte) error {
+	if err := os.MkdirAll(d.dir, 0700); err != nil {
+		return err
+	}
+
+	key := cacheKey(encHash)
+	if err := ioutil.WriteFile(d.path(key), data, 0600); err != nil {
+		return err
+	}
+
+	d.Lock()
+	evict := d.recordPutLocked(key, int64(len(data)))
+	d.Unlock()
+
+	for _, victim := range evict {
+		if err := os.Remove(d.path(victim)); err != nil && !os.IsNotExist(err) {
+			d.log.Debug("DiskAssetCache: failed to evict %s over quota: %s", victim, err)
+		}
+	}
+	return nil
+}
+
+func (d *DiskAssetCache) touchLocked(key string) {
+	if elem, ok := d.elems[key]; ok {
+		d.order.MoveToBack(elem)
+	}
+}
+
+// recordPutLocked records that key now occupies size bytes and returns
+// the keys of whichever least-recently-used entries (other than key)
+// must be evicted to bring total usage back under quota.
+func (d *DiskAssetCache) recordPutLocked(key string, size int64) (evict []string) {
+	d.removeLocked(key)
+	d.sizes[key] = size
+	d.elems[key] = d.order.PushBack(key)
+
+	if d.quota <= 0 {
+		return nil
+	}
+	for d.totalLocked() > d.quota && d.order.Len() > 1 {
+		front := d.order.Front()
+		victim := front.Value.(string)
+		if victim == key {
+			break
+		}
+		evict = append(evict, victim)
+		d.removeLocked(victim)
+	}
+	return evict
+}
+
+func (d *DiskAssetCache) removeLocked(key string) {
+	if elem, ok := d.elems[key]; ok {
+		d.order.Remove(elem)
+		delete(d.elems, key)
+	}
+	delete(d.sizes, key)
+}
+
+func (d *DiskAssetCache) totalLocked() int64 {
+	var total int64
+	for _, size := range d.sizes {
+		total += size
+	}
+	return total
+}
@@ -0,0 +1,59 @@
+package chat
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/keybase/client/go/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDiskAssetCache(t *testing.T, quota int64) (*DiskAssetCache, func()) {
+	dir, err := ioutil.TempDir(os.TempDir(), "attachment_cache_test")
+	require.NoError(t, err)
+	cache := NewDiskAssetCache(logger.NewTestLogger(t), dir, quota)
+	return cache, func() { os.RemoveAll(dir) }
+}
+
+func TestDiskAssetCacheGetPut(t *testing.T) {
+	cache, cleanup := newTestDiskAssetCache(t, 0)
+	defer cleanup()
+
+	hash := []byte("some content hash")
+	if _, found := cache.Get(hash); found {
+		t.Fatal("expected miss before Put")
+	}
+
+	require.NoError(t, cache.Put(hash, []byte("the decrypted attachment bytes")))
+
+	data, found := cache.Get(hash)
+	require.True(t, found)
+	require.Equal(t, "the decrypted attachment bytes", string(data))
+}
+
+func TestDiskAssetCacheEvictsLRU(t *testing.T) {
+	cache, cleanup := newTestDiskAssetCache(t, 25)
+	defer cleanup()
+
+	a, b, c := []byte("a"), []byte("b"), []byte("c")
+	require.NoError(t, cache.Put(a, make([]byte, 10)))
+	require.NoError(t, cache.Put(b, make([]byte, 10)))
+
+	// touch a, making b the least-recently-used entry
+	_, found := cache.Get(a)
+	require.True(t, found)
+
+	// total is now 30, over the quota of 25: b should be evicted, not a
+	require.NoError(t, cache.Put(c, make([]byte, 10)))
+
+	if _, found := cache.Get(b); found {
+		t.Fatal("expected b to have been evicted")
+	}
+	if _, found := cache.Get(a); !found {
+		t.Fatal("expected a to still be cached")
+	}
+	if _, found := cache.Get(c); !found {
+		t.Fatal("expected c to still be cached")
+	}
+}
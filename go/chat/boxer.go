@@ -355,8 +355,11 @@ func (b *Boxer) unboxV1(ctx context.Context, boxed chat1.MessageBoxed, encryptio
 			Sender:       hp.Sender,
 			SenderDevice: hp.SenderDevice,
 			// CORE-4540: MerkleRoot will be in signed header, but probably not in any V1 messages.
-			OutboxID:   hp.OutboxID,
-			OutboxInfo: hp.OutboxInfo,
+			OutboxID:          hp.OutboxID,
+			OutboxInfo:        hp.OutboxInfo,
+			EphemeralLifetime: hp.EphemeralLifetime,
+			AtMentions:        hp.AtMentions,
+			AtMentionsChannel: hp.AtMentionsChannel,
 		}
 	default:
 		return nil,
@@ -556,8 +559,11 @@ func (b *Boxer) unversionHeader(ctx context.Context, headerVersioned chat1.Heade
 			Sender:       hp.Sender,
 			SenderDevice: hp.SenderDevice,
 			// CORE-4540: MerkleRoot will be in signed header.
-			OutboxID:   hp.OutboxID,
-			OutboxInfo: hp.OutboxInfo,
+			OutboxID:          hp.OutboxID,
+			OutboxInfo:        hp.OutboxInfo,
+			EphemeralLifetime: hp.EphemeralLifetime,
+			AtMentions:        hp.AtMentions,
+			AtMentionsChannel: hp.AtMentionsChannel,
 		}, hp.BodyHash, nil
 	default:
 		return chat1.MessageClientHeaderVerified{}, nil,
@@ -671,9 +677,52 @@ func (b *Boxer) compareHeadersV2(ctx context.Context, hServer chat1.MessageClien
 		return NewPermanentUnboxingError(NewHeaderMismatchError("OutboxInfo"))
 	}
 
+	// EphemeralLifetime
+	if !eqEphemeralLifetime(hServer.EphemeralLifetime, hSigned.EphemeralLifetime) {
+		return NewPermanentUnboxingError(NewHeaderMismatchError("EphemeralLifetime"))
+	}
+
+	// AtMentions / AtMentionsChannel
+	if !eqAtMentions(hServer.AtMentions, hSigned.AtMentions) || hServer.AtMentionsChannel != hSigned.AtMentionsChannel {
+		return NewPermanentUnboxingError(NewHeaderMismatchError("AtMentions"))
+	}
+
 	return nil
 }
 
+// eqEphemeralLifetime compares two *int ephemeral lifetimes, treating nil
+// and 0 as equivalent (both mean "not ephemeral").
+func eqEphemeralLifetime(a, b *int) bool {
+	deref := func(p *int) int {
+		if p == nil {
+			return 0
+		}
+		return *p
+	}
+	return deref(a) == deref(b)
+}
+
+// eqAtMentions compares two @-mention username lists order-independently,
+// since a client only cares who was mentioned, not the order they appear in.
+func eqAtMentions(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	count := make(map[string]int)
+	for _, name := range a {
+		count[name]++
+	}
+	for _, name := range b {
+		count[name]--
+	}
+	for _, c := range count {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
+
 func (b *Boxer) makeHeaderHash(ctx context.Context, headerSealed chat1.SignEncryptedData) (chat1.Hash, UnboxingError) {
 	buf := bytes.Buffer{}
 	err := binary.Write(&buf, binary.BigEndian, int32(headerSealed.V))
@@ -883,9 +932,12 @@ func (b *Boxer) boxV1(messagePlaintext chat1.MessagePlaintext, key *keybase1.Cry
 		Sender:       messagePlaintext.ClientHeader.Sender,
 		SenderDevice: messagePlaintext.ClientHeader.SenderDevice,
 		// CORE-4540: Add MerkleRoot to signed header.
-		BodyHash:   bodyHash[:],
-		OutboxInfo: messagePlaintext.ClientHeader.OutboxInfo,
-		OutboxID:   messagePlaintext.ClientHeader.OutboxID,
+		BodyHash:          bodyHash[:],
+		OutboxInfo:        messagePlaintext.ClientHeader.OutboxInfo,
+		OutboxID:          messagePlaintext.ClientHeader.OutboxID,
+		EphemeralLifetime: messagePlaintext.ClientHeader.EphemeralLifetime,
+		AtMentions:        messagePlaintext.ClientHeader.AtMentions,
+		AtMentionsChannel: messagePlaintext.ClientHeader.AtMentionsChannel,
 	}
 
 	// sign the header and insert the signature
@@ -939,9 +991,12 @@ func (b *Boxer) boxV2(messagePlaintext chat1.MessagePlaintext, encryptionKey *ke
 		Sender:       messagePlaintext.ClientHeader.Sender,
 		SenderDevice: messagePlaintext.ClientHeader.SenderDevice,
 		// CORE-4540: Add MerkleRoot to signed header.
-		BodyHash:   bodyHash,
-		OutboxInfo: messagePlaintext.ClientHeader.OutboxInfo,
-		OutboxID:   messagePlaintext.ClientHeader.OutboxID,
+		BodyHash:          bodyHash,
+		OutboxInfo:        messagePlaintext.ClientHeader.OutboxInfo,
+		OutboxID:          messagePlaintext.ClientHeader.OutboxID,
+		EphemeralLifetime: messagePlaintext.ClientHeader.EphemeralLifetime,
+		AtMentions:        messagePlaintext.ClientHeader.AtMentions,
+		AtMentionsChannel: messagePlaintext.ClientHeader.AtMentionsChannel,
 		// In MessageBoxed.V2 HeaderSignature is nil.
 		HeaderSignature: nil,
 	})
@@ -1317,5 +1372,15 @@ func (b *Boxer) compareHeadersV1(ctx context.Context, hServer chat1.MessageClien
 
 	// OutboxID, OutboxInfo: Left unchecked as I'm not sure whether these hold in V1 messages.
 
+	// EphemeralLifetime
+	if !eqEphemeralLifetime(hServer.EphemeralLifetime, hSigned.EphemeralLifetime) {
+		return NewPermanentUnboxingError(NewHeaderMismatchError("EphemeralLifetime"))
+	}
+
+	// AtMentions / AtMentionsChannel
+	if !eqAtMentions(hServer.AtMentions, hSigned.AtMentions) || hServer.AtMentionsChannel != hSigned.AtMentionsChannel {
+		return NewPermanentUnboxingError(NewHeaderMismatchError("AtMentions"))
+	}
+
 	return nil
 }
@@ -12,11 +12,13 @@ type identifyModeKey int
 type keyfinderKey int
 type identifyNotifierKey int
 type chatTrace int
+type skipIdentifyKey int
 
 var identModeKey identifyModeKey
 var kfKey keyfinderKey
 var inKey identifyNotifierKey
 var chatTraceKey chatTrace
+var skipIdentifyCtxKey skipIdentifyKey
 
 type identModeData struct {
 	mode   keybase1.TLFIdentifyBehavior
@@ -37,6 +39,21 @@ func IdentifyMode(ctx context.Context) (ib keybase1.TLFIdentifyBehavior, breaks
 	return keybase1.TLFIdentifyBehavior_CHAT_CLI, nil, false
 }
 
+// WithIdentifyDisabled returns a context that instructs TLF crypt-key
+// resolution done with it to skip identify entirely, regardless of the
+// TLFIdentifyBehavior otherwise in play. Intended for background jobs
+// (e.g. data migrations) that need keys but have no UI to surface an
+// identify failure to.
+func WithIdentifyDisabled(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipIdentifyCtxKey, true)
+}
+
+// IdentifyDisabled reports whether ctx was built with WithIdentifyDisabled.
+func IdentifyDisabled(ctx context.Context) bool {
+	skip, _ := ctx.Value(skipIdentifyCtxKey).(bool)
+	return skip
+}
+
 func CtxKeyFinder(ctx context.Context) KeyFinder {
 	var kf KeyFinder
 	var ok bool
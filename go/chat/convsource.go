@@ -78,6 +78,12 @@ func (s *baseConversationSource) postProcessThread(ctx context.Context, uid greg
 		}
 	}
 
+	// Aggregate reactions onto the messages they target. This runs after
+	// supersedes resolution so that a reaction deleted via the ordinary
+	// DELETE mechanism is already gone from thread.Messages and doesn't
+	// get aggregated.
+	thread.Messages = Reactions(thread.Messages)
+
 	// Run type filter if it exists
 	thread.Messages = utils.FilterByType(thread.Messages, q, true)
 
@@ -174,6 +174,11 @@ func (f failingRemote) SetConversationStatus(context.Context, chat1.SetConversat
 	require.Fail(f.t, "SetConversationStatus call")
 	return chat1.SetConversationStatusRes{}, nil
 }
+func (f failingRemote) ReportConversation(context.Context, chat1.ReportConversationArg) (chat1.ReportConversationRes, error) {
+
+	require.Fail(f.t, "ReportConversation call")
+	return chat1.ReportConversationRes{}, nil
+}
 func (f failingRemote) GetUnreadUpdateFull(context.Context, chat1.InboxVers) (chat1.UnreadUpdateFull, error) {
 
 	require.Fail(f.t, "GetUnreadUpdateFull call")
@@ -244,6 +249,26 @@ func (f failingTlf) CompleteAndCanonicalizePrivateTlfName(context.Context, keyba
 	return keybase1.CanonicalTLFNameAndIDWithBreaks{}, nil
 }
 
+func (f failingTlf) CryptKeysBatch(context.Context, keybase1.TLFQueryBatch) ([]keybase1.CryptKeysBatchItem, error) {
+	require.Fail(f.t, "CryptKeysBatch call")
+	return nil, nil
+}
+
+func (f failingTlf) SetTlfIdentifyBehavior(context.Context, keybase1.SetTlfIdentifyBehaviorArg) error {
+	require.Fail(f.t, "SetTlfIdentifyBehavior call")
+	return nil
+}
+
+func (f failingTlf) GetTlfIdentifyBehavior(context.Context, string) (*keybase1.TLFIdentifyBehavior, error) {
+	require.Fail(f.t, "GetTlfIdentifyBehavior call")
+	return nil, nil
+}
+
+func (f failingTlf) ClearTlfIdentifyBehavior(context.Context, string) error {
+	require.Fail(f.t, "ClearTlfIdentifyBehavior call")
+	return nil
+}
+
 type failingUpak struct {
 	t *testing.T
 }
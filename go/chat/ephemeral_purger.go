@@ -0,0 +1,98 @@
+package chat
+
+import (
+	"context"
+
+	"github.com/keybase/client/go/chat/storage"
+	"github.com/keybase/client/go/chat/utils"
+	"github.com/keybase/client/go/libkb"
+	"github.com/keybase/client/go/protocol/gregor1"
+)
+
+// BackgroundEphemeralPurger periodically sweeps a user's locally cached
+// conversations, blanking the bodies of ephemeral messages whose lifetime
+// has elapsed (see Storage.PurgeExpiredEphemeral). It only ever touches the
+// local cache: an ephemeral message's body is still sitting on the server
+// (and on any other device that hasn't purged it yet) until that message is
+// deleted the normal way. There is no key rotation or wire-level "explode
+// for everyone" signal here -- doing that safely needs new server support,
+// which is out of scope for this client-only change.
+type BackgroundEphemeralPurger struct {
+	libkb.Contextified
+	utils.DebugLabeler
+
+	shutdownCh chan chan struct{}
+	uid        gregor1.UID
+}
+
+func NewBackgroundEphemeralPurger(g *libkb.GlobalContext) *BackgroundEphemeralPurger {
+	p := &BackgroundEphemeralPurger{
+		Contextified: libkb.NewContextified(g),
+		DebugLabeler: utils.NewDebugLabeler(g, "BackgroundEphemeralPurger", false),
+		shutdownCh:   make(chan chan struct{}, 1),
+	}
+
+	g.PushShutdownHook(func() error {
+		p.Stop(context.Background())
+		return nil
+	})
+
+	return p
+}
+
+func (p *BackgroundEphemeralPurger) Start(ctx context.Context, uid gregor1.UID) {
+	p.uid = uid
+	go p.purgeLoop()
+}
+
+func (p *BackgroundEphemeralPurger) Stop(ctx context.Context) chan struct{} {
+	cb := make(chan struct{})
+	select {
+	case p.shutdownCh <- cb:
+	default:
+		// Not started, or already stopped.
+		close(cb)
+	}
+	return cb
+}
+
+func (p *BackgroundEphemeralPurger) purgeLoop() {
+	bgctx := context.Background()
+	p.Debug(bgctx, "starting background ephemeral purge loop: uid: %s interval: %v",
+		p.uid, p.G().Env.GetChatEphemeralPurgeInterval())
+	for {
+		select {
+		case cb := <-p.shutdownCh:
+			p.Debug(bgctx, "shutting down ephemeral purge loop: uid: %s", p.uid)
+			close(cb)
+			return
+		case <-p.G().Clock().After(p.G().Env.GetChatEphemeralPurgeInterval()):
+		}
+		p.purgeAllConversations(bgctx)
+	}
+}
+
+func (p *BackgroundEphemeralPurger) purgeAllConversations(ctx context.Context) {
+	secretUI := func() libkb.SecretUI { return DelivererSecretUI{} }
+	_, convs, err := storage.NewInbox(p.G(), p.uid, secretUI).ReadAll(ctx)
+	if err != nil {
+		if _, ok := err.(storage.MissError); !ok {
+			p.Debug(ctx, "purgeAllConversations: unable to read inbox: %s", err.Error())
+		}
+		return
+	}
+
+	st := storage.New(p.G(), secretUI)
+	for _, conv := range convs {
+		convID := conv.GetConvID()
+		purged, err := st.PurgeExpiredEphemeral(ctx, convID, p.uid, p.G().Clock().Now())
+		if err != nil {
+			p.Debug(ctx, "purgeAllConversations: convID: %s: %s", convID, err.Error())
+			continue
+		}
+		if len(purged) > 0 {
+			p.Debug(ctx, "purgeAllConversations: convID: %s: purged %d expired messages",
+				convID, len(purged))
+		}
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/keybase/client/go/badges"
 	"github.com/keybase/client/go/chat/pager"
@@ -18,11 +20,29 @@ import (
 	"github.com/keybase/go-codec/codec"
 )
 
+// typingLifetime bounds how long a single "started typing" update is
+// believed for. A GUI that keeps a user in the "typing" state for longer than
+// this is expected to call StartTyping again to refresh it. If a StopTyping
+// (or the sender's device) never arrives, the typer is dropped once this
+// expires and a fresh ChatTypingUpdate goes out without it.
+const typingLifetime = 10 * time.Second
+
+// typer is used as a map key in PushHandler.typingStatus, so its fields are
+// the hex string forms of the gregor1 IDs (gregor1.UID/DeviceID are byte
+// slices and thus not comparable) rather than the IDs themselves.
+type typer struct {
+	Uid      string
+	DeviceID string
+}
+
 type PushHandler struct {
 	libkb.Contextified
 	utils.DebugLabeler
 
 	identNotifier *IdentifyNotifier
+
+	typingMu     sync.Mutex
+	typingStatus map[string]map[typer]*time.Timer
 }
 
 func NewPushHandler(g *libkb.GlobalContext) *PushHandler {
@@ -30,6 +50,7 @@ func NewPushHandler(g *libkb.GlobalContext) *PushHandler {
 		Contextified:  libkb.NewContextified(g),
 		DebugLabeler:  utils.NewDebugLabeler(g, "PushHandler", false),
 		identNotifier: NewIdentifyNotifier(g),
+		typingStatus:  make(map[string]map[typer]*time.Timer),
 	}
 }
 
@@ -116,6 +137,74 @@ func (g *PushHandler) TlfResolve(ctx context.Context, m gregor.OutOfBandMessage)
 	return nil
 }
 
+func (g *PushHandler) Typing(ctx context.Context, m gregor.OutOfBandMessage) error {
+	if m.Body() == nil {
+		return errors.New("gregor handler for chat.typing: nil message body")
+	}
+
+	var update chat1.TypingUpdate
+	reader := bytes.NewReader(m.Body().Bytes())
+	dec := codec.NewDecoder(reader, &codec.MsgpackHandle{WriteExt: true})
+	if err := dec.Decode(&update); err != nil {
+		return err
+	}
+
+	who := typer{Uid: gregor1.UID(m.UID().Bytes()).String(), DeviceID: update.DeviceID.String()}
+	uids := g.setTyping(update.ConvID, who, update.Typing)
+	g.G().NotifyRouter.HandleChatTypingUpdate(context.Background(), []chat1.ConvTypingUpdate{
+		{ConvID: update.ConvID, Uids: uids},
+	})
+
+	return nil
+}
+
+// setTyping records that who has started or stopped typing in convID,
+// (re)arming an expiry timer for "started" so a lost stop update can't wedge
+// the indicator on forever. It returns the current set of UIDs typing in
+// convID, deduped across devices.
+func (g *PushHandler) setTyping(convID chat1.ConversationID, who typer, typing bool) []keybase1.UID {
+	g.typingMu.Lock()
+	defer g.typingMu.Unlock()
+
+	key := convID.String()
+	status := g.typingStatus[key]
+	if status == nil {
+		status = make(map[typer]*time.Timer)
+		g.typingStatus[key] = status
+	}
+	if existing, ok := status[who]; ok {
+		existing.Stop()
+		delete(status, who)
+	}
+	if typing {
+		status[who] = time.AfterFunc(typingLifetime, func() {
+			g.expireTyping(convID, who)
+		})
+	}
+	if len(status) == 0 {
+		delete(g.typingStatus, key)
+	}
+
+	seen := make(map[string]bool)
+	var uids []keybase1.UID
+	for t := range status {
+		if seen[t.Uid] {
+			continue
+		}
+		seen[t.Uid] = true
+		uids = append(uids, keybase1.UID(t.Uid))
+	}
+	return uids
+}
+
+func (g *PushHandler) expireTyping(convID chat1.ConversationID, who typer) {
+	g.Debug(context.Background(), "expiring stale typer: convID: %s uid: %s", convID, who.Uid)
+	uids := g.setTyping(convID, who, false)
+	g.G().NotifyRouter.HandleChatTypingUpdate(context.Background(), []chat1.ConvTypingUpdate{
+		{ConvID: convID, Uids: uids},
+	})
+}
+
 func (g *PushHandler) Activity(ctx context.Context, m gregor.OutOfBandMessage, badger *badges.Badger) (err error) {
 	defer g.Trace(ctx, func() error { return err }, "Activity")()
 	if m.Body() == nil {
@@ -166,6 +255,10 @@ func (g *PushHandler) Activity(ctx context.Context, m gregor.OutOfBandMessage, b
 			g.Debug(ctx, "chat activity: unable to update inbox: %s", err.Error())
 		}
 
+		// A muted conversation still syncs normally, but per
+		// ConversationStatus_MUTED's contract it never badges or alerts.
+		muted := conv != nil && conv.Info.Status == chat1.ConversationStatus_MUTED
+
 		// If we have no error on this message, then notify the frontend
 		if pushErr == nil {
 			// Make a pagination object so client can use it in GetThreadLocal
@@ -181,6 +274,10 @@ func (g *PushHandler) Activity(ctx context.Context, m gregor.OutOfBandMessage, b
 				Conv:       conv,
 				Pagination: page,
 			})
+
+			if !muted {
+				g.notifyMentioned(ctx, keybase1.UID(m.UID().String()), nm.ConvID, decmsg)
+			}
 		}
 
 		// If this message was not "appended", meaning there is a hole between what we have in cache,
@@ -200,7 +297,7 @@ func (g *PushHandler) Activity(ctx context.Context, m gregor.OutOfBandMessage, b
 				[]chat1.ConversationID{nm.ConvID})
 		}
 
-		if badger != nil && nm.UnreadUpdate != nil {
+		if badger != nil && nm.UnreadUpdate != nil && !muted {
 			badger.PushChatUpdate(*nm.UnreadUpdate, nm.InboxVers)
 		}
 	case "readMessage":
@@ -302,3 +399,34 @@ func (g *PushHandler) notifyNewChatActivity(ctx context.Context, uid gregor.UID,
 	g.G().NotifyRouter.HandleNewChatActivity(ctx, kbUID, activity)
 	return nil
 }
+
+// notifyMentioned fires a ChatAttentionUpdate if msg @-mentions the current
+// user, either by username or via @channel/@here. It's a no-op for a
+// message the current user sent themselves -- sending a message that
+// happens to mention your own username shouldn't badge your own client.
+func (g *PushHandler) notifyMentioned(ctx context.Context, uid keybase1.UID, convID chat1.ConversationID,
+	msg chat1.MessageUnboxed) {
+	if !msg.IsValid() {
+		return
+	}
+	mvalid := msg.Valid()
+	if mvalid.ClientHeader.Sender.Eq(gregor1.UID(uid.ToBytes())) {
+		return
+	}
+
+	myUsername := string(g.G().Env.GetUsername())
+	mentioned := mvalid.ClientHeader.AtMentionsChannel
+	if !mentioned {
+		for _, name := range mvalid.ClientHeader.AtMentions {
+			if name == myUsername {
+				mentioned = true
+				break
+			}
+		}
+	}
+	if !mentioned {
+		return
+	}
+
+	g.G().NotifyRouter.HandleChatAttentionUpdate(ctx, uid, convID, msg.GetMessageID())
+}
@@ -0,0 +1,40 @@
+package chat
+
+import "github.com/keybase/client/go/protocol/chat1"
+
+// maxPushPreviewBodyLen bounds how much of a message body
+// BuildMobilePushNotificationPreview returns, since the result is meant for a
+// one-line OS notification banner, not for actually reading the message.
+const maxPushPreviewBodyLen = 100
+
+// BuildMobilePushNotificationPreview reduces an already-unboxed, valid message
+// down to a short sender/snippet preview suitable for an APNS/FCM notification
+// banner. It does no unboxing itself -- callers are expected to have already
+// unboxed the message (see Boxer.UnboxMessage) so this stays a pure function
+// over plaintext that's already been produced and verified.
+func BuildMobilePushNotificationPreview(valid chat1.MessageUnboxedValid) (chat1.MobilePushNotificationPreview, error) {
+	res := chat1.MobilePushNotificationPreview{
+		SenderUsername: valid.SenderUsername,
+	}
+
+	typ, err := valid.MessageBody.MessageType()
+	if err != nil {
+		return res, err
+	}
+
+	switch typ {
+	case chat1.MessageType_TEXT:
+		body := valid.MessageBody.Text().Body
+		if len(body) > maxPushPreviewBodyLen {
+			body = body[:maxPushPreviewBodyLen] + "…"
+		}
+		res.Body = body
+		res.IsPlaintext = true
+	case chat1.MessageType_ATTACHMENT:
+		res.Body = "sent an attachment"
+	default:
+		res.Body = "sent a message"
+	}
+
+	return res, nil
+}
@@ -0,0 +1,43 @@
+package chat
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/keybase/client/go/protocol/chat1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildMobilePushNotificationPreviewText(t *testing.T) {
+	valid := chat1.MessageUnboxedValid{
+		SenderUsername: "alice",
+		MessageBody:    chat1.NewMessageBodyWithText(chat1.MessageText{Body: "hey, you around?"}),
+	}
+	preview, err := BuildMobilePushNotificationPreview(valid)
+	require.NoError(t, err)
+	require.Equal(t, "alice", preview.SenderUsername)
+	require.Equal(t, "hey, you around?", preview.Body)
+	require.True(t, preview.IsPlaintext)
+}
+
+func TestBuildMobilePushNotificationPreviewTruncates(t *testing.T) {
+	longBody := strings.Repeat("a", maxPushPreviewBodyLen+50)
+	valid := chat1.MessageUnboxedValid{
+		SenderUsername: "bob",
+		MessageBody:    chat1.NewMessageBodyWithText(chat1.MessageText{Body: longBody}),
+	}
+	preview, err := BuildMobilePushNotificationPreview(valid)
+	require.NoError(t, err)
+	require.Equal(t, strings.Repeat("a", maxPushPreviewBodyLen)+"…", preview.Body)
+}
+
+func TestBuildMobilePushNotificationPreviewNonText(t *testing.T) {
+	valid := chat1.MessageUnboxedValid{
+		SenderUsername: "carol",
+		MessageBody:    chat1.NewMessageBodyWithAttachment(chat1.MessageAttachment{}),
+	}
+	preview, err := BuildMobilePushNotificationPreview(valid)
+	require.NoError(t, err)
+	require.Equal(t, "sent an attachment", preview.Body)
+	require.False(t, preview.IsPlaintext)
+}
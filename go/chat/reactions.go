@@ -0,0 +1,67 @@
+package chat
+
+import (
+	"github.com/keybase/client/go/protocol/chat1"
+)
+
+// reactionKey identifies one user's reaction of a given emoji on a given
+// target message, for deduping repeat reactions from the same user.
+type reactionKey struct {
+	targetMsgID chat1.MessageID
+	username    string
+	body        string
+}
+
+// Reactions aggregates every REACTION message in msgs onto the Reactions
+// field of the MessageUnboxedValid it targets. Like basicSupersedesTransform,
+// it only ever sees the messages it's handed -- a reaction targeting a
+// message outside of msgs is silently dropped. Callers should run this
+// after supersedes resolution, so a reaction that was itself deleted via
+// the ordinary DELETE mechanism has already been dropped from msgs and
+// doesn't get aggregated.
+func Reactions(msgs []chat1.MessageUnboxed) []chat1.MessageUnboxed {
+	byTarget := make(map[chat1.MessageID][]chat1.ReactionUpdate)
+	seen := make(map[reactionKey]bool)
+
+	for _, msg := range msgs {
+		if !msg.IsValid() || msg.GetMessageType() != chat1.MessageType_REACTION {
+			continue
+		}
+		mvalid := msg.Valid()
+		reaction := mvalid.MessageBody.Reaction()
+		key := reactionKey{
+			targetMsgID: reaction.MessageID,
+			username:    mvalid.SenderUsername,
+			body:        reaction.Body,
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		byTarget[reaction.MessageID] = append(byTarget[reaction.MessageID], chat1.ReactionUpdate{
+			Body:          reaction.Body,
+			Username:      mvalid.SenderUsername,
+			ReactionMsgID: msg.GetMessageID(),
+		})
+	}
+	if len(byTarget) == 0 {
+		return msgs
+	}
+
+	res := make([]chat1.MessageUnboxed, len(msgs))
+	copy(res, msgs)
+	for index, msg := range res {
+		if !msg.IsValid() {
+			continue
+		}
+		updates, ok := byTarget[msg.GetMessageID()]
+		if !ok {
+			continue
+		}
+		mvalid := msg.Valid()
+		mvalid.Reactions = chat1.ReactionMap{Reactions: updates}
+		res[index] = chat1.NewMessageUnboxedWithValid(mvalid)
+	}
+
+	return res
+}
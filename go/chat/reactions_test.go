@@ -0,0 +1,61 @@
+package chat
+
+import (
+	"testing"
+
+	"github.com/keybase/client/go/protocol/chat1"
+	"github.com/stretchr/testify/require"
+)
+
+func reactionMessage(id, targetID chat1.MessageID, username, body string) chat1.MessageUnboxed {
+	return chat1.NewMessageUnboxedWithValid(chat1.MessageUnboxedValid{
+		ServerHeader:   chat1.MessageServerHeader{MessageID: id},
+		ClientHeader:   chat1.MessageClientHeaderVerified{MessageType: chat1.MessageType_REACTION},
+		SenderUsername: username,
+		MessageBody: chat1.NewMessageBodyWithReaction(chat1.MessageReaction{
+			MessageID: targetID,
+			Body:      body,
+		}),
+	})
+}
+
+func TestReactionsAggregatesOntoTarget(t *testing.T) {
+	msgs := []chat1.MessageUnboxed{
+		textMessage(1, "hi"),
+		reactionMessage(2, 1, "alice", ":+1:"),
+		reactionMessage(3, 1, "bob", ":+1:"),
+		reactionMessage(4, 1, "alice", ":tada:"),
+	}
+
+	res := Reactions(msgs)
+	require.Len(t, res, 4)
+	require.Equal(t, []chat1.ReactionUpdate{
+		{Body: ":+1:", Username: "alice", ReactionMsgID: 2},
+		{Body: ":+1:", Username: "bob", ReactionMsgID: 3},
+		{Body: ":tada:", Username: "alice", ReactionMsgID: 4},
+	}, res[0].Valid().Reactions.Reactions)
+}
+
+func TestReactionsDedupsRepeatFromSameUser(t *testing.T) {
+	msgs := []chat1.MessageUnboxed{
+		textMessage(1, "hi"),
+		reactionMessage(2, 1, "alice", ":+1:"),
+		reactionMessage(3, 1, "alice", ":+1:"),
+	}
+
+	res := Reactions(msgs)
+	require.Equal(t, []chat1.ReactionUpdate{
+		{Body: ":+1:", Username: "alice", ReactionMsgID: 2},
+	}, res[0].Valid().Reactions.Reactions)
+}
+
+func TestReactionsTargetingMissingMessageIsDropped(t *testing.T) {
+	msgs := []chat1.MessageUnboxed{
+		textMessage(1, "hi"),
+		reactionMessage(2, 99, "alice", ":+1:"),
+	}
+
+	res := Reactions(msgs)
+	require.Len(t, res, 2)
+	require.Empty(t, res[0].Valid().Reactions.Reactions)
+}
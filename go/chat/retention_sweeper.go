@@ -0,0 +1,102 @@
+package chat
+
+import (
+	"context"
+	"time"
+
+	"github.com/keybase/client/go/chat/storage"
+	"github.com/keybase/client/go/chat/utils"
+	"github.com/keybase/client/go/libkb"
+	"github.com/keybase/client/go/protocol/gregor1"
+)
+
+// BackgroundRetentionSweeper periodically sweeps a user's locally cached
+// conversations, blanking the bodies of messages older than each
+// conversation's retention policy (see Storage.PurgeMessagesOlderThan). Like
+// BackgroundEphemeralPurger, it only ever touches the local cache: a swept
+// message's body is still sitting on the server (and on any other device
+// that hasn't swept it yet) until it ages out there too.
+type BackgroundRetentionSweeper struct {
+	libkb.Contextified
+	utils.DebugLabeler
+
+	shutdownCh chan chan struct{}
+	uid        gregor1.UID
+}
+
+func NewBackgroundRetentionSweeper(g *libkb.GlobalContext) *BackgroundRetentionSweeper {
+	s := &BackgroundRetentionSweeper{
+		Contextified: libkb.NewContextified(g),
+		DebugLabeler: utils.NewDebugLabeler(g, "BackgroundRetentionSweeper", false),
+		shutdownCh:   make(chan chan struct{}, 1),
+	}
+
+	g.PushShutdownHook(func() error {
+		s.Stop(context.Background())
+		return nil
+	})
+
+	return s
+}
+
+func (s *BackgroundRetentionSweeper) Start(ctx context.Context, uid gregor1.UID) {
+	s.uid = uid
+	go s.sweepLoop()
+}
+
+func (s *BackgroundRetentionSweeper) Stop(ctx context.Context) chan struct{} {
+	cb := make(chan struct{})
+	select {
+	case s.shutdownCh <- cb:
+	default:
+		// Not started, or already stopped.
+		close(cb)
+	}
+	return cb
+}
+
+func (s *BackgroundRetentionSweeper) sweepLoop() {
+	bgctx := context.Background()
+	s.Debug(bgctx, "starting background retention sweep loop: uid: %s interval: %v",
+		s.uid, s.G().Env.GetChatRetentionSweepInterval())
+	for {
+		select {
+		case cb := <-s.shutdownCh:
+			s.Debug(bgctx, "shutting down retention sweep loop: uid: %s", s.uid)
+			close(cb)
+			return
+		case <-s.G().Clock().After(s.G().Env.GetChatRetentionSweepInterval()):
+		}
+		s.sweepAllConversations(bgctx)
+	}
+}
+
+func (s *BackgroundRetentionSweeper) sweepAllConversations(ctx context.Context) {
+	secretUI := func() libkb.SecretUI { return DelivererSecretUI{} }
+	_, convs, err := storage.NewInbox(s.G(), s.uid, secretUI).ReadAll(ctx)
+	if err != nil {
+		if _, ok := err.(storage.MissError); !ok {
+			s.Debug(ctx, "sweepAllConversations: unable to read inbox: %s", err.Error())
+		}
+		return
+	}
+
+	st := storage.New(s.G(), secretUI)
+	for _, conv := range convs {
+		ageSecs := conv.Metadata.RetentionPolicy.AgeSecs
+		if ageSecs <= 0 {
+			continue
+		}
+		convID := conv.GetConvID()
+		maxAge := time.Duration(ageSecs) * time.Second
+		swept, err := st.PurgeMessagesOlderThan(ctx, convID, s.uid, maxAge, s.G().Clock().Now())
+		if err != nil {
+			s.Debug(ctx, "sweepAllConversations: convID: %s: %s", convID, err.Error())
+			continue
+		}
+		if len(swept) > 0 {
+			s.Debug(ctx, "sweepAllConversations: convID: %s: swept %d expired messages",
+				convID, len(swept))
+		}
+	}
+}
@@ -1,7 +1,19 @@
 package s3
 
-type AWS struct{}
+import "net/http"
+
+// AWS is the real S3 implementation of Root. Client is optional; when set,
+// it's used for all requests made by connections this AWS creates (see
+// AttachmentStore.s3c in ../attachment.go), letting callers route
+// attachment transfers through a proxy without New's callers needing to
+// know about that.
+type AWS struct {
+	Client *http.Client
+}
 
 func (a *AWS) New(signer Signer, region Region) Connection {
+	if a.Client != nil {
+		return New(signer, region, a.Client)
+	}
 	return New(signer, region)
 }
@@ -0,0 +1,147 @@
+package chat
+
+import (
+	"context"
+	"strings"
+
+	"github.com/keybase/client/go/chat/storage"
+	"github.com/keybase/client/go/chat/utils"
+	"github.com/keybase/client/go/libkb"
+	"github.com/keybase/client/go/protocol/chat1"
+	"github.com/keybase/client/go/protocol/gregor1"
+)
+
+// SearchHit is a single matching message, plus a few surrounding messages
+// for context, the way a search result snippet is usually shown.
+type SearchHit struct {
+	HitMessage    chat1.MessageUnboxed
+	BeforeContext []chat1.MessageUnboxed
+	AfterContext  []chat1.MessageUnboxed
+}
+
+// ConversationSearchHits collects every hit found in one conversation.
+type ConversationSearchHits struct {
+	ConvID  chat1.ConversationID
+	TlfName string
+	Hits    []SearchHit
+}
+
+// Searcher answers full-text queries against conversations the client
+// already has cached locally.
+//
+// This deliberately does not build a separate full-text index: the local
+// chat cache in go/chat/storage is already encrypted at rest (see
+// go/chat/storage/basebox.go), so scanning it directly gets "encrypted
+// on-disk index" behavior for free, and needs no invalidation logic of its
+// own beyond what clearing a conversation's cache already does -- a
+// cleared conversation simply has nothing left to scan. The tradeoff is
+// that a query costs time linear in how much of the inbox is cached
+// locally, rather than an index lookup; that's judged an acceptable
+// tradeoff for a client-side search over what's typically a modest amount
+// of cached chat history, without inventing a tokenizing/indexing
+// subsystem this repo doesn't otherwise have.
+type Searcher struct {
+	libkb.Contextified
+	utils.DebugLabeler
+}
+
+func NewSearcher(g *libkb.GlobalContext) *Searcher {
+	return &Searcher{
+		Contextified: libkb.NewContextified(g),
+		DebugLabeler: utils.NewDebugLabeler(g, "Searcher", false),
+	}
+}
+
+// messageMatchesQuery reports whether msg's text body contains query,
+// case-insensitively. Only TEXT messages are searched; edited text ends up
+// as a TEXT message once TransformSupersedes has run, so this also covers
+// the current version of an edited message.
+func messageMatchesQuery(msg chat1.MessageUnboxed, query string) bool {
+	if !msg.IsValid() || msg.GetMessageType() != chat1.MessageType_TEXT {
+		return false
+	}
+	body := msg.Valid().MessageBody.Text().Body
+	return strings.Contains(strings.ToLower(body), strings.ToLower(query))
+}
+
+// searchThread scans a single conversation's locally cached thread for
+// query, returning up to maxHits hits with beforeContext/afterContext
+// messages of surrounding conversation attached to each.
+func searchThread(thread chat1.ThreadView, query string, maxHits, beforeContext, afterContext int) []SearchHit {
+	var hits []SearchHit
+	for i, msg := range thread.Messages {
+		if len(hits) >= maxHits {
+			break
+		}
+		if !messageMatchesQuery(msg, query) {
+			continue
+		}
+
+		hit := SearchHit{HitMessage: msg}
+		for j := i - 1; j >= 0 && len(hit.BeforeContext) < beforeContext; j-- {
+			hit.BeforeContext = append(hit.BeforeContext, thread.Messages[j])
+		}
+		for j := i + 1; j < len(thread.Messages) && len(hit.AfterContext) < afterContext; j++ {
+			hit.AfterContext = append(hit.AfterContext, thread.Messages[j])
+		}
+		hits = append(hits, hit)
+	}
+	return hits
+}
+
+// SearchInbox searches locally cached conversations for query. If convID is
+// non-nil, only that conversation is searched; otherwise every conversation
+// with a local cache is. maxHits bounds the number of hits returned per
+// conversation, and beforeContext/afterContext bound how many surrounding
+// messages come back with each hit.
+func (s *Searcher) SearchInbox(ctx context.Context, uid gregor1.UID, query string,
+	convID *chat1.ConversationID, maxHits, beforeContext, afterContext int) ([]ConversationSearchHits, error) {
+
+	var convIDs []chat1.ConversationID
+	if convID != nil {
+		convIDs = append(convIDs, *convID)
+	} else {
+		_, convs, err := storage.NewInbox(s.G(), uid, func() libkb.SecretUI { return nil }).ReadAll(ctx)
+		if err != nil {
+			if _, ok := err.(storage.MissError); ok {
+				return nil, nil
+			}
+			return nil, err
+		}
+		for _, conv := range convs {
+			convIDs = append(convIDs, conv.GetConvID())
+		}
+	}
+
+	var res []ConversationSearchHits
+	for _, cid := range convIDs {
+		thread, err := s.G().ConvSource.PullLocalOnly(ctx, cid, uid, nil, nil)
+		if err != nil {
+			s.Debug(ctx, "SearchInbox: skipping convID %s, no local cache: %s", cid, err.Error())
+			continue
+		}
+		hits := searchThread(thread, query, maxHits, beforeContext, afterContext)
+		if len(hits) == 0 {
+			continue
+		}
+		res = append(res, ConversationSearchHits{
+			ConvID:  cid,
+			TlfName: threadTlfName(thread),
+			Hits:    hits,
+		})
+	}
+
+	return res, nil
+}
+
+// threadTlfName pulls the TLF name out of the first valid message header it
+// can find in thread, since raw conversation metadata (unlike a message
+// header) doesn't carry the plaintext TLF name.
+func threadTlfName(thread chat1.ThreadView) string {
+	for _, msg := range thread.Messages {
+		if msg.IsValid() {
+			return msg.Valid().ClientHeader.TlfName
+		}
+	}
+	return ""
+}
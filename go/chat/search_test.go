@@ -0,0 +1,66 @@
+package chat
+
+import (
+	"testing"
+
+	"github.com/keybase/client/go/protocol/chat1"
+	"github.com/stretchr/testify/require"
+)
+
+func textMessage(id chat1.MessageID, body string) chat1.MessageUnboxed {
+	return chat1.NewMessageUnboxedWithValid(chat1.MessageUnboxedValid{
+		ServerHeader: chat1.MessageServerHeader{
+			MessageID: id,
+		},
+		ClientHeader: chat1.MessageClientHeaderVerified{
+			MessageType: chat1.MessageType_TEXT,
+			TlfName:     "alice,bob",
+		},
+		MessageBody: chat1.NewMessageBodyWithText(chat1.MessageText{Body: body}),
+	})
+}
+
+func TestMessageMatchesQuery(t *testing.T) {
+	require.True(t, messageMatchesQuery(textMessage(1, "let's grab lunch"), "lunch"))
+	require.True(t, messageMatchesQuery(textMessage(1, "let's grab LUNCH"), "lunch"))
+	require.False(t, messageMatchesQuery(textMessage(1, "let's grab dinner"), "lunch"))
+
+	delMsg := chat1.NewMessageUnboxedWithValid(chat1.MessageUnboxedValid{
+		ServerHeader: chat1.MessageServerHeader{MessageID: 2},
+		ClientHeader: chat1.MessageClientHeaderVerified{MessageType: chat1.MessageType_DELETE},
+	})
+	require.False(t, messageMatchesQuery(delMsg, "lunch"))
+}
+
+func TestSearchThreadContext(t *testing.T) {
+	thread := chat1.ThreadView{
+		Messages: []chat1.MessageUnboxed{
+			textMessage(5, "hey there"),
+			textMessage(4, "what time works for lunch"),
+			textMessage(3, "noon?"),
+			textMessage(2, "sounds good"),
+			textMessage(1, "see you then"),
+		},
+	}
+
+	hits := searchThread(thread, "lunch", 10, 1, 1)
+	require.Len(t, hits, 1)
+	require.Equal(t, chat1.MessageID(4), hits[0].HitMessage.Valid().ServerHeader.MessageID)
+	require.Len(t, hits[0].BeforeContext, 1)
+	require.Equal(t, chat1.MessageID(5), hits[0].BeforeContext[0].Valid().ServerHeader.MessageID)
+	require.Len(t, hits[0].AfterContext, 1)
+	require.Equal(t, chat1.MessageID(3), hits[0].AfterContext[0].Valid().ServerHeader.MessageID)
+}
+
+func TestSearchThreadMaxHits(t *testing.T) {
+	thread := chat1.ThreadView{
+		Messages: []chat1.MessageUnboxed{
+			textMessage(3, "lunch today?"),
+			textMessage(2, "lunch tomorrow?"),
+			textMessage(1, "lunch next week?"),
+		},
+	}
+
+	hits := searchThread(thread, "lunch", 2, 0, 0)
+	require.Len(t, hits, 2)
+}
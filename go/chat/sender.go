@@ -75,6 +75,29 @@ func (s *BlockingSender) addSenderToMessage(msg chat1.MessagePlaintext) (chat1.M
 	return updated, nil
 }
 
+// addAtMentionsToMessage scans a TEXT or EDIT message's body for @-mentions
+// and signs the result into the header, so a receiving client can trust the
+// mention list without re-parsing (untrusted) message bodies itself.
+func (s *BlockingSender) addAtMentionsToMessage(msg chat1.MessagePlaintext) chat1.MessagePlaintext {
+	var body string
+	switch msg.ClientHeader.MessageType {
+	case chat1.MessageType_TEXT:
+		body = msg.MessageBody.Text().Body
+	case chat1.MessageType_EDIT:
+		body = msg.MessageBody.Edit().Body
+	default:
+		return msg
+	}
+
+	header := msg.ClientHeader
+	header.AtMentions = utils.ParseAtMentionedUsernames(body)
+	header.AtMentionsChannel = utils.ParseAtMentionsChannel(body)
+	return chat1.MessagePlaintext{
+		ClientHeader: header,
+		MessageBody:  msg.MessageBody,
+	}
+}
+
 func (s *BlockingSender) addPrevPointersToMessage(ctx context.Context, msg chat1.MessagePlaintext,
 	convID chat1.ConversationID) (chat1.MessagePlaintext, error) {
 
@@ -273,6 +296,7 @@ func (s *BlockingSender) Prepare(ctx context.Context, plaintext chat1.MessagePla
 	if err != nil {
 		return nil, nil, err
 	}
+	msg = s.addAtMentionsToMessage(msg)
 
 	// convID will be nil in makeFirstMessage, for example
 	if convID != nil {
@@ -24,6 +24,8 @@ type chatListener struct {
 	incoming       chan int
 	failing        chan []chat1.OutboxRecord
 	identifyUpdate chan keybase1.CanonicalTLFNameAndIDWithBreaks
+	inboxStale     chan struct{}
+	threadsStale   chan []chat1.ConversationID
 }
 
 var _ libkb.NotifyListener = (*chatListener)(nil)
@@ -47,12 +49,29 @@ func (n *chatListener) ReachabilityChanged(r keybase1.Reachability)
 func (n *chatListener) ChatIdentifyUpdate(update keybase1.CanonicalTLFNameAndIDWithBreaks) {
 	n.identifyUpdate <- update
 }
+func (n *chatListener) ChatTLFIdentifyProgress(progress chat1.TLFIdentifyProgress) {}
 func (n *chatListener) ChatTLFFinalize(uid keybase1.UID, convID chat1.ConversationID, info chat1.ConversationFinalizeInfo) {
 }
 func (n *chatListener) ChatTLFResolve(uid keybase1.UID, convID chat1.ConversationID, info chat1.ConversationResolveInfo) {
 }
-func (n *chatListener) ChatInboxStale(uid keybase1.UID)                                {}
-func (n *chatListener) ChatThreadsStale(uid keybase1.UID, cids []chat1.ConversationID) {}
+func (n *chatListener) ChatInboxStale(uid keybase1.UID) {
+	if n.inboxStale != nil {
+		select {
+		case n.inboxStale <- struct{}{}:
+		default:
+		}
+	}
+}
+func (n *chatListener) ChatThreadsStale(uid keybase1.UID, cids []chat1.ConversationID) {
+	if n.threadsStale != nil {
+		select {
+		case n.threadsStale <- cids:
+		default:
+		}
+	}
+}
+func (n *chatListener) ChatTypingUpdate(typingUpdates []chat1.ConvTypingUpdate)         {}
+func (n *chatListener) ChatAttentionUpdate(uid keybase1.UID, convID chat1.ConversationID, msgID chat1.MessageID) {}
 func (n *chatListener) NewChatActivity(uid keybase1.UID, activity chat1.ChatActivity) {
 	n.Lock()
 	defer n.Unlock()
@@ -124,6 +143,8 @@ func setupTest(t *testing.T, numUsers int) (*kbtest.ChatMockWorld, chat1.RemoteI
 		incoming:       make(chan int),
 		failing:        make(chan []chat1.OutboxRecord),
 		identifyUpdate: make(chan keybase1.CanonicalTLFNameAndIDWithBreaks),
+		inboxStale:     make(chan struct{}, 10),
+		threadsStale:   make(chan []chat1.ConversationID, 10),
 	}
 	tc.G.ConvSource = NewHybridConversationSource(tc.G, boxer, storage.New(tc.G, f),
 		func() chat1.RemoteInterface { return ri },
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/keybase/client/go/chat/pager"
 	"github.com/keybase/client/go/chat/utils"
@@ -301,6 +302,132 @@ func (s *Storage) updateAllSupersededBy(ctx context.Context, convID chat1.Conver
 	return nil
 }
 
+// PurgeExpiredEphemeral scans the locally cached messages for a
+// conversation and blanks the body of any ephemeral message whose
+// lifetime has elapsed as of now, the same way updateAllSupersededBy
+// blanks the body of a deleted message. It returns the message IDs that
+// were purged so callers (e.g. the UI layer) can react to the change.
+func (s *Storage) PurgeExpiredEphemeral(ctx context.Context, convID chat1.ConversationID,
+	uid gregor1.UID, now time.Time) ([]chat1.MessageID, Error) {
+	locks.Storage.Lock()
+	defer locks.Storage.Unlock()
+
+	key, ierr := getSecretBoxKey(ctx, s.G(), s.getSecretUI)
+	if ierr != nil {
+		return nil, MiscError{Msg: "unable to get secret key: " + ierr.Error()}
+	}
+
+	var err Error
+	ctx, err = s.engine.init(ctx, key, convID, uid)
+	if err != nil {
+		return nil, s.MaybeNuke(false, err, convID, uid)
+	}
+
+	maxMsgID, err := s.idtracker.getMaxMessageID(ctx, convID, uid)
+	if err != nil {
+		return nil, s.MaybeNuke(false, err, convID, uid)
+	}
+
+	rc := newSimpleResultCollector(10000)
+	if err = s.engine.readMessages(ctx, rc, convID, uid, maxMsgID); err != nil {
+		if _, ok := err.(MissError); ok {
+			return nil, nil
+		}
+		return nil, s.MaybeNuke(false, err, convID, uid)
+	}
+
+	var purged []chat1.MessageID
+	var towrite []chat1.MessageUnboxed
+	for _, msg := range rc.result() {
+		if !msg.IsValid() {
+			continue
+		}
+		mvalid := msg.Valid()
+		if !mvalid.IsEphemeralExpired(now) {
+			continue
+		}
+		s.Debug(ctx, "PurgeExpiredEphemeral: purging expired message: id: %d", msg.GetMessageID())
+		mvalid.MessageBody = chat1.MessageBody{}
+		towrite = append(towrite, chat1.NewMessageUnboxedWithValid(mvalid))
+		purged = append(purged, msg.GetMessageID())
+	}
+
+	if len(towrite) == 0 {
+		return nil, nil
+	}
+	if err = s.engine.writeMessages(ctx, convID, uid, towrite); err != nil {
+		return nil, s.MaybeNuke(false, err, convID, uid)
+	}
+
+	return purged, nil
+}
+
+// PurgeMessagesOlderThan scans the locally cached messages for a
+// conversation and blanks the body of any message older than maxAge as of
+// now, the same way PurgeExpiredEphemeral blanks the body of an expired
+// ephemeral message. It's how a conversation's retention policy is enforced
+// against the local cache; it does not touch the server or any other
+// device. maxAge of 0 means retain forever, and is a no-op.
+func (s *Storage) PurgeMessagesOlderThan(ctx context.Context, convID chat1.ConversationID,
+	uid gregor1.UID, maxAge time.Duration, now time.Time) ([]chat1.MessageID, Error) {
+	if maxAge <= 0 {
+		return nil, nil
+	}
+
+	locks.Storage.Lock()
+	defer locks.Storage.Unlock()
+
+	key, ierr := getSecretBoxKey(ctx, s.G(), s.getSecretUI)
+	if ierr != nil {
+		return nil, MiscError{Msg: "unable to get secret key: " + ierr.Error()}
+	}
+
+	var err Error
+	ctx, err = s.engine.init(ctx, key, convID, uid)
+	if err != nil {
+		return nil, s.MaybeNuke(false, err, convID, uid)
+	}
+
+	maxMsgID, err := s.idtracker.getMaxMessageID(ctx, convID, uid)
+	if err != nil {
+		return nil, s.MaybeNuke(false, err, convID, uid)
+	}
+
+	rc := newSimpleResultCollector(10000)
+	if err = s.engine.readMessages(ctx, rc, convID, uid, maxMsgID); err != nil {
+		if _, ok := err.(MissError); ok {
+			return nil, nil
+		}
+		return nil, s.MaybeNuke(false, err, convID, uid)
+	}
+
+	cutoff := now.Add(-maxAge)
+	var purged []chat1.MessageID
+	var towrite []chat1.MessageUnboxed
+	for _, msg := range rc.result() {
+		if !msg.IsValid() {
+			continue
+		}
+		mvalid := msg.Valid()
+		if mvalid.ServerHeader.Ctime.Time().After(cutoff) {
+			continue
+		}
+		s.Debug(ctx, "PurgeMessagesOlderThan: purging expired message: id: %d", msg.GetMessageID())
+		mvalid.MessageBody = chat1.MessageBody{}
+		towrite = append(towrite, chat1.NewMessageUnboxedWithValid(mvalid))
+		purged = append(purged, msg.GetMessageID())
+	}
+
+	if len(towrite) == 0 {
+		return nil, nil
+	}
+	if err = s.engine.writeMessages(ctx, convID, uid, towrite); err != nil {
+		return nil, s.MaybeNuke(false, err, convID, uid)
+	}
+
+	return purged, nil
+}
+
 func (s *Storage) fetchUpToMsgIDLocked(ctx context.Context, convID chat1.ConversationID,
 	uid gregor1.UID, msgID chat1.MessageID, query *chat1.GetThreadQuery, pagination *chat1.Pagination) (chat1.ThreadView, Error) {
 	// Fetch secret key
@@ -4,6 +4,7 @@ import (
 	"crypto/rand"
 	"math/big"
 	"testing"
+	"time"
 
 	"github.com/keybase/client/go/chat/pager"
 	"github.com/keybase/client/go/chat/utils"
@@ -78,6 +79,39 @@ func makeText(id chat1.MessageID, text string) chat1.MessageUnboxed {
 	return chat1.NewMessageUnboxedWithValid(msg)
 }
 
+func makeEphemeralText(id chat1.MessageID, text string, ctime gregor1.Time, lifetimeSecs int) chat1.MessageUnboxed {
+	msg := chat1.MessageUnboxedValid{
+		ServerHeader: chat1.MessageServerHeader{
+			MessageID: id,
+			Ctime:     ctime,
+		},
+		ClientHeader: chat1.MessageClientHeaderVerified{
+			MessageType:       chat1.MessageType_TEXT,
+			EphemeralLifetime: &lifetimeSecs,
+		},
+		MessageBody: chat1.NewMessageBodyWithText(chat1.MessageText{
+			Body: text,
+		}),
+	}
+	return chat1.NewMessageUnboxedWithValid(msg)
+}
+
+func makeTextWithCtime(id chat1.MessageID, text string, ctime gregor1.Time) chat1.MessageUnboxed {
+	msg := chat1.MessageUnboxedValid{
+		ServerHeader: chat1.MessageServerHeader{
+			MessageID: id,
+			Ctime:     ctime,
+		},
+		ClientHeader: chat1.MessageClientHeaderVerified{
+			MessageType: chat1.MessageType_TEXT,
+		},
+		MessageBody: chat1.NewMessageBodyWithText(chat1.MessageText{
+			Body: text,
+		}),
+	}
+	return chat1.NewMessageUnboxedWithValid(msg)
+}
+
 func makeMsgWithType(id chat1.MessageID, typ chat1.MessageType) chat1.MessageUnboxed {
 	msg := chat1.MessageUnboxedValid{
 		ServerHeader: chat1.MessageServerHeader{
@@ -315,6 +349,79 @@ func TestStorageSupersedes(t *testing.T) {
 	require.Equal(t, chat1.MessageType_NONE, deletedEditBodyType, "expected the edit's body to be deleted also, but it's not!!!")
 }
 
+func TestStoragePurgeExpiredEphemeral(t *testing.T) {
+	var err error
+	_, storage, uid := setupStorageTest(t, "purge expired ephemeral")
+
+	now := time.Now()
+	expired := makeEphemeralText(1, "boom", gregor1.ToTime(now.Add(-2*time.Minute)), 60)
+	notYetExpired := makeEphemeralText(2, "not yet", gregor1.ToTime(now), 60)
+	regular := makeText(3, "sticks around forever")
+	msgs := []chat1.MessageUnboxed{regular, notYetExpired, expired}
+	conv := makeConversation(msgs[0].GetMessageID())
+
+	require.NoError(t, storage.Merge(context.TODO(), conv.Metadata.ConversationID, uid, msgs))
+
+	var purged []chat1.MessageID
+	purged, err = storage.PurgeExpiredEphemeral(context.TODO(), conv.Metadata.ConversationID, uid, now)
+	require.NoError(t, err)
+	require.Equal(t, []chat1.MessageID{1}, purged)
+
+	res, err := storage.Fetch(context.TODO(), conv, uid, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, len(msgs), len(res.Messages), "wrong amount of messages")
+
+	byID := make(map[chat1.MessageID]chat1.MessageUnboxed)
+	for _, msg := range res.Messages {
+		byID[msg.GetMessageID()] = msg
+	}
+
+	expiredBody := byID[1].Valid().MessageBody
+	expiredBodyType, err := expiredBody.MessageType()
+	require.NoError(t, err)
+	require.Equal(t, chat1.MessageType_NONE, expiredBodyType, "expected the expired message's body to be purged")
+
+	require.Equal(t, "not yet", byID[2].Valid().MessageBody.Text().Body)
+	require.Equal(t, "sticks around forever", byID[3].Valid().MessageBody.Text().Body)
+}
+
+func TestStoragePurgeMessagesOlderThan(t *testing.T) {
+	var err error
+	_, storage, uid := setupStorageTest(t, "purge messages older than")
+
+	now := time.Now()
+	old := makeTextWithCtime(1, "ancient history", gregor1.ToTime(now.Add(-2*time.Hour)))
+	recent := makeTextWithCtime(2, "just now", gregor1.ToTime(now.Add(-time.Minute)))
+	msgs := []chat1.MessageUnboxed{recent, old}
+	conv := makeConversation(msgs[0].GetMessageID())
+
+	require.NoError(t, storage.Merge(context.TODO(), conv.Metadata.ConversationID, uid, msgs))
+
+	// A zero maxAge means "retain forever" and must be a no-op.
+	var purged []chat1.MessageID
+	purged, err = storage.PurgeMessagesOlderThan(context.TODO(), conv.Metadata.ConversationID, uid, 0, now)
+	require.NoError(t, err)
+	require.Nil(t, purged)
+
+	purged, err = storage.PurgeMessagesOlderThan(context.TODO(), conv.Metadata.ConversationID, uid, time.Hour, now)
+	require.NoError(t, err)
+	require.Equal(t, []chat1.MessageID{1}, purged)
+
+	res, err := storage.Fetch(context.TODO(), conv, uid, nil, nil)
+	require.NoError(t, err)
+
+	byID := make(map[chat1.MessageID]chat1.MessageUnboxed)
+	for _, msg := range res.Messages {
+		byID[msg.GetMessageID()] = msg
+	}
+
+	oldBody := byID[1].Valid().MessageBody
+	oldBodyType, err := oldBody.MessageType()
+	require.NoError(t, err)
+	require.Equal(t, chat1.MessageType_NONE, oldBodyType, "expected the old message's body to be purged")
+	require.Equal(t, "just now", byID[2].Valid().MessageBody.Text().Body)
+}
+
 func TestStorageMiss(t *testing.T) {
 	_, storage, uid := setupStorageTest(t, "miss")
 
@@ -2,6 +2,7 @@ package chat
 
 import (
 	"context"
+	"time"
 
 	"github.com/keybase/client/go/chat/utils"
 	"github.com/keybase/client/go/libkb"
@@ -144,6 +145,11 @@ func (t *basicSupersedesTransform) Run(ctx context.Context,
 	var newMsgs []chat1.MessageUnboxed
 	for _, msg := range originalMsgs {
 		if msg.IsValid() {
+			if t.isExploded(msg) {
+				// Same treatment as a DELETE: drop it from the thread rather
+				// than showing an empty body.
+				continue
+			}
 			// If the message is superseded, then transform it and add that
 			if superMsg, ok := smap[msg.GetMessageID()]; ok {
 				t.Debug(ctx, "transforming: msgID: %d superMsgID: %d", msg.GetMessageID(),
@@ -163,3 +169,18 @@ func (t *basicSupersedesTransform) Run(ctx context.Context,
 
 	return newMsgs, nil
 }
+
+// isExploded reports whether msg is an ephemeral message that has already
+// exploded, either because the background purger already blanked its body
+// in local storage, or because its lifetime has elapsed since it was last
+// read out of storage.
+func (t *basicSupersedesTransform) isExploded(msg chat1.MessageUnboxed) bool {
+	mvalid := msg.Valid()
+	if !mvalid.ClientHeader.IsEphemeral() {
+		return false
+	}
+	if mvalid.IsEphemeralExpired(time.Now()) {
+		return true
+	}
+	return mvalid.MessageBody == chat1.MessageBody{}
+}
@@ -0,0 +1,91 @@
+package chat
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keybase/client/go/chat/storage"
+	"github.com/keybase/client/go/protocol/chat1"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+func newTestConv(t *testing.T, ri chat1.RemoteInterface, sender Sender, trip chat1.ConversationIDTriple, tlfName string) chat1.ConversationID {
+	plaintext := chat1.MessagePlaintext{
+		ClientHeader: chat1.MessageClientHeader{
+			Conv:        trip,
+			TlfName:     tlfName,
+			MessageType: chat1.MessageType_TLFNAME,
+		},
+		MessageBody: chat1.MessageBody{},
+	}
+	boxed, _, err := sender.Prepare(context.TODO(), plaintext, nil)
+	require.NoError(t, err)
+	res, err := ri.NewConversationRemote2(context.TODO(), chat1.NewConversationRemote2Arg{
+		IdTriple:   trip,
+		TLFMessage: *boxed,
+	})
+	require.NoError(t, err)
+	return res.ConvID
+}
+
+func TestSyncerConnectedFreshClientClears(t *testing.T) {
+	world, ri, sender, _, listener, _, tlf := setupTest(t, 1)
+	defer world.Cleanup()
+
+	u := world.GetUsers()[0]
+	tc := userTc(t, world, u)
+	trip := newConvTriple(t, tlf, u.Username)
+	newTestConv(t, ri, sender, trip, u.Username)
+
+	uid := u.User.GetUID().ToBytes()
+	syncer := NewSyncer(tc.G)
+	require.NoError(t, syncer.Connected(context.TODO(), ri, uid))
+
+	select {
+	case <-listener.inboxStale:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "expected an inbox stale notification for a never-before-synced client")
+	}
+}
+
+func TestSyncerConnectedIncremental(t *testing.T) {
+	world, ri, sender, _, listener, f, tlf := setupTest(t, 1)
+	defer world.Cleanup()
+
+	u := world.GetUsers()[0]
+	tc := userTc(t, world, u)
+	trip := newConvTriple(t, tlf, u.Username)
+	newTestConv(t, ri, sender, trip, u.Username)
+
+	uid := u.User.GetUID().ToBytes()
+
+	// Prime the local inbox cache to the current server version, as if a
+	// full GetInboxLocal had already run.
+	inboxRes, err := ri.GetInboxRemote(context.TODO(), chat1.GetInboxRemoteArg{})
+	require.NoError(t, err)
+	full := inboxRes.Inbox.Full()
+	require.NoError(t, storage.NewInbox(tc.G, uid, f).Merge(context.TODO(), full.Vers, full.Conversations, nil, nil))
+
+	// A second conversation appears on the server, bumping the inbox
+	// version past what the client has cached.
+	trip2 := trip
+	trip2.TopicType = chat1.TopicType_DEV
+	trip2.TopicID = []byte{1}
+	newConvID := newTestConv(t, ri, sender, trip2, u.Username)
+
+	syncer := NewSyncer(tc.G)
+	require.NoError(t, syncer.Connected(context.TODO(), ri, uid))
+
+	select {
+	case cids := <-listener.threadsStale:
+		require.Equal(t, []chat1.ConversationID{newConvID}, cids,
+			"expected a targeted stale notification for just the new conversation")
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "expected a targeted threads stale notification")
+	}
+
+	vers, ierr := storage.NewInbox(tc.G, uid, f).Version(context.TODO())
+	require.NoError(t, ierr)
+	require.True(t, vers > full.Vers, "local inbox version should have advanced past the primed version")
+}
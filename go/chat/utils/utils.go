@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -287,6 +288,40 @@ func IsVisibleChatMessageType(messageType chat1.MessageType) bool {
 	return false
 }
 
+var atMentionRE = regexp.MustCompile(`@([a-zA-Z0-9][a-zA-Z0-9_]{1,15})`)
+
+// ParseAtMentionedUsernames scans body for "@username" tokens and returns the
+// distinct usernames found, in first-occurrence order. It doesn't check that
+// the usernames actually exist -- that's left to the caller, since doing so
+// here would mean a network round trip during message send.
+func ParseAtMentionedUsernames(body string) (usernames []string) {
+	seen := make(map[string]bool)
+	for _, match := range atMentionRE.FindAllStringSubmatch(body, -1) {
+		name := strings.ToLower(match[1])
+		if name == "channel" || name == "here" {
+			continue
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		usernames = append(usernames, name)
+	}
+	return usernames
+}
+
+// ParseAtMentionsChannel returns true if body contains an "@channel" or
+// "@here" token, either of which mentions everyone in the conversation.
+func ParseAtMentionsChannel(body string) bool {
+	for _, match := range atMentionRE.FindAllStringSubmatch(body, -1) {
+		name := strings.ToLower(match[1])
+		if name == "channel" || name == "here" {
+			return true
+		}
+	}
+	return false
+}
+
 type DebugLabeler struct {
 	libkb.Contextified
 	label   string
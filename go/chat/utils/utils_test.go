@@ -18,3 +18,30 @@ func TestParseDurationExtended(t *testing.T) {
 	test("1d", time.Hour*24)
 	test("123d12h2ns", 123*24*time.Hour+12*time.Hour+2*time.Nanosecond)
 }
+
+func TestParseAtMentionedUsernames(t *testing.T) {
+	names := ParseAtMentionedUsernames("hey @alice and @bob, also @alice again")
+	if len(names) != 2 || names[0] != "alice" || names[1] != "bob" {
+		t.Fatalf("wrong mentioned usernames: %v", names)
+	}
+
+	if names := ParseAtMentionedUsernames("no mentions here"); len(names) != 0 {
+		t.Fatalf("expected no mentions, got %v", names)
+	}
+
+	if names := ParseAtMentionedUsernames("@channel please look at this"); len(names) != 0 {
+		t.Fatalf("expected @channel to not be treated as a username, got %v", names)
+	}
+}
+
+func TestParseAtMentionsChannel(t *testing.T) {
+	if !ParseAtMentionsChannel("hey @channel") {
+		t.Fatal("expected @channel to be detected")
+	}
+	if !ParseAtMentionsChannel("hey @here") {
+		t.Fatal("expected @here to be detected")
+	}
+	if ParseAtMentionsChannel("hey @alice") {
+		t.Fatal("expected @alice to not trigger a channel mention")
+	}
+}
@@ -74,6 +74,10 @@ var chatFlags = map[string]cli.Flag{
 		Name:  "nonblock",
 		Usage: `Send message without success confirmation`,
 	},
+	"exploding-lifetime": cli.DurationFlag{
+		Name:  "exploding-lifetime",
+		Usage: `Explode the message's body out of local storage after this long (e.g. "5m", "24h"). Only affects locally cached copies of the message, not the server's or other devices'.`,
+	},
 	"include-hidden": cli.BoolFlag{
 		Name:  "include-hidden",
 		Usage: `Include hidden conversations`,
@@ -0,0 +1,23 @@
+// Copyright 2016 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package client
+
+import (
+	"github.com/keybase/cli"
+	"github.com/keybase/client/go/libcmdline"
+	"github.com/keybase/client/go/libkb"
+)
+
+// NewCmdAccount creates the account command, which is a holder for
+// subcommands that inspect or manage the logged-in user's account.
+func NewCmdAccount(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
+	return cli.Command{
+		Name:         "account",
+		Usage:        "Manage your account",
+		ArgumentHelp: "[secret-store-status]",
+		Subcommands: []cli.Command{
+			NewCmdAccountSecretStoreStatus(cl, g),
+		},
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2016 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package client
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/keybase/cli"
+	"github.com/keybase/client/go/libcmdline"
+	"github.com/keybase/client/go/libkb"
+)
+
+type CmdAccountSecretStoreStatus struct {
+	libkb.Contextified
+}
+
+func NewCmdAccountSecretStoreStatus(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
+	return cli.Command{
+		Name:  "secret-store-status",
+		Usage: "Show which secret store backend holds your saved login secret",
+		Action: func(c *cli.Context) {
+			cmd := &CmdAccountSecretStoreStatus{Contextified: libkb.NewContextified(g)}
+			cl.ChooseCommand(cmd, "secret-store-status", c)
+		},
+	}
+}
+
+func (c *CmdAccountSecretStoreStatus) ParseArgv(ctx *cli.Context) error {
+	if len(ctx.Args()) > 0 {
+		return UnexpectedArgsError("secret-store-status")
+	}
+	return nil
+}
+
+func (c *CmdAccountSecretStoreStatus) Run() error {
+	cli, err := GetAccountClient(c.G())
+	if err != nil {
+		return err
+	}
+	status, err := cli.SecretStoreStatus(context.Background(), 0)
+	if err != nil {
+		return err
+	}
+
+	t := c.G().UI.GetTerminalUI()
+	t.Printf("Secret store backend: %s\n", status.Backend)
+	if status.HasStoredSecret {
+		t.Printf("A login secret for this user is currently stored there.\n")
+	} else {
+		t.Printf("No login secret for this user is currently stored there.\n")
+	}
+	return nil
+}
+
+func (c *CmdAccountSecretStoreStatus) GetUsage() libkb.Usage {
+	return libkb.Usage{
+		API:    true,
+		Config: true,
+	}
+}
@@ -0,0 +1,26 @@
+// Copyright 2016 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package client
+
+import (
+	"github.com/keybase/cli"
+	"github.com/keybase/client/go/libcmdline"
+	"github.com/keybase/client/go/libkb"
+)
+
+// NewCmdAutoreset creates the autoreset command, which is just a
+// holder for subcommands that drive the account recovery pipeline for
+// a user who has lost every device and paper key.
+func NewCmdAutoreset(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
+	return cli.Command{
+		Name:         "autoreset",
+		Usage:        "Manage account recovery via the autoreset pipeline",
+		ArgumentHelp: "[enter, cancel, status]",
+		Subcommands: []cli.Command{
+			NewCmdAutoresetEnter(cl, g),
+			NewCmdAutoresetCancel(cl, g),
+			NewCmdAutoresetStatus(cl, g),
+		},
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2016 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package client
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/keybase/cli"
+	"github.com/keybase/client/go/libcmdline"
+	"github.com/keybase/client/go/libkb"
+)
+
+type CmdAutoresetCancel struct {
+	libkb.Contextified
+}
+
+func NewCmdAutoresetCancel(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
+	return cli.Command{
+		Name:  "cancel",
+		Usage: "Cancel a pending account recovery countdown",
+		Action: func(c *cli.Context) {
+			cmd := &CmdAutoresetCancel{Contextified: libkb.NewContextified(g)}
+			cl.ChooseCommand(cmd, "cancel", c)
+		},
+	}
+}
+
+func (c *CmdAutoresetCancel) ParseArgv(ctx *cli.Context) error {
+	if len(ctx.Args()) > 0 {
+		return UnexpectedArgsError("cancel")
+	}
+	return nil
+}
+
+func (c *CmdAutoresetCancel) Run() error {
+	cli, err := GetAccountClient(c.G())
+	if err != nil {
+		return err
+	}
+	if err := cli.CancelReset(context.Background(), 0); err != nil {
+		return err
+	}
+	c.G().UI.GetTerminalUI().Printf("Account recovery canceled.\n")
+	return nil
+}
+
+func (c *CmdAutoresetCancel) GetUsage() libkb.Usage {
+	return libkb.Usage{
+		API:       true,
+		Config:    true,
+		KbKeyring: true,
+	}
+}
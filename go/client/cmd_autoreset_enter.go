@@ -0,0 +1,63 @@
+// Copyright 2016 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package client
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/keybase/cli"
+	"github.com/keybase/client/go/libcmdline"
+	"github.com/keybase/client/go/libkb"
+	keybase1 "github.com/keybase/client/go/protocol/keybase1"
+)
+
+type CmdAutoresetEnter struct {
+	libkb.Contextified
+	usernameOrEmail string
+}
+
+func NewCmdAutoresetEnter(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
+	return cli.Command{
+		Name:         "enter",
+		Usage:        "Start the account recovery countdown for a username or email",
+		ArgumentHelp: "<username or email>",
+		Action: func(c *cli.Context) {
+			cmd := &CmdAutoresetEnter{Contextified: libkb.NewContextified(g)}
+			cl.ChooseCommand(cmd, "enter", c)
+		},
+	}
+}
+
+func (c *CmdAutoresetEnter) ParseArgv(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		return UnexpectedArgsError("enter")
+	}
+	c.usernameOrEmail = ctx.Args()[0]
+	return nil
+}
+
+func (c *CmdAutoresetEnter) Run() error {
+	cli, err := GetAccountClient(c.G())
+	if err != nil {
+		return err
+	}
+
+	if err := cli.EnterResetPipeline(context.Background(), keybase1.EnterResetPipelineArg{
+		UsernameOrEmail: c.usernameOrEmail,
+	}); err != nil {
+		return err
+	}
+
+	t := c.G().UI.GetTerminalUI()
+	t.Printf("Account recovery started for %s.\n", c.usernameOrEmail)
+	t.Printf("If this wasn't you, log in on any device with access to this account and run 'keybase autoreset cancel'.\n")
+	return nil
+}
+
+func (c *CmdAutoresetEnter) GetUsage() libkb.Usage {
+	return libkb.Usage{
+		API:    true,
+		Config: true,
+	}
+}
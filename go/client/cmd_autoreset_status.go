@@ -0,0 +1,61 @@
+// Copyright 2016 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package client
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/keybase/cli"
+	"github.com/keybase/client/go/libcmdline"
+	"github.com/keybase/client/go/libkb"
+)
+
+type CmdAutoresetStatus struct {
+	libkb.Contextified
+}
+
+func NewCmdAutoresetStatus(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
+	return cli.Command{
+		Name:  "status",
+		Usage: "Show the status of a pending account recovery countdown",
+		Action: func(c *cli.Context) {
+			cmd := &CmdAutoresetStatus{Contextified: libkb.NewContextified(g)}
+			cl.ChooseCommand(cmd, "status", c)
+		},
+	}
+}
+
+func (c *CmdAutoresetStatus) ParseArgv(ctx *cli.Context) error {
+	if len(ctx.Args()) > 0 {
+		return UnexpectedArgsError("status")
+	}
+	return nil
+}
+
+func (c *CmdAutoresetStatus) Run() error {
+	cli, err := GetAccountClient(c.G())
+	if err != nil {
+		return err
+	}
+	status, err := cli.GetResetStatus(context.Background(), 0)
+	if err != nil {
+		return err
+	}
+
+	t := c.G().UI.GetTerminalUI()
+	if !status.Active || status.EndTime == nil {
+		t.Printf("No account recovery is in progress.\n")
+		return nil
+	}
+	t.Printf("Account recovery is in progress and will complete at %s.\n", status.EndTime.Time())
+	t.Printf("Run 'keybase autoreset cancel' from a logged-in device to stop it.\n")
+	return nil
+}
+
+func (c *CmdAutoresetStatus) GetUsage() libkb.Usage {
+	return libkb.Usage{
+		API:    true,
+		Config: true,
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright 2017 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/keybase/cli"
+	"github.com/keybase/client/go/libcmdline"
+	"github.com/keybase/client/go/libkb"
+)
+
+// NewCmdBotToken creates the bot-token command, which is just a holder
+// for subcommands. Bot tokens scope access to the local HTTP gateway
+// (see CmdGateway, go/client/cmd_gateway.go) to a subset of its routes,
+// and optionally to a subset of conversations for chat/send, so a bot
+// integration can be handed a token narrower than full account access.
+func NewCmdBotToken(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
+	return cli.Command{
+		Name:         "bot-token",
+		Usage:        "Manage scoped tokens for the local HTTP gateway",
+		ArgumentHelp: "[arguments...]",
+		Subcommands: []cli.Command{
+			newCmdBotTokenCreate(cl, g),
+			newCmdBotTokenList(cl, g),
+			newCmdBotTokenRevoke(cl, g),
+		},
+	}
+}
+
+// bot-token create
+
+type cmdBotTokenCreate struct {
+	libkb.Contextified
+	label   string
+	routes  string
+	convIDs string
+}
+
+func newCmdBotTokenCreate(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
+	return cli.Command{
+		Name:  "create",
+		Usage: "Issue a new scoped bot token",
+		Action: func(c *cli.Context) {
+			cmd := &cmdBotTokenCreate{Contextified: libkb.NewContextified(g)}
+			cl.ChooseCommand(cmd, "create", c)
+		},
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "label",
+				Usage: "A human-readable name for this token, e.g. the bot's name.",
+			},
+			cli.StringFlag{
+				Name:  "routes",
+				Usage: "Comma-separated gateway routes this token may call (default: all). E.g. \"chat/send\".",
+			},
+			cli.StringFlag{
+				Name:  "conversation-id",
+				Usage: "Comma-separated conversation IDs chat/send is restricted to (default: any).",
+			},
+		},
+	}
+}
+
+func (c *cmdBotTokenCreate) ParseArgv(ctx *cli.Context) error {
+	if len(ctx.Args()) != 0 {
+		return errors.New("create takes no arguments")
+	}
+	c.label = ctx.String("label")
+	c.routes = ctx.String("routes")
+	c.convIDs = ctx.String("conversation-id")
+	return nil
+}
+
+func (c *cmdBotTokenCreate) Run() error {
+	scope := libkb.BotTokenScope{
+		Routes:  splitNonEmpty(c.routes),
+		ConvIDs: splitNonEmpty(c.convIDs),
+	}
+	tok, err := c.G().Env.CreateBotToken(c.label, scope)
+	if err != nil {
+		return err
+	}
+	return c.output(tok)
+}
+
+func (c *cmdBotTokenCreate) output(tok libkb.BotToken) error {
+	b, err := json.MarshalIndent(tok, "", "    ")
+	if err != nil {
+		return err
+	}
+	return DisplayJSON(string(b))
+}
+
+func (c *cmdBotTokenCreate) GetUsage() libkb.Usage {
+	return libkb.Usage{Config: true}
+}
+
+// bot-token list
+
+type cmdBotTokenList struct {
+	libkb.Contextified
+}
+
+func newCmdBotTokenList(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
+	return cli.Command{
+		Name:  "list",
+		Usage: "List all bot tokens",
+		Action: func(c *cli.Context) {
+			cmd := &cmdBotTokenList{Contextified: libkb.NewContextified(g)}
+			cl.ChooseCommand(cmd, "list", c)
+		},
+	}
+}
+
+func (c *cmdBotTokenList) ParseArgv(ctx *cli.Context) error {
+	if len(ctx.Args()) != 0 {
+		return errors.New("list takes no arguments")
+	}
+	return nil
+}
+
+func (c *cmdBotTokenList) Run() error {
+	tokens, err := c.G().Env.GetBotTokens()
+	if err != nil {
+		return err
+	}
+	w := GlobUI.DefaultTabWriter()
+	fmt.Fprintf(w, "ID\tLabel\tRoutes\tConvIDs\tRevoked\n")
+	fmt.Fprintf(w, "==========\t==========\t==========\t==========\t==========\n")
+	for _, t := range tokens {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%v\n", t.ID, t.Label, strings.Join(t.Scope.Routes, ","), strings.Join(t.Scope.ConvIDs, ","), t.Revoked)
+	}
+	return w.Flush()
+}
+
+func (c *cmdBotTokenList) GetUsage() libkb.Usage {
+	return libkb.Usage{Config: true}
+}
+
+// bot-token revoke
+
+type cmdBotTokenRevoke struct {
+	libkb.Contextified
+	id string
+}
+
+func newCmdBotTokenRevoke(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
+	return cli.Command{
+		Name:         "revoke",
+		Usage:        "Revoke a bot token",
+		ArgumentHelp: "<token id>",
+		Action: func(c *cli.Context) {
+			cmd := &cmdBotTokenRevoke{Contextified: libkb.NewContextified(g)}
+			cl.ChooseCommand(cmd, "revoke", c)
+		},
+	}
+}
+
+func (c *cmdBotTokenRevoke) ParseArgv(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		return errors.New("revoke takes exactly one argument, the token id")
+	}
+	c.id = ctx.Args()[0]
+	return nil
+}
+
+func (c *cmdBotTokenRevoke) Run() error {
+	return c.G().Env.RevokeBotToken(c.id)
+}
+
+func (c *cmdBotTokenRevoke) GetUsage() libkb.Usage {
+	return libkb.Usage{Config: true}
+}
+
+func splitNonEmpty(s string) []string {
+	if s == "" {
+		return nil
+	}
+	parts := strings.Split(s, ",")
+	ret := make([]string, 0, len(parts))
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			ret = append(ret, p)
+		}
+	}
+	return ret
+}
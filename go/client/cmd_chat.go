@@ -16,12 +16,16 @@ func NewCmdChat(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command
 		ArgumentHelp: "[arguments...]",
 		Subcommands: []cli.Command{
 			newCmdChatAPI(cl, g),
+			newCmdChatAPIListen(cl, g),
+			newCmdChatDelete(cl, g),
 			newCmdChatDownload(cl, g),
+			newCmdChatEdit(cl, g),
 			newCmdChatHide(cl, g),
 			newCmdChatMute(cl, g),
 			newCmdChatList(cl, g),
 			newCmdChatListUnread(cl, g),
 			newCmdChatRead(cl, g),
+			newCmdChatSearch(cl, g),
 			newCmdChatSend(cl, g),
 			newCmdChatUpload(cl, g),
 		},
@@ -0,0 +1,209 @@
+// Copyright 2017 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/keybase/cli"
+	"github.com/keybase/client/go/libcmdline"
+	"github.com/keybase/client/go/libkb"
+	"github.com/keybase/client/go/protocol/chat1"
+	keybase1 "github.com/keybase/client/go/protocol/keybase1"
+	"github.com/keybase/go-framed-msgpack-rpc/rpc"
+)
+
+// CmdChatAPIListen implements "keybase chat api-listen", a persistent
+// counterpart to "keybase chat api" for bots: instead of a request/reply
+// exchange over a single call, it stays attached and streams new
+// activity (messages, membership changes, read receipts) as JSON lines
+// to stdout as they happen, until killed.
+type CmdChatAPIListen struct {
+	libkb.Contextified
+	convFilter    map[string]bool
+	msgTypeFilter map[chat1.MessageType]bool
+}
+
+func newCmdChatAPIListen(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
+	return cli.Command{
+		Name:  "api-listen",
+		Usage: "Listen for chat activity and print it as JSON lines",
+		Action: func(c *cli.Context) {
+			cmd := &CmdChatAPIListen{Contextified: libkb.NewContextified(g)}
+			cl.ChooseCommand(cmd, "api-listen", c)
+		},
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "conversation-id",
+				Usage: "Only show activity for these conversation IDs (comma-separated). Default: all.",
+			},
+			cli.StringFlag{
+				Name:  "message-type",
+				Usage: "Only show these message types (comma-separated, e.g. \"text,attachment\"). Default: all.",
+			},
+		},
+	}
+}
+
+func (c *CmdChatAPIListen) ParseArgv(ctx *cli.Context) error {
+	if len(ctx.Args()) != 0 {
+		return errors.New("api-listen takes no arguments")
+	}
+
+	if convIDs := ctx.String("conversation-id"); len(convIDs) > 0 {
+		c.convFilter = make(map[string]bool)
+		for _, id := range strings.Split(convIDs, ",") {
+			c.convFilter[strings.TrimSpace(id)] = true
+		}
+	}
+
+	if msgTypes := ctx.String("message-type"); len(msgTypes) > 0 {
+		c.msgTypeFilter = make(map[chat1.MessageType]bool)
+		for _, name := range strings.Split(msgTypes, ",") {
+			typ, ok := chat1.MessageTypeMap[strings.ToUpper(strings.TrimSpace(name))]
+			if !ok {
+				return fmt.Errorf("unknown message type: %q", name)
+			}
+			c.msgTypeFilter[typ] = true
+		}
+	}
+
+	return nil
+}
+
+func (c *CmdChatAPIListen) Run() error {
+	listener := newChatAPIListener(c.G(), os.Stdout, c.convFilter, c.msgTypeFilter)
+
+	protocols := []rpc.Protocol{
+		chat1.NotifyChatProtocol(listener),
+	}
+	if err := RegisterProtocols(protocols); err != nil {
+		return err
+	}
+
+	notifyClient, err := GetNotifyCtlClient(c.G())
+	if err != nil {
+		return err
+	}
+	if err := notifyClient.SetNotifications(context.TODO(), keybase1.NotificationChannels{Chat: true}); err != nil {
+		return err
+	}
+
+	for {
+		time.Sleep(time.Second)
+	}
+}
+
+func (c *CmdChatAPIListen) GetUsage() libkb.Usage {
+	return libkb.Usage{
+		API:       true,
+		KbKeyring: true,
+		Config:    true,
+	}
+}
+
+// chatActivityJSON is the JSON-line shape "chat api-listen" prints to
+// stdout for each activity that passes the conversation/message-type
+// filters.
+type chatActivityJSON struct {
+	Type     string               `json:"type"`
+	ConvID   chat1.ConversationID `json:"convID,omitempty"`
+	Activity chat1.ChatActivity   `json:"activity"`
+}
+
+// chatAPIListener implements chat1.NotifyChatInterface, filtering and
+// re-emitting NewChatActivity pushes as JSON lines. The other
+// NotifyChatInterface methods (typing, identify, TLF finalize/resolve,
+// inbox/thread staleness) aren't activity a bot cares about streaming,
+// so they're no-ops here.
+type chatAPIListener struct {
+	libkb.Contextified
+	enc           *json.Encoder
+	convFilter    map[string]bool
+	msgTypeFilter map[chat1.MessageType]bool
+}
+
+func newChatAPIListener(g *libkb.GlobalContext, w io.Writer, convFilter map[string]bool, msgTypeFilter map[chat1.MessageType]bool) *chatAPIListener {
+	return &chatAPIListener{
+		Contextified:  libkb.NewContextified(g),
+		enc:           json.NewEncoder(w),
+		convFilter:    convFilter,
+		msgTypeFilter: msgTypeFilter,
+	}
+}
+
+func (l *chatAPIListener) allow(convID chat1.ConversationID, typ chat1.MessageType) bool {
+	if l.convFilter != nil && !l.convFilter[convID.String()] {
+		return false
+	}
+	if l.msgTypeFilter != nil && !l.msgTypeFilter[typ] {
+		return false
+	}
+	return true
+}
+
+func (l *chatAPIListener) NewChatActivity(ctx context.Context, arg chat1.NewChatActivityArg) error {
+	activityType, err := arg.Activity.ActivityType()
+	if err != nil {
+		return err
+	}
+
+	var convID chat1.ConversationID
+	var msgType chat1.MessageType
+	switch activityType {
+	case chat1.ChatActivityType_INCOMING_MESSAGE:
+		msg := arg.Activity.IncomingMessage()
+		convID = msg.ConvID
+		msgType = msg.Message.GetMessageType()
+	case chat1.ChatActivityType_READ_MESSAGE:
+		convID = arg.Activity.ReadMessage().ConvID
+	case chat1.ChatActivityType_NEW_CONVERSATION:
+		convID = arg.Activity.NewConversation().Conv.GetConvID()
+	case chat1.ChatActivityType_SET_STATUS:
+		convID = arg.Activity.SetStatus().ConvID
+	}
+
+	if !l.allow(convID, msgType) {
+		return nil
+	}
+
+	return l.enc.Encode(chatActivityJSON{
+		Type:     activityType.String(),
+		ConvID:   convID,
+		Activity: arg.Activity,
+	})
+}
+
+func (l *chatAPIListener) ChatIdentifyUpdate(context.Context, keybase1.CanonicalTLFNameAndIDWithBreaks) error {
+	return nil
+}
+func (l *chatAPIListener) ChatTLFIdentifyProgress(context.Context, chat1.TLFIdentifyProgress) error {
+	return nil
+}
+func (l *chatAPIListener) ChatTLFFinalize(context.Context, chat1.ChatTLFFinalizeArg) error {
+	return nil
+}
+func (l *chatAPIListener) ChatTLFResolve(context.Context, chat1.ChatTLFResolveArg) error {
+	return nil
+}
+func (l *chatAPIListener) ChatInboxStale(context.Context, keybase1.UID) error {
+	return nil
+}
+func (l *chatAPIListener) ChatThreadsStale(context.Context, chat1.ChatThreadsStaleArg) error {
+	return nil
+}
+func (l *chatAPIListener) ChatTypingUpdate(context.Context, []chat1.ConvTypingUpdate) error {
+	return nil
+}
+func (l *chatAPIListener) ChatAttentionUpdate(context.Context, chat1.ChatAttentionUpdateArg) error {
+	return nil
+}
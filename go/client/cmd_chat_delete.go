@@ -0,0 +1,94 @@
+// Copyright 2018 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package client
+
+import (
+	"fmt"
+	"strconv"
+
+	"golang.org/x/net/context"
+
+	"github.com/keybase/cli"
+	"github.com/keybase/client/go/libcmdline"
+	"github.com/keybase/client/go/libkb"
+	"github.com/keybase/client/go/protocol/chat1"
+	"github.com/keybase/client/go/protocol/keybase1"
+)
+
+type cmdChatDelete struct {
+	libkb.Contextified
+	resolvingRequest chatConversationResolvingRequest
+	messageID        chat1.MessageID
+}
+
+func newCmdChatDelete(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
+	return cli.Command{
+		Name:         "delete",
+		Usage:        "Delete a message in a conversation",
+		ArgumentHelp: "<conversation> <message id>",
+		Action: func(c *cli.Context) {
+			cl.ChooseCommand(&cmdChatDelete{Contextified: libkb.NewContextified(g)}, "delete", c)
+		},
+		Flags: getConversationResolverFlags(),
+	}
+}
+
+func (c *cmdChatDelete) ParseArgv(ctx *cli.Context) (err error) {
+	args := ctx.Args()
+	if len(args) != 2 {
+		return fmt.Errorf("chat delete takes 2 args: <conversation> <message id>")
+	}
+
+	if c.resolvingRequest, err = parseConversationResolvingRequest(ctx, args[0]); err != nil {
+		return err
+	}
+
+	id, err := strconv.ParseUint(args[1], 10, 64)
+	if err != nil {
+		return fmt.Errorf("bad message id: %s", err)
+	}
+	c.messageID = chat1.MessageID(id)
+
+	return nil
+}
+
+func (c *cmdChatDelete) Run() (err error) {
+	chatClient, err := GetChatLocalClient(c.G())
+	if err != nil {
+		return err
+	}
+	resolver := &chatConversationResolver{G: c.G(), ChatClient: chatClient}
+	resolver.TlfClient, err = GetTlfClient(c.G())
+	if err != nil {
+		return err
+	}
+
+	ctx := context.TODO()
+	conversation, _, err := resolver.Resolve(ctx, c.resolvingRequest, chatConversationResolvingBehavior{
+		Interactive:      false,
+		IdentifyBehavior: keybase1.TLFIdentifyBehavior_CHAT_CLI,
+	})
+	if err != nil {
+		return err
+	}
+	conversationInfo := conversation.Info
+
+	arg := chat1.PostDeleteNonblockArg{
+		ConversationID:   conversationInfo.Id,
+		Conv:             conversationInfo.Triple,
+		TlfName:          conversationInfo.TlfName,
+		TlfPublic:        conversationInfo.Visibility == chat1.TLFVisibility_PUBLIC,
+		Supersedes:       c.messageID,
+		IdentifyBehavior: keybase1.TLFIdentifyBehavior_CHAT_CLI,
+	}
+	_, err = chatClient.PostDeleteNonblock(ctx, arg)
+	return err
+}
+
+func (c *cmdChatDelete) GetUsage() libkb.Usage {
+	return libkb.Usage{
+		Config: true,
+		API:    true,
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2018 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package client
+
+import (
+	"fmt"
+	"strconv"
+
+	"golang.org/x/net/context"
+
+	"github.com/keybase/cli"
+	"github.com/keybase/client/go/libcmdline"
+	"github.com/keybase/client/go/libkb"
+	"github.com/keybase/client/go/protocol/chat1"
+	"github.com/keybase/client/go/protocol/keybase1"
+)
+
+type cmdChatEdit struct {
+	libkb.Contextified
+	resolvingRequest chatConversationResolvingRequest
+	messageID        chat1.MessageID
+	body             string
+}
+
+func newCmdChatEdit(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
+	return cli.Command{
+		Name:         "edit",
+		Usage:        "Edit a message in a conversation",
+		ArgumentHelp: "<conversation> <message id> <new message>",
+		Action: func(c *cli.Context) {
+			cl.ChooseCommand(&cmdChatEdit{Contextified: libkb.NewContextified(g)}, "edit", c)
+		},
+		Flags: getConversationResolverFlags(),
+	}
+}
+
+func (c *cmdChatEdit) ParseArgv(ctx *cli.Context) (err error) {
+	args := ctx.Args()
+	if len(args) != 3 {
+		return fmt.Errorf("chat edit takes 3 args: <conversation> <message id> <new message>")
+	}
+
+	if c.resolvingRequest, err = parseConversationResolvingRequest(ctx, args[0]); err != nil {
+		return err
+	}
+
+	id, err := strconv.ParseUint(args[1], 10, 64)
+	if err != nil {
+		return fmt.Errorf("bad message id: %s", err)
+	}
+	c.messageID = chat1.MessageID(id)
+	c.body = args[2]
+
+	return nil
+}
+
+func (c *cmdChatEdit) Run() (err error) {
+	chatClient, err := GetChatLocalClient(c.G())
+	if err != nil {
+		return err
+	}
+	resolver := &chatConversationResolver{G: c.G(), ChatClient: chatClient}
+	resolver.TlfClient, err = GetTlfClient(c.G())
+	if err != nil {
+		return err
+	}
+
+	ctx := context.TODO()
+	conversation, _, err := resolver.Resolve(ctx, c.resolvingRequest, chatConversationResolvingBehavior{
+		Interactive:      false,
+		IdentifyBehavior: keybase1.TLFIdentifyBehavior_CHAT_CLI,
+	})
+	if err != nil {
+		return err
+	}
+	conversationInfo := conversation.Info
+
+	arg := chat1.PostEditNonblockArg{
+		ConversationID:   conversationInfo.Id,
+		Conv:             conversationInfo.Triple,
+		TlfName:          conversationInfo.TlfName,
+		TlfPublic:        conversationInfo.Visibility == chat1.TLFVisibility_PUBLIC,
+		Supersedes:       c.messageID,
+		Body:             c.body,
+		IdentifyBehavior: keybase1.TLFIdentifyBehavior_CHAT_CLI,
+	}
+	_, err = chatClient.PostEditNonblock(ctx, arg)
+	return err
+}
+
+func (c *cmdChatEdit) GetUsage() libkb.Usage {
+	return libkb.Usage{
+		Config: true,
+		API:    true,
+	}
+}
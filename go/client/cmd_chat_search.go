@@ -0,0 +1,121 @@
+// Copyright 2018 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package client
+
+import (
+	"fmt"
+
+	"golang.org/x/net/context"
+
+	"github.com/keybase/cli"
+	"github.com/keybase/client/go/libcmdline"
+	"github.com/keybase/client/go/libkb"
+	"github.com/keybase/client/go/protocol/chat1"
+	"github.com/keybase/client/go/protocol/keybase1"
+)
+
+type cmdChatSearch struct {
+	libkb.Contextified
+	resolvingRequest chatConversationResolvingRequest
+	hasConversation  bool
+	query            string
+	maxHits          int
+}
+
+func newCmdChatSearch(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
+	return cli.Command{
+		Name:         "search",
+		Usage:        "Search the locally cached messages of a conversation, or of every conversation",
+		ArgumentHelp: "<query> [conversation]",
+		Action: func(c *cli.Context) {
+			cl.ChooseCommand(&cmdChatSearch{Contextified: libkb.NewContextified(g)}, "search", c)
+		},
+		Flags: append(getConversationResolverFlags(),
+			cli.IntFlag{
+				Name:  "max-hits",
+				Value: 10,
+				Usage: "Maximum number of hits to show per conversation",
+			},
+		),
+	}
+}
+
+func (c *cmdChatSearch) ParseArgv(ctx *cli.Context) (err error) {
+	args := ctx.Args()
+	if len(args) < 1 || len(args) > 2 {
+		return fmt.Errorf("chat search takes 1 or 2 args: <query> [conversation]")
+	}
+	c.query = args[0]
+	c.maxHits = ctx.Int("max-hits")
+
+	c.hasConversation = len(args) == 2
+	if c.hasConversation {
+		if c.resolvingRequest, err = parseConversationResolvingRequest(ctx, args[1]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *cmdChatSearch) Run() (err error) {
+	chatClient, err := GetChatLocalClient(c.G())
+	if err != nil {
+		return err
+	}
+
+	ctx := context.TODO()
+	arg := chat1.SearchInboxArg{
+		Query:            c.query,
+		MaxHits:          c.maxHits,
+		BeforeContext:    2,
+		AfterContext:     2,
+		IdentifyBehavior: keybase1.TLFIdentifyBehavior_CHAT_CLI,
+	}
+
+	if c.hasConversation {
+		resolver := &chatConversationResolver{G: c.G(), ChatClient: chatClient}
+		resolver.TlfClient, err = GetTlfClient(c.G())
+		if err != nil {
+			return err
+		}
+		conversation, _, err := resolver.Resolve(ctx, c.resolvingRequest, chatConversationResolvingBehavior{
+			Interactive:      false,
+			IdentifyBehavior: keybase1.TLFIdentifyBehavior_CHAT_CLI,
+		})
+		if err != nil {
+			return err
+		}
+		convID := conversation.Info.Id
+		arg.ConvID = &convID
+	}
+
+	res, err := chatClient.SearchInbox(ctx, arg)
+	if err != nil {
+		return err
+	}
+
+	ui := c.G().UI.GetTerminalUI()
+	if len(res.Conversations) == 0 {
+		ui.Printf("No results found for %q in the local chat cache.\n", c.query)
+		return nil
+	}
+	for _, conv := range res.Conversations {
+		ui.Printf("%s:\n", conv.TlfName)
+		for _, hit := range conv.Hits {
+			if hit.HitMessage.IsValid() {
+				ui.Printf("  [%d] %s\n", hit.HitMessage.Valid().ServerHeader.MessageID, hit.HitMessage.Valid().MessageBody.Text().Body)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *cmdChatSearch) GetUsage() libkb.Usage {
+	return libkb.Usage{
+		Config: true,
+		API:    true,
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"time"
 
 	"golang.org/x/net/context"
 
@@ -24,12 +25,13 @@ type cmdChatSend struct {
 	libkb.Contextified
 	resolvingRequest chatConversationResolvingRequest
 	// Only one of these should be set
-	message       string
-	setTopicName  string
-	setHeadline   string
-	clearHeadline bool
-	hasTTY        bool
-	nonBlock      bool
+	message           string
+	setTopicName      string
+	setHeadline       string
+	clearHeadline     bool
+	hasTTY            bool
+	nonBlock          bool
+	explodingLifetime time.Duration
 }
 
 func newCmdChatSend(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
@@ -41,7 +43,7 @@ func newCmdChatSend(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Comm
 			cl.ChooseCommand(&cmdChatSend{Contextified: libkb.NewContextified(g)}, "send", c)
 		},
 		Flags: append(getConversationResolverFlags(),
-			mustGetChatFlags("set-topic-name", "set-headline", "clear-headline", "nonblock")...,
+			mustGetChatFlags("set-topic-name", "set-headline", "clear-headline", "nonblock", "exploding-lifetime")...,
 		),
 	}
 }
@@ -77,6 +79,10 @@ func (c *cmdChatSend) Run() (err error) {
 	msg.ClientHeader.Conv = conversationInfo.Triple
 	msg.ClientHeader.TlfName = conversationInfo.TlfName
 	msg.ClientHeader.TlfPublic = (conversationInfo.Visibility == chat1.TLFVisibility_PUBLIC)
+	if c.explodingLifetime > 0 {
+		lifetime := int(c.explodingLifetime.Seconds())
+		msg.ClientHeader.EphemeralLifetime = &lifetime
+	}
 
 	// Whether the user is really sure they want to send to the selected conversation.
 	// We require an additional confirmation if the choose menu was used.
@@ -149,6 +155,7 @@ func (c *cmdChatSend) ParseArgv(ctx *cli.Context) (err error) {
 	c.clearHeadline = ctx.Bool("clear-headline")
 	c.hasTTY = isatty.IsTerminal(os.Stdin.Fd())
 	c.nonBlock = ctx.Bool("nonblock")
+	c.explodingLifetime = ctx.Duration("exploding-lifetime")
 
 	var tlfName string
 	// Get the TLF name from the first position arg
@@ -255,6 +255,65 @@ func NewCmdDbPut(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command
 	}
 }
 
+type CmdDbStats struct {
+	libkb.Contextified
+	dbType keybase1.DbType
+}
+
+func (c *CmdDbStats) ParseArgv(ctx *cli.Context) error {
+	c.dbType = keybase1.DbType_MAIN
+	if ctx.Bool("chat") {
+		c.dbType = keybase1.DbType_CHAT
+	}
+	return nil
+}
+
+func (c *CmdDbStats) Run() error {
+	cli, err := GetCtlClient(c.G())
+	if err != nil {
+		return err
+	}
+	entries, err := cli.DbStats(context.TODO(), keybase1.DbStatsArg{DbType: c.dbType})
+	if err != nil {
+		return err
+	}
+	ui := c.G().UI.GetTerminalUI()
+	var total int64
+	for _, e := range entries {
+		ui.Printf("0x%02x\t%d\n", e.ObjType, e.Bytes)
+		total += e.Bytes
+	}
+	ui.Printf("total\t%d\n", total)
+	return nil
+}
+
+func NewCmdDbStatsRunner(g *libkb.GlobalContext) *CmdDbStats {
+	return &CmdDbStats{Contextified: libkb.NewContextified(g)}
+}
+
+func NewCmdDbStats(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
+	return cli.Command{
+		Name:  "stats",
+		Usage: "Show tracked disk usage per object type",
+		Action: func(c *cli.Context) {
+			cl.ChooseCommand(NewCmdDbStatsRunner(g), "stats", c)
+		},
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "chat, c",
+				Usage: "Refer to the chat database.",
+			},
+		},
+	}
+}
+
+func (c *CmdDbStats) GetUsage() libkb.Usage {
+	return libkb.Usage{
+		Config: true,
+		API:    true,
+	}
+}
+
 func NewCmdDb(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
 	return cli.Command{
 		Name: "db",
@@ -263,6 +322,7 @@ func NewCmdDb(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
 			NewCmdDbDelete(cl, g),
 			NewCmdDbGet(cl, g),
 			NewCmdDbPut(cl, g),
+			NewCmdDbStats(cl, g),
 		},
 	}
 }
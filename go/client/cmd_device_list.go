@@ -4,6 +4,7 @@
 package client
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"golang.org/x/net/context"
@@ -14,10 +15,21 @@ import (
 	keybase1 "github.com/keybase/client/go/protocol/keybase1"
 )
 
+// deviceListJSONVersion is a schema version for CmdDeviceList's --json
+// output, bumped whenever the shape of deviceListJSON changes in a
+// backwards-incompatible way, so scripts consuming it can detect skew.
+const deviceListJSONVersion = 1
+
+type deviceListJSON struct {
+	Version int               `json:"version"`
+	Devices []keybase1.Device `json:"devices"`
+}
+
 // CmdDeviceList is the 'device list' command.  It displays all
 // the devices for the current user.
 type CmdDeviceList struct {
-	all bool
+	all  bool
+	json bool
 	libkb.Contextified
 }
 
@@ -29,6 +41,12 @@ func NewCmdDeviceList(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Co
 		Action: func(c *cli.Context) {
 			cl.ChooseCommand(&CmdDeviceList{Contextified: libkb.NewContextified(g)}, "list", c)
 		},
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "j, json",
+				Usage: "Output as JSON (default is text).",
+			},
+		},
 	}
 }
 
@@ -46,10 +64,21 @@ func (c *CmdDeviceList) Run() error {
 	if err != nil {
 		return err
 	}
+	if c.json {
+		return c.outputJSON(devs)
+	}
 	c.output(devs)
 	return nil
 }
 
+func (c *CmdDeviceList) outputJSON(devs []keybase1.Device) error {
+	b, err := json.MarshalIndent(deviceListJSON{Version: deviceListJSONVersion, Devices: devs}, "", "    ")
+	if err != nil {
+		return err
+	}
+	return DisplayJSON(string(b))
+}
+
 func (c *CmdDeviceList) output(devs []keybase1.Device) {
 	w := GlobUI.DefaultTabWriter()
 	fmt.Fprintf(w, "Name\tType\tID\tCreated\tLast Used\n")
@@ -66,6 +95,7 @@ func (c *CmdDeviceList) output(devs []keybase1.Device) {
 // ParseArgv does nothing for this command.
 func (c *CmdDeviceList) ParseArgv(ctx *cli.Context) error {
 	c.all = ctx.Bool("all")
+	c.json = ctx.Bool("json")
 	return nil
 }
 
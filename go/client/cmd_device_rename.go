@@ -0,0 +1,67 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package client
+
+import (
+	"fmt"
+
+	"github.com/keybase/cli"
+	"github.com/keybase/client/go/libcmdline"
+	"github.com/keybase/client/go/libkb"
+	keybase1 "github.com/keybase/client/go/protocol/keybase1"
+	"github.com/keybase/go-framed-msgpack-rpc/rpc"
+	"golang.org/x/net/context"
+)
+
+type CmdDeviceRename struct {
+	newName string
+	libkb.Contextified
+}
+
+func (c *CmdDeviceRename) ParseArgv(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		return fmt.Errorf("Device rename only takes one argument: the new device name.")
+	}
+	c.newName = ctx.Args()[0]
+	return nil
+}
+
+func (c *CmdDeviceRename) Run() (err error) {
+	protocols := []rpc.Protocol{
+		NewSecretUIProtocol(c.G()),
+	}
+	if err = RegisterProtocolsWithContext(protocols, c.G()); err != nil {
+		return err
+	}
+
+	cli, err := GetDeviceClient(c.G())
+	if err != nil {
+		return err
+	}
+
+	return cli.DeviceRename(context.TODO(), keybase1.DeviceRenameArg{
+		NewName: c.newName,
+	})
+}
+
+func NewCmdDeviceRename(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
+	return cli.Command{
+		Name:         "rename",
+		ArgumentHelp: "<new name>",
+		Usage:        "Rename the current device",
+		Action: func(c *cli.Context) {
+			cmd := &CmdDeviceRename{Contextified: libkb.NewContextified(g)}
+			cl.ChooseCommand(cmd, "rename", c)
+		},
+	}
+}
+
+func (c *CmdDeviceRename) GetUsage() libkb.Usage {
+	return libkb.Usage{
+		Config:     true,
+		GpgKeyring: true,
+		KbKeyring:  true,
+		API:        true,
+	}
+}
@@ -0,0 +1,250 @@
+// Copyright 2017 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package client
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"golang.org/x/net/context"
+
+	"github.com/keybase/cli"
+	"github.com/keybase/client/go/libcmdline"
+	"github.com/keybase/client/go/libkb"
+	keybase1 "github.com/keybase/client/go/protocol/keybase1"
+)
+
+// The gateway's routes, also used as BotTokenScope.Routes values so a
+// bot token can be restricted to a subset of them.
+const (
+	routeStatus     = "status"
+	routeUserLookup = "user/lookup"
+	routeChatSend   = "chat/send"
+)
+
+// gatewayJSONVersion is a schema version for the local HTTP gateway's JSON
+// responses, bumped whenever a response shape changes in a
+// backwards-incompatible way, so scripts consuming it can detect skew.
+const gatewayJSONVersion = 1
+
+type gatewayEnvelope struct {
+	Version int         `json:"version"`
+	Result  interface{} `json:"result,omitempty"`
+	Error   string      `json:"error,omitempty"`
+}
+
+// CmdGateway runs a localhost-only, token-authenticated HTTP server that
+// bridges a small, fixed set of RPCs (status, user lookup, chat send) to
+// REST+JSON, for tools that can't speak framed msgpack RPC. It's off
+// unless explicitly started with this command: there's no background
+// toggle, since a second, differently-authenticated door into the
+// service is worth requiring an explicit decision to open.
+//
+// It deliberately doesn't try to expose the whole keybase1 RPC surface --
+// see local_http_gateway_port/local_http_gateway_token in the config
+// file for how the port and bearer token are chosen.
+type CmdGateway struct {
+	libkb.Contextified
+	port int
+}
+
+func NewCmdGateway(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
+	return cli.Command{
+		Name:  "gateway",
+		Usage: "Run a local HTTP+JSON bridge to a subset of RPCs (status, user lookup, chat send)",
+		Action: func(c *cli.Context) {
+			cl.ChooseCommand(&CmdGateway{Contextified: libkb.NewContextified(g)}, "gateway", c)
+		},
+		Flags: []cli.Flag{
+			cli.IntFlag{
+				Name:  "port",
+				Usage: "Port to listen on (default: local_http_gateway_port config value)",
+			},
+		},
+	}
+}
+
+func (c *CmdGateway) ParseArgv(ctx *cli.Context) error {
+	c.port = ctx.Int("port")
+	return nil
+}
+
+func (c *CmdGateway) Run() error {
+	port := c.port
+	if port == 0 {
+		port = c.G().Env.GetLocalHTTPGatewayPort()
+	}
+	if port == 0 {
+		return errors.New("gateway: no port given; pass --port or set local_http_gateway_port in the config file")
+	}
+
+	token, err := c.G().Env.GetLocalHTTPGatewayToken()
+	if err != nil {
+		return err
+	}
+
+	addr := fmt.Sprintf("127.0.0.1:%d", port)
+	dui := c.G().UI.GetDumbOutputUI()
+	dui.Printf("gateway: listening on http://%s (Authorization: Bearer %s)\n", addr, token)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", c.authenticated(token, routeStatus, c.handleStatus))
+	mux.HandleFunc("/user/lookup", c.authenticated(token, routeUserLookup, c.handleUserLookup))
+	mux.HandleFunc("/chat/send", c.authenticated(token, routeChatSend, c.handleChatSend))
+
+	return http.ListenAndServe(addr, mux)
+}
+
+// authenticated wraps next with a check that the request carries either
+// the gateway's own bearer token (full access) or a live bot token
+// (see go/libkb/bot_token.go) whose scope allows this route -- and, for
+// chat/send, the conversation the request targets.
+func (c *CmdGateway) authenticated(token, route string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "Bearer ") {
+			c.writeError(w, http.StatusUnauthorized, errors.New("missing bearer token"))
+			return
+		}
+		bearer := strings.TrimPrefix(auth, "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) == 1 {
+			next(w, r)
+			return
+		}
+
+		botToken, ok := c.lookupBotToken(bearer)
+		if !ok {
+			c.writeError(w, http.StatusUnauthorized, errors.New("invalid bearer token"))
+			return
+		}
+		var convID string
+		if route == routeChatSend {
+			convID = peekConversationID(r)
+		}
+		if !botToken.Allows(route, convID) {
+			c.writeError(w, http.StatusForbidden, errors.New("bot token is not authorized for this request"))
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (c *CmdGateway) lookupBotToken(secret string) (libkb.BotToken, bool) {
+	tokens, err := c.G().Env.GetBotTokens()
+	if err != nil {
+		return libkb.BotToken{}, false
+	}
+	for _, t := range tokens {
+		if subtle.ConstantTimeCompare([]byte(t.Token), []byte(secret)) == 1 && !t.Revoked {
+			return t, true
+		}
+	}
+	return libkb.BotToken{}, false
+}
+
+// peekConversationID extracts the "conversation_id" field from a
+// chat/send request body (see sendOptionsV1 in chat_api_handler.go)
+// without consuming the body, so scope enforcement can happen before
+// handleChatSend does its normal decode.
+func peekConversationID(r *http.Request) string {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return ""
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	var call struct {
+		Params struct {
+			Options json.RawMessage `json:"options"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(body, &call); err != nil {
+		return ""
+	}
+	var opts struct {
+		ConversationID string `json:"conversation_id"`
+	}
+	json.Unmarshal(call.Params.Options, &opts)
+	return opts.ConversationID
+}
+
+func (c *CmdGateway) writeJSON(w http.ResponseWriter, result interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(gatewayEnvelope{Version: gatewayJSONVersion, Result: result})
+}
+
+func (c *CmdGateway) writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(gatewayEnvelope{Version: gatewayJSONVersion, Error: err.Error()})
+}
+
+func (c *CmdGateway) handleStatus(w http.ResponseWriter, r *http.Request) {
+	cli, err := GetConfigClient(c.G())
+	if err != nil {
+		c.writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	res, err := cli.GetCurrentStatus(r.Context(), 0)
+	if err != nil {
+		c.writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	c.writeJSON(w, res)
+}
+
+func (c *CmdGateway) handleUserLookup(w http.ResponseWriter, r *http.Request) {
+	username := r.URL.Query().Get("username")
+	if username == "" {
+		c.writeError(w, http.StatusBadRequest, errors.New("missing required \"username\" query parameter"))
+		return
+	}
+
+	cli, err := GetUserClient(c.G())
+	if err != nil {
+		c.writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	user, err := cli.LoadUserByName(r.Context(), keybase1.LoadUserByNameArg{Username: username})
+	if err != nil {
+		c.writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	c.writeJSON(w, user)
+}
+
+// handleChatSend accepts the same JSON body as `keybase chat api`'s "send"
+// method (see chatAPIDoc), and bridges it to the same chatServiceHandler
+// used there, so the wire format doesn't drift between the two.
+func (c *CmdGateway) handleChatSend(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		c.writeError(w, http.StatusMethodNotAllowed, errors.New("expected POST"))
+		return
+	}
+
+	h := newChatServiceHandler(c.G())
+	var out bytes.Buffer
+	dec := NewChatAPIDecoder(&ChatAPI{svcHandler: h})
+	if err := dec.Decode(context.Background(), r.Body, &out); err != nil {
+		c.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(out.Bytes())
+}
+
+func (c *CmdGateway) GetUsage() libkb.Usage {
+	return libkb.Usage{
+		Config:    true,
+		KbKeyring: true,
+		API:       true,
+	}
+}
@@ -4,6 +4,7 @@
 package client
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"golang.org/x/net/context"
@@ -15,12 +16,23 @@ import (
 	"github.com/keybase/go-framed-msgpack-rpc/rpc"
 )
 
+// idJSONVersion is a schema version for CmdID's --json output, bumped
+// whenever the shape of idResultJSON changes in a backwards-incompatible
+// way, so scripts consuming it can detect skew.
+const idJSONVersion = 1
+
+type idResultJSON struct {
+	Version int                   `json:"version"`
+	Result  keybase1.Identify2Res `json:"result"`
+}
+
 type CmdID struct {
 	libkb.Contextified
 	user           string
 	useDelegateUI  bool
 	skipProofCache bool
 	forceDisplay   bool
+	json           bool
 }
 
 func (v *CmdID) ParseArgv(ctx *cli.Context) error {
@@ -35,6 +47,7 @@ func (v *CmdID) ParseArgv(ctx *cli.Context) error {
 	v.useDelegateUI = ctx.Bool("ui")
 	v.skipProofCache = ctx.Bool("skip-proof-cache")
 	v.forceDisplay = ctx.Bool("force-display")
+	v.json = ctx.Bool("json")
 	return nil
 }
 
@@ -68,7 +81,7 @@ func (v *CmdID) Run() error {
 	}
 
 	arg := v.makeArg()
-	_, err = cli.Identify2(context.TODO(), arg)
+	res, err := cli.Identify2(context.TODO(), arg)
 	if _, ok := err.(libkb.SelfNotFoundError); ok {
 		msg := `Could not find UID or username for you on this device.
 You can either specify a user to id: keybase id <username>
@@ -77,7 +90,23 @@ Or log in once on this device and run "keybase id" again.
 		v.G().UI.GetDumbOutputUI().Printf(msg)
 		return nil
 	}
-	return err
+	if err != nil {
+		return err
+	}
+	if v.json {
+		return v.outputJSON(res)
+	}
+	return nil
+}
+
+// outputJSON prints the machine-readable Identify2Res alongside the
+// interactive identify UI output that Identify2 has already driven.
+func (v *CmdID) outputJSON(res keybase1.Identify2Res) error {
+	b, err := json.MarshalIndent(idResultJSON{Version: idJSONVersion, Result: res}, "", "    ")
+	if err != nil {
+		return err
+	}
+	return DisplayJSON(string(b))
 }
 
 func NewCmdID(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
@@ -101,6 +130,10 @@ func NewCmdID(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
 				Name:  "s, skip-proof-cache",
 				Usage: "Skip cached proofs, force re-check",
 			},
+			cli.BoolFlag{
+				Name:  "j, json",
+				Usage: "Also print the identify result as JSON.",
+			},
 		},
 		Action: func(c *cli.Context) {
 			cl.ChooseCommand(NewCmdIDRunner(g), "id", c)
@@ -0,0 +1,268 @@
+// Copyright 2016 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package client
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/keybase/cli"
+	"github.com/keybase/client/go/libcmdline"
+	"github.com/keybase/client/go/libkb"
+	keybase1 "github.com/keybase/client/go/protocol/keybase1"
+	"golang.org/x/net/context"
+)
+
+// NewCmdKVStore creates the kvstore command, which is a holder for
+// subcommands that read and write the per-user encrypted key-value
+// store.
+func NewCmdKVStore(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
+	return cli.Command{
+		Name:         "kvstore",
+		Usage:        "Get, put, and delete entries in your encrypted key-value store",
+		ArgumentHelp: "[put, get, del, list-namespaces, list-keys]",
+		Subcommands: []cli.Command{
+			NewCmdKVStorePut(cl, g),
+			NewCmdKVStoreGet(cl, g),
+			NewCmdKVStoreDelete(cl, g),
+			NewCmdKVStoreListNamespaces(cl, g),
+			NewCmdKVStoreListKeys(cl, g),
+		},
+	}
+}
+
+type CmdKVStorePut struct {
+	libkb.Contextified
+	namespace    string
+	key          string
+	value        string
+	syncToServer bool
+}
+
+func NewCmdKVStorePut(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
+	return cli.Command{
+		Name:         "put",
+		Usage:        "Encrypt and store a value under a namespace and key",
+		ArgumentHelp: "<namespace> <key> <value>",
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "sync",
+				Usage: "Also push the encrypted entry to the server for backup",
+			},
+		},
+		Action: func(c *cli.Context) {
+			cmd := &CmdKVStorePut{Contextified: libkb.NewContextified(g)}
+			cl.ChooseCommand(cmd, "put", c)
+		},
+	}
+}
+
+func (c *CmdKVStorePut) ParseArgv(ctx *cli.Context) error {
+	args := ctx.Args()
+	if len(args) != 3 {
+		return errors.New("put needs 3 args: namespace, key, value")
+	}
+	c.namespace, c.key, c.value = args[0], args[1], args[2]
+	c.syncToServer = ctx.Bool("sync")
+	return nil
+}
+
+func (c *CmdKVStorePut) Run() error {
+	cli, err := GetKVStoreClient(c.G())
+	if err != nil {
+		return err
+	}
+	return cli.KVStorePut(context.Background(), keybase1.KVStorePutArg{
+		Namespace:    c.namespace,
+		Key:          c.key,
+		Value:        c.value,
+		SyncToServer: c.syncToServer,
+	})
+}
+
+func (c *CmdKVStorePut) GetUsage() libkb.Usage {
+	return libkb.Usage{API: true, Config: true}
+}
+
+type CmdKVStoreGet struct {
+	libkb.Contextified
+	namespace string
+	key       string
+}
+
+func NewCmdKVStoreGet(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
+	return cli.Command{
+		Name:         "get",
+		Usage:        "Decrypt and print a value stored under a namespace and key",
+		ArgumentHelp: "<namespace> <key>",
+		Action: func(c *cli.Context) {
+			cmd := &CmdKVStoreGet{Contextified: libkb.NewContextified(g)}
+			cl.ChooseCommand(cmd, "get", c)
+		},
+	}
+}
+
+func (c *CmdKVStoreGet) ParseArgv(ctx *cli.Context) error {
+	args := ctx.Args()
+	if len(args) != 2 {
+		return errors.New("get needs 2 args: namespace, key")
+	}
+	c.namespace, c.key = args[0], args[1]
+	return nil
+}
+
+func (c *CmdKVStoreGet) Run() error {
+	cli, err := GetKVStoreClient(c.G())
+	if err != nil {
+		return err
+	}
+	res, err := cli.KVStoreGet(context.Background(), keybase1.KVStoreGetArg{
+		Namespace: c.namespace,
+		Key:       c.key,
+	})
+	if err != nil {
+		return err
+	}
+	if !res.Found {
+		return fmt.Errorf("no entry found for %s/%s", c.namespace, c.key)
+	}
+	c.G().UI.GetTerminalUI().Printf("%s\n", res.Value)
+	return nil
+}
+
+func (c *CmdKVStoreGet) GetUsage() libkb.Usage {
+	return libkb.Usage{API: true, Config: true}
+}
+
+type CmdKVStoreDelete struct {
+	libkb.Contextified
+	namespace string
+	key       string
+}
+
+func NewCmdKVStoreDelete(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
+	return cli.Command{
+		Name:         "del",
+		Usage:        "Delete a namespace/key entry",
+		ArgumentHelp: "<namespace> <key>",
+		Action: func(c *cli.Context) {
+			cmd := &CmdKVStoreDelete{Contextified: libkb.NewContextified(g)}
+			cl.ChooseCommand(cmd, "del", c)
+		},
+	}
+}
+
+func (c *CmdKVStoreDelete) ParseArgv(ctx *cli.Context) error {
+	args := ctx.Args()
+	if len(args) != 2 {
+		return errors.New("del needs 2 args: namespace, key")
+	}
+	c.namespace, c.key = args[0], args[1]
+	return nil
+}
+
+func (c *CmdKVStoreDelete) Run() error {
+	cli, err := GetKVStoreClient(c.G())
+	if err != nil {
+		return err
+	}
+	return cli.KVStoreDelete(context.Background(), keybase1.KVStoreDeleteArg{
+		Namespace: c.namespace,
+		Key:       c.key,
+	})
+}
+
+func (c *CmdKVStoreDelete) GetUsage() libkb.Usage {
+	return libkb.Usage{API: true, Config: true}
+}
+
+type CmdKVStoreListNamespaces struct {
+	libkb.Contextified
+}
+
+func NewCmdKVStoreListNamespaces(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
+	return cli.Command{
+		Name:  "list-namespaces",
+		Usage: "List namespaces that have at least one stored entry",
+		Action: func(c *cli.Context) {
+			cmd := &CmdKVStoreListNamespaces{Contextified: libkb.NewContextified(g)}
+			cl.ChooseCommand(cmd, "list-namespaces", c)
+		},
+	}
+}
+
+func (c *CmdKVStoreListNamespaces) ParseArgv(ctx *cli.Context) error {
+	if len(ctx.Args()) > 0 {
+		return UnexpectedArgsError("list-namespaces")
+	}
+	return nil
+}
+
+func (c *CmdKVStoreListNamespaces) Run() error {
+	cli, err := GetKVStoreClient(c.G())
+	if err != nil {
+		return err
+	}
+	namespaces, err := cli.KVStoreListNamespaces(context.Background(), 0)
+	if err != nil {
+		return err
+	}
+	t := c.G().UI.GetTerminalUI()
+	for _, ns := range namespaces {
+		t.Printf("%s\n", ns)
+	}
+	return nil
+}
+
+func (c *CmdKVStoreListNamespaces) GetUsage() libkb.Usage {
+	return libkb.Usage{API: true, Config: true}
+}
+
+type CmdKVStoreListKeys struct {
+	libkb.Contextified
+	namespace string
+}
+
+func NewCmdKVStoreListKeys(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
+	return cli.Command{
+		Name:         "list-keys",
+		Usage:        "List keys stored under a namespace",
+		ArgumentHelp: "<namespace>",
+		Action: func(c *cli.Context) {
+			cmd := &CmdKVStoreListKeys{Contextified: libkb.NewContextified(g)}
+			cl.ChooseCommand(cmd, "list-keys", c)
+		},
+	}
+}
+
+func (c *CmdKVStoreListKeys) ParseArgv(ctx *cli.Context) error {
+	args := ctx.Args()
+	if len(args) != 1 {
+		return errors.New("list-keys needs 1 arg: namespace")
+	}
+	c.namespace = args[0]
+	return nil
+}
+
+func (c *CmdKVStoreListKeys) Run() error {
+	cli, err := GetKVStoreClient(c.G())
+	if err != nil {
+		return err
+	}
+	keys, err := cli.KVStoreListKeys(context.Background(), keybase1.KVStoreListKeysArg{
+		Namespace: c.namespace,
+	})
+	if err != nil {
+		return err
+	}
+	t := c.G().UI.GetTerminalUI()
+	for _, key := range keys {
+		t.Printf("%s\n", key)
+	}
+	return nil
+}
+
+func (c *CmdKVStoreListKeys) GetUsage() libkb.Usage {
+	return libkb.Usage{API: true, Config: true}
+}
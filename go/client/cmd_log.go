@@ -16,6 +16,7 @@ func NewCmdLog(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
 		ArgumentHelp: "[arguments...]",
 		Subcommands: []cli.Command{
 			NewCmdLogSend(cl, g),
+			NewCmdLogMetrics(cl, g),
 		},
 	}
 }
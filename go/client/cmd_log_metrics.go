@@ -0,0 +1,53 @@
+// Copyright 2017 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package client
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/keybase/cli"
+	"github.com/keybase/client/go/libcmdline"
+	"github.com/keybase/client/go/libkb"
+)
+
+func NewCmdLogMetrics(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
+	return cli.Command{
+		Name:  "metrics",
+		Usage: "Dump service-side counters and latencies (pvl fetch cache hits, etc.)",
+		Action: func(c *cli.Context) {
+			cl.ChooseCommand(&CmdLogMetrics{Contextified: libkb.NewContextified(g)}, "metrics", c)
+		},
+	}
+}
+
+type CmdLogMetrics struct {
+	libkb.Contextified
+}
+
+func (c *CmdLogMetrics) ParseArgv(ctx *cli.Context) error {
+	if len(ctx.Args()) > 0 {
+		return UnexpectedArgsError("log metrics")
+	}
+	return nil
+}
+
+func (c *CmdLogMetrics) Run() error {
+	cli, err := GetConfigClient(c.G())
+	if err != nil {
+		return err
+	}
+	metrics, err := cli.GetPvlMetrics(context.TODO())
+	if err != nil {
+		return err
+	}
+	dui := c.G().UI.GetDumbOutputUI()
+	dui.Printf("%s\n", metrics)
+	return nil
+}
+
+func (c *CmdLogMetrics) GetUsage() libkb.Usage {
+	return libkb.Usage{
+		Config: true,
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2018 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package client
+
+import (
+	"fmt"
+	"strconv"
+
+	"golang.org/x/net/context"
+
+	"github.com/keybase/cli"
+	"github.com/keybase/client/go/libcmdline"
+	"github.com/keybase/client/go/libkb"
+	keybase1 "github.com/keybase/client/go/protocol/keybase1"
+)
+
+func NewCmdMerkleAudit(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
+	return cli.Command{
+		Name:         "audit",
+		Usage:        "Re-verify the skip-pointer chain between cached merkle roots",
+		ArgumentHelp: "<low seqno> <high seqno>",
+		Action: func(c *cli.Context) {
+			cmd := &CmdMerkleAudit{Contextified: libkb.NewContextified(g)}
+			cl.ChooseCommand(cmd, "audit", c)
+		},
+	}
+}
+
+type CmdMerkleAudit struct {
+	libkb.Contextified
+	arg keybase1.AuditArg
+}
+
+func (c *CmdMerkleAudit) ParseArgv(ctx *cli.Context) error {
+	args := ctx.Args()
+	if len(args) != 2 {
+		return fmt.Errorf("merkle audit takes 2 args: <low seqno> <high seqno>")
+	}
+	low, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("bad low seqno: %s", err)
+	}
+	high, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return fmt.Errorf("bad high seqno: %s", err)
+	}
+	c.arg.Low = low
+	c.arg.High = high
+	return nil
+}
+
+func (c *CmdMerkleAudit) Run() error {
+	cli, err := GetMerkleClient(c.G())
+	if err != nil {
+		return err
+	}
+	res, err := cli.Audit(context.TODO(), c.arg)
+	if err != nil {
+		return err
+	}
+
+	dui := c.G().UI.GetDumbOutputUI()
+	dui.Printf("audited %d roots\n", res.RootsAudited)
+	if res.Ok {
+		dui.Printf("ok: no discrepancies found\n")
+	} else {
+		for _, problem := range res.Problems {
+			dui.Printf("problem: %s\n", problem)
+		}
+	}
+	return nil
+}
+
+func (c *CmdMerkleAudit) GetUsage() libkb.Usage {
+	return libkb.Usage{
+		Config: true,
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/keybase/cli"
 	"github.com/keybase/client/go/libcmdline"
 	"github.com/keybase/client/go/libkb"
+	keybase1 "github.com/keybase/client/go/protocol/keybase1"
 	"github.com/keybase/go-framed-msgpack-rpc/rpc"
 	"golang.org/x/net/context"
 )
@@ -15,6 +16,16 @@ func NewCmdPaperKey(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Comm
 	return cli.Command{
 		Name:  "paperkey",
 		Usage: "Generate paper keys for recovering your account",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "label",
+				Usage: "Name the new paper key, so it's easy to tell apart from your other paper keys",
+			},
+			cli.BoolFlag{
+				Name:  "strong",
+				Usage: "Generate a longer, higher-entropy paper key phrase",
+			},
+		},
 		Action: func(c *cli.Context) {
 			cl.ChooseCommand(NewCmdPaperKeyRunner(g), "paperkey", c)
 		},
@@ -23,6 +34,8 @@ func NewCmdPaperKey(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Comm
 
 type CmdPaperKey struct {
 	libkb.Contextified
+	label  string
+	strong bool
 }
 
 func NewCmdPaperKeyRunner(g *libkb.GlobalContext) *CmdPaperKey {
@@ -43,10 +56,15 @@ func (c *CmdPaperKey) Run() error {
 	if err := RegisterProtocolsWithContext(protocols, c.G()); err != nil {
 		return err
 	}
-	return cli.PaperKey(context.TODO(), 0)
+	return cli.PaperKey(context.TODO(), keybase1.PaperKeyArg{
+		Label:  c.label,
+		Strong: c.strong,
+	})
 }
 
 func (c *CmdPaperKey) ParseArgv(ctx *cli.Context) error {
+	c.label = ctx.String("label")
+	c.strong = ctx.Bool("strong")
 	return nil
 }
 
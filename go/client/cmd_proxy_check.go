@@ -0,0 +1,62 @@
+// Copyright 2018 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package client
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/keybase/cli"
+	"github.com/keybase/client/go/libcmdline"
+	"github.com/keybase/client/go/libkb"
+)
+
+func NewCmdProxyCheck(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
+	return cli.Command{
+		Name:  "check",
+		Usage: "Check whether the configured proxy is reachable for each network component",
+		Action: func(c *cli.Context) {
+			cl.ChooseCommand(&CmdProxyCheck{Contextified: libkb.NewContextified(g)}, "check", c)
+		},
+	}
+}
+
+type CmdProxyCheck struct {
+	libkb.Contextified
+}
+
+func (c *CmdProxyCheck) ParseArgv(ctx *cli.Context) error {
+	if len(ctx.Args()) > 0 {
+		return UnexpectedArgsError("proxy check")
+	}
+	return nil
+}
+
+func (c *CmdProxyCheck) Run() error {
+	cli, err := GetConfigClient(c.G())
+	if err != nil {
+		return err
+	}
+	results, err := cli.CheckProxyConnectivity(context.TODO(), 0)
+	if err != nil {
+		return err
+	}
+	dui := c.G().UI.GetDumbOutputUI()
+	for _, res := range results {
+		switch {
+		case !res.Enabled:
+			dui.Printf("%s: no proxy configured\n", res.Component)
+		case res.Reachable:
+			dui.Printf("%s: proxy reachable\n", res.Component)
+		default:
+			dui.Printf("%s: proxy unreachable: %s\n", res.Component, res.Error)
+		}
+	}
+	return nil
+}
+
+func (c *CmdProxyCheck) GetUsage() libkb.Usage {
+	return libkb.Usage{
+		Config: true,
+	}
+}
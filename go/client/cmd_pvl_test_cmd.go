@@ -0,0 +1,77 @@
+// Copyright 2018 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package client
+
+import (
+	"fmt"
+
+	"golang.org/x/net/context"
+
+	"github.com/keybase/cli"
+	"github.com/keybase/client/go/libcmdline"
+	"github.com/keybase/client/go/libkb"
+	keybase1 "github.com/keybase/client/go/protocol/keybase1"
+)
+
+func NewCmdPvlTest(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
+	return cli.Command{
+		Name:         "test",
+		Usage:        "Run the PVL interpreter against a user's live proof in verbose mode",
+		ArgumentHelp: "<username> <service>",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "kit-file",
+				Usage: "Check against a local pvl kit file instead of the cached/fetched one",
+			},
+		},
+		Action: func(c *cli.Context) {
+			cmd := &CmdPvlTest{Contextified: libkb.NewContextified(g)}
+			cl.ChooseCommand(cmd, "test", c)
+		},
+	}
+}
+
+type CmdPvlTest struct {
+	libkb.Contextified
+	arg keybase1.PvlTestArg
+}
+
+func (c *CmdPvlTest) ParseArgv(ctx *cli.Context) error {
+	args := ctx.Args()
+	if len(args) != 2 {
+		return fmt.Errorf("pvl test takes 2 args: <username> <service>")
+	}
+	c.arg.Username = args[0]
+	c.arg.Service = args[1]
+	c.arg.KitFilename = ctx.String("kit-file")
+	return nil
+}
+
+func (c *CmdPvlTest) Run() error {
+	cli, err := GetPvlClient(c.G())
+	if err != nil {
+		return err
+	}
+	res, err := cli.PvlTest(context.TODO(), c.arg)
+	if err != nil {
+		return err
+	}
+
+	dui := c.G().UI.GetDumbOutputUI()
+	for _, step := range res.Steps {
+		dui.Printf("%s\n", step)
+	}
+	if res.Success {
+		dui.Printf("ok: proof passed\n")
+	} else {
+		dui.Printf("failed: %s\n", res.ErrorMessage)
+	}
+	return nil
+}
+
+func (c *CmdPvlTest) GetUsage() libkb.Usage {
+	return libkb.Usage{
+		Config: true,
+	}
+}
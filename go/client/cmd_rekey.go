@@ -16,10 +16,11 @@ func NewCmdRekey(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command
 	return cli.Command{
 		Name:         "rekey",
 		Usage:        "Rekey status and actions",
-		ArgumentHelp: "[status, paper]",
+		ArgumentHelp: "[status, paper, now]",
 		Subcommands: []cli.Command{
 			NewCmdRekeyStatus(cl, g),
 			NewCmdRekeyPaper(cl, g),
+			NewCmdRekeyNow(cl, g),
 		},
 	}
 }
@@ -16,10 +16,11 @@ func NewCmdRekey(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command
 	return cli.Command{
 		Name:         "rekey",
 		Usage:        "Rekey status and actions",
-		ArgumentHelp: "[status, paper, trigger]",
+		ArgumentHelp: "[status, paper, now, trigger]",
 		Subcommands: []cli.Command{
 			NewCmdRekeyStatus(cl, g),
 			NewCmdRekeyPaper(cl, g),
+			NewCmdRekeyNow(cl, g),
 			NewCmdRekeyTrigger(cl, g),
 		},
 	}
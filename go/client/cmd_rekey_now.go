@@ -0,0 +1,50 @@
+// Copyright 2016 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package client
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/keybase/cli"
+	"github.com/keybase/client/go/libcmdline"
+	"github.com/keybase/client/go/libkb"
+	keybase1 "github.com/keybase/client/go/protocol/keybase1"
+)
+
+type CmdRekeyNow struct {
+	libkb.Contextified
+}
+
+func NewCmdRekeyNow(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
+	return cli.Command{
+		Name:  "now",
+		Usage: "Force an immediate rekey attempt for any TLFs that need it",
+		Action: func(c *cli.Context) {
+			cmd := &CmdRekeyNow{Contextified: libkb.NewContextified(g)}
+			cl.ChooseCommand(cmd, "now", c)
+		},
+	}
+}
+
+func (c *CmdRekeyNow) ParseArgv(ctx *cli.Context) error {
+	if len(ctx.Args()) > 0 {
+		return UnexpectedArgsError("now")
+	}
+	return nil
+}
+
+func (c *CmdRekeyNow) Run() error {
+	cli, err := GetRekeyClient(c.G())
+	if err != nil {
+		return err
+	}
+	return cli.RekeySync(context.Background(), keybase1.RekeySyncArg{Force: true})
+}
+
+func (c *CmdRekeyNow) GetUsage() libkb.Usage {
+	return libkb.Usage{
+		API:    true,
+		Config: true,
+	}
+}
@@ -17,6 +17,7 @@ func NewCmdSigs(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command
 		Subcommands: []cli.Command{
 			NewCmdSigsList(cl, g),
 			NewCmdSigsRevoke(cl, g),
+			NewCmdSigsSelfCheck(cl, g),
 		},
 	}
 }
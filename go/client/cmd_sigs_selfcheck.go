@@ -0,0 +1,67 @@
+// Copyright 2016 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package client
+
+import (
+	"fmt"
+
+	"golang.org/x/net/context"
+
+	"github.com/keybase/cli"
+	"github.com/keybase/client/go/libcmdline"
+	"github.com/keybase/client/go/libkb"
+	keybase1 "github.com/keybase/client/go/protocol/keybase1"
+)
+
+type CmdSigsSelfCheck struct {
+	libkb.Contextified
+	username string
+}
+
+func NewCmdSigsSelfCheck(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
+	return cli.Command{
+		Name:         "self-check",
+		Usage:        "Re-verify a sigchain from scratch and compare against the cached result",
+		ArgumentHelp: "[username]",
+		Action: func(c *cli.Context) {
+			cl.ChooseCommand(&CmdSigsSelfCheck{Contextified: libkb.NewContextified(g)}, "self-check", c)
+		},
+	}
+}
+
+func (s *CmdSigsSelfCheck) ParseArgv(ctx *cli.Context) error {
+	nargs := len(ctx.Args())
+	if nargs > 1 {
+		return fmt.Errorf("self-check takes at most 1 arg, a username.")
+	}
+	if nargs == 1 {
+		s.username = ctx.Args()[0]
+	}
+	return nil
+}
+
+func (s *CmdSigsSelfCheck) Run() error {
+	cli, err := GetSigsClient(s.G())
+	if err != nil {
+		return err
+	}
+	res, err := cli.SigChainSelfCheck(context.TODO(), keybase1.SigChainSelfCheckArg{Username: s.username})
+	if err != nil {
+		return err
+	}
+	ui := s.G().UI.GetTerminalUI()
+	if res.Consistent {
+		ui.Printf("consistent (%d links checked)\n", res.NumLinks)
+		return nil
+	}
+	ui.Printf("INCONSISTENT (%d links checked): %s\n", res.NumLinks, res.Message)
+	return fmt.Errorf("sigchain self-check found an inconsistency: %s", res.Message)
+}
+
+func (s *CmdSigsSelfCheck) GetUsage() libkb.Usage {
+	return libkb.Usage{
+		Config: true,
+		API:    true,
+	}
+}
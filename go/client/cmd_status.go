@@ -9,6 +9,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"golang.org/x/net/context"
 
@@ -97,6 +98,8 @@ type fstatus struct {
 	Clients              []keybase1.ClientDetails
 	PlatformInfo         keybase1.PlatformInfo
 	OSVersion            string
+	NetworkBreakers      []keybase1.NetworkBreakerStatus
+	PvlDiagnostics       *keybase1.PvlDiagnostics
 }
 
 func (c *CmdStatus) Run() error {
@@ -196,6 +199,8 @@ func (c *CmdStatus) load() (*fstatus, error) {
 	status.ProvisionedUsernames = extStatus.ProvisionedUsernames
 	status.Clients = extStatus.Clients
 	status.PlatformInfo = extStatus.PlatformInfo
+	status.NetworkBreakers = extStatus.NetworkBreakers
+	status.PvlDiagnostics = extStatus.PvlDiagnostics
 
 	// set anything os-specific:
 	if err := c.osSpecific(&status); err != nil {
@@ -290,9 +295,35 @@ func (c *CmdStatus) outputTerminal(status *fstatus) error {
 	dui.Printf("Other users:   %s\n", strings.Join(status.ProvisionedUsernames, ", "))
 
 	c.outputClients(dui, status.Clients)
+	c.outputNetworkBreakers(dui, status.NetworkBreakers)
+	c.outputPvlDiagnostics(dui, status.PvlDiagnostics)
 	return nil
 }
 
+func (c *CmdStatus) outputNetworkBreakers(dui libkb.DumbOutputUI, breakers []keybase1.NetworkBreakerStatus) {
+	if len(breakers) == 0 {
+		return
+	}
+	dui.Printf("\nNetwork breakers:\n")
+	for _, b := range breakers {
+		dui.Printf("    %-16s %s (failures: %d, successes: %d, rate-limited: %d)\n",
+			b.Name, BoolString(b.Open, "open", "closed"), b.TotalFailures, b.TotalSuccesses, b.TotalRateLimited)
+	}
+}
+
+func (c *CmdStatus) outputPvlDiagnostics(dui libkb.DumbOutputUI, diag *keybase1.PvlDiagnostics) {
+	if diag == nil {
+		return
+	}
+	dui.Printf("\nPVL/merkle:\n")
+	dui.Printf("    merkle root:   seqno %d, fetched %s\n", diag.MerkleRootSeqno, keybase1.FromTime(diag.MerkleRootFetchTime))
+	dui.Printf("    active hash:   %s\n", diag.ActiveHash)
+	dui.Printf("    cache source:  %s\n", diag.CacheSource)
+	if diag.LastFetchError != "" {
+		dui.Printf("    last fetch err: %s\n", diag.LastFetchError)
+	}
+}
+
 func (c *CmdStatus) outputClients(dui libkb.DumbOutputUI, clients []keybase1.ClientDetails) {
 	var prev keybase1.ClientType
 	for _, cli := range clients {
@@ -308,7 +339,11 @@ func (c *CmdStatus) outputClients(dui libkb.DumbOutputUI, clients []keybase1.Cli
 		if len(cli.Desc) > 0 {
 			dstr = ", description: " + cli.Desc
 		}
-		dui.Printf("    %s [pid: %d%s%s]\n", strings.Join(cli.Argv, " "), cli.Pid, vstr, dstr)
+		var ustr string
+		if !cli.ConnectedAt.IsZero() {
+			ustr = ", uptime: " + time.Since(cli.ConnectedAt.Time()).Round(time.Second).String()
+		}
+		dui.Printf("    %s [pid: %d%s%s%s]\n", strings.Join(cli.Argv, " "), cli.Pid, vstr, dstr, ustr)
 	}
 }
 
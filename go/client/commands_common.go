@@ -14,7 +14,10 @@ import (
 
 func GetCommands(cl *libcmdline.CommandLine, g *libkb.GlobalContext) []cli.Command {
 	ret := []cli.Command{
+		NewCmdAccount(cl, g),
+		NewCmdAutoreset(cl, g),
 		NewCmdBase62(cl, g),
+		NewCmdBotToken(cl, g),
 		NewCmdBTC(cl, g),
 		NewCmdCA(cl, g),
 		NewCmdCert(cl),
@@ -32,17 +35,22 @@ func GetCommands(cl *libcmdline.CommandLine, g *libkb.GlobalContext) []cli.Comma
 		NewCmdDumpKeyfamily(cl, g),
 		NewCmdDumpPushNotifications(cl, g),
 		NewCmdEncrypt(cl, g),
+		NewCmdGateway(cl, g),
 		NewCmdID(cl, g),
+		NewCmdKVStore(cl, g),
 		NewCmdListTracking(cl, g),
 		NewCmdListTrackers(cl, g),
 		NewCmdLog(cl, g),
 		NewCmdLogin(cl, g),
 		NewCmdLogout(cl, g),
+		NewCmdMerkle(cl, g),
 		NewCmdPaperKey(cl, g),
 		NewCmdPassphrase(cl, g),
 		NewCmdPGP(cl, g),
 		NewCmdPing(cl, g),
 		NewCmdProve(cl, g),
+		NewCmdProxy(cl, g),
+		NewCmdPvl(cl, g),
 		NewCmdRekey(cl, g),
 		NewCmdSearch(cl, g),
 		NewCmdSign(cl, g),
@@ -121,6 +121,9 @@ func makeServerCommandLine(g *libkb.GlobalContext, cl libkb.CommandLine,
 		"tor-mode",
 		"tor-proxy",
 		"tor-hidden-address",
+		"proof-proxy-mode",
+		"proof-proxy",
+		"dns-over-https-server",
 	}
 	args = append(args, arg0)
 
@@ -158,6 +158,13 @@ information will creep in. A better guarantee would be to run the client inside
 of a Tails VM (https://tails.boum.org), with no identifying information
 available to the client at all. Even still, it's possible for your own behavior
 to identify you, like if you fetch the PGP keys of all of your friends.
+
+The --tor-mode flag only affects requests to the Keybase API server. Fetching a
+user's claimed proofs off of third-party sites (for "keybase identify" and
+friends) is controlled separately with --proof-proxy-mode and --proof-proxy,
+since you may want proof checks routed through Tor (e.g. to reach a site
+that's blocked in your region) without paying Tor's latency cost, or losing
+your session, on ordinary API calls.
 `,
 }
 
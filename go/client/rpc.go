@@ -89,6 +89,22 @@ func GetLogClient(g *libkb.GlobalContext) (cli keybase1.LogClient, err error) {
 	return
 }
 
+func GetPvlClient(g *libkb.GlobalContext) (cli keybase1.PvlClient, err error) {
+	var rcli *rpc.Client
+	if rcli, _, err = GetRPCClientWithContext(g); err == nil {
+		cli = keybase1.PvlClient{Cli: rcli}
+	}
+	return
+}
+
+func GetMerkleClient(g *libkb.GlobalContext) (cli keybase1.MerkleClient, err error) {
+	var rcli *rpc.Client
+	if rcli, _, err = GetRPCClientWithContext(g); err == nil {
+		cli = keybase1.MerkleClient{Cli: rcli}
+	}
+	return
+}
+
 func RegisterProtocolsWithContext(prots []rpc.Protocol, g *libkb.GlobalContext) (err error) {
 	var srv *rpc.Server
 	if srv, _, err = GetRPCServer(g); err != nil {
@@ -206,6 +222,14 @@ func GetAccountClient(g *libkb.GlobalContext) (cli keybase1.AccountClient, err e
 	return
 }
 
+func GetKVStoreClient(g *libkb.GlobalContext) (cli keybase1.KVStoreClient, err error) {
+	var rcli *rpc.Client
+	if rcli, _, err = GetRPCClientWithContext(g); err == nil {
+		cli = keybase1.KVStoreClient{Cli: rcli}
+	}
+	return
+}
+
 func GetFavoriteClient() (cli keybase1.FavoriteClient, err error) {
 	var rcli *rpc.Client
 	if rcli, _, err = GetRPCClient(); err == nil {
@@ -0,0 +1,106 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package engine
+
+import (
+	"github.com/keybase/client/go/libkb"
+	keybase1 "github.com/keybase/client/go/protocol/keybase1"
+	jsonw "github.com/keybase/go-jsonw"
+)
+
+// DeviceRename is an engine that renames one of the caller's own
+// devices via a signed statement, the same way EmailChange renames an
+// email address: no server-side identity of its own to update, just a
+// new sigchain link other clients pick up on their next replay.
+type DeviceRename struct {
+	arg *keybase1.DeviceRenameArg
+	libkb.Contextified
+}
+
+// NewDeviceRename creates a new engine for renaming one of the
+// caller's devices via signature (and therefore without passphrase
+// required).
+func NewDeviceRename(a *keybase1.DeviceRenameArg, g *libkb.GlobalContext) *DeviceRename {
+	return &DeviceRename{
+		arg:          a,
+		Contextified: libkb.NewContextified(g),
+	}
+}
+
+// Name provides the name of the engine for the engine interface
+func (d *DeviceRename) Name() string {
+	return "DeviceRename"
+}
+
+// Prereqs returns engine prereqs
+func (d *DeviceRename) Prereqs() Prereqs {
+	return Prereqs{Session: true}
+}
+
+// RequiredUIs returns the required UIs.
+func (d *DeviceRename) RequiredUIs() []libkb.UIKind {
+	return []libkb.UIKind{
+		libkb.SecretUIKind,
+	}
+}
+
+// SubConsumers requires the other UI consumers of this engine
+func (d *DeviceRename) SubConsumers() []libkb.UIConsumer {
+	return []libkb.UIConsumer{}
+}
+
+// Run the engine
+func (d *DeviceRename) Run(ctx *Context) (err error) {
+	defer d.G().Trace("DeviceRename#Run", func() error { return err })()
+
+	if !libkb.CheckDeviceName.F(d.arg.NewName) {
+		return libkb.DeviceBadNameError{}
+	}
+
+	me, err := libkb.LoadMe(libkb.NewLoadUserForceArg(d.G()))
+	if err != nil {
+		return err
+	}
+
+	deviceID := d.arg.DeviceID
+	if len(deviceID) == 0 {
+		deviceID = d.G().Env.GetDeviceID()
+	}
+
+	// need unlocked signing key
+	ska := libkb.SecretKeyArg{
+		Me:      me,
+		KeyType: libkb.DeviceSigningKeyType,
+	}
+	arg := ctx.SecretKeyPromptArg(ska, "device rename signature")
+	signingKey, err := d.G().Keyrings.GetSecretKeyWithPrompt(arg)
+	if err != nil {
+		return err
+	}
+	if signingKey == nil {
+		return libkb.NoSecretKeyError{}
+	}
+
+	var proof *jsonw.Wrapper
+	proof, err = me.UpdateDeviceNameProof(signingKey, deviceID, d.arg.NewName)
+	if err != nil {
+		return err
+	}
+	var sig string
+	sig, _, _, err = libkb.SignJSON(proof, signingKey)
+	if err != nil {
+		return err
+	}
+
+	_, err = d.G().API.Post(libkb.APIArg{
+		Endpoint:    "device/rename",
+		NeedSession: true,
+		Args: libkb.HTTPArgs{
+			"sig":         libkb.S{Val: sig},
+			"signing_kid": libkb.S{Val: signingKey.GetKID().String()},
+			"device_id":   libkb.S{Val: deviceID.String()},
+		},
+	})
+	return err
+}
@@ -77,6 +77,8 @@ func (e *FavoriteAdd) Run(ctx *Context) error {
 		return err
 	}
 
+	e.G().NotifyRouter.HandleFavoritesChanged(e.G().Env.GetUID())
+
 	// this should be in its own goroutine so that potential
 	// UI calls don't block FavoriteAdd calls
 	go e.checkInviteNeeded(ctx)
@@ -60,5 +60,11 @@ func (e *FavoriteIgnore) Run(ctx *Context) error {
 			"status":   libkb.S{Val: "ignored"},
 		},
 	})
-	return err
+	if err != nil {
+		return err
+	}
+
+	e.G().NotifyRouter.HandleFavoritesChanged(e.G().Env.GetUID())
+
+	return nil
 }
@@ -54,11 +54,31 @@ func (f *FavoritesAPIResult) GetAppStatus() *libkb.AppStatus {
 	return &f.Status
 }
 
-// Run starts the engine.
+// favoritesCacheDbKey namespaces the LocalDb entry holding the calling
+// user's most recently fetched favorites list, so GetFavorites has
+// something to fall back to while offline.
+func favoritesCacheDbKey(g *libkb.GlobalContext) libkb.DbKey {
+	return libkb.DbKeyUID(libkb.DBFavorites, g.Env.GetUID())
+}
+
+// Run starts the engine. If the API fetch fails -- most likely because
+// this device is offline -- it falls back to the last successfully
+// fetched list from LocalDb rather than failing FavoriteList outright.
 func (e *FavoriteList) Run(ctx *Context) error {
 	arg := libkb.NewRetryAPIArg("kbfs/favorite/list")
 	arg.NeedSession = true
-	return e.G().API.GetDecode(arg, &e.result)
+	if err := e.G().API.GetDecode(arg, &e.result); err != nil {
+		e.G().Log.Debug("FavoriteList: API fetch failed (%s), falling back to cached favorites", err)
+		found, cacheErr := e.G().LocalDb.GetInto(&e.result, favoritesCacheDbKey(e.G()))
+		if cacheErr != nil || !found {
+			return err
+		}
+		return nil
+	}
+	if err := e.G().LocalDb.PutObj(favoritesCacheDbKey(e.G()), nil, e.result); err != nil {
+		e.G().Log.Debug("FavoriteList: failed to cache favorites list: %s", err)
+	}
+	return nil
 }
 
 // Favorites returns the list of favorites that Run generated.
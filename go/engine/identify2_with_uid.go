@@ -681,6 +681,15 @@ func (e *Identify2WithUID) runIdentifyPrecomputation() (err error) {
 	return nil
 }
 
+// requiresTrackBeforeSend is true if this identify is running under a
+// track-before-send behavior and the sender doesn't already have a track of
+// the recipient -- meaning the identify UI needs to be shown for real (see
+// ShouldSuppressTrackerPopups below) and the send should be blocked unless
+// the confirm comes back with IdentityConfirmed set.
+func (e *Identify2WithUID) requiresTrackBeforeSend() bool {
+	return e.arg.IdentifyBehavior.RequiresTrackBeforeSend() && e.state.TrackLookup() == nil
+}
+
 func (e *Identify2WithUID) displayUserCardAsync(ctx context.Context, iui libkb.IdentifyUI) <-chan error {
 	if e.arg.IdentifyBehavior.WarningInsteadOfErrorOnBrokenTracks() {
 		return nil
@@ -757,6 +766,10 @@ func (e *Identify2WithUID) runIdentifyUI(netContext context.Context, ctx *Contex
 	// use Confirm to display the IdentifyOutcome
 	outcome := e.state.Result()
 	outcome.TrackOptions = e.trackOptions
+	if pvlSource := e.G().GetPvlSource(); pvlSource != nil && pvlSource.IsDegraded(netContext) {
+		outcome.Warnings = append(outcome.Warnings, libkb.Warningf(
+			"proof-checking rules may be stale; the network was unreachable long enough that a cached, possibly outdated copy was used"))
+	}
 	e.confirmResult, err = iui.Confirm(outcome.Export())
 	if err != nil {
 		e.G().Log.CDebugf(netContext, "| Failure in iui.Confirm")
@@ -765,6 +778,11 @@ func (e *Identify2WithUID) runIdentifyUI(netContext context.Context, ctx *Contex
 
 	e.insertTrackToken(ctx, outcome, iui)
 
+	if e.requiresTrackBeforeSend() && !e.confirmResult.IdentityConfirmed {
+		e.G().Log.CDebugf(netContext, "| %s has no existing track and wasn't tracked before sending", e.them.GetName())
+		return libkb.TrackNotConfirmedError{Username: e.them.GetName()}
+	}
+
 	if err = iui.Finish(); err != nil {
 		e.G().Log.CDebugf(netContext, "| Failure in iui.Finish")
 		return err
@@ -960,7 +978,7 @@ func (e *Identify2WithUID) loadSlowCacheFromDB() (ret *keybase1.Identify2Res) {
 	tm := ktm.Time()
 	now := e.getNow()
 	diff := now.Sub(tm)
-	if diff > libkb.Identify2CacheLongTimeout {
+	if diff > e.G().Env.GetIdentify2CacheSuccessTimeout() {
 		e.G().Log.Debug("| Object timed out %s ago", diff)
 		return nil
 	}
@@ -1010,9 +1028,9 @@ func (e *Identify2WithUID) checkSlowCacheHit() (ret bool) {
 	tfn := func(u keybase1.Identify2Res) keybase1.Time { return u.Upk.Uvv.LastIdentifiedAt }
 	dfn := func(u keybase1.Identify2Res) time.Duration {
 		if u.TrackBreaks != nil {
-			return libkb.Identify2CacheBrokenTimeout
+			return e.G().Env.GetIdentify2CacheFailureTimeout()
 		}
-		return libkb.Identify2CacheLongTimeout
+		return e.G().Env.GetIdentify2CacheSuccessTimeout()
 	}
 	u, err := e.getCache().Get(e.them.GetUID(), tfn, dfn, e.arg.IdentifyBehavior.WarningInsteadOfErrorOnBrokenTracks())
 
@@ -17,8 +17,20 @@ import (
 	keybase1 "github.com/keybase/client/go/protocol/keybase1"
 )
 
+// PaperKeyArg holds the user-supplied options for generating a new
+// paper key.
+type PaperKeyArg struct {
+	// Label, if set, names the new paper key so it can be told apart
+	// from a user's other active paper keys in `keybase device list`.
+	Label string
+	// Strong asks for a longer, higher-entropy phrase (see
+	// libkb.PaperKeySecretEntropyStrong) instead of the default length.
+	Strong bool
+}
+
 // PaperKey is an engine.
 type PaperKey struct {
+	arg        *PaperKeyArg
 	passphrase libkb.PaperKeyPhrase
 	gen        *PaperKeyGen
 	libkb.Contextified
@@ -26,7 +38,14 @@ type PaperKey struct {
 
 // NewPaperKey creates a PaperKey engine.
 func NewPaperKey(g *libkb.GlobalContext) *PaperKey {
+	return NewPaperKeyWithArg(g, &PaperKeyArg{})
+}
+
+// NewPaperKeyWithArg creates a PaperKey engine with label/strength
+// options.
+func NewPaperKeyWithArg(g *libkb.GlobalContext, arg *PaperKeyArg) *PaperKey {
 	return &PaperKey{
+		arg:          arg,
 		Contextified: libkb.NewContextified(g),
 	}
 }
@@ -115,7 +134,11 @@ func (e *PaperKey) Run(ctx *Context) error {
 		return err
 	}
 
-	e.passphrase, err = libkb.MakePaperKeyPhrase(libkb.PaperKeyVersion)
+	secretEntropyBits := libkb.PaperKeySecretEntropy
+	if e.arg.Strong {
+		secretEntropyBits = libkb.PaperKeySecretEntropyStrong
+	}
+	e.passphrase, err = libkb.MakePaperKeyPhraseWithEntropy(libkb.PaperKeyVersion, secretEntropyBits)
 	if err != nil {
 		return err
 	}
@@ -124,6 +147,7 @@ func (e *PaperKey) Run(ctx *Context) error {
 		Passphrase: e.passphrase,
 		Me:         me,
 		SigningKey: signingKey,
+		Label:      e.arg.Label,
 	}
 	e.gen = NewPaperKeyGen(kgarg, e.G())
 	if err := RunEngine(e.gen, ctx); err != nil {
@@ -20,6 +20,10 @@ type PaperKeyGenArg struct {
 	SkipPush   bool
 	Me         *libkb.User
 	SigningKey libkb.GenericKey
+	// Label, if set, names the resulting paper device so it can be
+	// told apart from other active paper keys. If empty, the device
+	// falls back to the passphrase's word prefix, as it always has.
+	Label string
 }
 
 // PaperKeyGen is an engine.
@@ -185,7 +189,7 @@ func (e *PaperKeyGen) push(ctx *Context) error {
 	// Create a new paper key device. Need the passphrase prefix
 	// for the paper device name.  This is the first two words in
 	// the passphrase.  There is sufficient entropy to cover this...
-	backupDev, err := libkb.NewPaperDevice(e.arg.Passphrase.Prefix())
+	backupDev, err := libkb.NewPaperDevice(e.arg.Passphrase.Prefix(), e.arg.Label)
 	if err != nil {
 		return err
 	}
@@ -106,9 +106,12 @@ func (n *nlistener) FSSyncEvent(arg keybase1.FSPathSyncStatus)
 func (n *nlistener) BadgeState(badgeState keybase1.BadgeState)                          {}
 func (n *nlistener) ReachabilityChanged(r keybase1.Reachability)                        {}
 func (n *nlistener) ChatIdentifyUpdate(update keybase1.CanonicalTLFNameAndIDWithBreaks) {}
+func (n *nlistener) ChatTLFIdentifyProgress(progress chat1.TLFIdentifyProgress)         {}
 func (n *nlistener) ChatTLFFinalize(uid keybase1.UID, convID chat1.ConversationID, info chat1.ConversationFinalizeInfo) {
 }
 func (n *nlistener) ChatTLFResolve(uid keybase1.UID, convID chat1.ConversationID, info chat1.ConversationResolveInfo) {
 }
 func (n *nlistener) ChatInboxStale(uid keybase1.UID)                                {}
 func (n *nlistener) ChatThreadsStale(uid keybase1.UID, cids []chat1.ConversationID) {}
+func (n *nlistener) ChatTypingUpdate(typingUpdates []chat1.ConvTypingUpdate)        {}
+func (n *nlistener) ChatAttentionUpdate(uid keybase1.UID, convID chat1.ConversationID, msgID chat1.MessageID) {}
@@ -223,21 +223,13 @@ func (p *Prove) checkAutoPost(ctx *Context, txt string) error {
 	if !p.arg.Auto {
 		return nil
 	}
-	if libkb.RemoteServiceTypes[p.arg.Service] != keybase1.ProofType_ROOTER {
+	auto := p.st.GetAutoPoster()
+	if auto == nil {
 		return nil
 	}
-	p.G().Log.Debug("making automatic post of proof to rooter")
-	apiArg := libkb.APIArg{
-		Endpoint:    "rooter",
-		NeedSession: true,
-		Args: libkb.HTTPArgs{
-			"post":     libkb.S{Val: txt},
-			"username": libkb.S{Val: p.arg.Username},
-		},
-	}
-	_, err := p.G().API.Post(apiArg)
-	if err != nil {
-		p.G().Log.Debug("error posting to rooter: %s", err)
+	p.G().Log.Debug("making automatic post of proof via %s's auto-post descriptor", p.st.GetTypeName())
+	if err := auto.PostProof(p.G(), p.arg.Username, txt); err != nil {
+		p.G().Log.Debug("error auto-posting proof to %s: %s", p.st.GetTypeName(), err)
 		return err
 	}
 	return nil
@@ -277,6 +269,13 @@ func (p *Prove) promptPostedLoop(ctx *Context) (err error) {
 		err = libkb.ProofNotYetAvailableError{}
 	}
 
+	if found {
+		// Our proof state changed underneath any cached identify of us
+		// (e.g. tlfHandler's or Identify2's), so make sure the next
+		// identify of us picks it up instead of serving a stale result.
+		p.G().UserChanged(p.me.GetUID())
+	}
+
 	return
 }
 
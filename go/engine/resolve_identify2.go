@@ -79,6 +79,15 @@ func (e *ResolveThenIdentify2) resolveUID(ctx *Context) (err error) {
 		return libkb.LoginRequiredError{Context: "to identify without specifying a user assertion"}
 	}
 
+	// If the assertion is just the caller's own username, short-circuit
+	// straight to their UID instead of paying for a resolve roundtrip
+	// (Identify2WithUID.isSelfLoad will still run the usual self
+	// short-circuit once it loads both sides).
+	if uid, ok := e.selfUIDForAssertion(ctx); ok {
+		e.arg.Uid = uid
+		return nil
+	}
+
 	rres := e.G().Resolver.ResolveFullExpressionWithBody(ctx.GetNetContext(), e.arg.UserAssertion)
 	if err = rres.GetError(); err != nil {
 		return err
@@ -97,6 +106,25 @@ func (e *ResolveThenIdentify2) resolveUID(ctx *Context) (err error) {
 	return nil
 }
 
+// selfUIDForAssertion returns the logged-in user's UID and true if the
+// assertion is a plain username (no social/service prefix) matching
+// their own, so callers can skip a needless resolve+identify of
+// themselves.
+func (e *ResolveThenIdentify2) selfUIDForAssertion(ctx *Context) (keybase1.UID, bool) {
+	if len(e.arg.UserAssertion) == 0 || !libkb.CheckUsername.F(e.arg.UserAssertion) {
+		return "", false
+	}
+	name := libkb.NewNormalizedUsername(e.arg.UserAssertion)
+	if !e.G().Env.GetUsername().Eq(name) {
+		return "", false
+	}
+	ok, uid, err := IsLoggedIn(e, ctx)
+	if err != nil || !ok {
+		return "", false
+	}
+	return uid, true
+}
+
 func (e *ResolveThenIdentify2) Run(ctx *Context) (err error) {
 	e.SetGlobalContext(ctx.CloneGlobalContextWithLogTags(e.G(), "ID2"))
 
@@ -0,0 +1,70 @@
+// Copyright 2016 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package engine
+
+import (
+	"github.com/keybase/client/go/libkb"
+)
+
+// SigChainSelfCheckEngine loads a user's sigchain and asks it to
+// re-verify itself from scratch, bypassing the incremental checkpoint
+// cache, to check that the cache hasn't drifted from a full replay.
+type SigChainSelfCheckEngine struct {
+	libkb.Contextified
+	username string
+	result   libkb.SigChainSelfCheckResult
+}
+
+// NewSigChainSelfCheck creates a SigChainSelfCheckEngine for username.
+// An empty username means the logged-in user.
+func NewSigChainSelfCheck(g *libkb.GlobalContext, username string) *SigChainSelfCheckEngine {
+	return &SigChainSelfCheckEngine{
+		Contextified: libkb.NewContextified(g),
+		username:     username,
+	}
+}
+
+// Name is the unique engine name.
+func (e *SigChainSelfCheckEngine) Name() string {
+	return "SigChainSelfCheckEngine"
+}
+
+// Prereqs returns the engine prereqs.
+func (e *SigChainSelfCheckEngine) Prereqs() Prereqs {
+	return Prereqs{Session: len(e.username) == 0}
+}
+
+// RequiredUIs returns the required UIs.
+func (e *SigChainSelfCheckEngine) RequiredUIs() []libkb.UIKind {
+	return nil
+}
+
+// SubConsumers returns the other UI consumers for this engine.
+func (e *SigChainSelfCheckEngine) SubConsumers() []libkb.UIConsumer {
+	return nil
+}
+
+// Run starts the engine.
+func (e *SigChainSelfCheckEngine) Run(ctx *Context) error {
+	var arg libkb.LoadUserArg
+	if len(e.username) > 0 {
+		arg = libkb.NewLoadUserByNameArg(e.G(), e.username)
+	} else {
+		arg = libkb.NewLoadUserSelfArg(e.G())
+	}
+	arg.ForcePoll = true
+
+	u, err := libkb.LoadUser(arg)
+	if err != nil {
+		return err
+	}
+
+	e.result, err = u.SigChainSelfCheck(ctx.GetNetContext())
+	return err
+}
+
+// Result returns the outcome of the self-check.
+func (e *SigChainSelfCheckEngine) Result() libkb.SigChainSelfCheckResult {
+	return e.result
+}
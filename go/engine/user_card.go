@@ -4,6 +4,8 @@
 package engine
 
 import (
+	"sync"
+
 	"github.com/keybase/client/go/libkb"
 	"github.com/keybase/client/go/protocol/keybase1"
 	context "golang.org/x/net/context"
@@ -38,10 +40,29 @@ func getUserCard(ctx context.Context, g *libkb.GlobalContext, uid keybase1.UID,
 		g.Log.CDebugf(ctx, "CardCache.Get error: %s", err)
 	} else if cached != nil {
 		g.Log.CDebugf(ctx, "CardCache.Get hit for %s", uid)
+		if g.CardCache.IsStale(uid, useSession) {
+			g.Log.CDebugf(ctx, "CardCache entry for %s is stale; refreshing in the background", uid)
+			refreshUserCardInBackground(g, uid, useSession)
+		}
 		return cached, nil
 	}
 	g.Log.CDebugf(ctx, "CardCache.Get miss for %s", uid)
 
+	ret, err = fetchUserCard(ctx, g, uid, useSession)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := g.CardCache.Set(ret, useSession); err != nil {
+		g.Log.CDebugf(ctx, "CardCache.Set error: %s", err)
+	}
+
+	return ret, nil
+}
+
+// fetchUserCard hits user/card on the API server and builds a
+// keybase1.UserCard from the response. It doesn't touch the cache.
+func fetchUserCard(ctx context.Context, g *libkb.GlobalContext, uid keybase1.UID, useSession bool) (*keybase1.UserCard, error) {
 	arg := libkb.APIArg{
 		Endpoint:    "user/card",
 		NeedSession: useSession,
@@ -50,13 +71,12 @@ func getUserCard(ctx context.Context, g *libkb.GlobalContext, uid keybase1.UID,
 	}
 
 	var card card
-
-	if err = g.API.GetDecode(arg, &card); err != nil {
+	if err := g.API.GetDecode(arg, &card); err != nil {
 		g.Log.CWarningf(ctx, "error getting user/card for %s: %s\n", uid, err)
 		return nil, err
 	}
 
-	ret = &keybase1.UserCard{
+	return &keybase1.UserCard{
 		Following:     card.FollowSummary.Following,
 		Followers:     card.FollowSummary.Followers,
 		Uid:           uid,
@@ -67,13 +87,48 @@ func getUserCard(ctx context.Context, g *libkb.GlobalContext, uid keybase1.UID,
 		Twitter:       card.Profile.Twitter,
 		YouFollowThem: card.YouFollowThem,
 		TheyFollowYou: card.TheyFollowYou,
-	}
+	}, nil
+}
 
-	if err := g.CardCache.Set(ret, useSession); err != nil {
-		g.Log.CDebugf(ctx, "CardCache.Set error: %s", err)
+// cardRefreshInFlight de-dupes background user card refreshes so that a
+// burst of stale cache hits for the same uid only triggers one
+// outstanding request to user/card.
+var cardRefreshInFlight = struct {
+	sync.Mutex
+	uids map[keybase1.UID]bool
+}{uids: make(map[keybase1.UID]bool)}
+
+// refreshUserCardInBackground re-fetches uid's UserCard without blocking
+// the caller. On success it updates the cache and fires a UserChanged
+// notification so that GUIs watching that uid know to re-pull the fresh
+// profile data.
+func refreshUserCardInBackground(g *libkb.GlobalContext, uid keybase1.UID, useSession bool) {
+	cardRefreshInFlight.Lock()
+	if cardRefreshInFlight.uids[uid] {
+		cardRefreshInFlight.Unlock()
+		return
 	}
+	cardRefreshInFlight.uids[uid] = true
+	cardRefreshInFlight.Unlock()
 
-	return ret, nil
+	go func() {
+		defer func() {
+			cardRefreshInFlight.Lock()
+			delete(cardRefreshInFlight.uids, uid)
+			cardRefreshInFlight.Unlock()
+		}()
+
+		ctx := context.Background()
+		fresh, err := fetchUserCard(ctx, g, uid, useSession)
+		if err != nil {
+			g.Log.CDebugf(ctx, "background user card refresh for %s failed: %s", uid, err)
+			return
+		}
+		if err := g.CardCache.Set(fresh, useSession); err != nil {
+			g.Log.CDebugf(ctx, "CardCache.Set error: %s", err)
+		}
+		g.NotifyRouter.HandleUserChanged(uid)
+	}()
 }
 
 func displayUserCard(ctx context.Context, g *libkb.GlobalContext, iui libkb.IdentifyUI, uid keybase1.UID, useSession bool) error {
@@ -0,0 +1,161 @@
+// Copyright 2018 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package externals
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	libkb "github.com/keybase/client/go/libkb"
+	"github.com/keybase/client/go/pvl"
+	keybase1 "github.com/keybase/client/go/protocol/keybase1"
+	jsonw "github.com/keybase/go-jsonw"
+)
+
+//=============================================================================
+// GenericChecker: verifies a proof purely through the PVL kit's own script
+// for the service, using no service-specific Go code.
+//
+
+type GenericChecker struct {
+	proof     libkb.RemoteProofChainLink
+	proofType keybase1.ProofType
+}
+
+var _ libkb.ProofChecker = (*GenericChecker)(nil)
+
+func NewGenericChecker(proofType keybase1.ProofType, p libkb.RemoteProofChainLink) (*GenericChecker, libkb.ProofError) {
+	return &GenericChecker{proof: p, proofType: proofType}, nil
+}
+
+func (rc *GenericChecker) GetTorError() libkb.ProofError { return nil }
+
+func (rc *GenericChecker) CheckStatus(ctx libkb.ProofContext, h libkb.SigHint, _ libkb.ProofCheckerMode) libkb.ProofError {
+	return CheckProofPvl(ctx, rc.proofType, rc.proof, h)
+}
+
+//=============================================================================
+// GenericServiceType: a libkb.ServiceType driven entirely by a pvl kit's
+// service_metadata entry, so a new proof service can appear in `keybase
+// prove` as soon as PVL knows how to check it, without a Go code change.
+//
+// This is necessarily narrower than a hand-written proof_support_*.go: it
+// can only offer a generic username validator, a plain-text prompt, and
+// generic instructions. Any service that needs bespoke normalization
+// (Facebook's dot-preserving remote names, DNS's TXT record format, etc.)
+// still needs real Go code, and its keybase1.ProofType still has to exist
+// in the compiled-in enum -- only the ServiceType wiring becomes data
+// driven.
+//
+
+var genericUsernameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_.-]{1,50}$`)
+
+type GenericServiceType struct {
+	libkb.BaseServiceType
+	proofType keybase1.ProofType
+	key       string
+	meta      pvl.ServiceMetadata
+}
+
+func (t GenericServiceType) AllStringKeys() []string { return t.meta.Keys }
+
+func (t GenericServiceType) NormalizeUsername(s string) (string, error) {
+	if !genericUsernameRegexp.MatchString(s) {
+		return "", libkb.NewBadUsernameError(s)
+	}
+	return strings.ToLower(s), nil
+}
+
+func (t GenericServiceType) NormalizeRemoteName(_ libkb.ProofContext, s string) (string, error) {
+	s = strings.TrimPrefix(s, "@")
+	return t.NormalizeUsername(s)
+}
+
+func (t GenericServiceType) GetPrompt() string {
+	if t.meta.Prompt != "" {
+		return t.meta.Prompt
+	}
+	return fmt.Sprintf("Your username on %s", t.DisplayName(""))
+}
+
+func (t GenericServiceType) ToServiceJSON(un string) *jsonw.Wrapper {
+	return t.BaseToServiceJSON(t, un)
+}
+
+func (t GenericServiceType) PostInstructions(un string) *libkb.Markup {
+	return libkb.FmtMarkup(fmt.Sprintf("Please post the following to your %s account, and don't delete it:", t.DisplayName("")))
+}
+
+func (t GenericServiceType) DisplayName(un string) string {
+	if t.meta.DisplayName != "" {
+		return t.meta.DisplayName
+	}
+	return t.key
+}
+
+func (t GenericServiceType) GetTypeName() string { return t.key }
+
+func (t GenericServiceType) RecheckProofPosting(tryNumber int, status keybase1.ProofStatus, _ string) (warning *libkb.Markup, err error) {
+	return t.BaseRecheckProofPosting(tryNumber, status)
+}
+
+func (t GenericServiceType) GetProofType() string { return t.BaseGetProofType(t) }
+
+func (t GenericServiceType) CheckProofText(text string, id keybase1.SigID, sig string) error {
+	return t.BaseCheckProofTextFull(text, id, sig)
+}
+
+func (t GenericServiceType) MakeProofChecker(l libkb.RemoteProofChainLink) libkb.ProofChecker {
+	pc, _ := NewGenericChecker(t.proofType, l)
+	return pc
+}
+
+//=============================================================================
+
+// registeredGenericTypes tracks which ProofTypes RegisterFromKit has
+// already registered a GenericServiceType for, so a later kit (with the
+// metadata removed, or a fresh process) doesn't leak stale services and
+// so a real proof_support_*.go landing later isn't shadowed retroactively
+// -- RegisterFromKit only ever fills in gaps, never overwrites.
+var registeredGenericTypesMu sync.Mutex
+var registeredGenericTypes = make(map[keybase1.ProofType]bool)
+
+// RegisterFromKit registers a GenericServiceType for every entry in a
+// pvl kit's service_metadata table whose proof type isn't already served
+// by a hardcoded proof_support_*.go ServiceType (checked by trying to
+// resolve each of its keys first) or by an earlier call to
+// RegisterFromKit. It's meant to be driven by libkb.PvlSource.OnPvlUpdate
+// (see externals.WatchPvlKitServices), so newly announced services show
+// up in `keybase prove` as soon as the client refreshes its pvl kit.
+func RegisterFromKit(metadata map[keybase1.ProofType]pvl.ServiceMetadata) {
+	registeredGenericTypesMu.Lock()
+	defer registeredGenericTypesMu.Unlock()
+
+	for proofType, meta := range metadata {
+		if registeredGenericTypes[proofType] {
+			continue
+		}
+		if len(meta.Keys) == 0 {
+			continue
+		}
+		alreadyHardcoded := false
+		for _, k := range meta.Keys {
+			if externalServices.GetServiceType(k) != nil {
+				alreadyHardcoded = true
+				break
+			}
+		}
+		if alreadyHardcoded {
+			continue
+		}
+		externalServices.Register(GenericServiceType{
+			proofType: proofType,
+			key:       meta.Keys[0],
+			meta:      meta,
+		})
+		registeredGenericTypes[proofType] = true
+	}
+}
@@ -1,7 +1,9 @@
 package externals
 
 import (
+	"github.com/keybase/client/go/avatars"
 	"github.com/keybase/client/go/libkb"
+	"github.com/keybase/client/go/pvl"
 	"github.com/keybase/client/go/pvlsource"
 )
 
@@ -9,5 +11,32 @@ func NewGlobalContextInit() *libkb.GlobalContext {
 	ret := libkb.NewGlobalContext().Init()
 	ret.SetServices(GetServices())
 	pvlsource.NewPvlSourceAndInstall(ret)
+	WatchPvlKitServices(ret)
+	avatars.NewSourceAndInstall(ret)
 	return ret
 }
+
+// WatchPvlKitServices registers a PvlUpdateListener that keeps the
+// dynamic proof-service registry (see RegisterFromKit) in sync with
+// whatever pvl kit the client currently has cached, so a new service
+// rolled out server-side appears without waiting for the next client
+// release.
+func WatchPvlKitServices(g *libkb.GlobalContext) {
+	pvlSource := g.GetPvlSource()
+	if pvlSource == nil {
+		return
+	}
+	pvlSource.OnPvlUpdate(func(oldHash, newHash string) {
+		kitString, err := pvlSource.GetPVL(g.GetNetContext(), pvl.SupportedVersion)
+		if err != nil {
+			g.Log.Debug("WatchPvlKitServices: could not load updated pvl kit: %s", err)
+			return
+		}
+		metadata, err := pvl.ExtractServiceMetadata(kitString)
+		if err != nil {
+			g.Log.Debug("WatchPvlKitServices: could not parse service_metadata: %s", err)
+			return
+		}
+		RegisterFromKit(metadata)
+	})
+}
@@ -85,6 +85,29 @@ func (t RooterServiceType) MakeProofChecker(l libkb.RemoteProofChainLink) libkb.
 
 func (t RooterServiceType) IsDevelOnly() bool { return true }
 
+// GetAutoPoster returns a descriptor that posts the proof to the test
+// "rooter" service via the Keybase API server, since Rooter isn't a real
+// remote service -- it exists so the auto-post path (Prove.checkAutoPost)
+// has something to drive end-to-end in tests without needing a real
+// per-service OAuth integration.
+func (t RooterServiceType) GetAutoPoster() libkb.AutoPostAPI { return rooterAutoPostAPI{} }
+
+type rooterAutoPostAPI struct{}
+
+func (rooterAutoPostAPI) PostProof(ctx libkb.ProofContext, remotename string, proofText string) error {
+	apiArg := libkb.APIArg{
+		Endpoint:    "rooter",
+		NeedSession: true,
+		Args: libkb.HTTPArgs{
+			"post":     libkb.S{Val: proofText},
+			"username": libkb.S{Val: remotename},
+		},
+		NetContext: ctx.GetNetContext(),
+	}
+	_, err := ctx.GetAPI().Post(apiArg)
+	return err
+}
+
 //=============================================================================
 
 func init() {
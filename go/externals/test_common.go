@@ -16,5 +16,6 @@ func SetupTest(tb testing.TB, name string, depth int) (tc libkb.TestContext) {
 	ret := libkb.SetupTest(tb, name, depth+1)
 	ret.G.SetServices(GetServices())
 	pvlsource.NewPvlSourceAndInstall(ret.G)
+	WatchPvlKitServices(ret.G)
 	return ret
 }
@@ -44,17 +44,38 @@ type ChatMockWorld struct {
 
 	// each slice should always be sorted by message ID in desc, i.e. newest messages first
 	Msgs map[string][]*chat1.MessageBoxed
+
+	// inboxVers is bumped by every mutation that changes a conversation, and
+	// convVersLog records which conversation changed at which version, so
+	// SyncInbox can answer "what changed since vers X" the way the real
+	// server does.
+	inboxVers   chat1.InboxVers
+	convVersLog []convVersLogEntry
+}
+
+type convVersLogEntry struct {
+	vers   chat1.InboxVers
+	convID chat1.ConversationID
+}
+
+// bumpInboxVers records convID as having changed, and returns the new
+// inbox version.
+func (w *ChatMockWorld) bumpInboxVers(convID chat1.ConversationID) chat1.InboxVers {
+	w.inboxVers++
+	w.convVersLog = append(w.convVersLog, convVersLogEntry{vers: w.inboxVers, convID: convID})
+	return w.inboxVers
 }
 
 func NewChatMockWorld(t *testing.T, name string, numUsers int) (world *ChatMockWorld) {
 	world = &ChatMockWorld{
-		Fc:      clockwork.NewFakeClockAt(time.Now()),
-		Tcs:     make(map[string]*ChatTestContext),
-		TcsByID: make(map[string]*ChatTestContext),
-		Users:   make(map[string]*FakeUser),
-		tlfs:    make(map[keybase1.CanonicalTlfName]chat1.TLFID),
-		tlfKeys: make(map[keybase1.CanonicalTlfName][]keybase1.CryptKey),
-		Msgs:    make(map[string][]*chat1.MessageBoxed),
+		Fc:        clockwork.NewFakeClockAt(time.Now()),
+		Tcs:       make(map[string]*ChatTestContext),
+		TcsByID:   make(map[string]*ChatTestContext),
+		Users:     make(map[string]*FakeUser),
+		tlfs:      make(map[keybase1.CanonicalTlfName]chat1.TLFID),
+		tlfKeys:   make(map[keybase1.CanonicalTlfName][]keybase1.CryptKey),
+		Msgs:      make(map[string][]*chat1.MessageBoxed),
+		inboxVers: 1,
 	}
 	for i := 0; i < numUsers; i++ {
 		kbTc := externals.SetupTest(t, "chat_"+name, 0)
@@ -200,6 +221,37 @@ func (m TlfMock) PublicCanonicalTLFNameAndID(ctx context.Context, arg keybase1.T
 	return res, nil
 }
 
+func (m TlfMock) CryptKeysBatch(ctx context.Context, arg keybase1.TLFQueryBatch) ([]keybase1.CryptKeysBatchItem, error) {
+	res := make([]keybase1.CryptKeysBatchItem, len(arg.TlfNames))
+	for i, name := range arg.TlfNames {
+		item := keybase1.CryptKeysBatchItem{TlfName: name}
+		cryptKeys, err := m.CryptKeys(ctx, keybase1.TLFQuery{
+			TlfName:          name,
+			IdentifyBehavior: arg.IdentifyBehavior,
+			BypassCache:      arg.BypassCache,
+		})
+		if err != nil {
+			item.Err = err.Error()
+		} else {
+			item.Res = cryptKeys
+		}
+		res[i] = item
+	}
+	return res, nil
+}
+
+func (m TlfMock) SetTlfIdentifyBehavior(ctx context.Context, arg keybase1.SetTlfIdentifyBehaviorArg) error {
+	return nil
+}
+
+func (m TlfMock) GetTlfIdentifyBehavior(ctx context.Context, tlfName string) (*keybase1.TLFIdentifyBehavior, error) {
+	return nil, nil
+}
+
+func (m TlfMock) ClearTlfIdentifyBehavior(ctx context.Context, tlfName string) error {
+	return nil
+}
+
 type ChatRemoteMock struct {
 	world     *ChatMockWorld
 	readMsgid map[string]chat1.MessageID
@@ -241,7 +293,9 @@ func (m *ChatRemoteMock) inConversation(conv *chat1.Conversation) bool {
 
 func (m *ChatRemoteMock) GetInboxRemote(ctx context.Context, arg chat1.GetInboxRemoteArg) (res chat1.GetInboxRemoteRes, err error) {
 	// TODO: add pagination support
-	var ibfull chat1.InboxViewFull
+	ibfull := chat1.InboxViewFull{
+		Vers: m.world.inboxVers,
+	}
 	for _, conv := range m.world.conversations {
 		if !m.inConversation(conv) {
 			continue
@@ -392,6 +446,7 @@ func (m *ChatRemoteMock) PostRemote(ctx context.Context, arg chat1.PostRemoteArg
 	for _, m := range conv.MaxMsgs {
 		conv.MaxMsgSummaries = append(conv.MaxMsgSummaries, m.Summary())
 	}
+	m.world.bumpInboxVers(arg.ConversationID)
 	sort.Sort(convByNewlyUpdated{mock: m})
 	res.MsgHeader = *inserted.ServerHeader
 	res.RateLimit = &chat1.RateLimit{}
@@ -453,6 +508,7 @@ func (m *ChatRemoteMock) NewConversationRemote2(ctx context.Context, arg chat1.N
 		MaxMsgSummaries: []chat1.MessageSummary{first.Summary()},
 	})
 	m.readMsgid[res.ConvID.String()] = first.ServerHeader.MessageID
+	m.world.bumpInboxVers(res.ConvID)
 
 	sort.Sort(convByNewlyUpdated{mock: m})
 	return res, nil
@@ -494,6 +550,20 @@ func (m *ChatRemoteMock) SetConversationStatus(ctx context.Context, arg chat1.Se
 	return chat1.SetConversationStatusRes{}, errors.New("not implemented")
 }
 
+func (m *ChatRemoteMock) SetConversationRetention(ctx context.Context, arg chat1.SetConversationRetentionArg) (res chat1.SetConversationRetentionRes, err error) {
+	conv := m.world.GetConversationByID(arg.ConversationID)
+	if conv == nil {
+		return chat1.SetConversationRetentionRes{}, errors.New("no such conversation")
+	}
+	conv.Metadata.RetentionPolicy = arg.Policy
+	m.world.bumpInboxVers(arg.ConversationID)
+	return chat1.SetConversationRetentionRes{}, nil
+}
+
+func (m *ChatRemoteMock) ReportConversation(ctx context.Context, arg chat1.ReportConversationArg) (res chat1.ReportConversationRes, err error) {
+	return chat1.ReportConversationRes{}, errors.New("not implemented")
+}
+
 func (m *ChatRemoteMock) TlfFinalize(ctx context.Context, arg chat1.TlfFinalizeArg) error {
 	return nil
 }
@@ -502,16 +572,48 @@ func (m *ChatRemoteMock) TlfResolve(ctx context.Context, arg chat1.TlfResolveArg
 	return nil
 }
 
+func (m *ChatRemoteMock) UpdateTypingRemote(ctx context.Context, arg chat1.UpdateTypingRemoteArg) error {
+	return nil
+}
+
 func (m *ChatRemoteMock) GetUnreadUpdateFull(ctx context.Context, inboxVers chat1.InboxVers) (chat1.UnreadUpdateFull, error) {
 	return chat1.UnreadUpdateFull{}, errors.New("not implemented")
 }
 
 func (m *ChatRemoteMock) GetInboxVersion(ctx context.Context, uid gregor1.UID) (chat1.InboxVers, error) {
-	return 1, nil
+	return m.world.inboxVers, nil
 }
 
+// SyncInbox tells the caller which conversations changed since vers, using
+// world.convVersLog to reconstruct the diff. This mirrors how the real
+// server answers chat.1.remote.syncInbox off its own version vector.
 func (m *ChatRemoteMock) SyncInbox(ctx context.Context, vers chat1.InboxVers) (chat1.SyncInboxRes, error) {
-	return chat1.SyncInboxRes{}, nil
+	if vers == m.world.inboxVers {
+		return chat1.NewSyncInboxResWithCurrent(), nil
+	}
+	if vers == 0 || vers > m.world.inboxVers {
+		// Never synced before, or referencing a version we don't have a
+		// changelog for -- the caller needs to throw away its cache and
+		// fetch a fresh inbox.
+		return chat1.NewSyncInboxResWithClear(), nil
+	}
+
+	seen := make(map[string]bool)
+	var convs []chat1.Conversation
+	for _, entry := range m.world.convVersLog {
+		if entry.vers <= vers || seen[entry.convID.String()] {
+			continue
+		}
+		seen[entry.convID.String()] = true
+		if conv := m.world.GetConversationByID(entry.convID); conv != nil {
+			convs = append(convs, *conv)
+		}
+	}
+
+	return chat1.NewSyncInboxResWithIncremental(chat1.SyncIncrementalRes{
+		Vers:  m.world.inboxVers,
+		Convs: convs,
+	}), nil
 }
 
 type convByNewlyUpdated struct {
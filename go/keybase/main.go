@@ -13,6 +13,7 @@ import (
 
 	"golang.org/x/net/context"
 
+	"github.com/keybase/client/go/avatars"
 	"github.com/keybase/client/go/client"
 	"github.com/keybase/client/go/externals"
 	"github.com/keybase/client/go/install"
@@ -62,6 +63,10 @@ func main() {
 
 	// Set a pvl source
 	pvlsource.NewPvlSourceAndInstall(g)
+	externals.WatchPvlKitServices(g)
+
+	// Set an avatar loader
+	avatars.NewSourceAndInstall(g)
 
 	// Don't abort here. This should not happen on any known version of Windows, but
 	// new MS platforms may create regressions.
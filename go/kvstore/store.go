@@ -0,0 +1,255 @@
+// Copyright 2016 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+// Package kvstore implements a small per-user encrypted key-value
+// store, namespaced so multiple Keybase components (or third-party
+// integrations talking to the RPC surface) can each keep their own
+// small secrets on disk without stepping on each other. Values are
+// encrypted with a symmetric key derived from the logged-in user's
+// per-device encryption key before they're written to LocalDb, the
+// same approach go/chat/storage uses for its local message cache.
+package kvstore
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/keybase/client/go/libkb"
+	keybase1 "github.com/keybase/client/go/protocol/keybase1"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/net/context"
+)
+
+type boxedEntry struct {
+	V int
+	N [24]byte
+	E []byte
+}
+
+// KVStore reads and writes entries in the per-user encrypted
+// key-value store. Namespace and key names are kept as plaintext
+// index metadata; only the values themselves are encrypted.
+type KVStore struct {
+	libkb.Contextified
+
+	getSecretUI func() libkb.SecretUI
+}
+
+func New(g *libkb.GlobalContext, getSecretUI func() libkb.SecretUI) *KVStore {
+	return &KVStore{
+		Contextified: libkb.NewContextified(g),
+		getSecretUI:  getSecretUI,
+	}
+}
+
+func entryDbKey(uid keybase1.UID, namespace, key string) libkb.DbKey {
+	return libkb.DbKey{
+		Typ: libkb.DBKVStore,
+		Key: fmt.Sprintf("entry:%s:%s:%s", uid, namespace, key),
+	}
+}
+
+func namespaceIndexDbKey(uid keybase1.UID) libkb.DbKey {
+	return libkb.DbKey{
+		Typ: libkb.DBKVStore,
+		Key: fmt.Sprintf("namespaces:%s", uid),
+	}
+}
+
+func keyIndexDbKey(uid keybase1.UID, namespace string) libkb.DbKey {
+	return libkb.DbKey{
+		Typ: libkb.DBKVStore,
+		Key: fmt.Sprintf("keys:%s:%s", uid, namespace),
+	}
+}
+
+func (s *KVStore) readIndex(dbKey libkb.DbKey) ([]string, error) {
+	var index []string
+	found, err := s.G().LocalDb.GetInto(&index, dbKey)
+	if err != nil || !found {
+		return nil, err
+	}
+	return index, nil
+}
+
+func addToIndex(index []string, item string) ([]string, bool) {
+	for _, existing := range index {
+		if existing == item {
+			return index, false
+		}
+	}
+	return append(index, item), true
+}
+
+func removeFromIndex(index []string, item string) ([]string, bool) {
+	for i, existing := range index {
+		if existing == item {
+			return append(index[:i], index[i+1:]...), true
+		}
+	}
+	return index, false
+}
+
+func (s *KVStore) addToIndices(uid keybase1.UID, namespace, key string) error {
+	namespaces, err := s.readIndex(namespaceIndexDbKey(uid))
+	if err != nil {
+		return err
+	}
+	if namespaces, changed := addToIndex(namespaces, namespace); changed {
+		if err := s.G().LocalDb.PutObj(namespaceIndexDbKey(uid), nil, namespaces); err != nil {
+			return err
+		}
+	}
+
+	keys, err := s.readIndex(keyIndexDbKey(uid, namespace))
+	if err != nil {
+		return err
+	}
+	if keys, changed := addToIndex(keys, key); changed {
+		if err := s.G().LocalDb.PutObj(keyIndexDbKey(uid, namespace), nil, keys); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *KVStore) removeFromIndices(uid keybase1.UID, namespace, key string) error {
+	keys, err := s.readIndex(keyIndexDbKey(uid, namespace))
+	if err != nil {
+		return err
+	}
+	keys, changed := removeFromIndex(keys, key)
+	if !changed {
+		return nil
+	}
+	if len(keys) == 0 {
+		if err := s.G().LocalDb.Delete(keyIndexDbKey(uid, namespace)); err != nil {
+			return err
+		}
+		namespaces, err := s.readIndex(namespaceIndexDbKey(uid))
+		if err != nil {
+			return err
+		}
+		if namespaces, changed := removeFromIndex(namespaces, namespace); changed {
+			return s.G().LocalDb.PutObj(namespaceIndexDbKey(uid), nil, namespaces)
+		}
+		return nil
+	}
+	return s.G().LocalDb.PutObj(keyIndexDbKey(uid, namespace), nil, keys)
+}
+
+// Put encrypts value and stores it under namespace/key for uid,
+// replacing any existing entry. If syncToServer is set, the encrypted
+// blob is also pushed to the server so it can be recovered from
+// another login on this same device; the server never sees value in
+// the clear.
+func (s *KVStore) Put(ctx context.Context, uid keybase1.UID, namespace, key, value string, syncToServer bool) error {
+	enckey, err := getSecretBoxKey(ctx, s.G(), s.getSecretUI)
+	if err != nil {
+		return err
+	}
+	nonce, err := libkb.RandBytes(24)
+	if err != nil {
+		return err
+	}
+	var fnonce [24]byte
+	copy(fnonce[:], nonce)
+
+	sealed := secretbox.Seal(nil, []byte(value), &fnonce, &enckey)
+	boxed := boxedEntry{V: cryptoVersion, N: fnonce, E: sealed}
+	raw, err := json.Marshal(boxed)
+	if err != nil {
+		return err
+	}
+
+	if err := s.G().LocalDb.PutRaw(entryDbKey(uid, namespace, key), raw); err != nil {
+		return err
+	}
+	if err := s.addToIndices(uid, namespace, key); err != nil {
+		return err
+	}
+
+	if syncToServer {
+		return s.putToServer(namespace, key, boxed)
+	}
+	return nil
+}
+
+// Get decrypts and returns the value stored under namespace/key for
+// uid, if any.
+func (s *KVStore) Get(ctx context.Context, uid keybase1.UID, namespace, key string) (value string, found bool, err error) {
+	raw, found, err := s.G().LocalDb.GetRaw(entryDbKey(uid, namespace, key))
+	if err != nil || !found {
+		return "", found, err
+	}
+
+	var boxed boxedEntry
+	if err := json.Unmarshal(raw, &boxed); err != nil {
+		return "", true, err
+	}
+	if boxed.V > cryptoVersion {
+		return "", true, fmt.Errorf("kvstore: unsupported crypto version: %d current: %d", boxed.V, cryptoVersion)
+	}
+
+	enckey, err := getSecretBoxKey(ctx, s.G(), s.getSecretUI)
+	if err != nil {
+		return "", true, err
+	}
+	pt, ok := secretbox.Open(nil, boxed.E, &boxed.N, &enckey)
+	if !ok {
+		return "", true, errors.New("kvstore: failed to decrypt entry")
+	}
+	return string(pt), true, nil
+}
+
+// Delete removes the entry at namespace/key for uid, if any.
+func (s *KVStore) Delete(uid keybase1.UID, namespace, key string) error {
+	if err := s.G().LocalDb.Delete(entryDbKey(uid, namespace, key)); err != nil {
+		return err
+	}
+	return s.removeFromIndices(uid, namespace, key)
+}
+
+// ListNamespaces returns the namespaces that currently hold at least
+// one entry for uid.
+func (s *KVStore) ListNamespaces(uid keybase1.UID) ([]string, error) {
+	namespaces, err := s.readIndex(namespaceIndexDbKey(uid))
+	if err != nil {
+		return nil, err
+	}
+	return namespaces, nil
+}
+
+// ListKeys returns the keys stored under namespace for uid.
+func (s *KVStore) ListKeys(uid keybase1.UID, namespace string) ([]string, error) {
+	keys, err := s.readIndex(keyIndexDbKey(uid, namespace))
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// putToServer pushes an already-encrypted entry to the server for
+// backup. This is a simple last-writer-wins upload -- there's no
+// cross-device merge or conflict resolution here, so entries synced
+// this way are only guaranteed to be as fresh as the last device that
+// called Put with syncToServer set.
+func (s *KVStore) putToServer(namespace, key string, boxed boxedEntry) error {
+	raw, err := json.Marshal(boxed)
+	if err != nil {
+		return err
+	}
+	arg := libkb.APIArg{
+		Endpoint:    "kvstore/put",
+		NeedSession: true,
+		Args: libkb.HTTPArgs{
+			"namespace": libkb.S{Val: namespace},
+			"key":       libkb.S{Val: key},
+			"entry":     libkb.S{Val: base64.StdEncoding.EncodeToString(raw)},
+		},
+	}
+	_, err = s.G().API.Post(arg)
+	return err
+}
@@ -87,6 +87,43 @@ func (p CommandLine) GetChatDbFilename() string {
 func (p CommandLine) GetPvlKitFilename() string {
 	return p.GetGString("pvl-kit")
 }
+func (p CommandLine) GetMerkleRefreshUID() string {
+	return p.GetGString("merkle-refresh-uid")
+}
+func (p CommandLine) GetPvlSigningKID() string {
+	return p.GetGString("pvl-signing-kid")
+}
+func (p CommandLine) GetPvlShouldRefreshInterval() (time.Duration, bool) {
+	ret, err := p.GetGDuration("pvl-should-refresh-interval")
+	if err != nil {
+		return 0, false
+	}
+	return ret, true
+}
+func (p CommandLine) GetPvlRequireRefreshInterval() (time.Duration, bool) {
+	ret, err := p.GetGDuration("pvl-require-refresh-interval")
+	if err != nil {
+		return 0, false
+	}
+	return ret, true
+}
+func (p CommandLine) GetPvlChannel() string {
+	return p.GetGString("pvl-channel")
+}
+func (p CommandLine) GetDbQuotaBytes() (int, bool) {
+	ret := p.GetGInt("db-quota-bytes")
+	if ret != 0 {
+		return ret, true
+	}
+	return 0, false
+}
+func (p CommandLine) GetChatAttachmentCacheQuotaBytes() (int, bool) {
+	ret := p.GetGInt("chat-attachment-cache-quota-bytes")
+	if ret != 0 {
+		return ret, true
+	}
+	return 0, false
+}
 func (p CommandLine) GetDebug() (bool, bool) {
 	// --no-debug suppresses --debug. Note that although we don't define a
 	// separate GetNoDebug() accessor, fork_server.go still looks for
@@ -157,6 +194,38 @@ func (p CommandLine) GetChatDelivererInterval() (time.Duration, bool) {
 	return ret, true
 }
 
+func (p CommandLine) GetChatEphemeralPurgeInterval() (time.Duration, bool) {
+	ret, err := p.GetGDuration("chat-ephemeral-purge-interval")
+	if err != nil {
+		return 0, false
+	}
+	return ret, true
+}
+
+func (p CommandLine) GetIdentify2CacheSuccessTimeout() (time.Duration, bool) {
+	ret, err := p.GetGDuration("identify2-cache-success-timeout")
+	if err != nil {
+		return 0, false
+	}
+	return ret, true
+}
+
+func (p CommandLine) GetIdentify2CacheFailureTimeout() (time.Duration, bool) {
+	ret, err := p.GetGDuration("identify2-cache-failure-timeout")
+	if err != nil {
+		return 0, false
+	}
+	return ret, true
+}
+
+func (p CommandLine) GetChatRetentionSweepInterval() (time.Duration, bool) {
+	ret, err := p.GetGDuration("chat-retention-sweep-interval")
+	if err != nil {
+		return 0, false
+	}
+	return ret, true
+}
+
 func (p CommandLine) GetRunMode() (libkb.RunMode, error) {
 	return libkb.StringToRunMode(p.GetGString("run-mode"))
 }
@@ -290,6 +359,21 @@ func (p CommandLine) GetTorProxy() string {
 	return p.GetGString("tor-proxy")
 }
 
+func (p CommandLine) GetProofProxyMode() (ret libkb.TorMode, err error) {
+	if s := p.GetGString("proof-proxy-mode"); s != "" {
+		ret, err = libkb.StringToTorMode(s)
+	}
+	return ret, err
+}
+
+func (p CommandLine) GetProofProxy() string {
+	return p.GetGString("proof-proxy")
+}
+
+func (p CommandLine) GetDNSOverHTTPSServer() string {
+	return p.GetGString("dns-over-https-server")
+}
+
 func (p CommandLine) GetMountDir() string {
 	return p.GetGString("mountdir")
 }
@@ -384,6 +468,26 @@ func (p *CommandLine) PopulateApp(addHelp bool, extraFlags []cli.Flag) {
 			Name:  "pvl-kit",
 			Usage: "Specify an alternate local PVL kit file location.",
 		},
+		cli.StringFlag{
+			Name:  "merkle-refresh-uid",
+			Usage: "Pin the UID used to force a merkle root refresh (for deployments without t_alice).",
+		},
+		cli.StringFlag{
+			Name:  "pvl-signing-kid",
+			Usage: "Require pvl kits to carry a valid signature from this KID, independent of the merkle hash check.",
+		},
+		cli.StringFlag{
+			Name:  "pvl-should-refresh-interval",
+			Usage: "Set how old a cached merkle root may be before pvl fetching tries to refresh it.",
+		},
+		cli.StringFlag{
+			Name:  "pvl-require-refresh-interval",
+			Usage: "Set how old a cached merkle root may get before pvl fetching refuses to serve it at all.",
+		},
+		cli.StringFlag{
+			Name:  "pvl-channel",
+			Usage: "Select a named pvl release channel (e.g. \"beta\") instead of the default stable channel.",
+		},
 		cli.BoolFlag{
 			Name:  "debug, d",
 			Usage: "Enable debugging mode.",
@@ -440,6 +544,26 @@ func (p *CommandLine) PopulateApp(addHelp bool, extraFlags []cli.Flag) {
 			Name:  "proof-cache-size",
 			Usage: "Number of proof entries to cache.",
 		},
+		cli.StringFlag{
+			Name:  "proof-proxy-mode",
+			Usage: "set proxy mode for proof checks to be 'leaky', 'none', or 'strict'; independent of tor-mode. 'none' by default.",
+		},
+		cli.StringFlag{
+			Name:  "proof-proxy",
+			Usage: fmt.Sprintf("set SOCKS5 proxy for proof checks; when proof-proxy-mode is on; defaults to the tor-proxy address (%s) when unset", libkb.TorProxy),
+		},
+		cli.StringFlag{
+			Name:  "dns-over-https-server",
+			Usage: "set a JSON-format DNS-over-HTTPS resolver (e.g. https://dns.google/resolve) to try before the system resolver for DNS proof checks",
+		},
+		cli.IntFlag{
+			Name:  "db-quota-bytes",
+			Usage: "Total bytes usage-tracked LocalDb entries may occupy before the oldest are evicted.",
+		},
+		cli.IntFlag{
+			Name:  "chat-attachment-cache-quota-bytes",
+			Usage: "Total bytes the decrypted chat attachment disk cache may occupy before the least-recently-used assets are evicted.",
+		},
 		cli.StringFlag{
 			Name:  "proxy",
 			Usage: "Specify an HTTP(s) proxy to ship all Web requests over.",
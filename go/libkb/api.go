@@ -135,6 +135,21 @@ func (api *BaseAPIEngine) getCli(cookied bool) (ret *Client) {
 	return client
 }
 
+// ConnStats returns a connection-reuse snapshot of every Client this
+// engine has created so far (there are at most two -- cookied and
+// uncookied -- see getCli). Used to populate ExtendedStatus for
+// debugging handshake overhead; see exportConnPoolStatus in
+// go/service/config.go.
+func (api *BaseAPIEngine) ConnStats() []ConnPoolStats {
+	api.clientsMu.Lock()
+	defer api.clientsMu.Unlock()
+	stats := make([]ConnPoolStats, 0, len(api.clients))
+	for _, client := range api.clients {
+		stats = append(stats, client.Stats())
+	}
+	return stats
+}
+
 func (api *BaseAPIEngine) PrepareGet(url1 url.URL, arg APIArg) (*http.Request, error) {
 	url1.RawQuery = arg.getHTTPArgs().Encode()
 	ruri := url1.String()
@@ -611,6 +626,25 @@ func (a *InternalAPIEngine) Post(arg APIArg) (*APIRes, error) {
 	return a.DoRequest(arg, req)
 }
 
+// PostDeferrable behaves like Post, except that if the request fails
+// it's persisted to a.G().DeferredAPIQueue instead of just returning
+// the error, so it can be retried once connectivity comes back (see
+// reachability.go in go/service, which calls DeferredAPIQueue.Flush on
+// every reachable transition). Only use this for endpoints that are
+// safe to retry blind, minutes or hours later, with no caller watching
+// the result synchronously -- proof checks and tracking statements are
+// the sort of thing this is for. Nothing in this tree calls it yet.
+func (a *InternalAPIEngine) PostDeferrable(arg APIArg) (*APIRes, error) {
+	res, err := a.Post(arg)
+	if err == nil {
+		return res, nil
+	}
+	if qerr := a.G().DeferredAPIQueue.Enqueue(arg.Endpoint, arg.Args.ToValues()); qerr != nil {
+		a.G().Log.Warning("PostDeferrable: failed to enqueue %s for retry: %s", arg.Endpoint, qerr)
+	}
+	return res, err
+}
+
 func (a *InternalAPIEngine) PostJSON(arg APIArg) (*APIRes, error) {
 	url1 := a.getURL(arg)
 	req, err := a.PreparePost(url1, arg, true)
@@ -718,6 +752,9 @@ func (api *ExternalAPIEngine) fixHeaders(arg APIArg, req *http.Request) {
 	if api.G().Env.GetTorMode().UseHeaders() {
 		req.Header.Set("User-Agent", userAgent)
 	}
+	for k, v := range arg.Headers {
+		req.Header.Set(k, v)
+	}
 }
 
 func isReddit(req *http.Request) bool {
@@ -19,6 +19,7 @@ type APIArg struct {
 	RetryMultiplier float64       // optional
 	RetryCount      int           // optional
 	NetContext      context.Context
+	Headers         map[string]string // optional extra headers, only honored by ExternalAPIEngine
 }
 
 // NewAPIArg creates a standard APIArg that will result
@@ -0,0 +1,152 @@
+// Copyright 2016 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// This is an implementation of Bech32 (BIP-0173), the checksummed
+// base32 encoding used by segwit addresses ("bc1...").  It only decodes
+// (we never need to mint an address here, only verify one a user typed
+// in), and it only supports the single-segwit-program encoding described
+// by BIP-0173 -- not the general-purpose Bech32 format used elsewhere.
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+var bech32CharsetMap = func() [256]byte {
+	var res [256]byte
+	for i := range res {
+		res[i] = base58InvalidIndex
+	}
+	for i, c := range []byte(bech32Charset) {
+		res[c] = uint8(i)
+	}
+	return res
+}()
+
+func bech32Polymod(values []byte) uint32 {
+	generator := []uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		b := byte(chk >> 25)
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (b>>uint(i))&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+func bech32HRPExpand(hrp string) []byte {
+	ret := make([]byte, 0, len(hrp)*2+1)
+	for _, c := range []byte(hrp) {
+		ret = append(ret, c>>5)
+	}
+	ret = append(ret, 0)
+	for _, c := range []byte(hrp) {
+		ret = append(ret, c&31)
+	}
+	return ret
+}
+
+func bech32VerifyChecksum(hrp string, data []byte) bool {
+	values := append(bech32HRPExpand(hrp), data...)
+	return bech32Polymod(values) == 1
+}
+
+// bech32Decode splits a Bech32 string into its human-readable part and its
+// 5-bit-per-byte data part (checksum stripped), verifying the checksum.
+func bech32Decode(s string) (hrp string, data []byte, err error) {
+	if s != strings.ToLower(s) && s != strings.ToUpper(s) {
+		return "", nil, fmt.Errorf("bech32 string has mixed case")
+	}
+	s = strings.ToLower(s)
+
+	pos := strings.LastIndexByte(s, '1')
+	if pos < 1 || pos+7 > len(s) {
+		return "", nil, fmt.Errorf("invalid bech32 separator position")
+	}
+	hrp = s[:pos]
+	for _, c := range []byte(hrp) {
+		if c < 33 || c > 126 {
+			return "", nil, fmt.Errorf("invalid character in bech32 human-readable part")
+		}
+	}
+
+	dataPart := s[pos+1:]
+	data = make([]byte, len(dataPart))
+	for i, c := range []byte(dataPart) {
+		v := bech32CharsetMap[c]
+		if v == base58InvalidIndex {
+			return "", nil, fmt.Errorf("invalid bech32 character '%c'", c)
+		}
+		data[i] = v
+	}
+
+	if !bech32VerifyChecksum(hrp, data) {
+		return "", nil, fmt.Errorf("invalid bech32 checksum")
+	}
+	return hrp, data[:len(data)-6], nil
+}
+
+// bech32ConvertBits regroups a slice of fromBits-wide values into a slice of
+// toBits-wide values, as needed to go from Bech32's 5-bit data alphabet to
+// 8-bit witness program bytes (and back).
+func bech32ConvertBits(data []byte, fromBits, toBits uint, pad bool) ([]byte, error) {
+	acc := uint32(0)
+	bits := uint(0)
+	maxv := uint32(1<<toBits) - 1
+	var ret []byte
+	for _, value := range data {
+		if uint32(value)>>fromBits != 0 {
+			return nil, fmt.Errorf("invalid data value for bit conversion")
+		}
+		acc = (acc << fromBits) | uint32(value)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			ret = append(ret, byte((acc>>bits)&maxv))
+		}
+	}
+	if pad {
+		if bits > 0 {
+			ret = append(ret, byte((acc<<(toBits-bits))&maxv))
+		}
+	} else if bits >= fromBits || ((acc<<(toBits-bits))&maxv) != 0 {
+		return nil, fmt.Errorf("invalid padding in bit conversion")
+	}
+	return ret, nil
+}
+
+// segwitAddressDecode decodes a BIP-0173 segwit address, returning the
+// witness version and program. It doesn't restrict hrp to "bc"/"tb" so that
+// callers can check that themselves and produce a more specific error.
+func segwitAddressDecode(addr string) (hrp string, witnessVersion int, witnessProgram []byte, err error) {
+	hrp, data, err := bech32Decode(addr)
+	if err != nil {
+		return "", 0, nil, err
+	}
+	if len(data) < 1 {
+		return "", 0, nil, fmt.Errorf("empty bech32 data section")
+	}
+	witnessVersion = int(data[0])
+	if witnessVersion > 16 {
+		return "", 0, nil, fmt.Errorf("invalid witness version %d", witnessVersion)
+	}
+	witnessProgram, err = bech32ConvertBits(data[1:], 5, 8, false)
+	if err != nil {
+		return "", 0, nil, err
+	}
+	if len(witnessProgram) < 2 || len(witnessProgram) > 40 {
+		return "", 0, nil, fmt.Errorf("invalid witness program length %d", len(witnessProgram))
+	}
+	if witnessVersion == 0 && len(witnessProgram) != 20 && len(witnessProgram) != 32 {
+		return "", 0, nil, fmt.Errorf("invalid witness program length %d for version 0", len(witnessProgram))
+	}
+	return hrp, witnessVersion, witnessProgram, nil
+}
@@ -0,0 +1,147 @@
+// Copyright 2017 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	jsonw "github.com/keybase/go-jsonw"
+)
+
+// BotTokenScope narrows what a bot token authorizes. An empty Routes
+// list means "any of the local HTTP gateway's routes"; an empty
+// ConvIDs list means "any conversation" for the chat/send route.
+// Routes/ConvIDs aren't validated against anything at issue time --
+// enforcement is entirely the caller's job (currently CmdGateway).
+type BotTokenScope struct {
+	Routes  []string `json:"routes,omitempty"`
+	ConvIDs []string `json:"conv_ids,omitempty"`
+}
+
+// BotToken is a bearer credential narrower than the full account,
+// suitable for handing to a bot integration instead of full device
+// access. It's bound to a BotTokenScope, and can be listed and revoked
+// independently of the account's keys.
+type BotToken struct {
+	ID        string        `json:"id"`
+	Token     string        `json:"token"`
+	Label     string        `json:"label"`
+	Scope     BotTokenScope `json:"scope"`
+	CreatedAt time.Time     `json:"created_at"`
+	Revoked   bool          `json:"revoked"`
+}
+
+// Allows reports whether this token authorizes the given route and (for
+// routes that scope by conversation, e.g. chat/send) the given
+// conversation ID. An empty convID means the route being checked
+// doesn't scope by conversation.
+func (t BotToken) Allows(route, convID string) bool {
+	if t.Revoked {
+		return false
+	}
+	if len(t.Scope.Routes) > 0 && !stringInSlice(route, t.Scope.Routes) {
+		return false
+	}
+	if convID != "" && len(t.Scope.ConvIDs) > 0 && !stringInSlice(convID, t.Scope.ConvIDs) {
+		return false
+	}
+	return true
+}
+
+func stringInSlice(needle string, haystack []string) bool {
+	for _, s := range haystack {
+		if s == needle {
+			return true
+		}
+	}
+	return false
+}
+
+const botTokensConfigPath = "bot_tokens"
+
+// GetBotTokens returns every bot token ever issued, including revoked
+// ones -- callers that only want live tokens should filter on Revoked.
+func (e *Env) GetBotTokens() ([]BotToken, error) {
+	iface, err := e.config.GetInterfaceAtPath(botTokensConfigPath)
+	if err != nil || iface == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(iface)
+	if err != nil {
+		return nil, err
+	}
+	var tokens []BotToken
+	if err := json.Unmarshal(raw, &tokens); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (e *Env) setBotTokens(tokens []BotToken) error {
+	raw, err := json.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+	w, err := jsonw.Unmarshal(raw)
+	if err != nil {
+		return err
+	}
+	return e.GetConfigWriter().SetWrapperAtPath(botTokensConfigPath, w)
+}
+
+// CreateBotToken issues and persists a new bot token with the given
+// label and scope, generating its secret the same way the local HTTP
+// gateway generates its own bearer token.
+func (e *Env) CreateBotToken(label string, scope BotTokenScope) (BotToken, error) {
+	tokens, err := e.GetBotTokens()
+	if err != nil {
+		return BotToken{}, err
+	}
+
+	idRaw, err := RandBytes(8)
+	if err != nil {
+		return BotToken{}, err
+	}
+	secretRaw, err := RandBytes(32)
+	if err != nil {
+		return BotToken{}, err
+	}
+
+	tok := BotToken{
+		ID:        hex.EncodeToString(idRaw),
+		Token:     hex.EncodeToString(secretRaw),
+		Label:     label,
+		Scope:     scope,
+		CreatedAt: time.Now(),
+	}
+
+	if err := e.setBotTokens(append(tokens, tok)); err != nil {
+		return BotToken{}, err
+	}
+	return tok, nil
+}
+
+// RevokeBotToken marks the bot token with the given ID as revoked. It
+// leaves the record in place (rather than deleting it) so `bot-token
+// list` retains an audit trail of tokens that used to work.
+func (e *Env) RevokeBotToken(id string) error {
+	tokens, err := e.GetBotTokens()
+	if err != nil {
+		return err
+	}
+	found := false
+	for i := range tokens {
+		if tokens[i].ID == id {
+			tokens[i].Revoked = true
+			found = true
+		}
+	}
+	if !found {
+		return errors.New("no such bot token: " + id)
+	}
+	return e.setBotTokens(tokens)
+}
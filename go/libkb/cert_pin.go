@@ -0,0 +1,183 @@
+// Copyright 2018 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/keybase/go-crypto/ed25519"
+)
+
+// PinSet is a set of acceptable certificate pins for a host: base64
+// (standard encoding) SHA-256 hashes of a certificate's DER-encoded
+// SubjectPublicKeyInfo, the same format HPKP used for pin-sha256. More
+// than one pin can be live at once so ops can stage the next key ahead
+// of a rotation and only drop the old pin once the new certificate is
+// actually deployed.
+type PinSet []string
+
+// Verify reports whether cert's public key matches any pin in the set.
+func (p PinSet) Verify(cert *x509.Certificate) bool {
+	sum := SPKIPin(cert)
+	for _, pin := range p {
+		if pin == sum {
+			return true
+		}
+	}
+	return false
+}
+
+// SPKIPin computes the base64 SHA-256 hash of a certificate's
+// DER-encoded SubjectPublicKeyInfo.
+func SPKIPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// pinnedAPICAPin is the SPKI pin of the CA bundled as apiCA in ca.go --
+// since api.keybase.io's leaf cert is issued by our own private CA, we
+// pin that CA's key rather than the leaf, which would otherwise need
+// re-pinning on every routine cert renewal.
+const pinnedAPICAPin = "MmY9Au0NtpSKzJQRT/UmEATLCXsmS+LRWN5/zwHrdbc="
+
+var pinnedHostsMu sync.RWMutex
+
+// pinnedHosts are the hosts NewClient enforces certificate pinning for,
+// on top of ordinary CA-chain validation. Only Keybase's own API host is
+// pinned by default -- pinning a proxy or a third-party proof site would
+// just break Keybase for people behind a corporate MITM proxy for
+// reasons that have nothing to do with us.
+var pinnedHosts = map[string]PinSet{
+	"api.keybase.io": {pinnedAPICAPin},
+}
+
+// PinsForHost returns the configured PinSet for host, if pinning is
+// enabled for it.
+func PinsForHost(host string) (PinSet, bool) {
+	pinnedHostsMu.RLock()
+	defer pinnedHostsMu.RUnlock()
+	pins, ok := pinnedHosts[host]
+	return pins, ok
+}
+
+// PinValidationError is returned by a pinned Client's transport when a
+// presented certificate chain passes ordinary CA validation but matches
+// none of the pins configured for the host. It's a distinct type from
+// the usual x509/tls errors specifically so callers (and our own error
+// reporting) can tell a pin mismatch -- a strong signal of a MITM, since
+// it means someone got a CA-trusted cert for our hostname that isn't
+// the one we shipped -- apart from an ordinary expired-or-misconfigured
+// TLS failure.
+type PinValidationError struct {
+	Host string
+}
+
+func (e PinValidationError) Error() string {
+	return fmt.Sprintf("certificate pin validation failed for %s: the presented certificate chain was trusted by the system CA pool, but didn't match any pinned key for this host -- this usually means a TLS-intercepting proxy is between you and %s, not an ordinary certificate problem", e.Host, e.Host)
+}
+
+// pinVerifyPeerCertificate builds a tls.Config.VerifyPeerCertificate
+// callback that enforces pins on top of whatever chain validation
+// tls.Config already did (VerifyPeerCertificate only runs after normal
+// verification succeeds, unless InsecureSkipVerify is set, which we
+// never set here).
+func pinVerifyPeerCertificate(host string, pins PinSet) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+		for _, chain := range verifiedChains {
+			for _, cert := range chain {
+				if pins.Verify(cert) {
+					return nil
+				}
+			}
+		}
+		return PinValidationError{Host: host}
+	}
+}
+
+// PinRotationPayload is the signed portion of a pin rotation document.
+// Field order matters: it's marshaled with encoding/json and the result
+// is exactly what PinUpdateSigningKey's signature covers, so reordering
+// these fields would break verification of existing signed documents.
+type PinRotationPayload struct {
+	Host     string   `json:"host"`
+	Pins     []string `json:"pins"`
+	IssuedAt int64    `json:"issuedAt"`
+}
+
+// PinRotationDoc is a signed instruction to replace the pin set for a
+// host, for the emergency case where a pinned key needs to change
+// faster than a client release can ship (e.g. the pinned CA needs to be
+// revoked). It's verified against PinUpdateSigningKey, a key kept
+// offline and separate from the CAs it can rotate, so a single
+// compromise doesn't let an attacker both MITM a pinned host and
+// authorize the rotation that would hide it.
+//
+// Sig is the base64 (standard encoding) NaclSignature over the JSON
+// encoding of PinRotationPayload.
+type PinRotationDoc struct {
+	PinRotationPayload
+	Sig string `json:"sig"`
+}
+
+// verify checks doc's signature against PinUpdateSigningKey.
+func (doc PinRotationDoc) verify() error {
+	msg, err := json.Marshal(doc.PinRotationPayload)
+	if err != nil {
+		return err
+	}
+	rawSig, err := base64.StdEncoding.DecodeString(doc.Sig)
+	if err != nil || len(rawSig) != ed25519.SignatureSize {
+		return fmt.Errorf("pin rotation document for %s: malformed signature", doc.Host)
+	}
+	var sig NaclSignature
+	copy(sig[:], rawSig)
+	if !PinUpdateSigningKey.Verify(msg, &sig) {
+		return fmt.Errorf("pin rotation document for %s: bad signature", doc.Host)
+	}
+	return nil
+}
+
+// pinUpdateSigningKeyHex is the placeholder public half of the offline
+// keypair that signs PinRotationDocs. Ops should replace this with the
+// real production key before this mechanism is relied on.
+const pinUpdateSigningKeyHex = "584686ae5808d4bde9835a46f67396352e5d541e4b1f48eb59f22909c8b7676d"
+
+// PinUpdateSigningKey verifies PinRotationDocs. See
+// pinUpdateSigningKeyHex.
+var PinUpdateSigningKey = mustDecodePinUpdateSigningKey(pinUpdateSigningKeyHex)
+
+func mustDecodePinUpdateSigningKey(s string) NaclSigningKeyPublic {
+	raw, err := hex.DecodeString(s)
+	if err != nil || len(raw) != ed25519.PublicKeySize {
+		panic("libkb: invalid pinUpdateSigningKeyHex")
+	}
+	var k NaclSigningKeyPublic
+	copy(k[:], raw)
+	return k
+}
+
+// ApplyPinRotation verifies doc's signature against
+// PinUpdateSigningKey and, if valid, replaces the pin set for doc.Host.
+// Rotation is a full replace rather than a merge: the caller decides
+// whether to keep the old pin alongside the new one during a staged
+// rollout by including both in doc.Pins.
+func ApplyPinRotation(doc PinRotationDoc) error {
+	if err := doc.verify(); err != nil {
+		return err
+	}
+	if len(doc.Pins) == 0 {
+		return fmt.Errorf("pin rotation document for %s: refusing to install an empty pin set", doc.Host)
+	}
+
+	pinnedHostsMu.Lock()
+	defer pinnedHostsMu.Unlock()
+	pinnedHosts[doc.Host] = PinSet(doc.Pins)
+	return nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2018 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"testing"
+)
+
+func TestPinSetVerify(t *testing.T) {
+	cert := &x509.Certificate{RawSubjectPublicKeyInfo: []byte("some spki bytes")}
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	pin := base64.StdEncoding.EncodeToString(sum[:])
+
+	pins := PinSet{"unrelated-pin", pin}
+	if !pins.Verify(cert) {
+		t.Fatal("expected pin set containing the cert's real pin to verify")
+	}
+
+	if (PinSet{"unrelated-pin"}).Verify(cert) {
+		t.Fatal("expected pin set without the cert's pin to fail verification")
+	}
+}
+
+func TestApplyPinRotationRejectsBadSignature(t *testing.T) {
+	doc := PinRotationDoc{
+		PinRotationPayload: PinRotationPayload{
+			Host:     "api.keybase.io",
+			Pins:     []string{"AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="},
+			IssuedAt: 1700000000,
+		},
+		Sig: "not-a-valid-signature",
+	}
+	if err := ApplyPinRotation(doc); err == nil {
+		t.Fatal("expected a malformed signature to be rejected")
+	}
+}
+
+func TestApplyPinRotationAcceptsValidSignature(t *testing.T) {
+	defer func() {
+		pinnedHostsMu.Lock()
+		pinnedHosts["api.keybase.io"] = PinSet{pinnedAPICAPin}
+		pinnedHostsMu.Unlock()
+	}()
+
+	doc := PinRotationDoc{
+		PinRotationPayload: PinRotationPayload{
+			Host: "api.keybase.io",
+			Pins: []string{
+				"MmY9Au0NtpSKzJQRT/UmEATLCXsmS+LRWN5/zwHrdbc=",
+				"AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=",
+			},
+			IssuedAt: 1700000000,
+		},
+		Sig: "Zo9h06/YYvYtZFiDGJ8Qjw9ratnRfTs93yoyzA4DeNaIYyntL0E40LS9WTmiafqfkVXpT0HfINYvZhNcj1n8Dg==",
+	}
+	if err := ApplyPinRotation(doc); err != nil {
+		t.Fatalf("expected a validly signed rotation document to be accepted: %s", err)
+	}
+
+	pins, ok := PinsForHost("api.keybase.io")
+	if !ok || len(pins) != 2 {
+		t.Fatalf("expected the rotated pin set to be installed, got %+v", pins)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"h12.me/socks"
@@ -27,11 +28,69 @@ type ClientConfig struct {
 	Prefix     string
 	UseCookies bool
 	Timeout    time.Duration
+
+	// UseProxy and ProxyAddress configure this client's network path
+	// independently of any other Client's -- e.g. the internal API client and
+	// the external (proof-checking) client are routed separately, so a proxy
+	// set up for one doesn't silently also apply to the other.
+	UseProxy     bool
+	ProxyAddress string
 }
 
 type Client struct {
 	cli    *http.Client
 	config *ClientConfig
+	stats  *connStats
+}
+
+// ConnPoolStats is a point-in-time snapshot of a Client's connection
+// reuse behavior, for surfacing in `keybase status` (see
+// exportConnPoolStatus in go/service/config.go) so mobile handshake
+// overhead is something we can actually measure instead of guess at.
+type ConnPoolStats struct {
+	ActiveRequests int
+	TotalRequests  int64
+}
+
+// connStats is the mutable counterpart Client keeps live; ConnPoolStats
+// is the immutable snapshot handed out to callers.
+type connStats struct {
+	sync.Mutex
+	active int
+	total  int64
+}
+
+func (s *connStats) snapshot() ConnPoolStats {
+	s.Lock()
+	defer s.Unlock()
+	return ConnPoolStats{ActiveRequests: s.active, TotalRequests: s.total}
+}
+
+// countingRoundTripper wraps a Transport to keep connStats up to date,
+// so ConnStats can report how many requests are in flight and how many
+// have been made in total without reaching into net/http.Transport
+// internals (which don't expose per-connection reuse counts).
+type countingRoundTripper struct {
+	http.RoundTripper
+	stats *connStats
+}
+
+func (c countingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.stats.Lock()
+	c.stats.active++
+	c.stats.total++
+	c.stats.Unlock()
+	defer func() {
+		c.stats.Lock()
+		c.stats.active--
+		c.stats.Unlock()
+	}()
+	return c.RoundTripper.RoundTrip(req)
+}
+
+// Stats returns a snapshot of this Client's connection reuse counters.
+func (c *Client) Stats() ConnPoolStats {
+	return c.stats.snapshot()
 }
 
 var hostRE = regexp.MustCompile("^([^:]+)(:([0-9]+))?$")
@@ -120,14 +179,33 @@ func (e *Env) GenClientConfigForInternalAPI() (*ClientConfig, error) {
 		return nil, err
 	}
 
-	ret := &ClientConfig{host, port, useTLS, url, rootCAs, url.Path, true, e.GetAPITimeout()}
+	ret := &ClientConfig{
+		Host:         host,
+		Port:         port,
+		UseTLS:       useTLS,
+		URL:          url,
+		RootCAs:      rootCAs,
+		Prefix:       url.Path,
+		UseCookies:   true,
+		Timeout:      e.GetAPITimeout(),
+		UseProxy:     e.GetTorMode().Enabled(),
+		ProxyAddress: e.GetTorProxy(),
+	}
 	return ret, nil
 }
 
+// GenClientConfigForScrapers builds the network config for the external API
+// engine, i.e. the one that fetches a user's claimed proofs off of
+// third-party sites for PVL to check. It's deliberately routed by
+// GetProofProxyMode/GetProofProxy rather than GetTorMode/GetTorProxy, so a
+// site-specific proxy policy for proof checks doesn't have to match whatever
+// policy is in effect for Keybase API traffic.
 func (e *Env) GenClientConfigForScrapers() (*ClientConfig, error) {
 	return &ClientConfig{
-		UseCookies: true,
-		Timeout:    e.GetScraperTimeout(),
+		UseCookies:   true,
+		Timeout:      e.GetScraperTimeout(),
+		UseProxy:     e.GetProofProxyMode().Enabled(),
+		ProxyAddress: e.GetProofProxy(),
 	}, nil
 }
 
@@ -137,36 +215,49 @@ func NewClient(e *Env, config *ClientConfig, needCookie bool) *Client {
 		jar, _ = cookiejar.New(nil)
 	}
 
-	var xprt *http.Transport
 	var timeout time.Duration
+	useProxy := config != nil && config.UseProxy
 
-	if (config != nil && config.RootCAs != nil) || e.GetTorMode().Enabled() {
-		xprt = &http.Transport{}
-		if config != nil && config.RootCAs != nil {
-			xprt.TLSClientConfig = &tls.Config{RootCAs: config.RootCAs}
-		}
-		if e.GetTorMode().Enabled() {
-			dialSocksProxy := socks.DialSocksProxy(socks.SOCKS5, e.GetTorProxy())
-			xprt.Dial = dialSocksProxy
-		} else {
-			xprt.Proxy = http.ProxyFromEnvironment
+	xprt := &http.Transport{
+		MaxIdleConns:        e.GetAPIMaxIdleConns(),
+		MaxIdleConnsPerHost: e.GetAPIMaxIdleConnsPerHost(),
+	}
+	if e.GetAPIDisableHTTP2() {
+		// A non-nil, empty TLSNextProto is the documented way to opt a
+		// Transport out of net/http's automatic HTTP/2 upgrade.
+		xprt.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+	if config != nil && config.RootCAs != nil {
+		xprt.TLSClientConfig = &tls.Config{RootCAs: config.RootCAs}
+	}
+	if config != nil {
+		if pins, ok := PinsForHost(config.Host); ok {
+			if xprt.TLSClientConfig == nil {
+				xprt.TLSClientConfig = &tls.Config{}
+			}
+			xprt.TLSClientConfig.VerifyPeerCertificate = pinVerifyPeerCertificate(config.Host, pins)
 		}
 	}
+	if useProxy {
+		dialSocksProxy := socks.DialSocksProxy(socks.SOCKS5, config.ProxyAddress)
+		xprt.Dial = dialSocksProxy
+	} else {
+		xprt.Proxy = http.ProxyFromEnvironment
+	}
 	if config == nil || config.Timeout == 0 {
 		timeout = HTTPDefaultTimeout
 	} else {
 		timeout = config.Timeout
 	}
 
+	stats := &connStats{}
 	ret := &Client{
-		cli:    &http.Client{Timeout: timeout},
+		cli:    &http.Client{Timeout: timeout, Transport: countingRoundTripper{RoundTripper: xprt, stats: stats}},
 		config: config,
+		stats:  stats,
 	}
 	if jar != nil {
 		ret.cli.Jar = jar
 	}
-	if xprt != nil {
-		ret.cli.Transport = xprt
-	}
 	return ret
 }
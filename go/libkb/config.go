@@ -433,6 +433,27 @@ func (f JSONConfigFile) GetChatDbFilename() string {
 func (f JSONConfigFile) GetPvlKitFilename() string {
 	return f.GetTopLevelString("pvl_kit")
 }
+func (f JSONConfigFile) GetMerkleRefreshUID() string {
+	return f.GetTopLevelString("merkle_refresh_uid")
+}
+func (f JSONConfigFile) GetPvlSigningKID() string {
+	return f.GetTopLevelString("pvl_signing_kid")
+}
+func (f JSONConfigFile) GetPvlShouldRefreshInterval() (time.Duration, bool) {
+	return f.GetDurationAtPath("pvl.should_refresh_interval")
+}
+func (f JSONConfigFile) GetPvlRequireRefreshInterval() (time.Duration, bool) {
+	return f.GetDurationAtPath("pvl.require_refresh_interval")
+}
+func (f JSONConfigFile) GetPvlChannel() string {
+	return f.GetTopLevelString("pvl_channel")
+}
+func (f JSONConfigFile) GetDbQuotaBytes() (int, bool) {
+	return f.getCacheSize("db.quota_bytes")
+}
+func (f JSONConfigFile) GetChatAttachmentCacheQuotaBytes() (int, bool) {
+	return f.getCacheSize("chat.attachment_cache_quota_bytes")
+}
 func (f JSONConfigFile) GetPinentry() string {
 	res, _ := f.GetStringAtPath("pinentry.path")
 	return res
@@ -523,6 +544,23 @@ func (f JSONConfigFile) GetTorProxy() string {
 	return s
 }
 
+func (f JSONConfigFile) GetProofProxyMode() (ret TorMode, err error) {
+	if s, isSet := f.GetStringAtPath("proof_proxy.mode"); isSet {
+		ret, err = StringToTorMode(s)
+	}
+	return ret, err
+}
+
+func (f JSONConfigFile) GetProofProxy() string {
+	s, _ := f.GetStringAtPath("proof_proxy.address")
+	return s
+}
+
+func (f JSONConfigFile) GetDNSOverHTTPSServer() string {
+	s, _ := f.GetStringAtPath("dns_over_https_server")
+	return s
+}
+
 func (f JSONConfigFile) GetProxy() string {
 	return f.GetTopLevelString("proxy")
 }
@@ -568,6 +606,22 @@ func (f JSONConfigFile) GetChatDelivererInterval() (time.Duration, bool) {
 	return f.GetDurationAtPath("chat.deliverer_interval")
 }
 
+func (f JSONConfigFile) GetChatEphemeralPurgeInterval() (time.Duration, bool) {
+	return f.GetDurationAtPath("chat.ephemeral_purge_interval")
+}
+
+func (f JSONConfigFile) GetChatRetentionSweepInterval() (time.Duration, bool) {
+	return f.GetDurationAtPath("chat.retention_sweep_interval")
+}
+
+func (f JSONConfigFile) GetIdentify2CacheSuccessTimeout() (time.Duration, bool) {
+	return f.GetDurationAtPath("cache.identify2.success_timeout")
+}
+
+func (f JSONConfigFile) GetIdentify2CacheFailureTimeout() (time.Duration, bool) {
+	return f.GetDurationAtPath("cache.identify2.failure_timeout")
+}
+
 func (f JSONConfigFile) getCacheSize(w string) (int, bool) {
 	return f.jw.AtPathGetInt(w)
 }
@@ -37,6 +37,11 @@ type lookupByClientTypeObj struct {
 	ch  chan<- rpc.Transporter
 }
 
+type lookupClientDetailsObj struct {
+	id ConnectionID
+	ch chan<- keybase1.ClientDetails
+}
+
 // ApplyFn can be applied to every connection. It is called with the
 // RPC transporter, and also the connectionID. It should return a bool
 // true to keep going and false to stop.
@@ -45,6 +50,7 @@ type ApplyFn func(i ConnectionID, xp rpc.Transporter) bool
 type rpcConnection struct {
 	transporter rpc.Transporter
 	details     *keybase1.ClientDetails
+	connectedAt time.Time
 }
 
 // ConnectionManager manages all connections active for a given service.
@@ -53,14 +59,15 @@ type ConnectionManager struct {
 	nxt    ConnectionID
 	lookup map[ConnectionID](*rpcConnection)
 
-	addConnectionCh      chan *addConnectionObj
-	lookupConnectionCh   chan *lookupConnectionObj
-	removeConnectionCh   chan ConnectionID
-	applyAllCh           chan ApplyFn
-	shutdownCh           chan struct{}
-	labelConnectionCh    chan labelConnectionObj
-	listAllCh            chan chan<- []keybase1.ClientDetails
-	lookupByClientTypeCh chan *lookupByClientTypeObj
+	addConnectionCh       chan *addConnectionObj
+	lookupConnectionCh    chan *lookupConnectionObj
+	removeConnectionCh    chan ConnectionID
+	applyAllCh            chan ApplyFn
+	shutdownCh            chan struct{}
+	labelConnectionCh     chan labelConnectionObj
+	listAllCh             chan chan<- []keybase1.ClientDetails
+	lookupByClientTypeCh  chan *lookupByClientTypeObj
+	lookupClientDetailsCh chan *lookupClientDetailsObj
 }
 
 // AddConnection adds a new connection to the table of Connection object, with a
@@ -104,6 +111,15 @@ func (c *ConnectionManager) LookupByClientType(clientType keybase1.ClientType) r
 	return <-retCh
 }
 
+// LookupClientDetails returns the details a connection labeled itself with
+// via Label (typically in response to helloIAm), or the zero value if the
+// connection has no label yet or doesn't exist.
+func (c *ConnectionManager) LookupClientDetails(i ConnectionID) keybase1.ClientDetails {
+	retCh := make(chan keybase1.ClientDetails)
+	c.lookupClientDetailsCh <- &lookupClientDetailsObj{i, retCh}
+	return <-retCh
+}
+
 func (c *ConnectionManager) Label(id ConnectionID, d keybase1.ClientDetails) error {
 	retCh := make(chan error)
 	c.labelConnectionCh <- labelConnectionObj{id: id, details: d, ch: retCh}
@@ -153,7 +169,9 @@ func (a byClientType) Less(i, j int) bool { return a[i].ClientType < a[j].Client
 func (c *ConnectionManager) listAllLabeledConnections() (ret []keybase1.ClientDetails) {
 	for _, v := range c.lookup {
 		if v.details != nil {
-			ret = append(ret, *v.details)
+			details := *v.details
+			details.ConnectedAt = keybase1.ToTime(v.connectedAt)
+			ret = append(ret, details)
 		}
 	}
 	sort.Sort(byClientType(ret))
@@ -168,7 +186,7 @@ func (c *ConnectionManager) run() {
 		case addConnectionObj := <-c.addConnectionCh:
 			c.nxt++ // increment first, since 0 is reserved
 			nxt := c.nxt
-			c.lookup[nxt] = &rpcConnection{transporter: addConnectionObj.xp}
+			c.lookup[nxt] = &rpcConnection{transporter: addConnectionObj.xp, connectedAt: time.Now()}
 			addConnectionObj.ch <- nxt
 		case lookupConnectionObj := <-c.lookupConnectionCh:
 			lookupConnectionObj.ch <- c.lookupTransporter(lookupConnectionObj.id)
@@ -194,6 +212,12 @@ func (c *ConnectionManager) run() {
 				}
 			}
 			lookupByClientTypeObj.ch <- found
+		case o := <-c.lookupClientDetailsCh:
+			var details keybase1.ClientDetails
+			if conn := c.lookup[o.id]; conn != nil && conn.details != nil {
+				details = *conn.details
+			}
+			o.ch <- details
 		case f := <-c.applyAllCh:
 			for k, v := range c.lookup {
 				if !f(k, v.transporter) {
@@ -215,15 +239,16 @@ func (c *ConnectionManager) ApplyAll(f ApplyFn) {
 // routing loop running.
 func NewConnectionManager() *ConnectionManager {
 	ret := &ConnectionManager{
-		lookup:               make(map[ConnectionID](*rpcConnection)),
-		addConnectionCh:      make(chan *addConnectionObj),
-		lookupConnectionCh:   make(chan *lookupConnectionObj),
-		removeConnectionCh:   make(chan ConnectionID),
-		labelConnectionCh:    make(chan labelConnectionObj),
-		applyAllCh:           make(chan ApplyFn),
-		listAllCh:            make(chan chan<- []keybase1.ClientDetails),
-		shutdownCh:           make(chan struct{}),
-		lookupByClientTypeCh: make(chan *lookupByClientTypeObj),
+		lookup:                make(map[ConnectionID](*rpcConnection)),
+		addConnectionCh:       make(chan *addConnectionObj),
+		lookupConnectionCh:    make(chan *lookupConnectionObj),
+		removeConnectionCh:    make(chan ConnectionID),
+		labelConnectionCh:     make(chan labelConnectionObj),
+		applyAllCh:            make(chan ApplyFn),
+		listAllCh:             make(chan chan<- []keybase1.ClientDetails),
+		shutdownCh:            make(chan struct{}),
+		lookupByClientTypeCh:  make(chan *lookupByClientTypeObj),
+		lookupClientDetailsCh: make(chan *lookupClientDetailsObj),
 	}
 	go ret.run()
 	return ret
@@ -93,10 +93,21 @@ const (
 	CachedUserTimeout           = 10 * time.Minute // How long we'll go without rerequesting hints/merkle seqno
 	LinkCacheSize               = 0x10000
 	LinkCacheCleanDur           = 1 * time.Minute
+	TlfNameCacheTimeout         = 10 * time.Minute // How long a resolved TLF name is cached for
 
 	SigShortIDBytes  = 27
 	LocalTrackMaxAge = 48 * time.Hour
 
+	// DbDefaultQuotaBytes bounds how much LocalDb usage-tracked callers
+	// (see JSONLocalDb's usage tracker) may write in total before the
+	// oldest tracked entries are evicted to make room.
+	DbDefaultQuotaBytes = 500 * 1024 * 1024
+
+	// ChatAttachmentCacheDefaultQuotaBytes bounds how much disk space the
+	// decrypted chat attachment cache (see chat.DiskAssetCache) may use
+	// before the least-recently-used assets are evicted to make room.
+	ChatAttachmentCacheDefaultQuotaBytes = 500 * 1024 * 1024
+
 	CriticalClockSkewLimit = time.Hour
 )
 
@@ -331,6 +342,15 @@ const (
 	HTTPPollMaximum           = 5 * time.Second
 )
 
+// The following constants configure the transport (net/http.Transport)
+// underlying every Client -- how many idle connections it's willing to
+// keep warm for reuse, so mobile devices making frequent short-lived API
+// calls don't pay a fresh TCP+TLS handshake every time.
+const (
+	HTTPDefaultMaxIdleConns        = 100
+	HTTPDefaultMaxIdleConnsPerHost = 10
+)
+
 // The following constants apply to APIArg parameters for
 // critical idempotent API calls
 const (
@@ -431,6 +451,14 @@ const (
 	PaperKeyVersionBits   = 4
 	PaperKeyVersion       = 0
 	PaperKeyWordCountMin  = 13 // this should never change to a value greater than 13
+
+	// PaperKeySecretEntropyStrong is the secret entropy used for the
+	// "strong" paper key option: roughly double PaperKeySecretEntropy,
+	// which yields a phrase of about 22 words instead of 13. The
+	// underlying phrase is just scrypt-stretched wholesale (see
+	// PaperKeyGen), so a longer phrase is a strictly stronger key with
+	// no format change required to decode it.
+	PaperKeySecretEntropyStrong = 209
 )
 
 const UserSummaryLimit = 500 // max number of user summaries in one request
@@ -485,6 +513,7 @@ const (
 
 const (
 	EncryptionReasonChatLocalStorage EncryptionReason = "Keybase-Chat-Local-Storage-1"
+	EncryptionReasonKVStoreLocal     EncryptionReason = "Keybase-KVStore-Local-Storage-1"
 )
 
 // FirstPRodMerkleSeqnoWithSkips is the first merkle root on production that
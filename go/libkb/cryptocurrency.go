@@ -6,6 +6,7 @@ package libkb
 import (
 	"crypto/sha256"
 	"errors"
+	"strings"
 )
 
 type CryptocurrencyType int
@@ -19,6 +20,12 @@ const (
 	CryptocurrencyTypeZCashShielded         CryptocurrencyType = 5786 // 0x169a
 	CryptocurrencyTypeZCashTransparentP2PKH CryptocurrencyType = 7352 // 0x1cb8
 	CryptocurrencyTypeZCashTransparentP2SH  CryptocurrencyType = 7357 // 0x1cbd
+	// CryptocurrencyTypeBTCSegwit is a v0 native segwit address
+	// (bc1..., BIP-0173 Bech32), covering both P2WPKH and P2WSH. Unlike the
+	// other types above, its tag isn't a base58check version byte -- Bech32
+	// addresses don't have one -- it's just an arbitrary value distinct from
+	// the rest of this enum.
+	CryptocurrencyTypeBTCSegwit CryptocurrencyType = 100000
 )
 
 const (
@@ -35,7 +42,7 @@ type CryptocurrencyPrefix struct {
 
 func (p CryptocurrencyType) String() string {
 	switch p {
-	case CryptocurrencyTypeBTC, CryptocurrencyTypeBTCMultiSig:
+	case CryptocurrencyTypeBTC, CryptocurrencyTypeBTCMultiSig, CryptocurrencyTypeBTCSegwit:
 		return "bitcoin"
 	case CryptocurrencyTypeZCashShielded:
 		return "zcash.z"
@@ -48,7 +55,7 @@ func (p CryptocurrencyType) String() string {
 
 func (p CryptocurrencyType) ToCryptocurrencyFamily() CryptocurrencyFamily {
 	switch p {
-	case CryptocurrencyTypeBTC, CryptocurrencyTypeBTCMultiSig:
+	case CryptocurrencyTypeBTC, CryptocurrencyTypeBTCMultiSig, CryptocurrencyTypeBTCSegwit:
 		return CryptocurrencyFamilyBitcoin
 	case CryptocurrencyTypeZCashShielded, CryptocurrencyTypeZCashTransparentP2PKH, CryptocurrencyTypeZCashTransparentP2SH:
 		return CryptocurrencyFamilyZCash
@@ -87,6 +94,9 @@ func addressToType(b []byte) (CryptocurrencyType, error) {
 }
 
 func CryptocurrencyParseAndCheck(s string) (CryptocurrencyType, []byte, error) {
+	if strings.HasPrefix(strings.ToLower(s), "bc1") {
+		return segwitBTCAddrParseAndCheck(s)
+	}
 	buf, err := Decode58(s)
 	if err != nil {
 		return CryptocurrencyTypeNone, nil, err
@@ -114,14 +124,32 @@ func CryptocurrencyParseAndCheck(s string) (CryptocurrencyType, []byte, error) {
 	return typ, pkhash, nil
 }
 
+// segwitBTCAddrParseAndCheck validates a v0 native segwit BTC address
+// (bc1..., BIP-0173 Bech32). It only accepts the "bc" (mainnet)
+// human-readable part, matching CryptocurrencyParseAndCheck's base58check
+// path, which likewise doesn't accept testnet addresses.
+func segwitBTCAddrParseAndCheck(s string) (CryptocurrencyType, []byte, error) {
+	hrp, witnessVersion, witnessProgram, err := segwitAddressDecode(s)
+	if err != nil {
+		return CryptocurrencyTypeNone, nil, err
+	}
+	if hrp != "bc" {
+		return CryptocurrencyTypeNone, nil, errors.New("unsupported bech32 human-readable part")
+	}
+	if witnessVersion != 0 {
+		return CryptocurrencyTypeNone, nil, errors.New("unsupported segwit witness version")
+	}
+	return CryptocurrencyTypeBTCSegwit, witnessProgram, nil
+}
+
 func BtcAddrCheck(s string, _ *BtcOpts) (version int, pkhash []byte, err error) {
 	var typ CryptocurrencyType
 	typ, pkhash, err = CryptocurrencyParseAndCheck(s)
 	if err != nil {
 		return version, pkhash, err
 	}
-	if typ != CryptocurrencyTypeBTC && typ != CryptocurrencyTypeBTCMultiSig {
-		return int(CryptocurrencyTypeNone), nil, errors.New("only support BTC vanila and multisig")
+	if typ != CryptocurrencyTypeBTC && typ != CryptocurrencyTypeBTCMultiSig && typ != CryptocurrencyTypeBTCSegwit {
+		return int(CryptocurrencyTypeNone), nil, errors.New("only support BTC vanila, multisig, and segwit")
 	}
 	return int(typ), pkhash, nil
 }
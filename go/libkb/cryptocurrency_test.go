@@ -48,6 +48,9 @@ var testVectors = []struct {
 	{"3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy", CryptocurrencyTypeBTCMultiSig},
 	{"3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLyx", CryptocurrencyTypeNone},
 	{"3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLx", CryptocurrencyTypeNone},
+	{"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", CryptocurrencyTypeBTCSegwit},
+	{"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4x", CryptocurrencyTypeNone},
+	{"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t", CryptocurrencyTypeNone},
 }
 
 func TestCryptocurrencyParseAndCheck(t *testing.T) {
@@ -4,11 +4,13 @@
 package libkb
 
 import (
+	"container/list"
 	"encoding/json"
 	"fmt"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 
 	keybase1 "github.com/keybase/client/go/protocol/keybase1"
 	jsonw "github.com/keybase/go-jsonw"
@@ -82,21 +84,53 @@ func jsonLocalDbLookup(ops LocalDbOps, id DbKey) (*jsonw.Wrapper, error) {
 }
 
 type JSONLocalDb struct {
+	Contextified
 	engine LocalDb
+	usage  *dbUsageTracker
 }
 
-func NewJSONLocalDb(e LocalDb) *JSONLocalDb  { return &JSONLocalDb{e} }
+func NewJSONLocalDb(g *GlobalContext, e LocalDb) *JSONLocalDb {
+	return &JSONLocalDb{
+		Contextified: NewContextified(g),
+		engine:       e,
+		usage:        newDbUsageTracker(),
+	}
+}
 func (j *JSONLocalDb) Open() error           { return j.engine.Open() }
 func (j *JSONLocalDb) ForceOpen() error      { return j.engine.ForceOpen() }
 func (j *JSONLocalDb) Close() error          { return j.engine.Close() }
-func (j *JSONLocalDb) Nuke() (string, error) { return j.engine.Nuke() }
+func (j *JSONLocalDb) Nuke() (string, error) {
+	fn, err := j.engine.Nuke()
+	j.usage = newDbUsageTracker()
+	return fn, err
+}
 
-func (j *JSONLocalDb) PutRaw(id DbKey, b []byte) error       { return j.engine.Put(id, nil, b) }
+func (j *JSONLocalDb) PutRaw(id DbKey, b []byte) error {
+	if err := j.engine.Put(id, nil, b); err != nil {
+		return err
+	}
+	j.trackPut(id, len(b))
+	return nil
+}
 func (j *JSONLocalDb) GetRaw(id DbKey) ([]byte, bool, error) { return j.engine.Get(id) }
-func (j *JSONLocalDb) Delete(id DbKey) error                 { return j.engine.Delete(id) }
+
+func (j *JSONLocalDb) Delete(id DbKey) error {
+	if err := j.engine.Delete(id); err != nil {
+		return err
+	}
+	j.usage.remove(id)
+	return nil
+}
 
 func (j *JSONLocalDb) Put(id DbKey, aliases []DbKey, val *jsonw.Wrapper) error {
-	return jsonLocalDbPut(j.engine, id, aliases, val)
+	if err := jsonLocalDbPut(j.engine, id, aliases, val); err != nil {
+		return err
+	}
+	b, err := val.Marshal()
+	if err == nil {
+		j.trackPut(id, len(b))
+	}
+	return nil
 }
 
 func (j *JSONLocalDb) Get(id DbKey) (*jsonw.Wrapper, error) {
@@ -108,7 +142,37 @@ func (j *JSONLocalDb) GetInto(obj interface{}, id DbKey) (found bool, err error)
 }
 
 func (j *JSONLocalDb) PutObj(id DbKey, aliases []DbKey, obj interface{}) (err error) {
-	return jsonLocalDbPutObj(j.engine, id, aliases, obj)
+	if err = jsonLocalDbPutObj(j.engine, id, aliases, obj); err != nil {
+		return err
+	}
+	if b, merr := json.Marshal(obj); merr == nil {
+		j.trackPut(id, len(b))
+	}
+	return nil
+}
+
+// trackPut records id's size with the usage tracker and, if that pushes
+// total usage-tracked bytes over Env.GetDbQuotaBytes, evicts the
+// least-recently-written tracked entries (other than id itself) until
+// back under quota. Eviction failures are logged and otherwise ignored,
+// since a stale usage estimate is far less harmful than losing a write
+// the caller is waiting on.
+func (j *JSONLocalDb) trackPut(id DbKey, size int) {
+	quota := int64(j.G().Env.GetDbQuotaBytes())
+	evict := j.usage.recordPut(id, size, quota)
+	for _, victim := range evict {
+		if err := j.engine.Delete(victim); err != nil {
+			j.G().Log.Debug("JSONLocalDb: failed to evict %s over quota: %s", victim.ToString(""), err)
+		}
+	}
+}
+
+// UsageStats reports, per ObjType, the total bytes JSONLocalDb's usage
+// tracker currently believes are stored for that type. It only reflects
+// activity that went through this JSONLocalDb since process start (or
+// since the last Nuke); it is not a full scan of the underlying engine.
+func (j *JSONLocalDb) UsageStats() map[ObjType]int64 {
+	return j.usage.byType()
 }
 
 func (j *JSONLocalDb) Lookup(id DbKey) (*jsonw.Wrapper, error) {
@@ -168,6 +232,7 @@ const (
 	DBSig                     = 0x0f
 	DBLink                    = 0xe0
 	DBLocalTrack              = 0xe1
+	DBTeamMembers             = 0xe2
 	DBPGPKey                  = 0xe3
 	DBSigHints                = 0xe4
 	DBProofCheck              = 0xe5
@@ -175,6 +240,10 @@ const (
 	DBSigChainTailPublic      = 0xe7
 	DBSigChainTailSemiprivate = 0xe8
 	DBSigChainTailEncrypted   = 0xe9
+	DBTeamNames               = 0xea
+	DBTeamInvites             = 0xeb
+	DBFavorites               = 0xec
+	DBKVStore                 = 0xed
 	DBMerkleRoot              = 0xf0
 	DBTrackers                = 0xf1
 	DBGregor                  = 0xf2
@@ -189,6 +258,9 @@ const (
 	DBResolveUsernameToUID    = 0xfb
 	DBChatBodyHashIndex       = 0xfc
 	DBPvl                     = 0xfd
+	DBTlfIdentifyBehavior     = 0xfe
+	DBBadgeState              = 0xff
+	DBDeferredAPIQueue        = 0xee
 )
 
 const (
@@ -206,3 +278,92 @@ func DbKeyNotificationDismiss(prefix string, username NormalizedUsername) DbKey
 		Key: fmt.Sprintf("%s:%s", prefix, username),
 	}
 }
+
+// dbUsageTracker keeps an approximate, in-memory byte budget over writes
+// made through a JSONLocalDb, so that unbounded caches like pvlsource's
+// kit cache can't quietly grow the on-disk db without limit. It only
+// knows about entries written since it was created (see JSONLocalDb.Nuke),
+// not the engine's full contents, so it's an estimate, not an audit.
+type dbUsageTracker struct {
+	sync.Mutex
+	order *list.List              // MRU at the back, LRU at the front
+	elems map[DbKey]*list.Element // id -> its node in order
+	sizes map[DbKey]int64         // id -> last known size
+	bytes map[ObjType]int64       // Typ -> total tracked bytes
+}
+
+func newDbUsageTracker() *dbUsageTracker {
+	return &dbUsageTracker{
+		order: list.New(),
+		elems: make(map[DbKey]*list.Element),
+		sizes: make(map[DbKey]int64),
+		bytes: make(map[ObjType]int64),
+	}
+}
+
+// recordPut records that id now occupies size bytes, replacing whatever
+// it occupied before, marks it most-recently-used, and returns the ids
+// of whichever least-recently-used entries (other than id) must be
+// evicted to bring the total back under quota. A non-positive quota
+// disables eviction.
+func (d *dbUsageTracker) recordPut(id DbKey, size int, quota int64) (evict []DbKey) {
+	d.Lock()
+	defer d.Unlock()
+
+	d.removeLocked(id)
+	d.sizes[id] = int64(size)
+	d.bytes[id.Typ] += int64(size)
+	d.elems[id] = d.order.PushBack(id)
+
+	if quota <= 0 {
+		return nil
+	}
+	for d.totalLocked() > quota && d.order.Len() > 1 {
+		front := d.order.Front()
+		victim := front.Value.(DbKey)
+		if victim == id {
+			break
+		}
+		evict = append(evict, victim)
+		d.removeLocked(victim)
+	}
+	return evict
+}
+
+func (d *dbUsageTracker) remove(id DbKey) {
+	d.Lock()
+	defer d.Unlock()
+	d.removeLocked(id)
+}
+
+func (d *dbUsageTracker) removeLocked(id DbKey) {
+	if elem, ok := d.elems[id]; ok {
+		d.order.Remove(elem)
+		delete(d.elems, id)
+	}
+	if size, ok := d.sizes[id]; ok {
+		d.bytes[id.Typ] -= size
+		if d.bytes[id.Typ] <= 0 {
+			delete(d.bytes, id.Typ)
+		}
+		delete(d.sizes, id)
+	}
+}
+
+func (d *dbUsageTracker) totalLocked() int64 {
+	var total int64
+	for _, b := range d.bytes {
+		total += b
+	}
+	return total
+}
+
+func (d *dbUsageTracker) byType() map[ObjType]int64 {
+	d.Lock()
+	defer d.Unlock()
+	ret := make(map[ObjType]int64, len(d.bytes))
+	for typ, b := range d.bytes {
+		ret[typ] = b
+	}
+	return ret
+}
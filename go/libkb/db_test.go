@@ -0,0 +1,57 @@
+// Copyright 2018 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import "testing"
+
+func TestDbUsageTrackerByType(t *testing.T) {
+	tr := newDbUsageTracker()
+	tr.recordPut(DbKey{Typ: DBUser, Key: "a"}, 10, 0)
+	tr.recordPut(DbKey{Typ: DBUser, Key: "b"}, 20, 0)
+	tr.recordPut(DbKey{Typ: DBSig, Key: "c"}, 5, 0)
+
+	stats := tr.byType()
+	if stats[DBUser] != 30 {
+		t.Fatalf("expected DBUser usage of 30, got %d", stats[DBUser])
+	}
+	if stats[DBSig] != 5 {
+		t.Fatalf("expected DBSig usage of 5, got %d", stats[DBSig])
+	}
+}
+
+func TestDbUsageTrackerEvictsLRU(t *testing.T) {
+	tr := newDbUsageTracker()
+	a := DbKey{Typ: DBUser, Key: "a"}
+	b := DbKey{Typ: DBUser, Key: "b"}
+	c := DbKey{Typ: DBUser, Key: "c"}
+
+	if evict := tr.recordPut(a, 10, 25); len(evict) != 0 {
+		t.Fatalf("expected no eviction yet, got: %v", evict)
+	}
+	if evict := tr.recordPut(b, 10, 25); len(evict) != 0 {
+		t.Fatalf("expected no eviction yet, got: %v", evict)
+	}
+	// Total is now 30, over the quota of 25: a (least recently used) should go.
+	evict := tr.recordPut(c, 10, 25)
+	if len(evict) != 1 || evict[0] != a {
+		t.Fatalf("expected a to be evicted, got: %v", evict)
+	}
+
+	stats := tr.byType()
+	if stats[DBUser] != 20 {
+		t.Fatalf("expected 20 bytes left after eviction, got %d", stats[DBUser])
+	}
+}
+
+func TestDbUsageTrackerRemove(t *testing.T) {
+	tr := newDbUsageTracker()
+	a := DbKey{Typ: DBUser, Key: "a"}
+	tr.recordPut(a, 10, 0)
+	tr.remove(a)
+
+	stats := tr.byType()
+	if _, ok := stats[DBUser]; ok {
+		t.Fatalf("expected DBUser entry to be gone after remove, got: %v", stats)
+	}
+}
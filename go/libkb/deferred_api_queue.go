@@ -0,0 +1,200 @@
+// Copyright 2018 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DeferredAPIRequestPolicy controls how a deferred request for a given
+// endpoint is retried: how many times, and with what backoff, before
+// it's given up on and dropped from the queue.
+type DeferredAPIRequestPolicy struct {
+	// MaxAttempts is how many times Flush will retry the request before
+	// dropping it. 0 means the default of 10.
+	MaxAttempts int
+	// BaseBackoff is the delay before the first retry; each subsequent
+	// retry doubles it, capped at MaxBackoff. 0 means the default of 5
+	// seconds.
+	BaseBackoff time.Duration
+	// MaxBackoff caps the exponential backoff. 0 means the default of
+	// 10 minutes.
+	MaxBackoff time.Duration
+}
+
+func (p DeferredAPIRequestPolicy) withDefaults() DeferredAPIRequestPolicy {
+	if p.MaxAttempts == 0 {
+		p.MaxAttempts = 10
+	}
+	if p.BaseBackoff == 0 {
+		p.BaseBackoff = 5 * time.Second
+	}
+	if p.MaxBackoff == 0 {
+		p.MaxBackoff = 10 * time.Minute
+	}
+	return p
+}
+
+// DeferredAPIRequest is a single idempotent POST that couldn't be sent
+// (typically because the device was offline) and is persisted until it
+// can be retried. Only the pieces needed to rebuild an APIArg are kept;
+// non-serializable APIArg fields like SessionR are the caller's problem
+// to reattach when it flushes the queue.
+type DeferredAPIRequest struct {
+	Endpoint    string     `json:"endpoint"`
+	Args        url.Values `json:"args"`
+	EnqueuedAt  time.Time  `json:"enqueued_at"`
+	Attempts    int        `json:"attempts"`
+	NextAttempt time.Time  `json:"next_attempt"`
+}
+
+func (r DeferredAPIRequest) ready(now time.Time) bool {
+	return now.After(r.NextAttempt) || now.Equal(r.NextAttempt)
+}
+
+func (r *DeferredAPIRequest) scheduleRetry(policy DeferredAPIRequestPolicy, now time.Time) {
+	r.Attempts++
+	backoff := policy.BaseBackoff << uint(r.Attempts-1)
+	if backoff <= 0 || backoff > policy.MaxBackoff {
+		backoff = policy.MaxBackoff
+	}
+	r.NextAttempt = now.Add(backoff)
+}
+
+var deferredAPIQueueDbKey = DbKey{Typ: DBDeferredAPIQueue, Key: "queue"}
+
+// DeferredAPIQueue is a generic, disk-persisted queue of idempotent API
+// POSTs that failed (typically for lack of connectivity) and need to be
+// retried later, with a retry policy configurable per endpoint. It's
+// meant for calls like proof checks, tracking statements, or read
+// receipts, where losing the call silently would be worse than
+// resending it late.
+//
+// DeferredAPIQueue only stores and schedules requests; it doesn't know
+// how to perform one. Flush is handed a poster function by the caller
+// (see InternalAPIEngine) so it can reattach session/context state that
+// isn't itself persisted.
+type DeferredAPIQueue struct {
+	Contextified
+
+	policiesMu sync.Mutex
+	policies   map[string]DeferredAPIRequestPolicy
+
+	// ioMu serializes the load-modify-save cycles Enqueue and Flush do
+	// against LocalDb, so concurrent callers can't clobber each other's
+	// writes.
+	ioMu sync.Mutex
+}
+
+// NewDeferredAPIQueue returns an empty queue backed by g.LocalDb.
+func NewDeferredAPIQueue(g *GlobalContext) *DeferredAPIQueue {
+	return &DeferredAPIQueue{
+		Contextified: NewContextified(g),
+		policies:     make(map[string]DeferredAPIRequestPolicy),
+	}
+}
+
+// SetPolicy configures how requests for the given endpoint are retried.
+// Endpoints with no configured policy use DeferredAPIRequestPolicy{}'s
+// defaults.
+func (q *DeferredAPIQueue) SetPolicy(endpoint string, policy DeferredAPIRequestPolicy) {
+	q.policiesMu.Lock()
+	defer q.policiesMu.Unlock()
+	q.policies[endpoint] = policy
+}
+
+func (q *DeferredAPIQueue) policyFor(endpoint string) DeferredAPIRequestPolicy {
+	q.policiesMu.Lock()
+	defer q.policiesMu.Unlock()
+	return q.policies[endpoint].withDefaults()
+}
+
+func (q *DeferredAPIQueue) load() ([]DeferredAPIRequest, error) {
+	var reqs []DeferredAPIRequest
+	if _, err := q.G().LocalDb.GetInto(&reqs, deferredAPIQueueDbKey); err != nil {
+		return nil, err
+	}
+	return reqs, nil
+}
+
+func (q *DeferredAPIQueue) save(reqs []DeferredAPIRequest) error {
+	return q.G().LocalDb.PutObj(deferredAPIQueueDbKey, nil, reqs)
+}
+
+// Enqueue persists a request for later retry by Flush.
+func (q *DeferredAPIQueue) Enqueue(endpoint string, args url.Values) error {
+	q.ioMu.Lock()
+	defer q.ioMu.Unlock()
+
+	reqs, err := q.load()
+	if err != nil {
+		return err
+	}
+	reqs = append(reqs, DeferredAPIRequest{
+		Endpoint:   endpoint,
+		Args:       args,
+		EnqueuedAt: q.G().Clock().Now(),
+	})
+	return q.save(reqs)
+}
+
+// Len returns the number of requests currently queued, ready or not.
+func (q *DeferredAPIQueue) Len() (int, error) {
+	reqs, err := q.load()
+	if err != nil {
+		return 0, err
+	}
+	return len(reqs), nil
+}
+
+// Flush retries every queued request that's ready (i.e. not still
+// backing off from a prior failure) via poster, in FIFO order.
+// Requests that succeed are dropped; requests that fail are
+// rescheduled with backoff and, once their policy's MaxAttempts is
+// exhausted, dropped and logged. Flush is meant to be called whenever
+// connectivity is restored (see reachability.go in go/service).
+func (q *DeferredAPIQueue) Flush(poster func(endpoint string, args url.Values) error) {
+	q.ioMu.Lock()
+	defer q.ioMu.Unlock()
+
+	reqs, err := q.load()
+	if err != nil {
+		q.G().Log.Warning("DeferredAPIQueue: failed to load queue: %s", err)
+		return
+	}
+	if len(reqs) == 0 {
+		return
+	}
+
+	now := q.G().Clock().Now()
+	remaining := make([]DeferredAPIRequest, 0, len(reqs))
+	for _, req := range reqs {
+		if !req.ready(now) {
+			remaining = append(remaining, req)
+			continue
+		}
+
+		policy := q.policyFor(req.Endpoint)
+		if err := poster(req.Endpoint, req.Args); err != nil {
+			req.scheduleRetry(policy, now)
+			if req.Attempts >= policy.MaxAttempts {
+				q.G().Log.Warning("DeferredAPIQueue: dropping %s after %d attempts: %s", req.Endpoint, req.Attempts, err)
+				continue
+			}
+			remaining = append(remaining, req)
+			continue
+		}
+	}
+
+	sort.SliceStable(remaining, func(i, j int) bool {
+		return remaining[i].EnqueuedAt.Before(remaining[j].EnqueuedAt)
+	})
+
+	if err := q.save(remaining); err != nil {
+		q.G().Log.Warning("DeferredAPIQueue: failed to persist queue after flush: %s", err)
+	}
+}
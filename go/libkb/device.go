@@ -41,14 +41,20 @@ type Device struct {
 	MTime       keybase1.Time     `json:"mtime"`
 }
 
-// NewPaperDevice creates a new paper backup key device
-func NewPaperDevice(passphrasePrefix string) (*Device, error) {
+// NewPaperDevice creates a new paper backup key device. If label is
+// non-empty, it's used as the device's user-facing name so multiple
+// paper keys can be told apart at a glance; otherwise the device falls
+// back to the passphrase's word prefix, as it always has.
+func NewPaperDevice(passphrasePrefix, label string) (*Device, error) {
 	did, err := NewDeviceID()
 	if err != nil {
 		return nil, err
 	}
 	s := DeviceStatusActive
 	desc := passphrasePrefix
+	if label != "" {
+		desc = label
+	}
 
 	d := &Device{
 		ID:          did,
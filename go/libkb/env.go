@@ -4,6 +4,7 @@
 package libkb
 
 import (
+	"encoding/hex"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -26,6 +27,13 @@ func (n NullConfiguration) GetSessionFilename() string
 func (n NullConfiguration) GetDbFilename() string                                          { return "" }
 func (n NullConfiguration) GetChatDbFilename() string                                      { return "" }
 func (n NullConfiguration) GetPvlKitFilename() string                                      { return "" }
+func (n NullConfiguration) GetMerkleRefreshUID() string                                    { return "" }
+func (n NullConfiguration) GetPvlSigningKID() string                                       { return "" }
+func (n NullConfiguration) GetPvlShouldRefreshInterval() (time.Duration, bool)              { return 0, false }
+func (n NullConfiguration) GetPvlRequireRefreshInterval() (time.Duration, bool)             { return 0, false }
+func (n NullConfiguration) GetPvlChannel() string                                          { return "" }
+func (n NullConfiguration) GetDbQuotaBytes() (int, bool)                                   { return 0, false }
+func (n NullConfiguration) GetChatAttachmentCacheQuotaBytes() (int, bool)                  { return 0, false }
 func (n NullConfiguration) GetUsername() NormalizedUsername                                { return NormalizedUsername("") }
 func (n NullConfiguration) GetEmail() string                                               { return "" }
 func (n NullConfiguration) GetProxy() string                                               { return "" }
@@ -65,6 +73,9 @@ func (n NullConfiguration) GetAPITimeout() (time.Duration, bool)
 func (n NullConfiguration) GetTorMode() (TorMode, error)                                   { return TorNone, nil }
 func (n NullConfiguration) GetTorHiddenAddress() string                                    { return "" }
 func (n NullConfiguration) GetTorProxy() string                                            { return "" }
+func (n NullConfiguration) GetProofProxyMode() (TorMode, error)                            { return TorNone, nil }
+func (n NullConfiguration) GetProofProxy() string                                          { return "" }
+func (n NullConfiguration) GetDNSOverHTTPSServer() string                                  { return "" }
 func (n NullConfiguration) GetUpdatePreferenceAuto() (bool, bool)                          { return false, false }
 func (n NullConfiguration) GetUpdatePreferenceSnoozeUntil() keybase1.Time                  { return keybase1.Time(0) }
 func (n NullConfiguration) GetUpdateLastChecked() keybase1.Time                            { return keybase1.Time(0) }
@@ -78,6 +89,10 @@ func (n NullConfiguration) GetGregorSaveInterval() (time.Duration, bool)
 func (n NullConfiguration) GetGregorPingInterval() (time.Duration, bool)                   { return 0, false }
 func (n NullConfiguration) GetGregorPingTimeout() (time.Duration, bool)                    { return 0, false }
 func (n NullConfiguration) GetChatDelivererInterval() (time.Duration, bool)                { return 0, false }
+func (n NullConfiguration) GetChatEphemeralPurgeInterval() (time.Duration, bool)           { return 0, false }
+func (n NullConfiguration) GetChatRetentionSweepInterval() (time.Duration, bool)           { return 0, false }
+func (n NullConfiguration) GetIdentify2CacheSuccessTimeout() (time.Duration, bool)         { return 0, false }
+func (n NullConfiguration) GetIdentify2CacheFailureTimeout() (time.Duration, bool)         { return 0, false }
 func (n NullConfiguration) IsAdmin() (bool, bool)                                          { return false, false }
 func (n NullConfiguration) GetGregorDisabled() (bool, bool)                                { return false, false }
 func (n NullConfiguration) GetMountDir() string                                            { return "" }
@@ -459,6 +474,224 @@ func (e *Env) GetPvlKitFilename() string {
 	)
 }
 
+// GetMerkleRefreshUID gets the UID to look up in order to force a merkle
+// root refresh (see PvlSource.refreshRoot). Its value is usually "" which
+// means to fall back to the logged-in user, or failing that, t_alice.
+// Private deployments without a t_alice user can pin this to a UID that's
+// guaranteed to exist in their own realm.
+func (e *Env) GetMerkleRefreshUID() keybase1.UID {
+	s := e.GetString(
+		func() string { return e.cmd.GetMerkleRefreshUID() },
+		func() string { return os.Getenv("KEYBASE_MERKLE_REFRESH_UID") },
+		func() string { return e.config.GetMerkleRefreshUID() },
+	)
+	return keybase1.UID(s)
+}
+
+// GetPvlSigningKID returns the KID a pvl kit's embedded signature must be
+// signed by, if kit signature verification is required. Its value is
+// usually "" which means kits are trusted based on the merkle hash check
+// alone, without requiring an embedded signature.
+func (e *Env) GetPvlSigningKID() string {
+	return e.GetString(
+		func() string { return e.cmd.GetPvlSigningKID() },
+		func() string { return os.Getenv("KEYBASE_PVL_SIGNING_KID") },
+		func() string { return e.config.GetPvlSigningKID() },
+	)
+}
+
+// GetPvlKitStrict reports whether a pvl kit that fails Tab-key
+// validation (negative or non-contiguous versions) should be
+// rejected outright rather than just logged as a warning.
+func (e *Env) GetPvlKitStrict() bool {
+	return e.GetBool(false,
+		func() (bool, bool) { return e.getEnvBool("KEYBASE_PVL_KIT_STRICT") },
+	)
+}
+
+// GetPvlAllowDegraded reports whether an identify may proceed with a
+// cached pvl kit pinned to a merkle root older than pvlsource's
+// tRequireRefresh, rather than failing outright, as long as the root is
+// still within pvlsource's degraded-mode grace window. Identifies that
+// take this path are expected to warn that their result may be based on
+// stale proof-checking rules.
+func (e *Env) GetPvlAllowDegraded() bool {
+	return e.GetBool(false,
+		func() (bool, bool) { return e.getEnvBool("KEYBASE_PVL_ALLOW_DEGRADED") },
+	)
+}
+
+// GetPvlShouldRefreshInterval gets how old a cached merkle root may be
+// before GetKitString attempts to refresh it. Defaults to pvlsource's
+// compiled-in tShouldRefresh.
+func (e *Env) GetPvlShouldRefreshInterval() time.Duration {
+	return e.GetDuration(time.Hour,
+		func() (time.Duration, bool) { return e.getEnvDuration("KEYBASE_PVL_SHOULD_REFRESH_INTERVAL") },
+		func() (time.Duration, bool) { return e.config.GetPvlShouldRefreshInterval() },
+		func() (time.Duration, bool) { return e.cmd.GetPvlShouldRefreshInterval() },
+	)
+}
+
+// GetPvlRequireRefreshInterval gets how old a cached merkle root may get
+// before it's too stale to serve at all (short of degraded mode; see
+// GetPvlAllowDegraded). Defaults to pvlsource's compiled-in
+// tRequireRefresh. Callers should treat a value below
+// GetPvlShouldRefreshInterval as invalid and fall back to the defaults.
+func (e *Env) GetPvlRequireRefreshInterval() time.Duration {
+	return e.GetDuration(24*time.Hour,
+		func() (time.Duration, bool) { return e.getEnvDuration("KEYBASE_PVL_REQUIRE_REFRESH_INTERVAL") },
+		func() (time.Duration, bool) { return e.config.GetPvlRequireRefreshInterval() },
+		func() (time.Duration, bool) { return e.cmd.GetPvlRequireRefreshInterval() },
+	)
+}
+
+// GetPvlChannel gets the named pvl release channel to request, e.g.
+// "beta". Its value is usually "" which means the default (stable)
+// channel: the plain pvl_hash on the merkle root, unchanged from
+// before channels existed.
+func (e *Env) GetPvlChannel() string {
+	return e.GetString(
+		func() string { return e.cmd.GetPvlChannel() },
+		func() string { return os.Getenv("KEYBASE_PVL_CHANNEL") },
+		func() string { return e.config.GetPvlChannel() },
+	)
+}
+
+// GetDbQuotaBytes gets the total size, in bytes, that LocalDb's usage
+// tracker allows usage-tracked callers to occupy before it starts
+// evicting the oldest entries. Defaults to DbDefaultQuotaBytes.
+func (e *Env) GetDbQuotaBytes() int {
+	return e.GetInt(DbDefaultQuotaBytes,
+		e.cmd.GetDbQuotaBytes,
+		func() (int, bool) { return e.getEnvInt("KEYBASE_DB_QUOTA_BYTES") },
+		e.config.GetDbQuotaBytes,
+	)
+}
+
+// GetChatAttachmentCacheQuotaBytes gets the total size, in bytes, that the
+// decrypted chat attachment disk cache may occupy before it starts
+// evicting the least-recently-used assets. Defaults to
+// ChatAttachmentCacheDefaultQuotaBytes.
+func (e *Env) GetChatAttachmentCacheQuotaBytes() int {
+	return e.GetInt(ChatAttachmentCacheDefaultQuotaBytes,
+		e.cmd.GetChatAttachmentCacheQuotaBytes,
+		func() (int, bool) { return e.getEnvInt("KEYBASE_CHAT_ATTACHMENT_CACHE_QUOTA_BYTES") },
+		e.config.GetChatAttachmentCacheQuotaBytes,
+	)
+}
+
+// GetLocalHTTPGatewayPort returns the localhost port the optional REST
+// gateway (see `keybase gateway`, in go/client/cmd_gateway.go) should
+// bind on. Zero, the default, means the gateway is disabled: it's an
+// opt-in feature, since it opens a second, differently-authenticated
+// door into the service.
+func (e *Env) GetLocalHTTPGatewayPort() int {
+	return e.GetInt(0,
+		func() (int, bool) { return e.getEnvInt("KEYBASE_LOCAL_HTTP_GATEWAY_PORT") },
+		func() (int, bool) { return e.config.GetIntAtPath("local_http_gateway_port") },
+	)
+}
+
+// GetAPIMaxIdleConns returns the max number of idle (keep-alive) HTTP
+// connections a Client's transport will hold open across all hosts, for
+// reuse by later requests. See net/http.Transport.MaxIdleConns.
+func (e *Env) GetAPIMaxIdleConns() int {
+	return e.GetInt(HTTPDefaultMaxIdleConns,
+		func() (int, bool) { return e.getEnvInt("KEYBASE_API_MAX_IDLE_CONNS") },
+		func() (int, bool) { return e.config.GetIntAtPath("api_max_idle_conns") },
+	)
+}
+
+// GetAPIMaxIdleConnsPerHost returns the max number of idle (keep-alive)
+// HTTP connections a Client's transport will hold open per host. See
+// net/http.Transport.MaxIdleConnsPerHost.
+func (e *Env) GetAPIMaxIdleConnsPerHost() int {
+	return e.GetInt(HTTPDefaultMaxIdleConnsPerHost,
+		func() (int, bool) { return e.getEnvInt("KEYBASE_API_MAX_IDLE_CONNS_PER_HOST") },
+		func() (int, bool) { return e.config.GetIntAtPath("api_max_idle_conns_per_host") },
+	)
+}
+
+// GetAPIDisableHTTP2 disables HTTP/2 negotiation on Client's transport
+// when true. HTTP/2 is used by default; this is an escape hatch for
+// networks where it causes trouble (some corporate proxies mishandle
+// it).
+func (e *Env) GetAPIDisableHTTP2() bool {
+	return e.GetBool(false,
+		func() (bool, bool) { return e.getEnvBool("KEYBASE_API_DISABLE_HTTP2") },
+		func() (bool, bool) { return e.config.GetBoolAtPath("api_disable_http2") },
+	)
+}
+
+// GetProxyType returns the default proxy type applied to network paths that
+// go through GetProxyPolicy and don't have a component-specific override.
+// This is independent of GetTorMode/GetProofProxyMode, which remain the
+// SOCKS5-only knobs for API and proof-check traffic respectively.
+func (e *Env) GetProxyType() ProxyType {
+	if s := os.Getenv("KEYBASE_PROXY_TYPE"); s != "" {
+		if t, err := StringToProxyType(s); err == nil {
+			return t
+		}
+	}
+	if s, isSet := e.config.GetStringAtPath("proxy.type"); isSet {
+		if t, err := StringToProxyType(s); err == nil {
+			return t
+		}
+	}
+	return NoProxy
+}
+
+// GetProxyAddress returns the default proxy address used by GetProxyPolicy.
+func (e *Env) GetProxyAddress() string {
+	return e.GetString(
+		func() string { return os.Getenv("KEYBASE_PROXY_ADDRESS") },
+		func() string { s, _ := e.config.GetStringAtPath("proxy.address"); return s },
+	)
+}
+
+// GetProxyPolicy returns the ProxyPolicy that component (e.g. "gregor",
+// "attachment") should dial through: a per-component override if one is
+// configured, else the default set by GetProxyType/GetProxyAddress.
+func (e *Env) GetProxyPolicy(component string) ProxyPolicy {
+	prefix := "proxy." + component
+	envPrefix := "KEYBASE_" + strings.ToUpper(component) + "_PROXY_"
+
+	address := e.GetString(
+		func() string { return os.Getenv(envPrefix + "ADDRESS") },
+		func() string { s, _ := e.config.GetStringAtPath(prefix + ".address"); return s },
+		func() string { return e.GetProxyAddress() },
+	)
+
+	if s := os.Getenv(envPrefix + "TYPE"); s != "" {
+		if t, err := StringToProxyType(s); err == nil {
+			return ProxyPolicy{Type: t, Address: address}
+		}
+	}
+	if s, isSet := e.config.GetStringAtPath(prefix + ".type"); isSet {
+		if t, err := StringToProxyType(s); err == nil {
+			return ProxyPolicy{Type: t, Address: address}
+		}
+	}
+	return ProxyPolicy{Type: e.GetProxyType(), Address: address}
+}
+
+// GetLocalHTTPGatewayToken returns the bearer token clients of the local
+// HTTP gateway must present, generating and persisting one on first use.
+func (e *Env) GetLocalHTTPGatewayToken() (string, error) {
+	if tok, isSet := e.config.GetStringAtPath("local_http_gateway_token"); isSet && len(tok) > 0 {
+		return tok, nil
+	}
+	raw, err := RandBytes(32)
+	if err != nil {
+		return "", err
+	}
+	tok := hex.EncodeToString(raw)
+	if err := e.GetConfigWriter().SetStringAtPath("local_http_gateway_token", tok); err != nil {
+		return "", err
+	}
+	return tok, nil
+}
+
 func (e *Env) GetDebug() bool {
 	return e.GetBool(false,
 		func() (bool, bool) { return e.Test.GetDebug() },
@@ -632,6 +865,46 @@ func (e *Env) GetChatDelivererInterval() time.Duration {
 	)
 }
 
+func (e *Env) GetChatEphemeralPurgeInterval() time.Duration {
+	return e.GetDuration(1*time.Minute,
+		func() (time.Duration, bool) { return e.getEnvDuration("KEYBASE_CHAT_EPHEMERAL_PURGE_INTERVAL") },
+		func() (time.Duration, bool) { return e.config.GetChatEphemeralPurgeInterval() },
+		func() (time.Duration, bool) { return e.cmd.GetChatEphemeralPurgeInterval() },
+	)
+}
+
+// GetIdentify2CacheSuccessTimeout returns how long a clean (no broken
+// tracks) Identify2 result is trusted from the shared identify cache before
+// a fresh identify is required. This cache is consulted by chat, KBFS, and
+// the CLI alike, since they all route through the Identify2WithUID engine.
+func (e *Env) GetIdentify2CacheSuccessTimeout() time.Duration {
+	return e.GetDuration(Identify2CacheLongTimeout,
+		func() (time.Duration, bool) { return e.getEnvDuration("KEYBASE_IDENTIFY2_CACHE_SUCCESS_TIMEOUT") },
+		func() (time.Duration, bool) { return e.config.GetIdentify2CacheSuccessTimeout() },
+		func() (time.Duration, bool) { return e.cmd.GetIdentify2CacheSuccessTimeout() },
+	)
+}
+
+// GetIdentify2CacheFailureTimeout returns how long an Identify2 result with
+// broken tracks is trusted from the shared identify cache. This is normally
+// shorter than the success timeout, so a resolved or re-signed proof gets
+// picked up sooner.
+func (e *Env) GetIdentify2CacheFailureTimeout() time.Duration {
+	return e.GetDuration(Identify2CacheBrokenTimeout,
+		func() (time.Duration, bool) { return e.getEnvDuration("KEYBASE_IDENTIFY2_CACHE_FAILURE_TIMEOUT") },
+		func() (time.Duration, bool) { return e.config.GetIdentify2CacheFailureTimeout() },
+		func() (time.Duration, bool) { return e.cmd.GetIdentify2CacheFailureTimeout() },
+	)
+}
+
+func (e *Env) GetChatRetentionSweepInterval() time.Duration {
+	return e.GetDuration(1*time.Hour,
+		func() (time.Duration, bool) { return e.getEnvDuration("KEYBASE_CHAT_RETENTION_SWEEP_INTERVAL") },
+		func() (time.Duration, bool) { return e.config.GetChatRetentionSweepInterval() },
+		func() (time.Duration, bool) { return e.cmd.GetChatRetentionSweepInterval() },
+	)
+}
+
 func (e *Env) GetPidFile() (ret string, err error) {
 	ret = e.GetString(
 		func() string { return e.cmd.GetPidFile() },
@@ -1035,6 +1308,55 @@ func (e *Env) GetTorProxy() string {
 	)
 }
 
+// GetProofProxyMode controls how PVL-driven proof checks (fetching a user's
+// claimed proof off e.g. GitHub or a personal website) are routed. It's
+// independent of GetTorMode, since a user might want their identity traffic
+// to the Keybase API server routed one way, and the (necessarily
+// service-identifying) traffic for checking a specific proof routed another
+// way -- e.g. through Tor to avoid a censored site block, without paying
+// Tor's latency cost for ordinary API calls.
+func (e *Env) GetProofProxyMode() TorMode {
+	var ret TorMode
+
+	pick := func(m TorMode, err error) {
+		if ret == TorNone && err == nil {
+			ret = m
+		}
+	}
+
+	pick(e.cmd.GetProofProxyMode())
+	pick(StringToTorMode(os.Getenv("KEYBASE_PROOF_PROXY_MODE")))
+	pick(e.config.GetProofProxyMode())
+
+	return ret
+}
+
+// GetProofProxy returns the SOCKS5 proxy address used for proof checks when
+// GetProofProxyMode is enabled. Defaults to the Tor proxy address, so setting
+// just the mode is enough to route proof checks through the same local Tor
+// daemon used for API traffic.
+func (e *Env) GetProofProxy() string {
+	return e.GetString(
+		func() string { return e.cmd.GetProofProxy() },
+		func() string { return os.Getenv("KEYBASE_PROOF_PROXY") },
+		func() string { return e.config.GetProofProxy() },
+		func() string { return e.GetTorProxy() },
+	)
+}
+
+// GetDNSOverHTTPSServer returns the URL of a JSON-format DNS-over-HTTPS
+// resolver (e.g. https://dns.google/resolve) to try before the system
+// resolver when checking a DNS proof. Empty by default, meaning DNS proof
+// checks go straight to the system resolver, same as before this setting
+// existed.
+func (e *Env) GetDNSOverHTTPSServer() string {
+	return e.GetString(
+		func() string { return e.cmd.GetDNSOverHTTPSServer() },
+		func() string { return os.Getenv("KEYBASE_DNS_OVER_HTTPS_SERVER") },
+		func() string { return e.config.GetDNSOverHTTPSServer() },
+	)
+}
+
 func (e *Env) GetStoredSecretAccessGroup() string {
 	var override = e.GetBool(
 		false,
@@ -506,6 +506,21 @@ func (e LoginRequiredError) Error() string {
 	return msg
 }
 
+// KBFSNotRunningError is returned when a client-side operation needs to
+// reach KBFS (e.g. to resolve TLF crypt keys) but no KBFS connection is
+// currently registered with the service.
+type KBFSNotRunningError struct {
+	Context string
+}
+
+func (e KBFSNotRunningError) Error() string {
+	msg := "KBFS is not running"
+	if len(e.Context) > 0 {
+		msg = fmt.Sprintf("%s: %s", msg, e.Context)
+	}
+	return msg
+}
+
 type ReloginRequiredError struct{}
 
 func (e ReloginRequiredError) Error() string {
@@ -1086,6 +1101,42 @@ func NewPvlSourceError(msgf string, a ...interface{}) PvlSourceError {
 
 //=============================================================================
 
+// PvlParseError is returned when a pvl kit, though it matched its expected
+// hash, fails to parse as valid JSON of the expected shape. It is kept
+// distinct from PvlSourceError so callers can tell a malformed kit apart
+// from a transport or availability failure.
+type PvlParseError struct {
+	msg string
+}
+
+func (e PvlParseError) Error() string {
+	return fmt.Sprintf("PvlSource: parse error: %s", e.msg)
+}
+
+func NewPvlParseError(msgf string, a ...interface{}) PvlParseError {
+	return PvlParseError{msg: fmt.Sprintf(msgf, a...)}
+}
+
+//=============================================================================
+
+// MerkleStoreError is returned by a MerkleStore when it can't produce a
+// verified blob: the root is unavailable or too stale, the server fetch
+// failed, or the fetched blob didn't match the hash pinned in the root.
+type MerkleStoreError struct {
+	name string
+	msg  string
+}
+
+func (e MerkleStoreError) Error() string {
+	return fmt.Sprintf("MerkleStore(%s): %s", e.name, e.msg)
+}
+
+func NewMerkleStoreError(name, msgf string, a ...interface{}) MerkleStoreError {
+	return MerkleStoreError{name: name, msg: fmt.Sprintf(msgf, a...)}
+}
+
+//=============================================================================
+
 type CanceledError struct {
 	M string
 }
@@ -1280,6 +1331,20 @@ func (e TrackBrokenError) Error() string {
 
 //=============================================================================
 
+// TrackNotConfirmedError is returned when a send is running under a
+// track-before-send identify behavior (see
+// TLFIdentifyBehavior.RequiresTrackBeforeSend) and the recipient has no
+// existing track, and the identify UI didn't confirm one inline.
+type TrackNotConfirmedError struct {
+	Username string
+}
+
+func (e TrackNotConfirmedError) Error() string {
+	return fmt.Sprintf("send blocked: %s is untracked and was not tracked before sending", e.Username)
+}
+
+//=============================================================================
+
 type IdentifyDidNotCompleteError struct{}
 
 func (e IdentifyDidNotCompleteError) Error() string {
@@ -1818,3 +1883,28 @@ func (e DeviceNotFoundError) Error() string {
 }
 
 //=============================================================================
+
+// NetworkBreakerOpenError is returned by NetworkBreaker.Allow when the
+// named breaker has tripped open after too many consecutive failures, so
+// the caller should fail fast instead of adding to a retry storm against
+// a misbehaving server.
+type NetworkBreakerOpenError struct {
+	Name string
+}
+
+func (e NetworkBreakerOpenError) Error() string {
+	return fmt.Sprintf("network breaker %q is open", e.Name)
+}
+
+// NetworkBreakerRateLimitedError is returned by NetworkBreaker.Allow when
+// a caller is asking to retry sooner than the breaker's configured
+// MinInterval, e.g. a caller looping on a transient error.
+type NetworkBreakerRateLimitedError struct {
+	Name string
+}
+
+func (e NetworkBreakerRateLimitedError) Error() string {
+	return fmt.Sprintf("network breaker %q rate-limited this attempt", e.Name)
+}
+
+//=============================================================================
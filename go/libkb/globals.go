@@ -41,6 +41,15 @@ type LogoutHook interface {
 	OnLogout() error
 }
 
+// ConnectivityMonitor lets something outside this package -- an OS-level
+// network-change callback from the mobile bind layer, or this package's own
+// route-change watcher on desktop -- trigger an immediate reachability
+// check, instead of everything waiting for the next periodic poll. See
+// service.reachability, which implements this.
+type ConnectivityMonitor interface {
+	ForceCheck()
+}
+
 type GlobalContext struct {
 	Log          logger.Logger // Handles all logging
 	VDL          *VDebugLog    // verbose debug log
@@ -55,15 +64,26 @@ type GlobalContext struct {
 	XAPI         ExternalAPI   // for contacting Twitter, Github, etc.
 	Output       io.Writer     // where 'Stdout'-style output goes
 
+	MetricsRegistry *MetricsRegistry // operator-facing counters and latencies
+
+	NetworkBreakers *NetworkBreakerRegistry // shared rate limiters/circuit breakers, keyed by endpoint name
+	DeferredAPIQueue *DeferredAPIQueue      // idempotent POSTs queued while offline, for retry once connectivity returns
+	ConnectivityMonitor ConnectivityMonitor // set in service mode; lets network-change hooks force a reachability recheck
+
 	cacheMu        *sync.RWMutex   // protects all caches
 	ProofCache     *ProofCache     // where to cache proof results
 	TrackCache     *TrackCache     // cache of IdentifyOutcomes for tracking purposes
 	Identify2Cache Identify2Cacher // cache of Identify2 results for fast-pathing identify2 RPCS
+	TlfNameCache   TlfNameCacher   // cache of resolved and canonicalized TLF names
 	LinkCache      *LinkCache      // cache of ChainLinks
 	upakLoader     UPAKLoader      // Load flat users with the ability to hit the cache
 	CardCache      *UserCardCache  // cache of keybase1.UserCard objects
 	fullSelfer     FullSelfer      // a loader that gets the full self object
 	pvlSource      PvlSource       // a cache and fetcher for pvl
+	avatarLoader   AvatarLoader    // a cache and fetcher for user/team avatars
+
+	tlfResolverMu *sync.Mutex // protects tlfResolver
+	tlfResolver   TlfResolver // resolves TLF crypt keys and canonical names, normally via KBFS RPC
 
 	GpgClient         *GpgCLI        // A standard GPG-client (optional)
 	ShutdownHooks     []ShutdownHook // on shutdown, fire these...
@@ -123,8 +143,11 @@ func (g *GlobalContext) GetVDebugLog() *VDebugLog       { return g.VDL }
 func (g *GlobalContext) GetAPI() API                    { return g.API }
 func (g *GlobalContext) GetExternalAPI() ExternalAPI    { return g.XAPI }
 func (g *GlobalContext) GetServerURI() string           { return g.Env.GetServerURI() }
+func (g *GlobalContext) GetDNSOverHTTPSServer() string  { return g.Env.GetDNSOverHTTPSServer() }
 func (g *GlobalContext) GetMerkleClient() *MerkleClient { return g.MerkleClient }
 func (g *GlobalContext) GetNetContext() context.Context { return g.NetContext }
+func (g *GlobalContext) GetMetricsRegistry() *MetricsRegistry { return g.MetricsRegistry }
+func (g *GlobalContext) GetNetworkBreakers() *NetworkBreakerRegistry { return g.NetworkBreakers }
 
 func NewGlobalContext() *GlobalContext {
 	log := logger.New("keybase")
@@ -137,6 +160,7 @@ func NewGlobalContext() *GlobalContext {
 		shutdownOnce:       new(sync.Once),
 		loginStateMu:       new(sync.RWMutex),
 		clockMu:            new(sync.Mutex),
+		tlfResolverMu:      new(sync.Mutex),
 		clock:              clockwork.NewRealClock(),
 		hookMu:             new(sync.RWMutex),
 		oodiMu:             new(sync.RWMutex),
@@ -145,6 +169,8 @@ func NewGlobalContext() *GlobalContext {
 		uchMu:              new(sync.Mutex),
 		NewTriplesec:       NewSecureTriplesec,
 		NetContext:         context.TODO(),
+		MetricsRegistry:    NewMetricsRegistry(),
+		NetworkBreakers:    NewNetworkBreakerRegistry(clockwork.NewRealClock()),
 	}
 }
 
@@ -181,6 +207,7 @@ func (g *GlobalContext) Init() *GlobalContext {
 	g.RateLimits = NewRateLimits(g)
 	g.upakLoader = NewUncachedUPAKLoader(g)
 	g.fullSelfer = NewUncachedFullSelf(g)
+	g.DeferredAPIQueue = NewDeferredAPIQueue(g)
 	return g
 }
 
@@ -245,12 +272,16 @@ func (g *GlobalContext) Logout() error {
 	if g.CardCache != nil {
 		g.CardCache.Shutdown()
 	}
+	if g.TlfNameCache != nil {
+		g.TlfNameCache.Shutdown()
+	}
 
 	g.GetFullSelfer().OnLogout()
 
 	g.TrackCache = NewTrackCache()
 	g.Identify2Cache = NewIdentify2Cache(g.Env.GetUserCacheMaxAge())
 	g.CardCache = NewUserCardCache(g.Env.GetUserCacheMaxAge())
+	g.TlfNameCache = NewTlfNameCache(TlfNameCacheTimeout)
 
 	// get a clean LoginState:
 	g.createLoginStateLocked()
@@ -363,6 +394,8 @@ func (g *GlobalContext) configureMemCachesLocked() {
 	g.Log.Debug("Created LinkCache, max size: %d, clean dur: %s", g.Env.GetLinkCacheSize(), g.Env.GetLinkCacheCleanDur())
 	g.CardCache = NewUserCardCache(g.Env.GetUserCacheMaxAge())
 	g.Log.Debug("Created CardCache, max age: %s", g.Env.GetUserCacheMaxAge())
+	g.TlfNameCache = NewTlfNameCache(TlfNameCacheTimeout)
+	g.Log.Debug("Created TlfNameCache, max age: %s", TlfNameCacheTimeout)
 	g.fullSelfer = NewCachedFullSelf(g)
 	g.Log.Debug("made a new full self cache")
 	g.upakLoader = NewCachedUPAKLoader(g, CachedUserTimeout)
@@ -386,8 +419,8 @@ func (g *GlobalContext) configureDiskCachesLocked() error {
 	// We consider the local DBs as caches; they're caching our
 	// fetches from the server after all (and also our cryptographic
 	// checking).
-	g.LocalDb = NewJSONLocalDb(NewLevelDb(g, g.Env.GetDbFilename))
-	g.LocalChatDb = NewJSONLocalDb(NewLevelDb(g, g.Env.GetChatDbFilename))
+	g.LocalDb = NewJSONLocalDb(g, NewLevelDb(g, g.Env.GetDbFilename))
+	g.LocalChatDb = NewJSONLocalDb(g, NewLevelDb(g, g.Env.GetChatDbFilename))
 
 	e1 := g.LocalDb.Open()
 	e2 := g.LocalChatDb.Open()
@@ -418,6 +451,10 @@ func (g *GlobalContext) GetPvlSource() PvlSource {
 	return g.pvlSource
 }
 
+func (g *GlobalContext) GetAvatarLoader() AvatarLoader {
+	return g.avatarLoader
+}
+
 func (g *GlobalContext) ConfigureExportedStreams() error {
 	g.XStreams = NewExportedStreams()
 	return nil
@@ -476,6 +513,9 @@ func (g *GlobalContext) Shutdown() error {
 		if g.CardCache != nil {
 			g.CardCache.Shutdown()
 		}
+		if g.TlfNameCache != nil {
+			g.TlfNameCache.Shutdown()
+		}
 		if g.Resolver != nil {
 			g.Resolver.Shutdown()
 		}
@@ -683,6 +723,27 @@ func (g *GlobalContext) SetClock(c clockwork.Clock) {
 	g.clock = c
 }
 
+// GetTlfResolver returns the installed TlfResolver, defaulting to the
+// KBFS-RPC-backed implementation if nothing has been installed yet.
+func (g *GlobalContext) GetTlfResolver() TlfResolver {
+	g.tlfResolverMu.Lock()
+	defer g.tlfResolverMu.Unlock()
+	if g.tlfResolver == nil {
+		g.tlfResolver = NewCachedTlfResolver(g, NewKBFSTlfResolver(g))
+	}
+	return g.tlfResolver
+}
+
+// SetTlfResolver installs r as the TlfResolver, replacing whatever
+// resolves TLF crypt keys and canonical names for the rest of this
+// process. Tests and alternative frontends use this to swap in a
+// backend other than the default KBFS-RPC-backed one.
+func (g *GlobalContext) SetTlfResolver(r TlfResolver) {
+	g.tlfResolverMu.Lock()
+	defer g.tlfResolverMu.Unlock()
+	g.tlfResolver = r
+}
+
 func (g *GlobalContext) GetMyClientDetails() keybase1.ClientDetails {
 	return keybase1.ClientDetails{
 		ClientType: keybase1.ClientType_CLI,
@@ -836,6 +897,10 @@ func (g *GlobalContext) SetPvlSource(s PvlSource) {
 	g.pvlSource = s
 }
 
+func (g *GlobalContext) SetAvatarLoader(a AvatarLoader) {
+	g.avatarLoader = a
+}
+
 func (g *GlobalContext) LoadUserByUID(uid keybase1.UID) (*User, error) {
 	arg := NewLoadUserByUIDArg(nil, g, uid)
 	arg.PublicKeyOptional = true
@@ -855,6 +920,14 @@ func (g *GlobalContext) UIDToUsername(uid keybase1.UID) (NormalizedUsername, err
 func (g *GlobalContext) BustLocalUserCache(u keybase1.UID) {
 	g.GetUPAKLoader().Invalidate(g.NetContext, u)
 	g.GetFullSelfer().HandleUserChanged(u)
+	if g.TlfNameCache != nil {
+		// We don't track which cached TLF resolutions a given user
+		// participates in, so a key change for anyone busts the whole
+		// cache rather than just their entries.
+		if err := g.TlfNameCache.Clear(); err != nil {
+			g.Log.Debug("BustLocalUserCache: failed to clear TlfNameCache: %s", err)
+		}
+	}
 }
 
 func (g *GlobalContext) OverrideUPAKLoader(upak UPAKLoader) {
@@ -1047,7 +1047,7 @@ func (idt *IdentityTable) insertLink(l TypedChainLink) {
 }
 
 func (idt *IdentityTable) MarkCheckResult(err ProofError) {
-	idt.checkResult = NewNowCheckResult(idt.G(), err)
+	idt.checkResult = NewNowCheckResult(idt.G(), keybase1.ProofType_NONE, err)
 }
 
 func NewTypedChainLink(cl *ChainLink) (ret TypedChainLink, w Warning) {
@@ -1360,7 +1360,7 @@ func (idt *IdentityTable) proofRemoteCheck(ctx context.Context, hasPreviousTrack
 
 		if doCache {
 			idt.G().Log.CDebugf(ctx, "| Caching results under key=%s", sid)
-			if cacheErr := idt.G().ProofCache.Put(sid, res.err); cacheErr != nil {
+			if cacheErr := idt.G().ProofCache.Put(sid, p.GetProofType(), res.err); cacheErr != nil {
 				idt.G().Log.CWarningf(ctx, "proof cache put error: %s", cacheErr)
 			}
 		}
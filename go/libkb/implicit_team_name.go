@@ -0,0 +1,138 @@
+// Copyright 2018 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"sort"
+	"strings"
+)
+
+// ImplicitTeamName is the canonicalized identity of an implicit team: a
+// deduped, sorted list of writer assertions, a deduped, sorted list of
+// reader assertions (nil if there are none), and whether the folder is
+// public. Two TLF names that list the same participants in a different
+// order, or with a different "(conflicted copy ...)" suffix, produce an
+// equal ImplicitTeamName -- which is what lets a resolver keyed on it
+// treat them as the same team instead of accidentally creating (or
+// caching) two.
+type ImplicitTeamName struct {
+	Writers []string
+	Readers []string
+	Public  bool
+}
+
+// ParseImplicitTeamTlfName splits a TLF-style name (e.g.
+// "alice,bob#carol,dave (conflicted copy 2018-01-02 #1)") into its
+// canonicalized writer and reader assertion lists. It doesn't resolve or
+// normalize the individual assertions themselves (e.g. "bob@twitter" is
+// left as-is) -- that happens later, in KBFS/CryptKeys, the same as it
+// always has.
+func ParseImplicitTeamTlfName(tlfName string, public bool) ImplicitTeamName {
+	name := tlfName
+	if idx := strings.IndexByte(name, ' '); idx >= 0 {
+		// Drop " (conflicted copy ...)" suffixes.
+		name = name[:idx]
+	}
+
+	writersPart := name
+	var readersPart string
+	if idx := strings.IndexByte(name, '#'); idx >= 0 {
+		writersPart = name[:idx]
+		readersPart = name[idx+1:]
+	}
+
+	return ImplicitTeamName{
+		Writers: splitAndSortAssertions(writersPart),
+		Readers: splitAndSortAssertions(readersPart),
+		Public:  public,
+	}
+}
+
+func splitAndSortAssertions(s string) []string {
+	var res []string
+	seen := make(map[string]bool)
+	for _, p := range strings.Split(s, ",") {
+		p = strings.TrimSpace(p)
+		if len(p) == 0 || seen[p] {
+			continue
+		}
+		seen[p] = true
+		res = append(res, p)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// String renders n back into TLF-name form ("writer1,writer2#reader1"),
+// the inverse of ParseImplicitTeamTlfName (modulo the ordering and
+// dedup normalization ParseImplicitTeamTlfName already applies).
+func (n ImplicitTeamName) String() string {
+	s := strings.Join(n.Writers, ",")
+	if len(n.Readers) > 0 {
+		s += "#" + strings.Join(n.Readers, ",")
+	}
+	return s
+}
+
+// IsWriter reports whether assertion appears in n's writer list.
+func (n ImplicitTeamName) IsWriter(assertion string) bool {
+	for _, w := range n.Writers {
+		if w == assertion {
+			return true
+		}
+	}
+	return false
+}
+
+// IsReader reports whether assertion appears in n's reader list.
+func (n ImplicitTeamName) IsReader(assertion string) bool {
+	for _, r := range n.Readers {
+		if r == assertion {
+			return true
+		}
+	}
+	return false
+}
+
+// HasMember reports whether assertion is a writer or a reader of n.
+func (n ImplicitTeamName) HasMember(assertion string) bool {
+	return n.IsWriter(assertion) || n.IsReader(assertion)
+}
+
+// RemoveMember removes assertion from whichever of n's writer or reader
+// lists it's in, reporting whether it was found in either.
+func (n *ImplicitTeamName) RemoveMember(assertion string) bool {
+	if idx := indexOf(n.Writers, assertion); idx >= 0 {
+		n.Writers = append(n.Writers[:idx], n.Writers[idx+1:]...)
+		return true
+	}
+	if idx := indexOf(n.Readers, assertion); idx >= 0 {
+		n.Readers = append(n.Readers[:idx], n.Readers[idx+1:]...)
+		return true
+	}
+	return false
+}
+
+func indexOf(list []string, s string) int {
+	for i, v := range list {
+		if v == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// CacheKey returns a string that's equal for two ImplicitTeamNames if and
+// only if they're equal, suitable for use as a cache/lookup key. Writers
+// and readers are kept in separate segments so that the same assertion
+// listed as a writer in one name and a reader in another isn't treated as
+// interchangeable, unlike the plain comma-joined participant lists used
+// elsewhere in this package for display purposes only.
+func (n ImplicitTeamName) CacheKey() string {
+	prefix := "priv"
+	if n.Public {
+		prefix = "pub"
+	}
+	return prefix + ":" + strings.Join(n.Writers, ",") + "#" + strings.Join(n.Readers, ",")
+}
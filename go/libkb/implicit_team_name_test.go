@@ -0,0 +1,41 @@
+// Copyright 2018 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import "testing"
+
+func TestParseImplicitTeamTlfName(t *testing.T) {
+	n := ParseImplicitTeamTlfName("bob,alice#carol,dave (conflicted copy 2018-01-02 #1)", false)
+	if len(n.Writers) != 2 || n.Writers[0] != "alice" || n.Writers[1] != "bob" {
+		t.Fatalf("unexpected writers: %v", n.Writers)
+	}
+	if len(n.Readers) != 2 || n.Readers[0] != "carol" || n.Readers[1] != "dave" {
+		t.Fatalf("unexpected readers: %v", n.Readers)
+	}
+	if n.Public {
+		t.Fatalf("expected Public to be false")
+	}
+}
+
+func TestImplicitTeamNameCacheKeyDistinguishesWritersAndReaders(t *testing.T) {
+	allWriters := ParseImplicitTeamTlfName("alice,bob,carol", false)
+	bobWriterCarolReader := ParseImplicitTeamTlfName("alice,bob#carol", false)
+
+	if allWriters.CacheKey() == bobWriterCarolReader.CacheKey() {
+		t.Fatalf("expected distinct cache keys, got %q for both", allWriters.CacheKey())
+	}
+}
+
+func TestImplicitTeamNameCacheKeyOrderIndependentAndPublicSeparated(t *testing.T) {
+	k1 := ParseImplicitTeamTlfName("bob,alice#dave,carol", false).CacheKey()
+	k2 := ParseImplicitTeamTlfName("alice,bob#carol,dave", false).CacheKey()
+	if k1 != k2 {
+		t.Fatalf("expected key to be order-independent, got %q and %q", k1, k2)
+	}
+
+	pub := ParseImplicitTeamTlfName("alice,bob#carol,dave", true).CacheKey()
+	if pub == k1 {
+		t.Fatalf("expected a public key to differ from a private one with the same participants, got %q for both", k1)
+	}
+}
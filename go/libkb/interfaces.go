@@ -34,6 +34,12 @@ type configGetter interface {
 	GetAutoFork() (bool, bool)
 	GetChatDbFilename() string
 	GetPvlKitFilename() string
+	GetPvlSigningKID() string
+	GetPvlShouldRefreshInterval() (time.Duration, bool)
+	GetPvlRequireRefreshInterval() (time.Duration, bool)
+	GetPvlChannel() string
+	GetDbQuotaBytes() (int, bool)
+	GetChatAttachmentCacheQuotaBytes() (int, bool)
 	GetCodeSigningKIDs() []string
 	GetConfigFilename() string
 	GetDbFilename() string
@@ -54,10 +60,14 @@ type configGetter interface {
 	GetLogFile() string
 	GetLogFormat() string
 	GetMerkleKIDs() []string
+	GetMerkleRefreshUID() string
 	GetMountDir() string
 	GetPidFile() string
 	GetPinentry() string
+	GetDNSOverHTTPSServer() string
 	GetProofCacheSize() (int, bool)
+	GetProofProxyMode() (TorMode, error)
+	GetProofProxy() string
 	GetProxy() string
 	GetRunMode() (RunMode, error)
 	GetScraperTimeout() (time.Duration, bool)
@@ -74,6 +84,10 @@ type configGetter interface {
 	GetUserCacheMaxAge() (time.Duration, bool)
 	GetVDebugSetting() string
 	GetChatDelivererInterval() (time.Duration, bool)
+	GetChatEphemeralPurgeInterval() (time.Duration, bool)
+	GetChatRetentionSweepInterval() (time.Duration, bool)
+	GetIdentify2CacheSuccessTimeout() (time.Duration, bool)
+	GetIdentify2CacheFailureTimeout() (time.Duration, bool)
 	GetFeatureFlags() (FeatureFlags, error)
 }
 
@@ -243,10 +257,12 @@ type API interface {
 	GetResp(APIArg) (*http.Response, error)
 	GetDecode(APIArg, APIResponseWrapper) error
 	Post(APIArg) (*APIRes, error)
+	PostDeferrable(APIArg) (*APIRes, error)
 	PostJSON(APIArg) (*APIRes, error)
 	PostResp(APIArg) (*http.Response, error)
 	PostDecode(APIArg, APIResponseWrapper) error
 	PostRaw(APIArg, string, io.Reader) (*APIRes, error)
+	ConnStats() []ConnPoolStats
 }
 
 type ExternalAPI interface {
@@ -476,6 +492,11 @@ type ProofContext interface {
 	APIContext
 	NetContext
 	GetPvlSource() PvlSource
+	// GetDNSOverHTTPSServer returns the URL of a JSON-format DNS-over-HTTPS
+	// resolver to try before the system resolver for DNS proof lookups (see
+	// GetProofProxyMode for the analogous HTTP proxy setting), or "" to skip
+	// straight to the system resolver.
+	GetDNSOverHTTPSServer() string
 }
 
 type AssertionContext interface {
@@ -531,6 +552,26 @@ type ServiceType interface {
 	IsDevelOnly() bool
 
 	MakeProofChecker(l RemoteProofChainLink) ProofChecker
+
+	// GetAutoPoster returns a non-nil AutoPostAPI if this service type
+	// supports posting a generated proof automatically (as opposed to the
+	// user copy/pasting it by hand), or nil if it doesn't.
+	GetAutoPoster() AutoPostAPI
+}
+
+// AutoPostAPI is a per-service automation descriptor: it lets the Prove
+// engine post a generated proof to the remote service automatically, once
+// the user has authorized it, instead of showing them text to copy/paste.
+// Services that need an OAuth-style authorization step (e.g. GitHub gists,
+// Reddit posts) would drive that authorization elsewhere and hand the
+// resulting credential to their AutoPostAPI implementation; this client
+// doesn't yet implement that authorization flow for any real service, so
+// GetAutoPoster returns nil except where noted on the implementing type.
+type AutoPostAPI interface {
+	// PostProof posts proofText -- the same text a user would otherwise be
+	// instructed to paste manually -- to the remote service on behalf of
+	// remotename.
+	PostProof(ctx ProofContext, remotename string, proofText string) error
 }
 
 type ExternalServicesCollector interface {
@@ -538,8 +579,61 @@ type ExternalServicesCollector interface {
 	ListProofCheckers(mode RunMode) []string
 }
 
+// PvlUpdateListener is called when a PvlSource promotes a new pvl hash
+// into its cache. oldHash may be empty, on the first fetch.
+type PvlUpdateListener func(oldHash, newHash string)
+
+// PvlDiagnostics is a point-in-time snapshot of a PvlSource's state, for
+// support to triage identify failures from `keybase status`/`keybase log
+// send` without asking a user to run anything extra.
+type PvlDiagnostics struct {
+	MerkleRootSeqno     int64
+	MerkleRootFetchTime time.Time
+	MerkleRootHash      string
+	ActiveHash          string
+	// CacheSource is which tier last served a kit: "mem", "db", "server",
+	// or "" if none has yet.
+	CacheSource string
+	// LastFetchError is the error from the most recent failed server
+	// fetch, or "" if the most recent fetch (if any) succeeded.
+	LastFetchError string
+}
+
 type PvlSource interface {
 	GetPVL(ctx context.Context, pvlVersion int) (string, error)
+	// GetPVLInRange is like GetPVL but accepts a version range and
+	// returns the highest version available within it.
+	GetPVLInRange(ctx context.Context, minVersion int, maxVersion int) (string, error)
+	// OnPvlUpdate registers a listener that fires, off of any internal
+	// lock, whenever a new pvl hash is promoted into the source's cache.
+	OnPvlUpdate(f PvlUpdateListener)
+	// IsDegraded reports whether the most recent fetch had to serve a
+	// kit pinned to a stale merkle root because degraded mode allowed it.
+	IsDegraded(ctx context.Context) bool
+	// Invalidate clears any cached kit and immediately refetches, so a
+	// server-side pvl rollout announced out of band takes effect right
+	// away instead of waiting for the usual freshness window.
+	Invalidate(ctx context.Context) error
+	// Diagnostics returns a snapshot of merkle/pvl freshness and cache
+	// state, for `keybase status`/`keybase log send`.
+	Diagnostics(ctx context.Context) PvlDiagnostics
+}
+
+// AvatarLoader loads and caches avatar images for users and teams. It
+// fetches format-specific URLs from the server, downloads and
+// disk-caches the image bytes for each, and hands back local file
+// paths -- so GUIs render avatars without having to know about the
+// server API or cache management themselves.
+type AvatarLoader interface {
+	// LoadUsers fetches avatars for the given usernames in the given
+	// formats, serving cached images where possible.
+	LoadUsers(ctx context.Context, usernames []string, formats []keybase1.AvatarFormat) (keybase1.LoadAvatarsRes, error)
+	// LoadTeams is LoadUsers for team names.
+	LoadTeams(ctx context.Context, teams []string, formats []keybase1.AvatarFormat) (keybase1.LoadAvatarsRes, error)
+	// OnCacheInvalidate drops any cached URLs and images for name (a
+	// username or team name), so the next Load* call re-fetches it.
+	// Called in response to an avatar-changed gregor message.
+	OnCacheInvalidate(ctx context.Context, name string) error
 }
 
 // UserChangedHandler is a generic interface for handling user changed events.
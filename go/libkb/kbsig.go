@@ -559,3 +559,26 @@ func (u *User) UpdateEmailProof(key GenericKey, newEmail string) (*jsonw.Wrapper
 	body.SetKey("update_settings", settings)
 	return ret, nil
 }
+
+// UpdateDeviceNameProof builds a signed statement renaming deviceID to
+// newName, so that other clients replaying u's sigchain pick up the new
+// name the same way they already pick up any other update_settings
+// change.
+func (u *User) UpdateDeviceNameProof(key GenericKey, deviceID keybase1.DeviceID, newName string) (*jsonw.Wrapper, error) {
+	ret, err := ProofMetadata{
+		Me:         u,
+		LinkType:   LinkTypeUpdateSettings,
+		SigningKey: key,
+	}.ToJSON(u.G())
+	if err != nil {
+		return nil, err
+	}
+	body := ret.AtKey("body")
+	device := jsonw.NewDictionary()
+	device.SetKey("id", jsonw.NewString(deviceID.String()))
+	device.SetKey("name", jsonw.NewString(newName))
+	settings := jsonw.NewDictionary()
+	settings.SetKey("device", device)
+	body.SetKey("update_settings", settings)
+	return ret, nil
+}
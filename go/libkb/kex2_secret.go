@@ -4,8 +4,10 @@
 package libkb
 
 import (
+	"fmt"
 	"strings"
 
+	"golang.org/x/crypto/nacl/box"
 	"golang.org/x/crypto/scrypt"
 
 	"github.com/keybase/client/go/kex2"
@@ -43,3 +45,82 @@ func (s *Kex2Secret) Secret() kex2.Secret {
 func (s *Kex2Secret) Phrase() string {
 	return s.phrase
 }
+
+// Kex2QRSessionIDLen is the length, in bytes, of the random rendezvous
+// session token that accompanies an ephemeral DH public key in a kex2
+// QR code.
+const Kex2QRSessionIDLen = 16
+
+// Kex2QRSecret is the QR-code counterpart to Kex2Secret: instead of a
+// scrypt-stretched human-typed phrase, it's built from a fresh NaCl DH
+// keypair, so the low-entropy step of reading and re-typing a phrase on
+// a phone is replaced by scanning a QR code. The device that generates
+// this keeps the private half and publishes SessionID/PublicKey (see
+// QRText); the scanning device replies with its own ephemeral public
+// key over the same rendezvous session ID, and each side calls
+// DeriveSecret with the other's public key to compute the shared kex2
+// session secret.
+type Kex2QRSecret struct {
+	sessionID string
+	keyPair   NaclDHKeyPair
+}
+
+// NewKex2QRSecret generates a fresh ephemeral DH keypair and rendezvous
+// session ID for one side of a QR-code kex2 exchange.
+func NewKex2QRSecret() (*Kex2QRSecret, error) {
+	keyPair, err := GenerateNaclDHKeyPair()
+	if err != nil {
+		return nil, err
+	}
+	sessionID, err := RandString("", Kex2QRSessionIDLen)
+	if err != nil {
+		return nil, err
+	}
+	return &Kex2QRSecret{sessionID: sessionID, keyPair: keyPair}, nil
+}
+
+// QRText renders the public half of this exchange as the short ASCII
+// string that gets encoded into a QR code (see qrcode.Encode) and
+// scanned by the other device.
+func (s *Kex2QRSecret) QRText() string {
+	return fmt.Sprintf("keybase://kex2/%s/%x", s.sessionID, s.keyPair.Public)
+}
+
+// SessionID is the rendezvous session token embedded in this secret's
+// QR code.
+func (s *Kex2QRSecret) SessionID() string {
+	return s.sessionID
+}
+
+// ParseKex2QRText parses the text scanned off the other device's QR
+// code back into a session ID and public key.
+func ParseKex2QRText(s string) (sessionID string, publicKey NaclDHKeyPublic, err error) {
+	const prefix = "keybase://kex2/"
+	if !strings.HasPrefix(s, prefix) {
+		return "", publicKey, fmt.Errorf("not a kex2 QR code")
+	}
+	parts := strings.SplitN(strings.TrimPrefix(s, prefix), "/", 2)
+	if len(parts) != 2 || len(parts[0]) == 0 {
+		return "", publicKey, fmt.Errorf("malformed kex2 QR code")
+	}
+	if _, err := fmt.Sscanf(parts[1], "%x", &publicKey); err != nil {
+		return "", publicKey, fmt.Errorf("malformed kex2 QR public key: %s", err)
+	}
+	return parts[0], publicKey, nil
+}
+
+// DeriveSecret computes the kex2 session secret shared with the device
+// that published theirPublicKey, using this side's ephemeral private
+// key. There's no scrypt stretching here, unlike NewKex2SecretFromPhrase
+// -- the NaCl box shared key is already a uniformly random 32 bytes,
+// not a low-entropy human-typed phrase.
+func (s *Kex2QRSecret) DeriveSecret(theirPublicKey NaclDHKeyPublic) (kex2.Secret, error) {
+	var ret kex2.Secret
+	if s.keyPair.Private == nil {
+		return ret, NoSecretKeyError{}
+	}
+	var shared [32]byte
+	box.Precompute(&shared, (*[32]byte)(&theirPublicKey), (*[32]byte)(s.keyPair.Private))
+	copy(ret[:], shared[:])
+	return ret, nil
+}
@@ -65,6 +65,14 @@ func (a *FakeAPI) Post(APIArg) (*APIRes, error) {
 	return nil, fmt.Errorf("Post is phony")
 }
 
+func (a *FakeAPI) PostDeferrable(APIArg) (*APIRes, error) {
+	return nil, fmt.Errorf("PostDeferrable is phony")
+}
+
+func (a *FakeAPI) ConnStats() []ConnPoolStats {
+	return nil
+}
+
 func (a *FakeAPI) PostJSON(APIArg) (*APIRes, error) {
 	return nil, fmt.Errorf("PostJSON is phony")
 }
@@ -428,6 +428,69 @@ func (s *LoginState) ResetAccount(un string) (err error) {
 	return err
 }
 
+// EnterResetPipeline starts the autoreset countdown for the account
+// identified by usernameOrEmail. Unlike ResetAccount (an immediate,
+// devel-only nuke), this doesn't require an active login session --
+// it's meant for the case where the caller has lost every device and
+// paper key and can no longer authenticate at all.
+func (s *LoginState) EnterResetPipeline(usernameOrEmail string) error {
+	arg := APIArg{
+		Endpoint:    "autoreset/enter",
+		NeedSession: false,
+		Args: HTTPArgs{
+			"username_or_email": S{Val: usernameOrEmail},
+		},
+	}
+	_, err := s.G().API.Post(arg)
+	return err
+}
+
+// CancelReset aborts the calling user's in-progress autoreset
+// countdown, if any.
+func (s *LoginState) CancelReset() (err error) {
+	err = s.loginHandle(func(lctx LoginContext) error {
+		arg := APIArg{
+			Endpoint:    "autoreset/cancel",
+			NeedSession: true,
+			Args:        NewHTTPArgs(),
+			SessionR:    lctx.LocalSession(),
+		}
+		_, aerr := s.G().API.Post(arg)
+		return aerr
+	}, nil, "CancelReset")
+	return err
+}
+
+type autoresetStatusAPIResult struct {
+	Status  AppStatus      `json:"status"`
+	Active  bool           `json:"active"`
+	EndTime *keybase1.Time `json:"end_time"`
+}
+
+func (r *autoresetStatusAPIResult) GetAppStatus() *AppStatus {
+	return &r.Status
+}
+
+// GetResetStatus fetches the calling user's current autoreset
+// countdown state, if any is active.
+func (s *LoginState) GetResetStatus() (res keybase1.AutoresetStatus, err error) {
+	err = s.loginHandle(func(lctx LoginContext) error {
+		arg := APIArg{
+			Endpoint:    "autoreset/status",
+			NeedSession: true,
+			Args:        NewHTTPArgs(),
+			SessionR:    lctx.LocalSession(),
+		}
+		var apiRes autoresetStatusAPIResult
+		if aerr := s.G().API.GetDecode(arg, &apiRes); aerr != nil {
+			return aerr
+		}
+		res = keybase1.AutoresetStatus{Active: apiRes.Active, EndTime: apiRes.EndTime}
+		return nil
+	}, nil, "GetResetStatus")
+	return res, err
+}
+
 func (s *LoginState) postLoginToServer(lctx LoginContext, eOu string, lp PDPKALoginPackage) (*loginAPIResult, error) {
 
 	arg := APIArg{
@@ -279,15 +279,16 @@ type MerkleRootPayloadUnpacked struct {
 			Fingerprint PGPFingerprint `json:"fingerprint"`
 			KeyID       string         `json:"key_id"`
 		} `json:"key"`
-		LegacyUIDRoot NodeHashShort `json:"legacy_uid_root"`
-		Prev          NodeHashLong  `json:"prev"`
-		Root          NodeHashLong  `json:"root"`
-		Seqno         Seqno         `json:"seqno"`
-		Skips         SkipTable     `json:"skips"`
-		Txid          string        `json:"txid"`
-		Type          string        `json:"type"`
-		Version       int           `json:"version"`
-		PvlHash       string        `json:"pvl_hash"`
+		LegacyUIDRoot NodeHashShort     `json:"legacy_uid_root"`
+		Prev          NodeHashLong      `json:"prev"`
+		Root          NodeHashLong      `json:"root"`
+		Seqno         Seqno             `json:"seqno"`
+		Skips         SkipTable         `json:"skips"`
+		Txid          string            `json:"txid"`
+		Type          string            `json:"type"`
+		Version       int               `json:"version"`
+		PvlHash       string            `json:"pvl_hash"`
+		PvlHashes     map[string]string `json:"pvl_hashes,omitempty"`
 	} `json:"body"`
 	Ctime int64  `json:"ctime"`
 	Tag   string `json:"tag"`
@@ -815,6 +816,9 @@ func (mc *MerkleClient) verifyAndStoreRoot(ctx context.Context, root *MerkleRoot
 
 	// First make sure it's not a rollback
 	if seqnoWhenCalled != nil && *seqnoWhenCalled > *root.Seqno() {
+		if mc.G().NotifyRouter != nil {
+			mc.G().NotifyRouter.HandleMerkleRootRolledBack(*seqnoWhenCalled, *root.Seqno())
+		}
 		return fmt.Errorf("Server rolled back Merkle tree: %d > %d", *seqnoWhenCalled, root.Seqno())
 	}
 
@@ -1169,6 +1173,36 @@ func (mc *MerkleClient) LookupUser(ctx context.Context, q HTTPArgs, sigHints *Si
 	return u, nil
 }
 
+// FetchRootFromServer fetches, verifies, and stores just the latest
+// merkle root, with no user lookup involved. Use this when all you want
+// is a fresh, verified root (e.g. to satisfy a staleness check before an
+// identify) rather than borrowing LookupUser against some arbitrary UID
+// as a way to force a root refresh.
+func (mc *MerkleClient) FetchRootFromServer(ctx context.Context) (root *MerkleRoot, err error) {
+	defer mc.G().CTrace(ctx, "MerkleClient#FetchRootFromServer", func() error { return err })()
+
+	if err = mc.init(ctx); err != nil {
+		return nil, err
+	}
+
+	rootBeforeCall := mc.LastRoot()
+
+	path, ss, _, err := mc.lookupPathAndSkipSequence(ctx, NewHTTPArgs(), nil, rootBeforeCall)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = mc.verifySkipSequence(ctx, ss, path.root, rootBeforeCall); err != nil {
+		return nil, err
+	}
+
+	if err = mc.verifyAndStoreRoot(ctx, path.root, rootBeforeCall.Seqno()); err != nil {
+		return nil, err
+	}
+
+	return mc.LastRoot(), nil
+}
+
 func (mr *MerkleRoot) ToSigJSON() (ret *jsonw.Wrapper) {
 
 	ret = jsonw.NewDictionary()
@@ -1272,6 +1306,25 @@ func (mr *MerkleRoot) PvlHash() string {
 	return mr.payload.pvlHash()
 }
 
+// PvlHashForChannel returns the pvl hash published for the named release
+// channel (e.g. "beta"), so a client configured with Env.GetPvlChannel
+// can run newer proof-verification logic while everyone else stays on
+// the default channel. An empty or unrecognized channel, including the
+// zero value "", falls back to the default pvl_hash field, which is
+// what channel-less clients have always used.
+func (mr *MerkleRoot) PvlHashForChannel(channel string) string {
+	if mr == nil {
+		return ""
+	}
+	if channel == "" {
+		return mr.payload.pvlHash()
+	}
+	if hash, ok := mr.payload.pvlHashes()[channel]; ok {
+		return hash
+	}
+	return mr.payload.pvlHash()
+}
+
 func (mr *MerkleRoot) SkipToSeqno(s Seqno) NodeHash {
 	if mr == nil {
 		return nil
@@ -1300,8 +1353,9 @@ func (mrp MerkleRootPayload) skipToSeqno(s Seqno) NodeHash {
 	return mrp.unpacked.Body.Skips[s]
 }
 
-func (mrp MerkleRootPayload) seqno() Seqno                { return mrp.unpacked.Body.Seqno }
-func (mrp MerkleRootPayload) rootHash() NodeHash          { return mrp.unpacked.Body.Root }
-func (mrp MerkleRootPayload) legacyUIDRootHash() NodeHash { return mrp.unpacked.Body.LegacyUIDRoot }
-func (mrp MerkleRootPayload) pvlHash() string             { return mrp.unpacked.Body.PvlHash }
-func (mrp MerkleRootPayload) ctime() int64                { return mrp.unpacked.Ctime }
+func (mrp MerkleRootPayload) seqno() Seqno                 { return mrp.unpacked.Body.Seqno }
+func (mrp MerkleRootPayload) rootHash() NodeHash           { return mrp.unpacked.Body.Root }
+func (mrp MerkleRootPayload) legacyUIDRootHash() NodeHash  { return mrp.unpacked.Body.LegacyUIDRoot }
+func (mrp MerkleRootPayload) pvlHash() string              { return mrp.unpacked.Body.PvlHash }
+func (mrp MerkleRootPayload) pvlHashes() map[string]string { return mrp.unpacked.Body.PvlHashes }
+func (mrp MerkleRootPayload) ctime() int64                 { return mrp.unpacked.Ctime }
@@ -35,6 +35,64 @@ func TestMerkleRootPayloadUnmarshalWithoutSkips(t *testing.T) {
 	}
 }
 
+func TestPvlHashForChannel(t *testing.T) {
+	unpacked := &MerkleRootPayloadUnpacked{}
+	unpacked.Body.PvlHash = "stablehash"
+	unpacked.Body.PvlHashes = map[string]string{"beta": "betahash"}
+	mr := &MerkleRoot{payload: MerkleRootPayload{unpacked: unpacked}}
+
+	if h := mr.PvlHashForChannel(""); h != "stablehash" {
+		t.Fatalf("expected the default channel to use pvl_hash, got: %s", h)
+	}
+	if h := mr.PvlHashForChannel("beta"); h != "betahash" {
+		t.Fatalf("expected the beta channel to use its own hash, got: %s", h)
+	}
+	if h := mr.PvlHashForChannel("nonexistent"); h != "stablehash" {
+		t.Fatalf("expected an unrecognized channel to fall back to pvl_hash, got: %s", h)
+	}
+
+	var nilRoot *MerkleRoot
+	if h := nilRoot.PvlHashForChannel("beta"); h != "" {
+		t.Fatalf("expected a nil root to return empty, got: %s", h)
+	}
+}
+
+func TestVerifyAdjacentAuditedRoots(t *testing.T) {
+	older := MerkleRootPayload{packed: `{"seqno":100}`}
+	older.unpacked = &MerkleRootPayloadUnpacked{}
+	older.unpacked.Body.Seqno = 100
+	olderRoot := &MerkleRoot{payload: older}
+
+	olderHash := older.shortHash()
+	newer := MerkleRootPayload{packed: `{"seqno":200}`}
+	newer.unpacked = &MerkleRootPayloadUnpacked{}
+	newer.unpacked.Body.Seqno = 200
+	newer.unpacked.Body.Skips = SkipTable{100: NodeHashAny{s: &olderHash}}
+	newerRoot := &MerkleRoot{payload: newer}
+
+	if err := verifyAdjacentAuditedRoots(olderRoot, newerRoot); err != nil {
+		t.Fatalf("expected roots to verify, got: %s", err)
+	}
+
+	// Simulate local storage having been tampered with: older's packed json
+	// no longer hashes to what newer's skip table says it should.
+	tampered := older
+	tampered.packed = `{"seqno":100,"tampered":true}`
+	tamperedRoot := &MerkleRoot{payload: tampered}
+	err := verifyAdjacentAuditedRoots(tamperedRoot, newerRoot)
+	if err == nil {
+		t.Fatal("expected a hash mismatch error")
+	}
+	if me, ok := err.(MerkleClientError); !ok || me.t != merkleErrorSkipHashMismatch {
+		t.Fatalf("expected a skip hash mismatch error, got: %v", err)
+	}
+
+	// And out-of-order seqnos should be rejected outright.
+	if err := verifyAdjacentAuditedRoots(newerRoot, olderRoot); err == nil {
+		t.Fatal("expected an out-of-order seqno error")
+	}
+}
+
 func TestMerkleSkipVectors(t *testing.T) {
 	tc := SetupTest(t, "TestMerkleSkipVectors", 1)
 	defer tc.Cleanup()
@@ -0,0 +1,196 @@
+// Copyright 2017 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// MerkleStoreConfig describes one merkle-pinned blob: where its hash
+// lives in the merkle root, where to fetch its bytes from, and how long
+// a root can go stale before a Get should refuse to serve it. A blob
+// published this way (a pvl kit, a parameter file, a display config, ...)
+// gets root-freshness checking, hash verification, and mem/db caching for
+// free from MerkleStore, instead of every publisher reimplementing the
+// same fetch-and-verify plumbing.
+type MerkleStoreConfig struct {
+	// Name identifies this store in logs and errors, e.g. "pvl".
+	Name string
+	// Endpoint is the API endpoint that returns the blob for a given
+	// hash, as an object with a JSONField holding the blob as a string.
+	Endpoint string
+	// JSONField is the field of the endpoint's response that holds the
+	// blob itself, e.g. "kit_json".
+	JSONField string
+	// RootHash extracts this store's pinned hash out of a merkle root.
+	RootHash func(root *MerkleRoot) string
+	// DbKey is where the last-fetched blob is cached on disk.
+	DbKey DbKey
+	// ShouldRefresh is how old the merkle root can get before a Get
+	// tries to refresh it before serving a blob.
+	ShouldRefresh time.Duration
+	// RequireRefresh is how old the merkle root can get before a Get
+	// fails outright rather than serving a blob pinned to a stale root.
+	RequireRefresh time.Duration
+}
+
+// MerkleStore fetches, verifies, and caches a single blob whose hash is
+// pinned in the current merkle root. It has an in-memory cache backed by
+// a local db cache, just like a MerkleClient-backed lookup, but with no
+// user or sigchain involved: the root is the only source of trust.
+type MerkleStore struct {
+	Contextified
+	sync.Mutex
+
+	config MerkleStoreConfig
+	mem    *merkleStoreEntry
+}
+
+type merkleStoreEntry struct {
+	Hash string
+	Blob string
+}
+
+// NewMerkleStore creates a store for the blob described by config.
+func NewMerkleStore(g *GlobalContext, config MerkleStoreConfig) *MerkleStore {
+	return &MerkleStore{
+		Contextified: NewContextified(g),
+		config:       config,
+	}
+}
+
+// Get returns the blob currently pinned in the merkle root, refreshing
+// the root first if it's due, and using the in-memory or on-disk cache
+// when the pinned hash is already available there.
+func (m *MerkleStore) Get(ctx context.Context) (string, error) {
+	ctx = WithLogTag(ctx, "MSTORE")
+
+	mc := m.G().GetMerkleClient()
+	if mc == nil {
+		return "", NewMerkleStoreError(m.config.Name, "no MerkleClient available")
+	}
+
+	root := mc.LastRoot()
+	if root == nil || m.pastDue(root.Fetched(), m.config.ShouldRefresh) {
+		m.G().Log.CDebugf(ctx, "MerkleStore(%s): root should refresh", m.config.Name)
+		if _, err := mc.FetchRootFromServer(ctx); err != nil {
+			m.G().Log.CWarningf(ctx, "MerkleStore(%s): could not refresh merkle root: %s", m.config.Name, err)
+		} else {
+			root = mc.LastRoot()
+		}
+	}
+
+	if root == nil {
+		return "", NewMerkleStoreError(m.config.Name, "no merkle root")
+	}
+	if m.pastDue(root.Fetched(), m.config.RequireRefresh) {
+		return "", NewMerkleStoreError(m.config.Name, "merkle root too old: %s", root.Fetched())
+	}
+
+	hash := m.config.RootHash(root)
+	if hash == "" {
+		return "", NewMerkleStoreError(m.config.Name, "merkle root has no hash for this store")
+	}
+
+	if blob := m.memGet(hash); blob != nil {
+		m.G().Log.CDebugf(ctx, "MerkleStore(%s): mem cache hit", m.config.Name)
+		return *blob, nil
+	}
+	if blob := m.dbGet(ctx, hash); blob != nil {
+		m.G().Log.CDebugf(ctx, "MerkleStore(%s): db cache hit", m.config.Name)
+		m.memSet(hash, *blob)
+		return *blob, nil
+	}
+
+	blob, err := m.fetch(ctx, hash)
+	if err != nil {
+		return "", err
+	}
+	m.memSet(hash, blob)
+	go m.dbSet(context.Background(), hash, blob)
+	return blob, nil
+}
+
+func (m *MerkleStore) fetch(ctx context.Context, hash string) (string, error) {
+	m.G().Log.CDebugf(ctx, "MerkleStore(%s): fetching from server: %s", m.config.Name, hash)
+	res, err := m.G().API.Get(APIArg{
+		Endpoint:    m.config.Endpoint,
+		NeedSession: false,
+		NetContext:  ctx,
+		Args: HTTPArgs{
+			"hash": S{Val: hash},
+		},
+	})
+	if err != nil {
+		return "", NewMerkleStoreError(m.config.Name, err.Error())
+	}
+	blob, err := res.Body.AtKey(m.config.JSONField).GetString()
+	if err != nil || blob == "" {
+		return "", NewMerkleStoreError(m.config.Name, "server returned an empty blob")
+	}
+	if m.hash(blob) != hash {
+		return "", NewMerkleStoreError(m.config.Name, "server returned a blob with the wrong hash")
+	}
+	return blob, nil
+}
+
+func (m *MerkleStore) memGet(hash string) *string {
+	m.Lock()
+	defer m.Unlock()
+	if m.mem != nil && m.mem.Hash == hash {
+		blob := m.mem.Blob
+		return &blob
+	}
+	return nil
+}
+
+func (m *MerkleStore) memSet(hash string, blob string) {
+	m.Lock()
+	defer m.Unlock()
+	m.mem = &merkleStoreEntry{Hash: hash, Blob: blob}
+}
+
+func (m *MerkleStore) dbGet(ctx context.Context, hash string) *string {
+	db := m.G().LocalDb
+	if db == nil {
+		return nil
+	}
+	var ent merkleStoreEntry
+	found, err := db.GetInto(&ent, m.config.DbKey)
+	if err != nil {
+		m.G().Log.CWarningf(ctx, "MerkleStore(%s): error reading from db: %s", m.config.Name, err)
+		return nil
+	}
+	if !found || ent.Hash != hash {
+		return nil
+	}
+	return &ent.Blob
+}
+
+func (m *MerkleStore) dbSet(ctx context.Context, hash string, blob string) {
+	db := m.G().LocalDb
+	if db == nil {
+		m.G().Log.CErrorf(ctx, "MerkleStore(%s): storing blob: no db", m.config.Name)
+		return
+	}
+	ent := merkleStoreEntry{Hash: hash, Blob: blob}
+	if err := db.PutObj(m.config.DbKey, nil, ent); err != nil {
+		m.G().Log.CErrorf(ctx, "MerkleStore(%s): storing blob: %s", m.config.Name, err)
+	}
+}
+
+func (m *MerkleStore) pastDue(event time.Time, limit time.Duration) bool {
+	return m.G().Clock().Now().Sub(event) > limit
+}
+
+// hash is hex-encoded sha512, the same digest MerkleRoot's own hashes use.
+func (m *MerkleStore) hash(in string) string {
+	buf := sha512.Sum512([]byte(in))
+	return hex.EncodeToString(buf[:])
+}
@@ -0,0 +1,92 @@
+// Copyright 2017 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func testMerkleStoreConfig() MerkleStoreConfig {
+	return MerkleStoreConfig{
+		Name:      "test",
+		Endpoint:  "test/store",
+		JSONField: "blob_json",
+		RootHash:  func(root *MerkleRoot) string { return root.PvlHash() },
+		DbKey:     DbKey{Typ: DBPvl, Key: "merkle-store-test"},
+	}
+}
+
+func TestMerkleStoreMemCache(t *testing.T) {
+	tc := SetupTest(t, "merklestore", 1)
+	defer tc.Cleanup()
+
+	m := NewMerkleStore(tc.G, testMerkleStoreConfig())
+	if blob := m.memGet("abc"); blob != nil {
+		t.Fatal("expected no mem cache hit before any set")
+	}
+
+	m.memSet("abc", "the-blob")
+	if blob := m.memGet("abc"); blob == nil || *blob != "the-blob" {
+		t.Fatalf("expected mem cache hit, got: %v", blob)
+	}
+	if blob := m.memGet("def"); blob != nil {
+		t.Fatal("expected no hit for a different hash")
+	}
+}
+
+func TestMerkleStoreDbRoundTrip(t *testing.T) {
+	tc := SetupTest(t, "merklestore", 1)
+	defer tc.Cleanup()
+
+	m := NewMerkleStore(tc.G, testMerkleStoreConfig())
+	ctx := context.Background()
+
+	if blob := m.dbGet(ctx, "abc"); blob != nil {
+		t.Fatal("expected no db hit before any set")
+	}
+
+	m.dbSet(ctx, "abc", "the-blob")
+	if blob := m.dbGet(ctx, "abc"); blob == nil || *blob != "the-blob" {
+		t.Fatalf("expected db hit, got: %v", blob)
+	}
+	if blob := m.dbGet(ctx, "def"); blob != nil {
+		t.Fatal("expected no hit for a different hash")
+	}
+}
+
+func TestMerkleStoreGetWithoutMerkleClient(t *testing.T) {
+	tc := SetupTest(t, "merklestore", 1)
+	defer tc.Cleanup()
+
+	tc.G.MerkleClient = nil
+	m := NewMerkleStore(tc.G, testMerkleStoreConfig())
+	if _, err := m.Get(context.Background()); err == nil {
+		t.Fatal("expected an error with no MerkleClient available")
+	}
+}
+
+func TestMerkleStoreGetWithoutRoot(t *testing.T) {
+	tc := SetupTest(t, "merklestore", 1)
+	defer tc.Cleanup()
+
+	m := NewMerkleStore(tc.G, testMerkleStoreConfig())
+	if _, err := m.Get(context.Background()); err == nil {
+		t.Fatal("expected an error with no merkle root and no server to refresh from")
+	}
+}
+
+func TestMerkleStoreHash(t *testing.T) {
+	tc := SetupTest(t, "merklestore", 1)
+	defer tc.Cleanup()
+
+	m := NewMerkleStore(tc.G, testMerkleStoreConfig())
+	if m.hash("x") != m.hash("x") {
+		t.Fatal("hash should be deterministic")
+	}
+	if m.hash("x") == m.hash("y") {
+		t.Fatal("different content should hash differently")
+	}
+}
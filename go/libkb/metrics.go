@@ -0,0 +1,101 @@
+// Copyright 2017 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// LatencyStats is a running min/max/avg summary of a named operation's
+// durations, kept cheap enough to update on every call rather than
+// sampling.
+type LatencyStats struct {
+	Count int64         `json:"count"`
+	Min   time.Duration `json:"min"`
+	Max   time.Duration `json:"max"`
+	Sum   time.Duration `json:"sum"`
+	Avg   time.Duration `json:"avg"`
+}
+
+// MetricsRegistry is a process-wide bag of named counters and latency
+// stats, for lightweight operator-facing instrumentation (cache hit
+// rates, fetch latencies, error counts) that isn't worth a full metrics
+// backend. It's dumped by the `getPvlMetrics` RPC and `keybase log
+// metrics`.
+type MetricsRegistry struct {
+	sync.Mutex
+	counters  map[string]int64
+	latencies map[string]*LatencyStats
+}
+
+// NewMetricsRegistry returns an empty registry.
+func NewMetricsRegistry() *MetricsRegistry {
+	return &MetricsRegistry{
+		counters:  make(map[string]int64),
+		latencies: make(map[string]*LatencyStats),
+	}
+}
+
+// IncrCounter adds 1 to the named counter, creating it if necessary.
+func (m *MetricsRegistry) IncrCounter(name string) {
+	m.Lock()
+	defer m.Unlock()
+	m.counters[name]++
+}
+
+// RecordLatency folds d into the named latency stats.
+func (m *MetricsRegistry) RecordLatency(name string, d time.Duration) {
+	m.Lock()
+	defer m.Unlock()
+	s, ok := m.latencies[name]
+	if !ok {
+		s = &LatencyStats{Min: d, Max: d}
+		m.latencies[name] = s
+	}
+	s.Count++
+	s.Sum += d
+	if d < s.Min {
+		s.Min = d
+	}
+	if d > s.Max {
+		s.Max = d
+	}
+	s.Avg = s.Sum / time.Duration(s.Count)
+}
+
+// MetricsSnapshot is a point-in-time copy of a MetricsRegistry's
+// contents, safe to marshal or hand to a caller outside the lock.
+type MetricsSnapshot struct {
+	Counters  map[string]int64        `json:"counters"`
+	Latencies map[string]LatencyStats `json:"latencies"`
+}
+
+// Snapshot returns a copy of the registry's current state.
+func (m *MetricsRegistry) Snapshot() MetricsSnapshot {
+	m.Lock()
+	defer m.Unlock()
+	snap := MetricsSnapshot{
+		Counters:  make(map[string]int64, len(m.counters)),
+		Latencies: make(map[string]LatencyStats, len(m.latencies)),
+	}
+	for k, v := range m.counters {
+		snap.Counters[k] = v
+	}
+	for k, v := range m.latencies {
+		snap.Latencies[k] = *v
+	}
+	return snap
+}
+
+// JSON renders a snapshot as an indented JSON blob, for RPC and CLI
+// consumers that just want something readable to display.
+func (s MetricsSnapshot) JSON() (string, error) {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2017 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMetricsRegistryCounters(t *testing.T) {
+	m := NewMetricsRegistry()
+	snap := m.Snapshot()
+	if len(snap.Counters) != 0 {
+		t.Fatalf("expected no counters yet, got: %+v", snap.Counters)
+	}
+
+	m.IncrCounter("foo")
+	m.IncrCounter("foo")
+	m.IncrCounter("bar")
+
+	snap = m.Snapshot()
+	if snap.Counters["foo"] != 2 || snap.Counters["bar"] != 1 {
+		t.Fatalf("unexpected counters: %+v", snap.Counters)
+	}
+}
+
+func TestMetricsRegistryLatencies(t *testing.T) {
+	m := NewMetricsRegistry()
+	m.RecordLatency("op", 10*time.Millisecond)
+	m.RecordLatency("op", 30*time.Millisecond)
+
+	snap := m.Snapshot()
+	stats, ok := snap.Latencies["op"]
+	if !ok {
+		t.Fatal("expected latency stats for op")
+	}
+	if stats.Count != 2 {
+		t.Fatalf("expected count 2, got %d", stats.Count)
+	}
+	if stats.Min != 10*time.Millisecond || stats.Max != 30*time.Millisecond {
+		t.Fatalf("unexpected min/max: %+v", stats)
+	}
+	if stats.Avg != 20*time.Millisecond {
+		t.Fatalf("expected avg of 20ms, got %s", stats.Avg)
+	}
+}
+
+func TestMetricsSnapshotJSON(t *testing.T) {
+	m := NewMetricsRegistry()
+	m.IncrCounter("foo")
+	m.RecordLatency("op", time.Millisecond)
+
+	j, err := m.Snapshot().JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(j) == 0 {
+		t.Fatal("expected non-empty JSON")
+	}
+}
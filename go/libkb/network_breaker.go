@@ -0,0 +1,209 @@
+// Copyright 2018 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// NetworkBreakerConfig configures a NetworkBreaker. The zero value of
+// each field disables that half of the breaker (no rate limiting, or a
+// breaker that never trips), so a caller can opt into just the piece it
+// needs.
+type NetworkBreakerConfig struct {
+	// MaxConsecutiveFailures is how many Failure calls in a row trip the
+	// breaker open. 0 means the breaker never trips.
+	MaxConsecutiveFailures int
+	// Cooldown is how long an open breaker refuses calls before letting a
+	// single probe back through (half-open).
+	Cooldown time.Duration
+	// MinInterval is the minimum spacing Allow enforces between calls,
+	// regardless of breaker state, so a caller in a tight retry loop
+	// can't hammer a flapping endpoint even before it fails enough to
+	// trip the breaker. 0 means no rate limiting.
+	MinInterval time.Duration
+}
+
+// NetworkBreakerState is a point-in-time, marshalable snapshot of a
+// NetworkBreaker, safe to hand to a caller outside the breaker's lock.
+type NetworkBreakerState struct {
+	Name                string    `json:"name"`
+	Open                bool      `json:"open"`
+	ConsecutiveFailures int       `json:"consecutiveFailures"`
+	TotalFailures       int64     `json:"totalFailures"`
+	TotalSuccesses      int64     `json:"totalSuccesses"`
+	TotalRateLimited    int64     `json:"totalRateLimited"`
+	OpenedAt            time.Time `json:"openedAt,omitempty"`
+}
+
+// NetworkBreaker is a combined rate limiter and circuit breaker for a
+// single named network endpoint (or class of endpoint), meant to be
+// shared across every caller that hits that endpoint so a misbehaving
+// server or flapping network produces one clean failure instead of a
+// retry storm. Get an instance from a GlobalContext's NetworkBreakers
+// registry rather than constructing one directly, so all callers of the
+// same endpoint share state.
+//
+// Typical use:
+//
+//	if err := breaker.Allow(); err != nil {
+//	    return err
+//	}
+//	res, err := doTheCall()
+//	if err != nil {
+//	    breaker.Failure()
+//	    return err
+//	}
+//	breaker.Success()
+type NetworkBreaker struct {
+	sync.Mutex
+	name   string
+	config NetworkBreakerConfig
+	clock  Clock
+
+	open                bool
+	openedAt            time.Time
+	consecutiveFailures int
+	totalFailures       int64
+	totalSuccesses      int64
+	totalRateLimited    int64
+	lastAttempt         time.Time
+}
+
+// newNetworkBreaker constructs a NetworkBreaker against the given clock.
+// Unexported since callers should go through a GlobalContext's
+// NetworkBreakers registry instead, to ensure every caller of the same
+// named endpoint shares one breaker.
+func newNetworkBreaker(name string, config NetworkBreakerConfig, clock Clock) *NetworkBreaker {
+	return &NetworkBreaker{
+		name:   name,
+		config: config,
+		clock:  clock,
+	}
+}
+
+// Allow reports whether a call should proceed. It returns
+// NetworkBreakerOpenError if the breaker is open and still within its
+// cooldown, or NetworkBreakerRateLimitedError if the call is arriving
+// sooner than MinInterval after the last one. A successful Allow counts
+// as an attempt for rate-limiting purposes whether or not the caller goes
+// on to record Success or Failure.
+func (b *NetworkBreaker) Allow() error {
+	b.Lock()
+	defer b.Unlock()
+
+	now := b.clock.Now()
+
+	if b.open {
+		if b.config.Cooldown > 0 && now.Sub(b.openedAt) < b.config.Cooldown {
+			return NetworkBreakerOpenError{Name: b.name}
+		}
+		// Cooldown elapsed: let this one probe through half-open. It'll
+		// close the breaker on Success or re-open it (resetting the
+		// cooldown) on Failure.
+	}
+
+	if b.config.MinInterval > 0 && !b.lastAttempt.IsZero() && now.Sub(b.lastAttempt) < b.config.MinInterval {
+		b.totalRateLimited++
+		return NetworkBreakerRateLimitedError{Name: b.name}
+	}
+
+	b.lastAttempt = now
+	return nil
+}
+
+// Success records a successful call, closing the breaker and resetting
+// its consecutive-failure count.
+func (b *NetworkBreaker) Success() {
+	b.Lock()
+	defer b.Unlock()
+	b.open = false
+	b.consecutiveFailures = 0
+	b.totalSuccesses++
+}
+
+// Failure records a failed call, tripping the breaker open if
+// MaxConsecutiveFailures is configured and has now been reached.
+func (b *NetworkBreaker) Failure() {
+	b.Lock()
+	defer b.Unlock()
+	b.consecutiveFailures++
+	b.totalFailures++
+	if b.config.MaxConsecutiveFailures > 0 && b.consecutiveFailures >= b.config.MaxConsecutiveFailures {
+		b.open = true
+		b.openedAt = b.clock.Now()
+	}
+}
+
+// State returns a snapshot of the breaker's current counters.
+func (b *NetworkBreaker) State() NetworkBreakerState {
+	b.Lock()
+	defer b.Unlock()
+	return NetworkBreakerState{
+		Name:                b.name,
+		Open:                b.open,
+		ConsecutiveFailures: b.consecutiveFailures,
+		TotalFailures:       b.totalFailures,
+		TotalSuccesses:      b.totalSuccesses,
+		TotalRateLimited:    b.totalRateLimited,
+		OpenedAt:            b.openedAt,
+	}
+}
+
+// NetworkBreakerRegistry is a process-wide, name-keyed set of
+// NetworkBreakers, so every caller hitting the same logical endpoint (by
+// name) shares one breaker rather than each keeping its own private
+// failure count. Modeled on MetricsRegistry.
+type NetworkBreakerRegistry struct {
+	sync.Mutex
+	clock    Clock
+	breakers map[string]*NetworkBreaker
+}
+
+// NewNetworkBreakerRegistry returns an empty registry that times breakers
+// against clock.
+func NewNetworkBreakerRegistry(clock Clock) *NetworkBreakerRegistry {
+	return &NetworkBreakerRegistry{
+		clock:    clock,
+		breakers: make(map[string]*NetworkBreaker),
+	}
+}
+
+// Get returns the named breaker, creating it with config if this is the
+// first call for that name. Later calls for the same name return the
+// existing breaker regardless of the config passed, since the point is
+// for every caller of an endpoint to share one breaker's state.
+func (r *NetworkBreakerRegistry) Get(name string, config NetworkBreakerConfig) *NetworkBreaker {
+	r.Lock()
+	defer r.Unlock()
+	b, ok := r.breakers[name]
+	if !ok {
+		b = newNetworkBreaker(name, config, r.clock)
+		r.breakers[name] = b
+	}
+	return b
+}
+
+// Snapshot returns the current state of every breaker that's been
+// created so far, sorted by name for stable display.
+func (r *NetworkBreakerRegistry) Snapshot() []NetworkBreakerState {
+	r.Lock()
+	names := make([]string, 0, len(r.breakers))
+	breakers := make(map[string]*NetworkBreaker, len(r.breakers))
+	for name, b := range r.breakers {
+		names = append(names, name)
+		breakers[name] = b
+	}
+	r.Unlock()
+
+	sort.Strings(names)
+
+	states := make([]NetworkBreakerState, 0, len(names))
+	for _, name := range names {
+		states = append(states, breakers[name].State())
+	}
+	return states
+}
@@ -0,0 +1,100 @@
+// Copyright 2018 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeBreakerClock is a minimal, manually-advanced Clock for testing
+// NetworkBreaker without sleeping.
+type fakeBreakerClock struct {
+	now time.Time
+}
+
+func (c *fakeBreakerClock) Now() time.Time { return c.now }
+
+func TestNetworkBreakerTripsAfterMaxConsecutiveFailures(t *testing.T) {
+	clock := &fakeBreakerClock{now: time.Now()}
+	b := newNetworkBreaker("test", NetworkBreakerConfig{MaxConsecutiveFailures: 2, Cooldown: time.Minute}, clock)
+
+	if err := b.Allow(); err != nil {
+		t.Fatalf("unexpected error before any failures: %s", err)
+	}
+	b.Failure()
+	if state := b.State(); state.Open {
+		t.Fatal("breaker should not be open after only 1 failure")
+	}
+
+	b.Failure()
+	state := b.State()
+	if !state.Open {
+		t.Fatal("expected breaker to be open after 2 consecutive failures")
+	}
+	if state.ConsecutiveFailures != 2 || state.TotalFailures != 2 {
+		t.Fatalf("unexpected failure counts: %+v", state)
+	}
+
+	if _, ok := b.Allow().(NetworkBreakerOpenError); !ok {
+		t.Fatal("expected NetworkBreakerOpenError while breaker is open and within cooldown")
+	}
+}
+
+func TestNetworkBreakerHalfOpensAfterCooldown(t *testing.T) {
+	clock := &fakeBreakerClock{now: time.Now()}
+	b := newNetworkBreaker("test", NetworkBreakerConfig{MaxConsecutiveFailures: 1, Cooldown: time.Minute}, clock)
+
+	b.Failure()
+	if !b.State().Open {
+		t.Fatal("expected breaker to trip after 1 failure")
+	}
+
+	clock.now = clock.now.Add(2 * time.Minute)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("expected a probe to be allowed through after cooldown, got: %s", err)
+	}
+
+	b.Success()
+	if b.State().Open {
+		t.Fatal("expected a successful probe to close the breaker")
+	}
+}
+
+func TestNetworkBreakerRateLimitsMinInterval(t *testing.T) {
+	clock := &fakeBreakerClock{now: time.Now()}
+	b := newNetworkBreaker("test", NetworkBreakerConfig{MinInterval: time.Second}, clock)
+
+	if err := b.Allow(); err != nil {
+		t.Fatalf("unexpected error on first call: %s", err)
+	}
+	if _, ok := b.Allow().(NetworkBreakerRateLimitedError); !ok {
+		t.Fatal("expected NetworkBreakerRateLimitedError for a call inside MinInterval")
+	}
+
+	clock.now = clock.now.Add(2 * time.Second)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("expected call to be allowed once MinInterval has elapsed, got: %s", err)
+	}
+}
+
+func TestNetworkBreakerRegistrySharesBreakerByName(t *testing.T) {
+	r := NewNetworkBreakerRegistry(&fakeBreakerClock{now: time.Now()})
+
+	b1 := r.Get("foo", NetworkBreakerConfig{MaxConsecutiveFailures: 1})
+	b2 := r.Get("foo", NetworkBreakerConfig{MaxConsecutiveFailures: 100})
+	if b1 != b2 {
+		t.Fatal("expected two Gets of the same name to return the same breaker")
+	}
+
+	b1.Failure()
+	if !b2.State().Open {
+		t.Fatal("expected the shared breaker to reflect the failure recorded via the other handle")
+	}
+
+	states := r.Snapshot()
+	if len(states) != 1 || states[0].Name != "foo" {
+		t.Fatalf("unexpected snapshot: %+v", states)
+	}
+}
@@ -42,12 +42,15 @@ type NotifyListener interface {
 	KeyfamilyChanged(uid keybase1.UID)
 	NewChatActivity(uid keybase1.UID, activity chat1.ChatActivity)
 	ChatIdentifyUpdate(update keybase1.CanonicalTLFNameAndIDWithBreaks)
+	ChatTLFIdentifyProgress(progress chat1.TLFIdentifyProgress)
 	ChatTLFFinalize(uid keybase1.UID, convID chat1.ConversationID,
 		finalizeInfo chat1.ConversationFinalizeInfo)
 	ChatTLFResolve(uid keybase1.UID, convID chat1.ConversationID,
 		resolveInfo chat1.ConversationResolveInfo)
 	ChatInboxStale(uid keybase1.UID)
 	ChatThreadsStale(uid keybase1.UID, cids []chat1.ConversationID)
+	ChatTypingUpdate(typingUpdates []chat1.ConvTypingUpdate)
+	ChatAttentionUpdate(uid keybase1.UID, convID chat1.ConversationID, msgID chat1.MessageID)
 	PGPKeyInSecretStoreFile()
 	BadgeState(badgeState keybase1.BadgeState)
 	ReachabilityChanged(r keybase1.Reachability)
@@ -61,6 +64,7 @@ type NotifyRouter struct {
 	state      map[ConnectionID]keybase1.NotificationChannels
 	setCh      chan setObj
 	getCh      chan getObj
+	removeCh   chan ConnectionID
 	shutdownCh chan struct{}
 	listener   NotifyListener
 }
@@ -74,6 +78,7 @@ func NewNotifyRouter(g *GlobalContext) *NotifyRouter {
 		state:        make(map[ConnectionID]keybase1.NotificationChannels),
 		setCh:        make(chan setObj),
 		getCh:        make(chan getObj),
+		removeCh:     make(chan ConnectionID),
 		shutdownCh:   make(chan struct{}),
 	}
 	go ret.run()
@@ -107,6 +112,8 @@ func (n *NotifyRouter) run() {
 			n.state[o.id] = o.val
 		case o := <-n.getCh:
 			o.retCh <- n.state[o.id]
+		case id := <-n.removeCh:
+			delete(n.state, id)
 		}
 	}
 }
@@ -129,6 +136,19 @@ func (n *NotifyRouter) SetChannels(i ConnectionID, nc keybase1.NotificationChann
 	n.setNotificationChannels(i, nc)
 }
 
+// RemoveConnection forgets the notification channel subscription for the
+// connection with the given connection ID. It should be called whenever a
+// connection is torn down, so that state doesn't accumulate forever for
+// connection IDs (which, unlike the connections themselves, are never
+// reused) over the life of a long-running service, e.g. one reconnected to
+// repeatedly by a mobile client.
+func (n *NotifyRouter) RemoveConnection(i ConnectionID) {
+	if n == nil {
+		return
+	}
+	n.removeCh <- i
+}
+
 // HandleLogout is called whenever the current user logged out. It will broadcast
 // the message to all connections who care about such a mesasge.
 func (n *NotifyRouter) HandleLogout() {
@@ -530,6 +550,35 @@ func (n *NotifyRouter) HandleChatIdentifyUpdate(ctx context.Context, update keyb
 	n.G().Log.Debug("- Sent ChatIdentifyUpdate notification")
 }
 
+// HandleChatTLFIdentifyProgress reports that a single participant of
+// progress.TlfName has finished identifying, so a GUI resolving a TLF
+// with many participants can show progress instead of going silent until
+// CryptKeys returns with everyone's results at once.
+func (n *NotifyRouter) HandleChatTLFIdentifyProgress(ctx context.Context, progress chat1.TLFIdentifyProgress) {
+	if n == nil {
+		return
+	}
+	var wg sync.WaitGroup
+	n.G().Log.Debug("+ Sending ChatTLFIdentifyProgress notification")
+	n.cm.ApplyAll(func(id ConnectionID, xp rpc.Transporter) bool {
+		if n.getNotificationChannels(id).Chat {
+			wg.Add(1)
+			go func() {
+				(chat1.NotifyChatClient{
+					Cli: rpc.NewClient(xp, ErrorUnwrapper{}),
+				}).ChatTLFIdentifyProgress(context.Background(), progress)
+				wg.Done()
+			}()
+		}
+		return true
+	})
+	wg.Wait()
+	if n.listener != nil {
+		n.listener.ChatTLFIdentifyProgress(progress)
+	}
+	n.G().Log.Debug("- Sent ChatTLFIdentifyProgress notification")
+}
+
 func (n *NotifyRouter) HandleChatTLFFinalize(ctx context.Context, uid keybase1.UID, convID chat1.ConversationID, finalizeInfo chat1.ConversationFinalizeInfo, conv *chat1.ConversationLocal) {
 	if n == nil {
 		return
@@ -643,6 +692,62 @@ func (n *NotifyRouter) HandleChatThreadsStale(ctx context.Context, uid keybase1.
 	n.G().Log.Debug("- Sent ChatThreadsStale notification")
 }
 
+func (n *NotifyRouter) HandleChatTypingUpdate(ctx context.Context, typingUpdates []chat1.ConvTypingUpdate) {
+	if n == nil {
+		return
+	}
+	var wg sync.WaitGroup
+	n.G().Log.Debug("+ Sending ChatTypingUpdate notification")
+	n.cm.ApplyAll(func(id ConnectionID, xp rpc.Transporter) bool {
+		if n.getNotificationChannels(id).Chat {
+			wg.Add(1)
+			go func() {
+				(chat1.NotifyChatClient{
+					Cli: rpc.NewClient(xp, ErrorUnwrapper{}),
+				}).ChatTypingUpdate(context.Background(), typingUpdates)
+				wg.Done()
+			}()
+		}
+		return true
+	})
+	wg.Wait()
+	if n.listener != nil {
+		n.listener.ChatTypingUpdate(typingUpdates)
+	}
+	n.G().Log.Debug("- Sent ChatTypingUpdate notification")
+}
+
+// HandleChatAttentionUpdate notifies connections that uid was @-mentioned in
+// convID by msgID.
+func (n *NotifyRouter) HandleChatAttentionUpdate(ctx context.Context, uid keybase1.UID, convID chat1.ConversationID, msgID chat1.MessageID) {
+	if n == nil {
+		return
+	}
+	var wg sync.WaitGroup
+	n.G().Log.Debug("+ Sending ChatAttentionUpdate notification")
+	n.cm.ApplyAll(func(id ConnectionID, xp rpc.Transporter) bool {
+		if n.getNotificationChannels(id).Chat {
+			wg.Add(1)
+			go func() {
+				(chat1.NotifyChatClient{
+					Cli: rpc.NewClient(xp, ErrorUnwrapper{}),
+				}).ChatAttentionUpdate(context.Background(), chat1.ChatAttentionUpdateArg{
+					Uid:    uid,
+					ConvID: convID,
+					MsgID:  msgID,
+				})
+				wg.Done()
+			}()
+		}
+		return true
+	})
+	wg.Wait()
+	if n.listener != nil {
+		n.listener.ChatAttentionUpdate(uid, convID, msgID)
+	}
+	n.G().Log.Debug("- Sent ChatAttentionUpdate notification")
+}
+
 // HandlePaperKeyCached is called whenever a paper key is cached
 // in response to a rekey harassment.
 func (n *NotifyRouter) HandlePaperKeyCached(uid keybase1.UID, encKID keybase1.KID, sigKID keybase1.KID) {
@@ -748,6 +853,65 @@ func (n *NotifyRouter) HandleServiceShutdown() {
 	n.G().Log.Debug("- Sent service shutdown notification")
 }
 
+// HandleMerkleRootRolledBack is called whenever the MerkleClient notices the
+// server present a root with a seqno lower than one it has already seen,
+// which can indicate a compromised or misbehaving server.
+func (n *NotifyRouter) HandleMerkleRootRolledBack(rolledBackFrom, rolledBackTo Seqno) {
+	if n == nil {
+		return
+	}
+
+	n.G().Log.Debug("+ Sending merkle root rolled back notification (%d -> %d)", rolledBackFrom, rolledBackTo)
+
+	// For all connections we currently have open...
+	n.cm.ApplyAll(func(id ConnectionID, xp rpc.Transporter) bool {
+		// If the connection wants the `Service` notification type
+		if n.getNotificationChannels(id).Service {
+			// In the background do...
+			go func() {
+				(keybase1.NotifyServiceClient{
+					Cli: rpc.NewClient(xp, ErrorUnwrapper{}),
+				}).MerkleRootRolledBack(context.Background(), keybase1.MerkleRootRolledBackArg{
+					RolledBackFrom: int(rolledBackFrom),
+					RolledBackTo:   int(rolledBackTo),
+				})
+			}()
+		}
+		return true
+	})
+
+	n.G().Log.Debug("- Sent merkle root rolled back notification")
+}
+
+// HandleMerkleAuditError is called whenever a merkle root audit (see
+// MerkleClient#AuditRange) finds that two cached roots don't chain
+// together the way their skip pointers claim they should.
+func (n *NotifyRouter) HandleMerkleAuditError(problems []string) {
+	if n == nil {
+		return
+	}
+
+	n.G().Log.Debug("+ Sending merkle audit error notification (%d problems)", len(problems))
+
+	// For all connections we currently have open...
+	n.cm.ApplyAll(func(id ConnectionID, xp rpc.Transporter) bool {
+		// If the connection wants the `Service` notification type
+		if n.getNotificationChannels(id).Service {
+			// In the background do...
+			go func() {
+				(keybase1.NotifyServiceClient{
+					Cli: rpc.NewClient(xp, ErrorUnwrapper{}),
+				}).MerkleAuditError(context.Background(), keybase1.MerkleAuditErrorArg{
+					Problems: problems,
+				})
+			}()
+		}
+		return true
+	})
+
+	n.G().Log.Debug("- Sent merkle audit error notification")
+}
+
 // HandleAppExit is called whenever an app exit command is issued
 func (n *NotifyRouter) HandleAppExit() {
 	if n == nil {
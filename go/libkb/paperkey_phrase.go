@@ -18,7 +18,15 @@ type PaperKeyPhrase string
 // MakePaperKeyPhrase creates a new, random paper key phrase for
 // the given version.
 func MakePaperKeyPhrase(version uint8) (PaperKeyPhrase, error) {
-	nbits := PaperKeySecretEntropy + PaperKeyIDBits + PaperKeyVersionBits
+	return MakePaperKeyPhraseWithEntropy(version, PaperKeySecretEntropy)
+}
+
+// MakePaperKeyPhraseWithEntropy is like MakePaperKeyPhrase, but lets
+// the caller ask for more secret entropy bits than the default,
+// producing a longer, harder-to-guess phrase (see
+// PaperKeySecretEntropyStrong).
+func MakePaperKeyPhraseWithEntropy(version uint8, secretEntropyBits int) (PaperKeyPhrase, error) {
+	nbits := secretEntropyBits + PaperKeyIDBits + PaperKeyVersionBits
 	for i := 0; i < 1000; i++ {
 		words, err := SecWordList(nbits)
 		if err != nil {
@@ -4,6 +4,7 @@
 package libkb
 
 import (
+	"math/rand"
 	"sync"
 	"time"
 
@@ -14,8 +15,9 @@ import (
 
 type CheckResult struct {
 	Contextified
-	Status ProofError // Or nil if it was a success
-	Time   time.Time  // When the last check was
+	Status    ProofError         // Or nil if it was a success
+	Time      time.Time          // When the last check was
+	ProofType keybase1.ProofType // Which service this proof is for, for per-service TTLs
 }
 
 func (cr CheckResult) Pack() *jsonw.Wrapper {
@@ -27,18 +29,45 @@ func (cr CheckResult) Pack() *jsonw.Wrapper {
 		p.SetKey("status", s)
 	}
 	p.SetKey("time", jsonw.NewInt64(cr.Time.Unix()))
+	p.SetKey("proof_type", jsonw.NewInt(int(cr.ProofType)))
 	return p
 }
 
+// proofCacheTTLScale scales the base proof-cache durations per proof type.
+// Some services (like DNS TXT records) can be edited by the prover in
+// seconds, so a cached success shouldn't be trusted nearly as long as one for
+// a service like GitHub, where changing the underlying content is slower and
+// more visible.
+func proofCacheTTLScale(t keybase1.ProofType) float64 {
+	switch t {
+	case keybase1.ProofType_DNS:
+		return 0.25
+	case keybase1.ProofType_GENERIC_WEB_SITE:
+		return 0.5
+	default:
+		return 1.0
+	}
+}
+
+// proofCacheJitter adds up to +/-10% noise to a cache duration, so that a
+// batch of proofs cached around the same time (e.g. from one identify) don't
+// all go stale in the same instant and trigger a thundering herd of rechecks
+// the next time they're all looked up together.
+func proofCacheJitter(d time.Duration) time.Duration {
+	jitter := 0.9 + 0.2*rand.Float64()
+	return time.Duration(float64(d) * jitter)
+}
+
 func (cr CheckResult) Freshness() keybase1.CheckResultFreshness {
 	now := cr.G().Clock().Now()
 	age := now.Sub(cr.Time)
+	scale := proofCacheTTLScale(cr.ProofType)
 	switch {
 	case cr.Status == nil:
 		switch {
-		case age < cr.G().Env.GetProofCacheMediumDur():
+		case age < proofCacheJitter(time.Duration(float64(cr.G().Env.GetProofCacheMediumDur())*scale)):
 			return keybase1.CheckResultFreshness_FRESH
-		case age < cr.G().Env.GetProofCacheLongDur():
+		case age < proofCacheJitter(time.Duration(float64(cr.G().Env.GetProofCacheLongDur())*scale)):
 			return keybase1.CheckResultFreshness_AGED
 		}
 	case ProofErrorIsPvlBad(cr.Status):
@@ -46,7 +75,7 @@ func (cr CheckResult) Freshness() keybase1.CheckResultFreshness {
 		// The hope is that they will soon be resolved server-side.
 		return keybase1.CheckResultFreshness_RANCID
 	case !ProofErrorIsSoft(cr.Status):
-		if age < cr.G().Env.GetProofCacheShortDur() {
+		if age < proofCacheJitter(time.Duration(float64(cr.G().Env.GetProofCacheShortDur())*scale)) {
 			return keybase1.CheckResultFreshness_FRESH
 		}
 	default:
@@ -56,11 +85,12 @@ func (cr CheckResult) Freshness() keybase1.CheckResultFreshness {
 	return keybase1.CheckResultFreshness_RANCID
 }
 
-func NewNowCheckResult(g *GlobalContext, pe ProofError) *CheckResult {
+func NewNowCheckResult(g *GlobalContext, pt keybase1.ProofType, pe ProofError) *CheckResult {
 	return &CheckResult{
 		Contextified: NewContextified(g),
 		Status:       pe,
 		Time:         g.Clock().Now(),
+		ProofType:    pt,
 	}
 }
 
@@ -68,8 +98,10 @@ func NewCheckResult(g *GlobalContext, jw *jsonw.Wrapper) (res *CheckResult, err
 	var t int64
 	var code int
 	var desc string
+	var pt int
 
 	jw.AtKey("time").GetInt64Void(&t, &err)
+	jw.AtKey("proof_type").GetIntVoid(&pt, &err)
 	status := jw.AtKey("status")
 	var pe ProofError
 
@@ -83,6 +115,7 @@ func NewCheckResult(g *GlobalContext, jw *jsonw.Wrapper) (res *CheckResult, err
 			Contextified: NewContextified(g),
 			Status:       pe,
 			Time:         time.Unix(t, 0),
+			ProofType:    keybase1.ProofType(pt),
 		}
 	}
 	return
@@ -233,7 +266,7 @@ func (pc *ProofCache) dbPut(sid keybase1.SigID, cr CheckResult) error {
 	return pc.G().LocalDb.Put(dbkey, []DbKey{}, jw)
 }
 
-func (pc *ProofCache) Put(sid keybase1.SigID, pe ProofError) error {
+func (pc *ProofCache) Put(sid keybase1.SigID, pt keybase1.ProofType, pe ProofError) error {
 	if pc == nil {
 		return nil
 	}
@@ -241,6 +274,7 @@ func (pc *ProofCache) Put(sid keybase1.SigID, pe ProofError) error {
 		Contextified: pc.Contextified,
 		Status:       pe,
 		Time:         pc.G().Clock().Now(),
+		ProofType:    pt,
 	}
 	pc.memPut(sid, cr)
 	return pc.dbPut(sid, cr)
@@ -0,0 +1,129 @@
+// Copyright 2018 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+
+	"h12.me/socks"
+)
+
+// ProxyType identifies the proxying protocol a component's outbound
+// connections should be routed through. This is deliberately separate from
+// TorMode (see tor.go): Tor and the proof-checker's proxy mode are
+// SOCKS5-only and carry Tor-specific identity semantics (cookies, sessions,
+// CSRF), whereas ProxyType is a plain transport choice for network paths
+// (gregor, attachment transfers) that just need to get through a proxy.
+type ProxyType int
+
+const (
+	NoProxy ProxyType = iota
+	Socks5Proxy
+	HTTPConnectProxy
+)
+
+func (t ProxyType) String() string {
+	switch t {
+	case Socks5Proxy:
+		return "socks5"
+	case HTTPConnectProxy:
+		return "http-connect"
+	default:
+		return "none"
+	}
+}
+
+func StringToProxyType(s string) (ProxyType, error) {
+	switch s {
+	case "", "none":
+		return NoProxy, nil
+	case "socks5":
+		return Socks5Proxy, nil
+	case "http-connect":
+		return HTTPConnectProxy, nil
+	default:
+		return NoProxy, fmt.Errorf("unknown proxy type: %q", s)
+	}
+}
+
+// ProxyPolicy is the dialing configuration for one component of the client
+// (gregor, attachment transfers, ...). See Env.GetProxyPolicy.
+type ProxyPolicy struct {
+	Type    ProxyType
+	Address string
+}
+
+// Enabled reports whether p describes an actual proxy to dial through.
+func (p ProxyPolicy) Enabled() bool {
+	return p.Type != NoProxy && p.Address != ""
+}
+
+// Dialer returns a net.Dial-compatible function that routes connections
+// through p, or nil if p isn't Enabled(), in which case the caller should
+// dial directly.
+func (p ProxyPolicy) Dialer() (func(network, addr string) (net.Conn, error), error) {
+	if !p.Enabled() {
+		return nil, nil
+	}
+	switch p.Type {
+	case Socks5Proxy:
+		return socks.DialSocksProxy(socks.SOCKS5, p.Address), nil
+	case HTTPConnectProxy:
+		return httpConnectDialer(p.Address), nil
+	default:
+		return nil, fmt.Errorf("libkb: proxy policy has unknown type %d", p.Type)
+	}
+}
+
+// HTTPClient returns an *http.Client that routes through p, or nil if p
+// isn't Enabled(), in which case the caller should use its usual client.
+func (p ProxyPolicy) HTTPClient() (*http.Client, error) {
+	dialer, err := p.Dialer()
+	if err != nil {
+		return nil, err
+	}
+	if dialer == nil {
+		return nil, nil
+	}
+	return &http.Client{Transport: &http.Transport{Dial: dialer}}, nil
+}
+
+// httpConnectDialer returns a dialer that reaches addr by opening a TCP
+// connection to proxyAddr and issuing an HTTP CONNECT request, the standard
+// way of tunneling an arbitrary TCP stream (including TLS, handled by the
+// caller on top of the returned net.Conn) through an HTTP proxy.
+// golang.org/x/net/proxy isn't vendored in this tree, so this is hand
+// rolled rather than pulled in for the sake of one dialer.
+func httpConnectDialer(proxyAddr string) func(network, addr string) (net.Conn, error) {
+	return func(network, addr string) (net.Conn, error) {
+		conn, err := net.Dial("tcp", proxyAddr)
+		if err != nil {
+			return nil, err
+		}
+		req := &http.Request{
+			Method: "CONNECT",
+			URL:    &url.URL{Opaque: addr},
+			Host:   addr,
+			Header: make(http.Header),
+		}
+		if err := req.Write(conn); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			conn.Close()
+			return nil, fmt.Errorf("libkb: CONNECT to %s via proxy %s failed: %s", addr, proxyAddr, resp.Status)
+		}
+		return conn, nil
+	}
+}
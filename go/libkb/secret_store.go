@@ -30,7 +30,33 @@ type SecretStoreAll interface {
 	GetUsersWithStoredSecrets() ([]string, error)
 	GetApprovalPrompt() string
 	GetTerminalPrompt() string
-}
+	// GetBackendType names the concrete storage this backend uses, so
+	// callers (see `keybase account secret-store-status`) can tell a
+	// user whether their secret lives in the platform's real secure
+	// storage or in our own on-disk fallback.
+	GetBackendType() SecretStoreBackendType
+}
+
+// SecretStoreBackendType names one of the pluggable SecretStoreAll
+// implementations selected by build tag in NewSecretStoreAll.
+type SecretStoreBackendType string
+
+const (
+	// SecretStoreBackendFile is the cross-platform on-disk fallback
+	// (see SecretStoreFile) used whenever a platform has no better
+	// option wired up yet -- notably Windows and Linux today, neither
+	// of which has a DPAPI/TPM or libsecret backend implemented.
+	SecretStoreBackendFile SecretStoreBackendType = "file"
+	// SecretStoreBackendKeychain is macOS/iOS's Keychain (see
+	// KeychainSecretStore).
+	SecretStoreBackendKeychain SecretStoreBackendType = "keychain"
+	// SecretStoreBackendExternal delegates to a keystore registered by
+	// the host app (see SetGlobalExternalKeyStore), used on Android.
+	SecretStoreBackendExternal SecretStoreBackendType = "external"
+	// SecretStoreBackendNone means no secret store is configured at
+	// all.
+	SecretStoreBackendNone SecretStoreBackendType = "none"
+)
 
 type SecretStoreContext interface {
 	GetAllUserNames() (NormalizedUsername, []NormalizedUsername, error)
@@ -161,3 +187,12 @@ func (s *SecretStoreLocked) GetUsersWithStoredSecrets() ([]string, error) {
 	defer s.Unlock()
 	return s.SecretStoreAll.GetUsersWithStoredSecrets()
 }
+
+func (s *SecretStoreLocked) GetBackendType() SecretStoreBackendType {
+	if s == nil || s.SecretStoreAll == nil {
+		return SecretStoreBackendNone
+	}
+	s.Lock()
+	defer s.Unlock()
+	return s.SecretStoreAll.GetBackendType()
+}
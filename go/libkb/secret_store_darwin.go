@@ -125,3 +125,7 @@ func (k KeychainSecretStore) GetApprovalPrompt() string {
 func (k KeychainSecretStore) GetTerminalPrompt() string {
 	return "Store your key in Apple's local keychain?"
 }
+
+func (k KeychainSecretStore) GetBackendType() SecretStoreBackendType {
+	return SecretStoreBackendKeychain
+}
@@ -138,3 +138,7 @@ func (s secretStoreAccountName) GetTerminalPrompt() string {
 func (s secretStoreAccountName) GetApprovalPrompt() string {
 	return "Store secret in Android's KeyStore?"
 }
+
+func (s secretStoreAccountName) GetBackendType() SecretStoreBackendType {
+	return SecretStoreBackendExternal
+}
@@ -118,6 +118,10 @@ func (s *SecretStoreFile) GetTerminalPrompt() string {
 	return "Remember your login key?"
 }
 
+func (s *SecretStoreFile) GetBackendType() SecretStoreBackendType {
+	return SecretStoreBackendFile
+}
+
 func (s *SecretStoreFile) userpath(username NormalizedUsername) string {
 	return filepath.Join(s.dir, fmt.Sprintf("%s.ss", username))
 }
@@ -31,6 +31,10 @@ func (t TestSecretStoreAll) GetApprovalPrompt() string {
 	return "Store your key in the local secret store?"
 }
 
+func (t TestSecretStoreAll) GetBackendType() SecretStoreBackendType {
+	return SecretStoreBackendNone
+}
+
 func NewTestSecretStoreAll(c SecretStoreContext, g *GlobalContext) SecretStoreAll {
 	ret := TestSecretStoreAll{context: c, secretStoreNoneMap: make(map[NormalizedUsername]LKSecFullSecret)}
 	ret.SetGlobalContext(g)
@@ -134,6 +134,11 @@ func (t BaseServiceType) GetAPIArgKey() string {
 
 func (t BaseServiceType) IsDevelOnly() bool { return false }
 
+// GetAutoPoster returns nil by default, meaning most services require the
+// user to copy/paste the proof text manually. Override on a specific
+// ServiceType to opt in.
+func (t BaseServiceType) GetAutoPoster() AutoPostAPI { return nil }
+
 //=============================================================================
 
 type assertionContext struct {
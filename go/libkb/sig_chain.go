@@ -34,6 +34,11 @@ type SigChain struct {
 
 	// When the local chain was updated.
 	localChainUpdateTime time.Time
+
+	// When set, verifySubchain ignores any per-link checkpoint cache and
+	// re-verifies every link from scratch. Used by SelfCheck to test the
+	// checkpoint cache against a from-scratch replay.
+	bustLinkCache bool
 }
 
 func (sc SigChain) Len() int {
@@ -380,6 +385,19 @@ func (sc *SigChain) Dump(w io.Writer) {
 // verifySubchain verifies the given subchain and outputs a yes/no answer
 // on whether or not it's well-formed, and also yields ComputedKeyInfos for
 // all keys found in the process, including those that are now retired.
+//
+// Each link in the subchain is a potential checkpoint: once a link has
+// been verified, its resulting ComputedKeyInfos snapshot is cached on
+// the link itself (see ChainLink.PutSigCheckCache) and persisted to
+// LocalDb the next time the link is stored. That means that when a
+// user's sigchain grows, we don't have to replay the whole thing again
+// -- we can resume from the deepest cached checkpoint found in this
+// subchain and only verify the newly-appended links. The cache is
+// implicitly scoped to (uid, seqno, link hash): GetCurrentSubchain
+// already slices sc.chainLinks down to the run of links owned by the
+// current eldest key, so a reset or an eldest change naturally drops
+// every stale checkpoint from consideration without any extra
+// invalidation logic here.
 func (sc *SigChain) verifySubchain(ctx context.Context, kf KeyFamily, links []*ChainLink) (cached bool, cki *ComputedKeyInfos, err error) {
 	un := sc.username
 
@@ -394,18 +412,38 @@ func (sc *SigChain) verifySubchain(ctx context.Context, kf KeyFamily, links []*C
 	}
 
 	last := links[len(links)-1]
-	if cki = last.GetSigCheckCache(); cki != nil {
-		cached = true
-		sc.G().Log.CDebugf(ctx, "Skipped verification (cached): %s", last.id)
-		return
+	if !sc.bustLinkCache {
+		if cki = last.GetSigCheckCache(); cki != nil {
+			cached = true
+			sc.G().Log.CDebugf(ctx, "Skipped verification (cached): %s", last.id)
+			return
+		}
 	}
 
-	cki = NewComputedKeyInfos(sc.G())
+	// Look for the deepest checkpoint among the links we haven't already
+	// ruled out above, and resume verification just after it instead of
+	// replaying the whole subchain.
+	startIndex := 0
+	if !sc.bustLinkCache {
+		for i := len(links) - 2; i >= 0; i-- {
+			if checkpoint := links[i].GetSigCheckCache(); checkpoint != nil {
+				cki = checkpoint.ShallowCopy()
+				startIndex = i + 1
+				sc.G().Log.CDebugf(ctx, "Resuming verification after cached checkpoint at seqno=%d", links[i].GetSeqno())
+				break
+			}
+		}
+	}
+
+	if cki == nil {
+		cki = NewComputedKeyInfos(sc.G())
+	}
 	ckf := ComputedKeyFamily{kf: &kf, cki: cki, Contextified: sc.Contextified}
 
-	first := true
+	first := startIndex == 0
 
-	for linkIndex, link := range links {
+	for linkIndex := startIndex; linkIndex < len(links); linkIndex++ {
+		link := links[linkIndex]
 		if isBad, reason := link.IsBad(); isBad {
 			sc.G().Log.CDebugf(ctx, "Ignoring bad chain link with sig ID %s: %s", link.GetSigID(), reason)
 			continue
@@ -477,12 +515,81 @@ func (sc *SigChain) verifySubchain(ctx context.Context, kf KeyFamily, links []*C
 		if err != nil {
 			return
 		}
+
+		// Checkpoint our progress at each key-run boundary, not just on
+		// the final link, so that the next load of this chain (with one
+		// or more new links appended) can resume from here instead of
+		// replaying everything we just did. Each checkpoint gets its own
+		// ShallowCopy of cki, since cki continues to be mutated in place
+		// (via Delegate/Revoke) as we keep walking forward.
+		if isModifyingKeys || isFinalLink || isLastLinkInSameKeyRun {
+			link.PutSigCheckCache(cki.ShallowCopy())
+		}
 	}
 
-	last.PutSigCheckCache(cki)
 	return
 }
 
+// SigChainSelfCheckResult describes the outcome of comparing the
+// currently cached/computed key state for a sigchain against a
+// from-scratch replay of the same subchain. It's meant to catch bugs in
+// the incremental checkpoint-cache logic in verifySubchain, not to
+// audit the deeper trust boundary of local sigchain storage itself.
+type SigChainSelfCheckResult struct {
+	Consistent bool
+	NumLinks   int
+	Message    string
+}
+
+// SelfCheck re-verifies the current subchain for eldest from scratch,
+// bypassing the per-link checkpoint cache, and compares the result
+// against the ComputedKeyInfos this SigChain currently has cached. It's
+// a diagnostic to confirm that the checkpoint-resume logic added to
+// verifySubchain hasn't drifted from what a full replay would produce.
+func (sc *SigChain) SelfCheck(ctx context.Context, kf KeyFamily, eldest keybase1.KID) (res SigChainSelfCheckResult, err error) {
+	cached := sc.GetComputedKeyInfos()
+	if cached == nil {
+		return res, InternalError{"SelfCheck: no cached ComputedKeyInfos to compare against"}
+	}
+
+	links, err := sc.GetCurrentSubchain(eldest)
+	if err != nil {
+		return res, err
+	}
+	if len(links) == 0 {
+		return SigChainSelfCheckResult{Consistent: true, Message: "empty subchain"}, nil
+	}
+
+	sc.bustLinkCache = true
+	_, fresh, err := sc.verifySubchain(ctx, kf, links)
+	sc.bustLinkCache = false
+	if err != nil {
+		return res, err
+	}
+
+	res.NumLinks = len(links)
+
+	if len(cached.Infos) != len(fresh.Infos) {
+		res.Message = fmt.Sprintf("cached state has %d keys, from-scratch replay has %d", len(cached.Infos), len(fresh.Infos))
+		return res, nil
+	}
+
+	for kid, cachedInfo := range cached.Infos {
+		freshInfo, ok := fresh.Infos[kid]
+		if !ok {
+			res.Message = fmt.Sprintf("key %s is in the cached state but missing from the from-scratch replay", kid)
+			return res, nil
+		}
+		if cachedInfo.Status != freshInfo.Status || cachedInfo.Sibkey != freshInfo.Sibkey {
+			res.Message = fmt.Sprintf("key %s has a status/sibkey mismatch between the cached state and the from-scratch replay", kid)
+			return res, nil
+		}
+	}
+
+	res.Consistent = true
+	return res, nil
+}
+
 func (sc *SigChain) VerifySigsAndComputeKeys(ctx context.Context, eldest keybase1.KID, ckf *ComputedKeyFamily) (cached bool, err error) {
 
 	cached = false
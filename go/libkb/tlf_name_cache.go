@@ -0,0 +1,102 @@
+// Copyright 2018 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	keybase1 "github.com/keybase/client/go/protocol/keybase1"
+	"stathat.com/c/ramcache"
+)
+
+// TlfNameCacher caches the result of resolving and canonicalizing a TLF
+// name, keyed by whatever the caller considers identifies the query (see
+// CanonicalTlfNameCacheKey). This saves a round trip to KBFS (and the
+// identify work behind it) when the same set of participants is resolved
+// repeatedly in quick succession, e.g. while a chat conversation sends
+// several messages in a row.
+type TlfNameCacher interface {
+	Get(key string) (*keybase1.CanonicalTLFNameAndIDWithBreaks, error)
+	Insert(key string, val keybase1.CanonicalTLFNameAndIDWithBreaks) error
+	Clear() error
+	Shutdown()
+}
+
+// TlfNameCache stores CanonicalTLFNameAndIDWithBreaks results in memory,
+// expiring them maxAge after they were inserted.
+type TlfNameCache struct {
+	cache *ramcache.Ramcache
+}
+
+var _ TlfNameCacher = (*TlfNameCache)(nil)
+
+// NewTlfNameCache creates a TlfNameCache that expires entries maxAge after
+// they were inserted.
+func NewTlfNameCache(maxAge time.Duration) *TlfNameCache {
+	res := &TlfNameCache{
+		cache: ramcache.New(),
+	}
+	res.cache.MaxAge = maxAge
+	res.cache.TTL = maxAge
+	return res
+}
+
+// CanonicalTlfNameCacheKey builds the cache key for a TLF name resolution,
+// so that the same set of participants always hashes to the same key
+// regardless of the order they were listed in, and so that a public lookup
+// never collides with a private one for the same participants.
+func CanonicalTlfNameCacheKey(assertions []string, public bool) string {
+	sorted := make([]string, len(assertions))
+	copy(sorted, assertions)
+	sort.Strings(sorted)
+	prefix := "priv"
+	if public {
+		prefix = "pub"
+	}
+	return prefix + ":" + strings.Join(sorted, ",")
+}
+
+// Get returns a cached resolution result for key, or nil if there is none
+// (including if it has expired).
+func (c *TlfNameCache) Get(key string) (*keybase1.CanonicalTLFNameAndIDWithBreaks, error) {
+	v, err := c.cache.Get(key)
+	if err != nil {
+		if err == ramcache.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	res, ok := v.(*keybase1.CanonicalTLFNameAndIDWithBreaks)
+	if !ok {
+		return nil, fmt.Errorf("invalid type in cache: %T", v)
+	}
+	return res, nil
+}
+
+// Insert adds a resolution result to the cache, keyed on key.
+func (c *TlfNameCache) Insert(key string, val keybase1.CanonicalTLFNameAndIDWithBreaks) error {
+	tmp := val
+	return c.cache.Set(key, &tmp)
+}
+
+// Clear wipes every entry from the cache. We call this whenever a user's
+// keys change, since a cached resolution has no record of which
+// participants it depends on, so we can't invalidate just the entries that
+// involve the changed user.
+func (c *TlfNameCache) Clear() error {
+	for _, key := range c.cache.Keys() {
+		if err := c.cache.Delete(key); err != nil && err != ramcache.ErrNotFound {
+			return err
+		}
+	}
+	return nil
+}
+
+// Shutdown stops any goroutines started by the cache.
+func (c *TlfNameCache) Shutdown() {
+	c.cache.Shutdown()
+}
@@ -0,0 +1,55 @@
+// Copyright 2018 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"testing"
+	"time"
+
+	keybase1 "github.com/keybase/client/go/protocol/keybase1"
+)
+
+func TestCanonicalTlfNameCacheKeySortsAndSeparatesPublic(t *testing.T) {
+	k1 := CanonicalTlfNameCacheKey([]string{"bob", "alice"}, false)
+	k2 := CanonicalTlfNameCacheKey([]string{"alice", "bob"}, false)
+	if k1 != k2 {
+		t.Fatalf("expected key to be order-independent, got %q and %q", k1, k2)
+	}
+
+	pub := CanonicalTlfNameCacheKey([]string{"alice", "bob"}, true)
+	if pub == k1 {
+		t.Fatalf("expected a public key to differ from a private one with the same participants, got %q for both", k1)
+	}
+}
+
+func TestTlfNameCacheGetInsertClear(t *testing.T) {
+	c := NewTlfNameCache(time.Minute)
+	defer c.Shutdown()
+
+	key := CanonicalTlfNameCacheKey([]string{"alice", "bob"}, false)
+
+	if cached, err := c.Get(key); err != nil || cached != nil {
+		t.Fatalf("expected a cache miss before any insert, got: %+v, %v", cached, err)
+	}
+
+	want := keybase1.CanonicalTLFNameAndIDWithBreaks{TlfID: keybase1.TLFID("abc123")}
+	if err := c.Insert(key, want); err != nil {
+		t.Fatalf("unexpected error inserting: %s", err)
+	}
+
+	cached, err := c.Get(key)
+	if err != nil {
+		t.Fatalf("unexpected error getting: %s", err)
+	}
+	if cached == nil || cached.TlfID != want.TlfID {
+		t.Fatalf("expected cached entry %+v, got %+v", want, cached)
+	}
+
+	if err := c.Clear(); err != nil {
+		t.Fatalf("unexpected error clearing: %s", err)
+	}
+	if cached, err := c.Get(key); err != nil || cached != nil {
+		t.Fatalf("expected a cache miss after Clear, got: %+v, %v", cached, err)
+	}
+}
@@ -0,0 +1,319 @@
+// Copyright 2018 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	keybase1 "github.com/keybase/client/go/protocol/keybase1"
+	"github.com/keybase/go-framed-msgpack-rpc/rpc"
+	"golang.org/x/net/context"
+)
+
+// TlfResolver resolves TLF crypt keys and canonical names. It's
+// installed on GlobalContext (see GetTlfResolver/SetTlfResolver) so that
+// callers don't have to know how resolution is actually performed, and
+// so tests or alternative frontends (e.g. a pure-service build with no
+// KBFS at all) can install a different backend without touching any
+// caller.
+type TlfResolver interface {
+	GetTLFCryptKeys(ctx context.Context, query keybase1.TLFQuery) (keybase1.GetTLFCryptKeysRes, error)
+	GetTLFCryptKeysByID(ctx context.Context, query keybase1.TLFQueryWithID) (keybase1.GetTLFCryptKeysRes, error)
+	GetPublicCanonicalTLFNameAndID(ctx context.Context, query keybase1.TLFQuery) (keybase1.CanonicalTLFNameAndIDWithBreaks, error)
+}
+
+var _ TlfResolver = (*kbfsTlfResolver)(nil)
+
+// kbfsTlfResolver is the default TlfResolver: it resolves against
+// whichever KBFS client is currently connected, looked up fresh on each
+// call since KBFS can connect and disconnect over the service's
+// lifetime. With no KBFS connection, it reports KBFSNotRunningError from
+// each method rather than guessing at key material.
+type kbfsTlfResolver struct {
+	Contextified
+}
+
+// NewKBFSTlfResolver makes the default, KBFS-RPC-backed TlfResolver.
+func NewKBFSTlfResolver(g *GlobalContext) TlfResolver {
+	return &kbfsTlfResolver{Contextified: NewContextified(g)}
+}
+
+// client returns the real KBFS-backed TlfKeysInterface if KBFS is
+// currently connected, or noKBFSTlfKeysClient otherwise. It does not go
+// through the tlf-identify breaker itself: "no KBFS connection" is a
+// normal, common state (e.g. a pure-service build) rather than a
+// misbehaving-server condition, so it shouldn't count against the
+// breaker or be masked by NetworkBreakerOpenError once the breaker trips.
+func (r *kbfsTlfResolver) client() keybase1.TlfKeysInterface {
+	xp := r.G().ConnectionManager.LookupByClientType(keybase1.ClientType_KBFS)
+	if xp == nil {
+		return noKBFSTlfKeysClient{}
+	}
+	return &keybase1.TlfKeysClient{
+		Cli: rpc.NewClient(xp, ErrorUnwrapper{}),
+	}
+}
+
+// tlfResolverBreakerName names the shared NetworkBreaker guarding actual
+// RPCs to a connected KBFS, so a KBFS that's connected but wedged doesn't
+// turn every identify into a retried, timed-out RPC.
+const tlfResolverBreakerName = "tlf-identify"
+
+// tlfResolverBreakerConfig trips after 5 consecutive failures and holds
+// the breaker open for 30 seconds, short enough to notice a KBFS
+// connection recovering quickly, since unlike a remote server this is a
+// local RPC peer that's expected to reconnect on its own.
+var tlfResolverBreakerConfig = NetworkBreakerConfig{
+	MaxConsecutiveFailures: 5,
+	Cooldown:               30 * time.Second,
+}
+
+// callWithBreaker runs call through the shared tlf-identify breaker,
+// recording its outcome so a run of failures (e.g. a hung KBFS) trips the
+// breaker for every caller, not just the one that hit it first. It's only
+// applied once a real KBFS client is in hand; see client's doc comment.
+func (r *kbfsTlfResolver) callWithBreaker(client keybase1.TlfKeysInterface, call func(keybase1.TlfKeysInterface) error) error {
+	if _, noKBFS := client.(noKBFSTlfKeysClient); noKBFS {
+		return call(client)
+	}
+	breaker := r.G().NetworkBreakers.Get(tlfResolverBreakerName, tlfResolverBreakerConfig)
+	if err := breaker.Allow(); err != nil {
+		return err
+	}
+	err := call(client)
+	if err != nil {
+		breaker.Failure()
+	} else {
+		breaker.Success()
+	}
+	return err
+}
+
+func (r *kbfsTlfResolver) GetTLFCryptKeys(ctx context.Context, query keybase1.TLFQuery) (res keybase1.GetTLFCryptKeysRes, err error) {
+	err = r.callWithBreaker(r.client(), func(c keybase1.TlfKeysInterface) (err error) {
+		res, err = c.GetTLFCryptKeys(ctx, query)
+		return err
+	})
+	return res, err
+}
+
+func (r *kbfsTlfResolver) GetTLFCryptKeysByID(ctx context.Context, query keybase1.TLFQueryWithID) (res keybase1.GetTLFCryptKeysRes, err error) {
+	err = r.callWithBreaker(r.client(), func(c keybase1.TlfKeysInterface) (err error) {
+		res, err = c.GetTLFCryptKeysByID(ctx, query)
+		return err
+	})
+	return res, err
+}
+
+func (r *kbfsTlfResolver) GetPublicCanonicalTLFNameAndID(ctx context.Context, query keybase1.TLFQuery) (res keybase1.CanonicalTLFNameAndIDWithBreaks, err error) {
+	err = r.callWithBreaker(r.client(), func(c keybase1.TlfKeysInterface) (err error) {
+		res, err = c.GetPublicCanonicalTLFNameAndID(ctx, query)
+		return err
+	})
+	return res, err
+}
+
+// noKBFSTlfKeysClient stands in for the real KBFS-backed
+// keybase1.TlfKeysInterface when there's no KBFS connection to resolve
+// against. TLF crypt keys ultimately come from KBFS's own key
+// derivation, so there's no correct way to synthesize them client-side;
+// every method here just reports KBFSNotRunningError.
+type noKBFSTlfKeysClient struct{}
+
+var _ keybase1.TlfKeysInterface = noKBFSTlfKeysClient{}
+
+func (noKBFSTlfKeysClient) GetTLFCryptKeys(ctx context.Context, query keybase1.TLFQuery) (keybase1.GetTLFCryptKeysRes, error) {
+	return keybase1.GetTLFCryptKeysRes{}, KBFSNotRunningError{}
+}
+
+func (noKBFSTlfKeysClient) GetTLFCryptKeysByID(ctx context.Context, query keybase1.TLFQueryWithID) (keybase1.GetTLFCryptKeysRes, error) {
+	return keybase1.GetTLFCryptKeysRes{}, KBFSNotRunningError{}
+}
+
+func (noKBFSTlfKeysClient) GetPublicCanonicalTLFNameAndID(ctx context.Context, query keybase1.TLFQuery) (keybase1.CanonicalTLFNameAndIDWithBreaks, error) {
+	return keybase1.CanonicalTLFNameAndIDWithBreaks{}, KBFSNotRunningError{}
+}
+
+// tlfCryptKeyCacheEntry holds every crypt key generation the cache has seen
+// for a single TLF, plus the CanonicalTLFNameAndIDWithBreaks that came back
+// alongside the most recent one of them.
+type tlfCryptKeyCacheEntry struct {
+	nameIDBreaks keybase1.CanonicalTLFNameAndIDWithBreaks
+	keys         map[int]keybase1.CryptKey // by KeyGeneration
+}
+
+// res reassembles a GetTLFCryptKeysRes out of the entry's accumulated keys.
+func (e *tlfCryptKeyCacheEntry) res() keybase1.GetTLFCryptKeysRes {
+	res := keybase1.GetTLFCryptKeysRes{NameIDBreaks: e.nameIDBreaks}
+	for _, key := range e.keys {
+		res.CryptKeys = append(res.CryptKeys, key)
+	}
+	return res
+}
+
+// merge folds a freshly-fetched response into the entry. It never drops a
+// generation the entry already had, since KBFS is only ever expected to add
+// generations, not take them away.
+func (e *tlfCryptKeyCacheEntry) merge(res keybase1.GetTLFCryptKeysRes) {
+	e.nameIDBreaks = res.NameIDBreaks
+	if e.keys == nil {
+		e.keys = make(map[int]keybase1.CryptKey)
+	}
+	for _, key := range res.CryptKeys {
+		e.keys[key.KeyGeneration] = key
+	}
+}
+
+// TlfCacheInvalidator is implemented by TlfResolvers that cache crypt keys
+// and need to be told when a TLF has been rekeyed, so stale keys and
+// membership don't linger past a rekey. GlobalContext.GetTlfResolver()'s
+// default resolver implements this; callers that only have a TlfResolver
+// should type-assert for it (see service.KBFSHandler.checkConversationRekey)
+// rather than adding it to the TlfResolver interface itself, since not every
+// implementation (e.g. tests' fakeTlfResolver) needs to care.
+type TlfCacheInvalidator interface {
+	InvalidateTLF(name string)
+}
+
+var _ TlfResolver = (*cachedTlfResolver)(nil)
+var _ TlfCacheInvalidator = (*cachedTlfResolver)(nil)
+
+// cachedTlfResolver decorates another TlfResolver with an in-memory cache of
+// resolved TLF crypt keys, keyed by TLF ID and, within that, by key
+// generation (see tlfCryptKeyCacheEntry), so that repeated chat box/unbox
+// calls for the same TLF don't cost a fresh KBFS round trip every time. A
+// TLFQuery or TLFQueryWithID with BypassCache set always goes straight to
+// the inner resolver, and its result is used to refresh the cache.
+//
+// GetPublicCanonicalTLFNameAndID isn't cached: it never returns crypt keys,
+// which is the only thing this cache is scoped to, per its name.
+//
+// Cache entries are invalidated wholesale for a TLF by InvalidateTLF, which
+// service.KBFSHandler calls when it sees a rekey-finished notification for
+// that TLF, since a rekey can both add a new generation and change who can
+// read the existing ones.
+//
+// The cache lives in memory only; it is never spilled to LocalDb. Doing that
+// with encryption at rest, matching how go/chat/storage encrypts its local
+// message cache, would need a device secret key derived via
+// engine.GetMySecretKey -- and libkb can't import engine without an import
+// cycle, since engine already imports libkb. So this cache is scoped to a
+// single run of the service; a restart starts it cold again.
+type cachedTlfResolver struct {
+	Contextified
+	inner TlfResolver
+
+	sync.RWMutex
+	byID   map[keybase1.TLFID]*tlfCryptKeyCacheEntry
+	byName map[string]keybase1.TLFID // "name|behavior" -> resolved TLFID
+}
+
+// NewCachedTlfResolver wraps inner with an in-memory TLF crypt key cache.
+func NewCachedTlfResolver(g *GlobalContext, inner TlfResolver) TlfResolver {
+	return &cachedTlfResolver{
+		Contextified: NewContextified(g),
+		inner:        inner,
+		byID:         make(map[keybase1.TLFID]*tlfCryptKeyCacheEntry),
+		byName:       make(map[string]keybase1.TLFID),
+	}
+}
+
+func tlfNameCacheKey(name string, behavior keybase1.TLFIdentifyBehavior) string {
+	return fmt.Sprintf("%s|%d", name, behavior)
+}
+
+func (r *cachedTlfResolver) lookupByID(id keybase1.TLFID) (keybase1.GetTLFCryptKeysRes, bool) {
+	r.RLock()
+	defer r.RUnlock()
+	entry, ok := r.byID[id]
+	if !ok {
+		return keybase1.GetTLFCryptKeysRes{}, false
+	}
+	return entry.res(), true
+}
+
+func (r *cachedTlfResolver) lookupByName(name string, behavior keybase1.TLFIdentifyBehavior) (keybase1.GetTLFCryptKeysRes, bool) {
+	r.RLock()
+	defer r.RUnlock()
+	id, ok := r.byName[tlfNameCacheKey(name, behavior)]
+	if !ok {
+		return keybase1.GetTLFCryptKeysRes{}, false
+	}
+	entry, ok := r.byID[id]
+	if !ok {
+		return keybase1.GetTLFCryptKeysRes{}, false
+	}
+	return entry.res(), true
+}
+
+func (r *cachedTlfResolver) store(name string, behavior keybase1.TLFIdentifyBehavior, res keybase1.GetTLFCryptKeysRes) {
+	r.Lock()
+	defer r.Unlock()
+	id := res.NameIDBreaks.TlfID
+	entry, ok := r.byID[id]
+	if !ok {
+		entry = &tlfCryptKeyCacheEntry{}
+		r.byID[id] = entry
+	}
+	entry.merge(res)
+	if name != "" {
+		r.byName[tlfNameCacheKey(name, behavior)] = id
+	}
+}
+
+func (r *cachedTlfResolver) GetTLFCryptKeys(ctx context.Context, query keybase1.TLFQuery) (keybase1.GetTLFCryptKeysRes, error) {
+	if !query.BypassCache {
+		if res, ok := r.lookupByName(query.TlfName, query.IdentifyBehavior); ok {
+			return res, nil
+		}
+	}
+	res, err := r.inner.GetTLFCryptKeys(ctx, query)
+	if err != nil {
+		return res, err
+	}
+	r.store(query.TlfName, query.IdentifyBehavior, res)
+	return res, nil
+}
+
+func (r *cachedTlfResolver) GetTLFCryptKeysByID(ctx context.Context, query keybase1.TLFQueryWithID) (keybase1.GetTLFCryptKeysRes, error) {
+	if !query.BypassCache {
+		if res, ok := r.lookupByID(query.TlfID); ok {
+			return res, nil
+		}
+	}
+	res, err := r.inner.GetTLFCryptKeysByID(ctx, query)
+	if err != nil {
+		return res, err
+	}
+	r.store("", query.IdentifyBehavior, res)
+	return res, nil
+}
+
+func (r *cachedTlfResolver) GetPublicCanonicalTLFNameAndID(ctx context.Context, query keybase1.TLFQuery) (keybase1.CanonicalTLFNameAndIDWithBreaks, error) {
+	return r.inner.GetPublicCanonicalTLFNameAndID(ctx, query)
+}
+
+// InvalidateTLF drops every cached crypt key generation for the TLF known by
+// name, so the next lookup goes back to KBFS. It's a no-op if the TLF was
+// never cached in the first place.
+func (r *cachedTlfResolver) InvalidateTLF(name string) {
+	r.Lock()
+	defer r.Unlock()
+	for key := range r.byName {
+		if tlfNameKeyMatchesName(key, name) {
+			delete(r.byID, r.byName[key])
+			delete(r.byName, key)
+		}
+	}
+}
+
+// tlfNameKeyMatchesName reports whether a "name|behavior" cache key was
+// derived from name, regardless of which TLFIdentifyBehavior it was queried
+// with.
+func tlfNameKeyMatchesName(key, name string) bool {
+	prefix := name + "|"
+	return len(key) > len(prefix) && key[:len(prefix)] == prefix
+}
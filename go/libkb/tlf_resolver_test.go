@@ -0,0 +1,172 @@
+// Copyright 2018 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"testing"
+
+	keybase1 "github.com/keybase/client/go/protocol/keybase1"
+	"golang.org/x/net/context"
+)
+
+func TestGetTlfResolverDefaultsToKBFS(t *testing.T) {
+	tc := SetupTest(t, "tlf_resolver", 1)
+	defer tc.Cleanup()
+
+	r1 := tc.G.GetTlfResolver()
+	r2 := tc.G.GetTlfResolver()
+	if r1 != r2 {
+		t.Fatal("expected GetTlfResolver to return the same instance once installed")
+	}
+	if _, ok := r1.(*cachedTlfResolver); !ok {
+		t.Fatalf("expected a *cachedTlfResolver wrapping the KBFS backend by default, got %T", r1)
+	}
+}
+
+func TestSetTlfResolverOverridesDefault(t *testing.T) {
+	tc := SetupTest(t, "tlf_resolver", 1)
+	defer tc.Cleanup()
+
+	fake := &fakeTlfResolver{}
+	tc.G.SetTlfResolver(fake)
+	if tc.G.GetTlfResolver() != TlfResolver(fake) {
+		t.Fatal("expected GetTlfResolver to return the installed resolver")
+	}
+}
+
+func TestNoKBFSTlfKeysClientReportsKBFSNotRunning(t *testing.T) {
+	cli := noKBFSTlfKeysClient{}
+
+	if _, err := cli.GetTLFCryptKeys(context.Background(), keybase1.TLFQuery{}); !isKBFSNotRunningError(err) {
+		t.Fatalf("expected KBFSNotRunningError, got: %v", err)
+	}
+	if _, err := cli.GetTLFCryptKeysByID(context.Background(), keybase1.TLFQueryWithID{}); !isKBFSNotRunningError(err) {
+		t.Fatalf("expected KBFSNotRunningError, got: %v", err)
+	}
+	if _, err := cli.GetPublicCanonicalTLFNameAndID(context.Background(), keybase1.TLFQuery{}); !isKBFSNotRunningError(err) {
+		t.Fatalf("expected KBFSNotRunningError, got: %v", err)
+	}
+}
+
+func TestKBFSTlfResolverFallsBackWithoutKBFS(t *testing.T) {
+	tc := SetupTest(t, "tlf_resolver", 1)
+	defer tc.Cleanup()
+
+	r := NewKBFSTlfResolver(tc.G)
+	if _, err := r.GetTLFCryptKeys(context.Background(), keybase1.TLFQuery{}); !isKBFSNotRunningError(err) {
+		t.Fatalf("expected KBFSNotRunningError, got: %v", err)
+	}
+}
+
+func isKBFSNotRunningError(err error) bool {
+	_, ok := err.(KBFSNotRunningError)
+	return ok
+}
+
+func TestCachedTlfResolverServesFromCache(t *testing.T) {
+	tc := SetupTest(t, "tlf_resolver", 1)
+	defer tc.Cleanup()
+
+	inner := &countingTlfResolver{
+		res: keybase1.GetTLFCryptKeysRes{
+			NameIDBreaks: keybase1.CanonicalTLFNameAndIDWithBreaks{TlfID: keybase1.TLFID("abc")},
+			CryptKeys:    []keybase1.CryptKey{{KeyGeneration: 1}},
+		},
+	}
+	r := NewCachedTlfResolver(tc.G, inner)
+
+	query := keybase1.TLFQuery{TlfName: "alice,bob"}
+	if _, err := r.GetTLFCryptKeys(context.Background(), query); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.GetTLFCryptKeys(context.Background(), query); err != nil {
+		t.Fatal(err)
+	}
+	if inner.calls != 1 {
+		t.Fatalf("expected the second lookup to be served from cache, inner was called %d times", inner.calls)
+	}
+
+	// Adding a second generation should be visible on top of the first,
+	// not replace it.
+	inner.res.CryptKeys = []keybase1.CryptKey{{KeyGeneration: 2}}
+	res, err := r.GetTLFCryptKeys(context.Background(), keybase1.TLFQuery{TlfName: "alice,bob", BypassCache: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.CryptKeys) != 1 {
+		t.Fatalf("expected 1 crypt key back from the bypassed call, got %d", len(res.CryptKeys))
+	}
+	res, err = r.GetTLFCryptKeys(context.Background(), query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.CryptKeys) != 2 {
+		t.Fatalf("expected both generations merged into the cache, got %d", len(res.CryptKeys))
+	}
+	if inner.calls != 2 {
+		t.Fatalf("expected BypassCache to force exactly one more inner call, inner was called %d times", inner.calls)
+	}
+}
+
+func TestCachedTlfResolverInvalidateTLF(t *testing.T) {
+	tc := SetupTest(t, "tlf_resolver", 1)
+	defer tc.Cleanup()
+
+	inner := &countingTlfResolver{
+		res: keybase1.GetTLFCryptKeysRes{
+			NameIDBreaks: keybase1.CanonicalTLFNameAndIDWithBreaks{TlfID: keybase1.TLFID("abc")},
+			CryptKeys:    []keybase1.CryptKey{{KeyGeneration: 1}},
+		},
+	}
+	r := NewCachedTlfResolver(tc.G, inner)
+	invalidator, ok := r.(TlfCacheInvalidator)
+	if !ok {
+		t.Fatal("expected cachedTlfResolver to implement TlfCacheInvalidator")
+	}
+
+	query := keybase1.TLFQuery{TlfName: "alice,bob"}
+	if _, err := r.GetTLFCryptKeys(context.Background(), query); err != nil {
+		t.Fatal(err)
+	}
+	invalidator.InvalidateTLF("alice,bob")
+	if _, err := r.GetTLFCryptKeys(context.Background(), query); err != nil {
+		t.Fatal(err)
+	}
+	if inner.calls != 2 {
+		t.Fatalf("expected invalidation to force a fresh lookup, inner was called %d times", inner.calls)
+	}
+}
+
+type countingTlfResolver struct {
+	calls int
+	res   keybase1.GetTLFCryptKeysRes
+}
+
+func (c *countingTlfResolver) GetTLFCryptKeys(ctx context.Context, query keybase1.TLFQuery) (keybase1.GetTLFCryptKeysRes, error) {
+	c.calls++
+	return c.res, nil
+}
+
+func (c *countingTlfResolver) GetTLFCryptKeysByID(ctx context.Context, query keybase1.TLFQueryWithID) (keybase1.GetTLFCryptKeysRes, error) {
+	c.calls++
+	return c.res, nil
+}
+
+func (c *countingTlfResolver) GetPublicCanonicalTLFNameAndID(ctx context.Context, query keybase1.TLFQuery) (keybase1.CanonicalTLFNameAndIDWithBreaks, error) {
+	return keybase1.CanonicalTLFNameAndIDWithBreaks{}, nil
+}
+
+type fakeTlfResolver struct{}
+
+func (fakeTlfResolver) GetTLFCryptKeys(ctx context.Context, query keybase1.TLFQuery) (keybase1.GetTLFCryptKeysRes, error) {
+	return keybase1.GetTLFCryptKeysRes{}, nil
+}
+
+func (fakeTlfResolver) GetTLFCryptKeysByID(ctx context.Context, query keybase1.TLFQueryWithID) (keybase1.GetTLFCryptKeysRes, error) {
+	return keybase1.GetTLFCryptKeysRes{}, nil
+}
+
+func (fakeTlfResolver) GetPublicCanonicalTLFNameAndID(ctx context.Context, query keybase1.TLFQuery) (keybase1.CanonicalTLFNameAndIDWithBreaks, error) {
+	return keybase1.CanonicalTLFNameAndIDWithBreaks{}, nil
+}
@@ -129,6 +129,18 @@ func (u *User) GetComputedKeyInfos() *ComputedKeyInfos {
 	return u.sigChain().GetComputedKeyInfos()
 }
 
+// SigChainSelfCheck re-verifies this user's sigchain from scratch,
+// bypassing the incremental checkpoint cache, and compares the result
+// against the already-computed key state. It's a diagnostic for the
+// consistency of the sigchain verification cache, not a security
+// re-check of the underlying chain itself.
+func (u *User) SigChainSelfCheck(ctx context.Context) (SigChainSelfCheckResult, error) {
+	if u.sigChain() == nil || u.keyFamily == nil {
+		return SigChainSelfCheckResult{}, InternalError{"SigChainSelfCheck: user has no sigchain or key family loaded"}
+	}
+	return u.sigChain().SelfCheck(ctx, *u.keyFamily, u.GetEldestKID())
+}
+
 func (u *User) GetSigHintsVersion() int {
 	if u.sigHints == nil {
 		return 0
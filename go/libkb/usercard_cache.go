@@ -9,6 +9,13 @@ import (
 	"stathat.com/c/ramcache"
 )
 
+// userCardFreshWindow is how long a cached UserCard is considered fresh
+// enough that callers shouldn't bother kicking off a background
+// revalidation. Entries older than this (but still within maxAge) are
+// still served immediately -- they're just also refreshed in the
+// background.
+const userCardFreshWindow = 20 * time.Second
+
 // UserCardCache caches keybase1.UserCard objects in memory.
 type UserCardCache struct {
 	cache *ramcache.Ramcache
@@ -64,3 +71,16 @@ func (c *UserCardCache) key(uid keybase1.UID, session bool) string {
 func (c *UserCardCache) Delete(uid keybase1.UID) error {
 	return c.cache.Delete(c.key(uid, true))
 }
+
+// IsStale reports whether the cached entry for uid (if any) is old
+// enough that it should be revalidated in the background. It doesn't
+// evict or otherwise disturb the entry -- staleness here only affects
+// whether a caller decides to kick off a refresh, not whether the
+// cached value is still usable.
+func (c *UserCardCache) IsStale(uid keybase1.UID, useSession bool) bool {
+	createdAt, err := c.cache.CreatedAt(c.key(uid, useSession))
+	if err != nil {
+		return true
+	}
+	return time.Since(createdAt) > userCardFreshWindow
+}
@@ -29,6 +29,7 @@ const (
 	MessageType_TLFNAME            MessageType = 6
 	MessageType_HEADLINE           MessageType = 7
 	MessageType_ATTACHMENTUPLOADED MessageType = 8
+	MessageType_REACTION           MessageType = 9
 )
 
 var MessageTypeMap = map[string]MessageType{
@@ -41,6 +42,7 @@ var MessageTypeMap = map[string]MessageType{
 	"TLFNAME":            6,
 	"HEADLINE":           7,
 	"ATTACHMENTUPLOADED": 8,
+	"REACTION":           9,
 }
 
 var MessageTypeRevMap = map[MessageType]string{
@@ -53,6 +55,7 @@ var MessageTypeRevMap = map[MessageType]string{
 	6: "TLFNAME",
 	7: "HEADLINE",
 	8: "ATTACHMENTUPLOADED",
+	9: "REACTION",
 }
 
 type TopicType int
@@ -182,15 +185,24 @@ type ConversationResolveInfo struct {
 	NewTLFName string `codec:"newTLFName" json:"newTLFName"`
 }
 
+// RetentionPolicy governs how long messages are kept in a conversation's
+// local cache before the client purges them. AgeSecs of 0 means retain
+// forever (the default). Setting a policy does not delete anything on the
+// server or on any other device -- see SetConversationRetentionLocal.
+type RetentionPolicy struct {
+	AgeSecs int `codec:"ageSecs" json:"ageSecs"`
+}
+
 type ConversationMetadata struct {
-	IdTriple       ConversationIDTriple      `codec:"idTriple" json:"idTriple"`
-	ConversationID ConversationID            `codec:"conversationID" json:"conversationID"`
-	Visibility     TLFVisibility             `codec:"visibility" json:"visibility"`
-	Status         ConversationStatus        `codec:"status" json:"status"`
-	FinalizeInfo   *ConversationFinalizeInfo `codec:"finalizeInfo,omitempty" json:"finalizeInfo,omitempty"`
-	Supersedes     []ConversationMetadata    `codec:"supersedes" json:"supersedes"`
-	SupersededBy   []ConversationMetadata    `codec:"supersededBy" json:"supersededBy"`
-	ActiveList     []gregor1.UID             `codec:"activeList" json:"activeList"`
+	IdTriple        ConversationIDTriple      `codec:"idTriple" json:"idTriple"`
+	ConversationID  ConversationID            `codec:"conversationID" json:"conversationID"`
+	Visibility      TLFVisibility             `codec:"visibility" json:"visibility"`
+	Status          ConversationStatus        `codec:"status" json:"status"`
+	RetentionPolicy RetentionPolicy           `codec:"retentionPolicy" json:"retentionPolicy"`
+	FinalizeInfo    *ConversationFinalizeInfo `codec:"finalizeInfo,omitempty" json:"finalizeInfo,omitempty"`
+	Supersedes      []ConversationMetadata    `codec:"supersedes" json:"supersedes"`
+	SupersededBy    []ConversationMetadata    `codec:"supersededBy" json:"supersededBy"`
+	ActiveList      []gregor1.UID             `codec:"activeList" json:"activeList"`
 }
 
 type ConversationReaderInfo struct {
@@ -231,30 +243,36 @@ type OutboxInfo struct {
 }
 
 type MessageClientHeader struct {
-	Conv         ConversationIDTriple     `codec:"conv" json:"conv"`
-	TlfName      string                   `codec:"tlfName" json:"tlfName"`
-	TlfPublic    bool                     `codec:"tlfPublic" json:"tlfPublic"`
-	MessageType  MessageType              `codec:"messageType" json:"messageType"`
-	Supersedes   MessageID                `codec:"supersedes" json:"supersedes"`
-	Deletes      []MessageID              `codec:"deletes" json:"deletes"`
-	Prev         []MessagePreviousPointer `codec:"prev" json:"prev"`
-	Sender       gregor1.UID              `codec:"sender" json:"sender"`
-	SenderDevice gregor1.DeviceID         `codec:"senderDevice" json:"senderDevice"`
-	MerkleRoot   *MerkleRoot              `codec:"merkleRoot,omitempty" json:"merkleRoot,omitempty"`
-	OutboxID     *OutboxID                `codec:"outboxID,omitempty" json:"outboxID,omitempty"`
-	OutboxInfo   *OutboxInfo              `codec:"outboxInfo,omitempty" json:"outboxInfo,omitempty"`
+	Conv              ConversationIDTriple     `codec:"conv" json:"conv"`
+	TlfName           string                   `codec:"tlfName" json:"tlfName"`
+	TlfPublic         bool                     `codec:"tlfPublic" json:"tlfPublic"`
+	MessageType       MessageType              `codec:"messageType" json:"messageType"`
+	Supersedes        MessageID                `codec:"supersedes" json:"supersedes"`
+	Deletes           []MessageID              `codec:"deletes" json:"deletes"`
+	Prev              []MessagePreviousPointer `codec:"prev" json:"prev"`
+	Sender            gregor1.UID              `codec:"sender" json:"sender"`
+	SenderDevice      gregor1.DeviceID         `codec:"senderDevice" json:"senderDevice"`
+	MerkleRoot        *MerkleRoot              `codec:"merkleRoot,omitempty" json:"merkleRoot,omitempty"`
+	OutboxID          *OutboxID                `codec:"outboxID,omitempty" json:"outboxID,omitempty"`
+	OutboxInfo        *OutboxInfo              `codec:"outboxInfo,omitempty" json:"outboxInfo,omitempty"`
+	EphemeralLifetime *int                     `codec:"ephemeralLifetime,omitempty" json:"ephemeralLifetime,omitempty"`
+	AtMentions        []string                 `codec:"atMentions" json:"atMentions"`
+	AtMentionsChannel bool                     `codec:"atMentionsChannel" json:"atMentionsChannel"`
 }
 
 type MessageClientHeaderVerified struct {
-	Conv         ConversationIDTriple     `codec:"conv" json:"conv"`
-	TlfName      string                   `codec:"tlfName" json:"tlfName"`
-	TlfPublic    bool                     `codec:"tlfPublic" json:"tlfPublic"`
-	MessageType  MessageType              `codec:"messageType" json:"messageType"`
-	Prev         []MessagePreviousPointer `codec:"prev" json:"prev"`
-	Sender       gregor1.UID              `codec:"sender" json:"sender"`
-	SenderDevice gregor1.DeviceID         `codec:"senderDevice" json:"senderDevice"`
-	OutboxID     *OutboxID                `codec:"outboxID,omitempty" json:"outboxID,omitempty"`
-	OutboxInfo   *OutboxInfo              `codec:"outboxInfo,omitempty" json:"outboxInfo,omitempty"`
+	Conv              ConversationIDTriple     `codec:"conv" json:"conv"`
+	TlfName           string                   `codec:"tlfName" json:"tlfName"`
+	TlfPublic         bool                     `codec:"tlfPublic" json:"tlfPublic"`
+	MessageType       MessageType              `codec:"messageType" json:"messageType"`
+	Prev              []MessagePreviousPointer `codec:"prev" json:"prev"`
+	Sender            gregor1.UID              `codec:"sender" json:"sender"`
+	SenderDevice      gregor1.DeviceID         `codec:"senderDevice" json:"senderDevice"`
+	OutboxID          *OutboxID                `codec:"outboxID,omitempty" json:"outboxID,omitempty"`
+	OutboxInfo        *OutboxInfo              `codec:"outboxInfo,omitempty" json:"outboxInfo,omitempty"`
+	EphemeralLifetime *int                     `codec:"ephemeralLifetime,omitempty" json:"ephemeralLifetime,omitempty"`
+	AtMentions        []string                 `codec:"atMentions" json:"atMentions"`
+	AtMentionsChannel bool                     `codec:"atMentionsChannel" json:"atMentionsChannel"`
 }
 
 type EncryptedData struct {
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/keybase/client/go/protocol/gregor1"
 )
@@ -159,6 +160,30 @@ func (m MessageUnboxed) IsValid() bool {
 	return false
 }
 
+// IsEphemeral returns whether this header carries a message that should
+// explode out of local storage some number of seconds after it was sent.
+func (h MessageClientHeaderVerified) IsEphemeral() bool {
+	return h.EphemeralLifetime != nil && *h.EphemeralLifetime > 0
+}
+
+// Etime returns the time at which a valid message's body should be purged
+// from local storage, given the ctime the server assigned it. The zero
+// value is returned if the message is not ephemeral.
+func (m MessageUnboxedValid) Etime() (etime time.Time, ok bool) {
+	if !m.ClientHeader.IsEphemeral() {
+		return time.Time{}, false
+	}
+	lifetime := time.Duration(*m.ClientHeader.EphemeralLifetime) * time.Second
+	return m.ServerHeader.Ctime.Time().Add(lifetime), true
+}
+
+// IsEphemeralExpired returns whether a valid ephemeral message's body should
+// have already been purged from local storage as of the given time.
+func (m MessageUnboxedValid) IsEphemeralExpired(now time.Time) bool {
+	etime, ok := m.Etime()
+	return ok && !now.Before(etime)
+}
+
 func (m MessageBoxed) GetMessageID() MessageID {
 	return m.ServerHeader.MessageID
 }
@@ -4,6 +4,7 @@
 package chat1
 
 import (
+	gregor1 "github.com/keybase/client/go/protocol/gregor1"
 	"github.com/keybase/go-framed-msgpack-rpc/rpc"
 )
 
@@ -58,6 +59,15 @@ type TLFResolveUpdate struct {
 	InboxVers InboxVers      `codec:"inboxVers" json:"inboxVers"`
 }
 
+// TypingUpdate is the OOBM payload for the "chat.typing" system. The
+// sending user's UID comes from the gregor OutOfBandMessage envelope,
+// not this payload.
+type TypingUpdate struct {
+	ConvID   ConversationID   `codec:"convID" json:"convID"`
+	DeviceID gregor1.DeviceID `codec:"deviceID" json:"deviceID"`
+	Typing   bool             `codec:"typing" json:"typing"`
+}
+
 type GregorInterface interface {
 }
 
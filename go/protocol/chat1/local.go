@@ -28,6 +28,21 @@ type MessageDelete struct {
 	MessageIDs []MessageID `codec:"messageIDs" json:"messageIDs"`
 }
 
+type MessageReaction struct {
+	MessageID MessageID `codec:"messageID" json:"messageID"`
+	Body      string    `codec:"body" json:"body"`
+}
+
+type ReactionUpdate struct {
+	Body          string    `codec:"body" json:"body"`
+	Username      string    `codec:"username" json:"username"`
+	ReactionMsgID MessageID `codec:"reactionMsgID" json:"reactionMsgID"`
+}
+
+type ReactionMap struct {
+	Reactions []ReactionUpdate `codec:"reactions" json:"reactions"`
+}
+
 type MessageHeadline struct {
 	Headline string `codec:"headline" json:"headline"`
 }
@@ -204,6 +219,7 @@ type MessageBody struct {
 	Metadata__           *MessageConversationMetadata `codec:"metadata,omitempty" json:"metadata,omitempty"`
 	Headline__           *MessageHeadline             `codec:"headline,omitempty" json:"headline,omitempty"`
 	Attachmentuploaded__ *MessageAttachmentUploaded   `codec:"attachmentuploaded,omitempty" json:"attachmentuploaded,omitempty"`
+	Reaction__           *MessageReaction             `codec:"reaction,omitempty" json:"reaction,omitempty"`
 }
 
 func (o *MessageBody) MessageType() (ret MessageType, err error) {
@@ -243,6 +259,11 @@ func (o *MessageBody) MessageType() (ret MessageType, err error) {
 			err = errors.New("unexpected nil value for Attachmentuploaded__")
 			return ret, err
 		}
+	case MessageType_REACTION:
+		if o.Reaction__ == nil {
+			err = errors.New("unexpected nil value for Reaction__")
+			return ret, err
+		}
 	}
 	return o.MessageType__, nil
 }
@@ -317,6 +338,16 @@ func (o MessageBody) Attachmentuploaded() MessageAttachmentUploaded {
 	return *o.Attachmentuploaded__
 }
 
+func (o MessageBody) Reaction() MessageReaction {
+	if o.MessageType__ != MessageType_REACTION {
+		panic("wrong case accessed")
+	}
+	if o.Reaction__ == nil {
+		return MessageReaction{}
+	}
+	return *o.Reaction__
+}
+
 func NewMessageBodyWithText(v MessageText) MessageBody {
 	return MessageBody{
 		MessageType__: MessageType_TEXT,
@@ -345,6 +376,13 @@ func NewMessageBodyWithDelete(v MessageDelete) MessageBody {
 	}
 }
 
+func NewMessageBodyWithReaction(v MessageReaction) MessageBody {
+	return MessageBody{
+		MessageType__: MessageType_REACTION,
+		Reaction__:    &v,
+	}
+}
+
 func NewMessageBodyWithMetadata(v MessageConversationMetadata) MessageBody {
 	return MessageBody{
 		MessageType__: MessageType_METADATA,
@@ -554,17 +592,20 @@ type HeaderPlaintextUnsupported struct {
 }
 
 type HeaderPlaintextV1 struct {
-	Conv            ConversationIDTriple     `codec:"conv" json:"conv"`
-	TlfName         string                   `codec:"tlfName" json:"tlfName"`
-	TlfPublic       bool                     `codec:"tlfPublic" json:"tlfPublic"`
-	MessageType     MessageType              `codec:"messageType" json:"messageType"`
-	Prev            []MessagePreviousPointer `codec:"prev" json:"prev"`
-	Sender          gregor1.UID              `codec:"sender" json:"sender"`
-	SenderDevice    gregor1.DeviceID         `codec:"senderDevice" json:"senderDevice"`
-	BodyHash        Hash                     `codec:"bodyHash" json:"bodyHash"`
-	OutboxInfo      *OutboxInfo              `codec:"outboxInfo,omitempty" json:"outboxInfo,omitempty"`
-	OutboxID        *OutboxID                `codec:"outboxID,omitempty" json:"outboxID,omitempty"`
-	HeaderSignature *SignatureInfo           `codec:"headerSignature,omitempty" json:"headerSignature,omitempty"`
+	Conv              ConversationIDTriple     `codec:"conv" json:"conv"`
+	TlfName           string                   `codec:"tlfName" json:"tlfName"`
+	TlfPublic         bool                     `codec:"tlfPublic" json:"tlfPublic"`
+	MessageType       MessageType              `codec:"messageType" json:"messageType"`
+	Prev              []MessagePreviousPointer `codec:"prev" json:"prev"`
+	Sender            gregor1.UID              `codec:"sender" json:"sender"`
+	SenderDevice      gregor1.DeviceID         `codec:"senderDevice" json:"senderDevice"`
+	BodyHash          Hash                     `codec:"bodyHash" json:"bodyHash"`
+	OutboxInfo        *OutboxInfo              `codec:"outboxInfo,omitempty" json:"outboxInfo,omitempty"`
+	OutboxID          *OutboxID                `codec:"outboxID,omitempty" json:"outboxID,omitempty"`
+	EphemeralLifetime *int                     `codec:"ephemeralLifetime,omitempty" json:"ephemeralLifetime,omitempty"`
+	AtMentions        []string                 `codec:"atMentions" json:"atMentions"`
+	AtMentionsChannel bool                     `codec:"atMentionsChannel" json:"atMentionsChannel"`
+	HeaderSignature   *SignatureInfo           `codec:"headerSignature,omitempty" json:"headerSignature,omitempty"`
 }
 
 type HeaderPlaintext struct {
@@ -1151,6 +1192,7 @@ type MessageUnboxedValid struct {
 	HeaderSignature       *SignatureInfo              `codec:"headerSignature,omitempty" json:"headerSignature,omitempty"`
 	VerificationKey       *[]byte                     `codec:"verificationKey,omitempty" json:"verificationKey,omitempty"`
 	SenderDeviceRevokedAt *gregor1.Time               `codec:"senderDeviceRevokedAt,omitempty" json:"senderDeviceRevokedAt,omitempty"`
+	Reactions             ReactionMap                 `codec:"reactions" json:"reactions"`
 }
 
 type MessageUnboxedErrorType int
@@ -1426,6 +1468,16 @@ type SetConversationStatusLocalRes struct {
 	IdentifyFailures []keybase1.TLFIdentifyFailure `codec:"identifyFailures" json:"identifyFailures"`
 }
 
+type SetConversationRetentionLocalRes struct {
+	RateLimits       []RateLimit                   `codec:"rateLimits" json:"rateLimits"`
+	IdentifyFailures []keybase1.TLFIdentifyFailure `codec:"identifyFailures" json:"identifyFailures"`
+}
+
+type ReportConversationLocalRes struct {
+	RateLimits       []RateLimit                   `codec:"rateLimits" json:"rateLimits"`
+	IdentifyFailures []keybase1.TLFIdentifyFailure `codec:"identifyFailures" json:"identifyFailures"`
+}
+
 type NewConversationLocalRes struct {
 	Conv             ConversationLocal             `codec:"conv" json:"conv"`
 	RateLimits       []RateLimit                   `codec:"rateLimits" json:"rateLimits"`
@@ -1557,12 +1609,37 @@ type PostEditNonblockArg struct {
 	IdentifyBehavior keybase1.TLFIdentifyBehavior `codec:"identifyBehavior" json:"identifyBehavior"`
 }
 
+type PostReactionNonblockArg struct {
+	ConversationID   ConversationID               `codec:"conversationID" json:"conversationID"`
+	Conv             ConversationIDTriple         `codec:"conv" json:"conv"`
+	TlfName          string                       `codec:"tlfName" json:"tlfName"`
+	TlfPublic        bool                         `codec:"tlfPublic" json:"tlfPublic"`
+	Supersedes       MessageID                    `codec:"supersedes" json:"supersedes"`
+	Body             string                       `codec:"body" json:"body"`
+	ClientPrev       MessageID                    `codec:"clientPrev" json:"clientPrev"`
+	IdentifyBehavior keybase1.TLFIdentifyBehavior `codec:"identifyBehavior" json:"identifyBehavior"`
+}
+
 type SetConversationStatusLocalArg struct {
 	ConversationID   ConversationID               `codec:"conversationID" json:"conversationID"`
 	Status           ConversationStatus           `codec:"status" json:"status"`
 	IdentifyBehavior keybase1.TLFIdentifyBehavior `codec:"identifyBehavior" json:"identifyBehavior"`
 }
 
+type SetConversationRetentionLocalArg struct {
+	ConversationID   ConversationID               `codec:"conversationID" json:"conversationID"`
+	Policy           RetentionPolicy              `codec:"policy" json:"policy"`
+	WholeTLF         bool                         `codec:"wholeTLF" json:"wholeTLF"`
+	IdentifyBehavior keybase1.TLFIdentifyBehavior `codec:"identifyBehavior" json:"identifyBehavior"`
+}
+
+type ReportConversationLocalArg struct {
+	ConversationID   ConversationID               `codec:"conversationID" json:"conversationID"`
+	Reason           ReportConversationReason     `codec:"reason" json:"reason"`
+	Note             string                       `codec:"note" json:"note"`
+	IdentifyBehavior keybase1.TLFIdentifyBehavior `codec:"identifyBehavior" json:"identifyBehavior"`
+}
+
 type NewConversationLocalArg struct {
 	TlfName          string                       `codec:"tlfName" json:"tlfName"`
 	TopicType        TopicType                    `codec:"topicType" json:"topicType"`
@@ -1640,6 +1717,14 @@ type MarkAsReadLocalArg struct {
 	MsgID          MessageID      `codec:"msgID" json:"msgID"`
 }
 
+type StartTypingArg struct {
+	ConversationID ConversationID `codec:"conversationID" json:"conversationID"`
+}
+
+type StopTypingArg struct {
+	ConversationID ConversationID `codec:"conversationID" json:"conversationID"`
+}
+
 type FindConversationsLocalArg struct {
 	TlfName          string                       `codec:"tlfName" json:"tlfName"`
 	Visibility       TLFVisibility                `codec:"visibility" json:"visibility"`
@@ -1649,6 +1734,52 @@ type FindConversationsLocalArg struct {
 	IdentifyBehavior keybase1.TLFIdentifyBehavior `codec:"identifyBehavior" json:"identifyBehavior"`
 }
 
+type ListOutboxRes struct {
+	Records []OutboxRecord `codec:"records" json:"records"`
+}
+
+type ListOutboxArg struct {
+}
+
+type ChatSearchHit struct {
+	HitMessage    MessageUnboxed   `codec:"hitMessage" json:"hitMessage"`
+	BeforeContext []MessageUnboxed `codec:"beforeContext" json:"beforeContext"`
+	AfterContext  []MessageUnboxed `codec:"afterContext" json:"afterContext"`
+}
+
+type ChatSearchInboxHit struct {
+	ConvID  ConversationID  `codec:"convID" json:"convID"`
+	TlfName string          `codec:"tlfName" json:"tlfName"`
+	Hits    []ChatSearchHit `codec:"hits" json:"hits"`
+}
+
+type SearchInboxRes struct {
+	Conversations []ChatSearchInboxHit `codec:"conversations" json:"conversations"`
+}
+
+type SearchInboxArg struct {
+	Query            string                       `codec:"query" json:"query"`
+	ConvID           *ConversationID              `codec:"convID,omitempty" json:"convID,omitempty"`
+	MaxHits          int                          `codec:"maxHits" json:"maxHits"`
+	BeforeContext    int                          `codec:"beforeContext" json:"beforeContext"`
+	AfterContext     int                          `codec:"afterContext" json:"afterContext"`
+	IdentifyBehavior keybase1.TLFIdentifyBehavior `codec:"identifyBehavior" json:"identifyBehavior"`
+}
+
+// MobilePushNotificationPreview is a short, decrypted preview of a single
+// message, meant for display in a mobile OS notification banner.
+type MobilePushNotificationPreview struct {
+	SenderUsername string `codec:"senderUsername" json:"senderUsername"`
+	Body           string `codec:"body" json:"body"`
+	IsPlaintext    bool   `codec:"isPlaintext" json:"isPlaintext"`
+}
+
+type UnboxMobilePushNotificationArg struct {
+	ConvID           ConversationID               `codec:"convID" json:"convID"`
+	MsgID            MessageID                    `codec:"msgID" json:"msgID"`
+	IdentifyBehavior keybase1.TLFIdentifyBehavior `codec:"identifyBehavior" json:"identifyBehavior"`
+}
+
 type LocalInterface interface {
 	GetThreadLocal(context.Context, GetThreadLocalArg) (GetThreadLocalRes, error)
 	GetInboxAndUnboxLocal(context.Context, GetInboxAndUnboxLocalArg) (GetInboxAndUnboxLocalRes, error)
@@ -1658,7 +1789,10 @@ type LocalInterface interface {
 	PostTextNonblock(context.Context, PostTextNonblockArg) (PostLocalNonblockRes, error)
 	PostDeleteNonblock(context.Context, PostDeleteNonblockArg) (PostLocalNonblockRes, error)
 	PostEditNonblock(context.Context, PostEditNonblockArg) (PostLocalNonblockRes, error)
+	PostReactionNonblock(context.Context, PostReactionNonblockArg) (PostLocalNonblockRes, error)
 	SetConversationStatusLocal(context.Context, SetConversationStatusLocalArg) (SetConversationStatusLocalRes, error)
+	SetConversationRetentionLocal(context.Context, SetConversationRetentionLocalArg) (SetConversationRetentionLocalRes, error)
+	ReportConversationLocal(context.Context, ReportConversationLocalArg) (ReportConversationLocalRes, error)
 	NewConversationLocal(context.Context, NewConversationLocalArg) (NewConversationLocalRes, error)
 	GetInboxSummaryForCLILocal(context.Context, GetInboxSummaryForCLILocalQuery) (GetInboxSummaryForCLILocalRes, error)
 	GetConversationForCLILocal(context.Context, GetConversationForCLILocalQuery) (GetConversationForCLILocalRes, error)
@@ -1670,7 +1804,12 @@ type LocalInterface interface {
 	CancelPost(context.Context, OutboxID) error
 	RetryPost(context.Context, OutboxID) error
 	MarkAsReadLocal(context.Context, MarkAsReadLocalArg) (MarkAsReadRes, error)
+	StartTyping(context.Context, ConversationID) error
+	StopTyping(context.Context, ConversationID) error
 	FindConversationsLocal(context.Context, FindConversationsLocalArg) (FindConversationsLocalRes, error)
+	ListOutbox(context.Context) (ListOutboxRes, error)
+	SearchInbox(context.Context, SearchInboxArg) (SearchInboxRes, error)
+	UnboxMobilePushNotification(context.Context, UnboxMobilePushNotificationArg) (MobilePushNotificationPreview, error)
 }
 
 func LocalProtocol(i LocalInterface) rpc.Protocol {
@@ -1805,6 +1944,22 @@ func LocalProtocol(i LocalInterface) rpc.Protocol {
 				},
 				MethodType: rpc.MethodCall,
 			},
+			"postReactionNonblock": {
+				MakeArg: func() interface{} {
+					ret := make([]PostReactionNonblockArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]PostReactionNonblockArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]PostReactionNonblockArg)(nil), args)
+						return
+					}
+					ret, err = i.PostReactionNonblock(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
 			"SetConversationStatusLocal": {
 				MakeArg: func() interface{} {
 					ret := make([]SetConversationStatusLocalArg, 1)
@@ -1821,6 +1976,38 @@ func LocalProtocol(i LocalInterface) rpc.Protocol {
 				},
 				MethodType: rpc.MethodCall,
 			},
+			"setConversationRetentionLocal": {
+				MakeArg: func() interface{} {
+					ret := make([]SetConversationRetentionLocalArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]SetConversationRetentionLocalArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]SetConversationRetentionLocalArg)(nil), args)
+						return
+					}
+					ret, err = i.SetConversationRetentionLocal(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
+			"ReportConversationLocal": {
+				MakeArg: func() interface{} {
+					ret := make([]ReportConversationLocalArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]ReportConversationLocalArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]ReportConversationLocalArg)(nil), args)
+						return
+					}
+					ret, err = i.ReportConversationLocal(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
 			"newConversationLocal": {
 				MakeArg: func() interface{} {
 					ret := make([]NewConversationLocalArg, 1)
@@ -1997,6 +2184,38 @@ func LocalProtocol(i LocalInterface) rpc.Protocol {
 				},
 				MethodType: rpc.MethodCall,
 			},
+			"StartTyping": {
+				MakeArg: func() interface{} {
+					ret := make([]StartTypingArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]StartTypingArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]StartTypingArg)(nil), args)
+						return
+					}
+					err = i.StartTyping(ctx, (*typedArgs)[0].ConversationID)
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
+			"StopTyping": {
+				MakeArg: func() interface{} {
+					ret := make([]StopTypingArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]StopTypingArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]StopTypingArg)(nil), args)
+						return
+					}
+					err = i.StopTyping(ctx, (*typedArgs)[0].ConversationID)
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
 			"findConversationsLocal": {
 				MakeArg: func() interface{} {
 					ret := make([]FindConversationsLocalArg, 1)
@@ -2013,6 +2232,49 @@ func LocalProtocol(i LocalInterface) rpc.Protocol {
 				},
 				MethodType: rpc.MethodCall,
 			},
+			"listOutbox": {
+				MakeArg: func() interface{} {
+					ret := make([]ListOutboxArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					ret, err = i.ListOutbox(ctx)
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
+			"searchInbox": {
+				MakeArg: func() interface{} {
+					ret := make([]SearchInboxArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]SearchInboxArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]SearchInboxArg)(nil), args)
+						return
+					}
+					ret, err = i.SearchInbox(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
+			"UnboxMobilePushNotification": {
+				MakeArg: func() interface{} {
+					ret := make([]UnboxMobilePushNotificationArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]UnboxMobilePushNotificationArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]UnboxMobilePushNotificationArg)(nil), args)
+						return
+					}
+					ret, err = i.UnboxMobilePushNotification(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
 		},
 	}
 }
@@ -2061,11 +2323,26 @@ func (c LocalClient) PostEditNonblock(ctx context.Context, __arg PostEditNonbloc
 	return
 }
 
+func (c LocalClient) PostReactionNonblock(ctx context.Context, __arg PostReactionNonblockArg) (res PostLocalNonblockRes, err error) {
+	err = c.Cli.Call(ctx, "chat.1.local.postReactionNonblock", []interface{}{__arg}, &res)
+	return
+}
+
 func (c LocalClient) SetConversationStatusLocal(ctx context.Context, __arg SetConversationStatusLocalArg) (res SetConversationStatusLocalRes, err error) {
 	err = c.Cli.Call(ctx, "chat.1.local.SetConversationStatusLocal", []interface{}{__arg}, &res)
 	return
 }
 
+func (c LocalClient) SetConversationRetentionLocal(ctx context.Context, __arg SetConversationRetentionLocalArg) (res SetConversationRetentionLocalRes, err error) {
+	err = c.Cli.Call(ctx, "chat.1.local.setConversationRetentionLocal", []interface{}{__arg}, &res)
+	return
+}
+
+func (c LocalClient) ReportConversationLocal(ctx context.Context, __arg ReportConversationLocalArg) (res ReportConversationLocalRes, err error) {
+	err = c.Cli.Call(ctx, "chat.1.local.ReportConversationLocal", []interface{}{__arg}, &res)
+	return
+}
+
 func (c LocalClient) NewConversationLocal(ctx context.Context, __arg NewConversationLocalArg) (res NewConversationLocalRes, err error) {
 	err = c.Cli.Call(ctx, "chat.1.local.newConversationLocal", []interface{}{__arg}, &res)
 	return
@@ -2125,7 +2402,34 @@ func (c LocalClient) MarkAsReadLocal(ctx context.Context, __arg MarkAsReadLocalA
 	return
 }
 
+func (c LocalClient) StartTyping(ctx context.Context, conversationID ConversationID) (err error) {
+	__arg := StartTypingArg{ConversationID: conversationID}
+	err = c.Cli.Call(ctx, "chat.1.local.StartTyping", []interface{}{__arg}, nil)
+	return
+}
+
+func (c LocalClient) StopTyping(ctx context.Context, conversationID ConversationID) (err error) {
+	__arg := StopTypingArg{ConversationID: conversationID}
+	err = c.Cli.Call(ctx, "chat.1.local.StopTyping", []interface{}{__arg}, nil)
+	return
+}
+
+func (c LocalClient) ListOutbox(ctx context.Context) (res ListOutboxRes, err error) {
+	err = c.Cli.Call(ctx, "chat.1.local.listOutbox", []interface{}{ListOutboxArg{}}, &res)
+	return
+}
+
 func (c LocalClient) FindConversationsLocal(ctx context.Context, __arg FindConversationsLocalArg) (res FindConversationsLocalRes, err error) {
 	err = c.Cli.Call(ctx, "chat.1.local.findConversationsLocal", []interface{}{__arg}, &res)
 	return
 }
+
+func (c LocalClient) SearchInbox(ctx context.Context, __arg SearchInboxArg) (res SearchInboxRes, err error) {
+	err = c.Cli.Call(ctx, "chat.1.local.searchInbox", []interface{}{__arg}, &res)
+	return
+}
+
+func (c LocalClient) UnboxMobilePushNotification(ctx context.Context, __arg UnboxMobilePushNotificationArg) (res MobilePushNotificationPreview, err error) {
+	err = c.Cli.Call(ctx, "chat.1.local.UnboxMobilePushNotification", []interface{}{__arg}, &res)
+	return
+}
@@ -73,6 +73,25 @@ type FailedMessageInfo struct {
 	OutboxRecords []OutboxRecord `codec:"outboxRecords" json:"outboxRecords"`
 }
 
+// ConvTypingUpdate carries the current set of users typing in a single
+// conversation. An empty uids list means everyone has stopped.
+type ConvTypingUpdate struct {
+	ConvID ConversationID `codec:"convID" json:"convID"`
+	Uids   []keybase1.UID `codec:"uids" json:"uids"`
+}
+
+// TLFIdentifyProgress reports that a single participant of tlfName has
+// finished identifying, so a GUI resolving a TLF with many participants
+// can show progress instead of going silent until every participant is
+// done. It's sent once per participant, in whatever order KBFS reports
+// them back to CryptKeys -- there's no partial result within a single
+// participant's own identify.
+type TLFIdentifyProgress struct {
+	TlfName  keybase1.CanonicalTlfName     `codec:"tlfName" json:"tlfName"`
+	Username string                        `codec:"username" json:"username"`
+	Breaks   *keybase1.IdentifyTrackBreaks `codec:"breaks,omitempty" json:"breaks,omitempty"`
+}
+
 type ChatActivity struct {
 	ActivityType__    ChatActivityType     `codec:"activityType" json:"activityType"`
 	IncomingMessage__ *IncomingMessage     `codec:"incomingMessage,omitempty" json:"incomingMessage,omitempty"`
@@ -207,6 +226,10 @@ type ChatIdentifyUpdateArg struct {
 	Update keybase1.CanonicalTLFNameAndIDWithBreaks `codec:"update" json:"update"`
 }
 
+type ChatTLFIdentifyProgressArg struct {
+	Progress TLFIdentifyProgress `codec:"progress" json:"progress"`
+}
+
 type ChatTLFFinalizeArg struct {
 	Uid          keybase1.UID             `codec:"uid" json:"uid"`
 	ConvID       ConversationID           `codec:"convID" json:"convID"`
@@ -229,13 +252,26 @@ type ChatThreadsStaleArg struct {
 	ConvIDs []ConversationID `codec:"convIDs" json:"convIDs"`
 }
 
+type ChatTypingUpdateArg struct {
+	TypingUpdates []ConvTypingUpdate `codec:"typingUpdates" json:"typingUpdates"`
+}
+
+type ChatAttentionUpdateArg struct {
+	Uid    keybase1.UID   `codec:"uid" json:"uid"`
+	ConvID ConversationID `codec:"convID" json:"convID"`
+	MsgID  MessageID      `codec:"msgID" json:"msgID"`
+}
+
 type NotifyChatInterface interface {
 	NewChatActivity(context.Context, NewChatActivityArg) error
 	ChatIdentifyUpdate(context.Context, keybase1.CanonicalTLFNameAndIDWithBreaks) error
+	ChatTLFIdentifyProgress(context.Context, TLFIdentifyProgress) error
 	ChatTLFFinalize(context.Context, ChatTLFFinalizeArg) error
 	ChatTLFResolve(context.Context, ChatTLFResolveArg) error
 	ChatInboxStale(context.Context, keybase1.UID) error
 	ChatThreadsStale(context.Context, ChatThreadsStaleArg) error
+	ChatTypingUpdate(context.Context, []ConvTypingUpdate) error
+	ChatAttentionUpdate(context.Context, ChatAttentionUpdateArg) error
 }
 
 func NotifyChatProtocol(i NotifyChatInterface) rpc.Protocol {
@@ -274,6 +310,22 @@ func NotifyChatProtocol(i NotifyChatInterface) rpc.Protocol {
 				},
 				MethodType: rpc.MethodNotify,
 			},
+			"ChatTLFIdentifyProgress": {
+				MakeArg: func() interface{} {
+					ret := make([]ChatTLFIdentifyProgressArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]ChatTLFIdentifyProgressArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]ChatTLFIdentifyProgressArg)(nil), args)
+						return
+					}
+					err = i.ChatTLFIdentifyProgress(ctx, (*typedArgs)[0].Progress)
+					return
+				},
+				MethodType: rpc.MethodNotify,
+			},
 			"ChatTLFFinalize": {
 				MakeArg: func() interface{} {
 					ret := make([]ChatTLFFinalizeArg, 1)
@@ -338,6 +390,38 @@ func NotifyChatProtocol(i NotifyChatInterface) rpc.Protocol {
 				},
 				MethodType: rpc.MethodNotify,
 			},
+			"ChatTypingUpdate": {
+				MakeArg: func() interface{} {
+					ret := make([]ChatTypingUpdateArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]ChatTypingUpdateArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]ChatTypingUpdateArg)(nil), args)
+						return
+					}
+					err = i.ChatTypingUpdate(ctx, (*typedArgs)[0].TypingUpdates)
+					return
+				},
+				MethodType: rpc.MethodNotify,
+			},
+			"ChatAttentionUpdate": {
+				MakeArg: func() interface{} {
+					ret := make([]ChatAttentionUpdateArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]ChatAttentionUpdateArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]ChatAttentionUpdateArg)(nil), args)
+						return
+					}
+					err = i.ChatAttentionUpdate(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodNotify,
+			},
 		},
 	}
 }
@@ -357,6 +441,12 @@ func (c NotifyChatClient) ChatIdentifyUpdate(ctx context.Context, update keybase
 	return
 }
 
+func (c NotifyChatClient) ChatTLFIdentifyProgress(ctx context.Context, progress TLFIdentifyProgress) (err error) {
+	__arg := ChatTLFIdentifyProgressArg{Progress: progress}
+	err = c.Cli.Notify(ctx, "chat.1.NotifyChat.ChatTLFIdentifyProgress", []interface{}{__arg})
+	return
+}
+
 func (c NotifyChatClient) ChatTLFFinalize(ctx context.Context, __arg ChatTLFFinalizeArg) (err error) {
 	err = c.Cli.Notify(ctx, "chat.1.NotifyChat.ChatTLFFinalize", []interface{}{__arg})
 	return
@@ -377,3 +467,14 @@ func (c NotifyChatClient) ChatThreadsStale(ctx context.Context, __arg ChatThread
 	err = c.Cli.Notify(ctx, "chat.1.NotifyChat.ChatThreadsStale", []interface{}{__arg})
 	return
 }
+
+func (c NotifyChatClient) ChatTypingUpdate(ctx context.Context, typingUpdates []ConvTypingUpdate) (err error) {
+	__arg := ChatTypingUpdateArg{TypingUpdates: typingUpdates}
+	err = c.Cli.Notify(ctx, "chat.1.NotifyChat.ChatTypingUpdate", []interface{}{__arg})
+	return
+}
+
+func (c NotifyChatClient) ChatAttentionUpdate(ctx context.Context, __arg ChatAttentionUpdateArg) (err error) {
+	err = c.Cli.Notify(ctx, "chat.1.NotifyChat.ChatAttentionUpdate", []interface{}{__arg})
+	return
+}
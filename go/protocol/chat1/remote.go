@@ -95,6 +95,38 @@ type SetConversationStatusRes struct {
 	RateLimit *RateLimit `codec:"rateLimit,omitempty" json:"rateLimit,omitempty"`
 }
 
+type SetConversationRetentionRes struct {
+	RateLimit *RateLimit `codec:"rateLimit,omitempty" json:"rateLimit,omitempty"`
+}
+
+type ReportConversationReason int
+
+const (
+	ReportConversationReason_SPAM  ReportConversationReason = 0
+	ReportConversationReason_OTHER ReportConversationReason = 1
+)
+
+var ReportConversationReasonMap = map[string]ReportConversationReason{
+	"SPAM":  0,
+	"OTHER": 1,
+}
+
+var ReportConversationReasonRevMap = map[ReportConversationReason]string{
+	0: "SPAM",
+	1: "OTHER",
+}
+
+func (e ReportConversationReason) String() string {
+	if v, ok := ReportConversationReasonRevMap[e]; ok {
+		return v
+	}
+	return ""
+}
+
+type ReportConversationRes struct {
+	RateLimit *RateLimit `codec:"rateLimit,omitempty" json:"rateLimit,omitempty"`
+}
+
 type GetPublicConversationsRes struct {
 	Conversations []Conversation `codec:"conversations" json:"conversations"`
 	RateLimit     *RateLimit     `codec:"rateLimit,omitempty" json:"rateLimit,omitempty"`
@@ -240,6 +272,17 @@ type SetConversationStatusArg struct {
 	Status         ConversationStatus `codec:"status" json:"status"`
 }
 
+type SetConversationRetentionArg struct {
+	ConversationID ConversationID  `codec:"conversationID" json:"conversationID"`
+	Policy         RetentionPolicy `codec:"policy" json:"policy"`
+}
+
+type ReportConversationArg struct {
+	ConversationID ConversationID           `codec:"conversationID" json:"conversationID"`
+	Reason         ReportConversationReason `codec:"reason" json:"reason"`
+	Note           string                   `codec:"note" json:"note"`
+}
+
 type GetUnreadUpdateFullArg struct {
 	InboxVers InboxVers `codec:"inboxVers" json:"inboxVers"`
 }
@@ -275,6 +318,11 @@ type TlfResolveArg struct {
 	ResolvedReaders []gregor1.UID `codec:"resolvedReaders" json:"resolvedReaders"`
 }
 
+type UpdateTypingRemoteArg struct {
+	ConvID ConversationID `codec:"convID" json:"convID"`
+	Typing bool           `codec:"typing" json:"typing"`
+}
+
 type PublishReadMessageArg struct {
 	Uid    gregor1.UID    `codec:"uid" json:"uid"`
 	ConvID ConversationID `codec:"convID" json:"convID"`
@@ -297,6 +345,8 @@ type RemoteInterface interface {
 	GetMessagesRemote(context.Context, GetMessagesRemoteArg) (GetMessagesRemoteRes, error)
 	MarkAsRead(context.Context, MarkAsReadArg) (MarkAsReadRes, error)
 	SetConversationStatus(context.Context, SetConversationStatusArg) (SetConversationStatusRes, error)
+	SetConversationRetention(context.Context, SetConversationRetentionArg) (SetConversationRetentionRes, error)
+	ReportConversation(context.Context, ReportConversationArg) (ReportConversationRes, error)
 	GetUnreadUpdateFull(context.Context, InboxVers) (UnreadUpdateFull, error)
 	GetS3Params(context.Context, ConversationID) (S3Params, error)
 	S3Sign(context.Context, S3SignArg) ([]byte, error)
@@ -304,6 +354,7 @@ type RemoteInterface interface {
 	SyncInbox(context.Context, InboxVers) (SyncInboxRes, error)
 	TlfFinalize(context.Context, TlfFinalizeArg) error
 	TlfResolve(context.Context, TlfResolveArg) error
+	UpdateTypingRemote(context.Context, UpdateTypingRemoteArg) error
 	PublishReadMessage(context.Context, PublishReadMessageArg) error
 	PublishSetConversationStatus(context.Context, PublishSetConversationStatusArg) error
 }
@@ -456,6 +507,38 @@ func RemoteProtocol(i RemoteInterface) rpc.Protocol {
 				},
 				MethodType: rpc.MethodCall,
 			},
+			"SetConversationRetention": {
+				MakeArg: func() interface{} {
+					ret := make([]SetConversationRetentionArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]SetConversationRetentionArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]SetConversationRetentionArg)(nil), args)
+						return
+					}
+					ret, err = i.SetConversationRetention(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
+			"ReportConversation": {
+				MakeArg: func() interface{} {
+					ret := make([]ReportConversationArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]ReportConversationArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]ReportConversationArg)(nil), args)
+						return
+					}
+					ret, err = i.ReportConversation(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
 			"GetUnreadUpdateFull": {
 				MakeArg: func() interface{} {
 					ret := make([]GetUnreadUpdateFullArg, 1)
@@ -568,6 +651,22 @@ func RemoteProtocol(i RemoteInterface) rpc.Protocol {
 				},
 				MethodType: rpc.MethodCall,
 			},
+			"UpdateTypingRemote": {
+				MakeArg: func() interface{} {
+					ret := make([]UpdateTypingRemoteArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]UpdateTypingRemoteArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]UpdateTypingRemoteArg)(nil), args)
+						return
+					}
+					err = i.UpdateTypingRemote(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
 			"publishReadMessage": {
 				MakeArg: func() interface{} {
 					ret := make([]PublishReadMessageArg, 1)
@@ -654,6 +753,16 @@ func (c RemoteClient) SetConversationStatus(ctx context.Context, __arg SetConver
 	return
 }
 
+func (c RemoteClient) SetConversationRetention(ctx context.Context, __arg SetConversationRetentionArg) (res SetConversationRetentionRes, err error) {
+	err = c.Cli.Call(ctx, "chat.1.remote.SetConversationRetention", []interface{}{__arg}, &res)
+	return
+}
+
+func (c RemoteClient) ReportConversation(ctx context.Context, __arg ReportConversationArg) (res ReportConversationRes, err error) {
+	err = c.Cli.Call(ctx, "chat.1.remote.ReportConversation", []interface{}{__arg}, &res)
+	return
+}
+
 func (c RemoteClient) GetUnreadUpdateFull(ctx context.Context, inboxVers InboxVers) (res UnreadUpdateFull, err error) {
 	__arg := GetUnreadUpdateFullArg{InboxVers: inboxVers}
 	err = c.Cli.Call(ctx, "chat.1.remote.GetUnreadUpdateFull", []interface{}{__arg}, &res)
@@ -693,6 +802,11 @@ func (c RemoteClient) TlfResolve(ctx context.Context, __arg TlfResolveArg) (err
 	return
 }
 
+func (c RemoteClient) UpdateTypingRemote(ctx context.Context, __arg UpdateTypingRemoteArg) (err error) {
+	err = c.Cli.Call(ctx, "chat.1.remote.UpdateTypingRemote", []interface{}{__arg}, nil)
+	return
+}
+
 func (c RemoteClient) PublishReadMessage(ctx context.Context, __arg PublishReadMessageArg) (err error) {
 	err = c.Cli.Call(ctx, "chat.1.remote.publishReadMessage", []interface{}{__arg}, nil)
 	return
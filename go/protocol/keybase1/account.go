@@ -37,6 +37,33 @@ type ResetAccountArg struct {
 	SessionID int `codec:"sessionID" json:"sessionID"`
 }
 
+type AutoresetStatus struct {
+	Active  bool  `codec:"active" json:"active"`
+	EndTime *Time `codec:"endTime,omitempty" json:"endTime,omitempty"`
+}
+
+type EnterResetPipelineArg struct {
+	SessionID       int    `codec:"sessionID" json:"sessionID"`
+	UsernameOrEmail string `codec:"usernameOrEmail" json:"usernameOrEmail"`
+}
+
+type CancelResetArg struct {
+	SessionID int `codec:"sessionID" json:"sessionID"`
+}
+
+type GetResetStatusArg struct {
+	SessionID int `codec:"sessionID" json:"sessionID"`
+}
+
+type SecretStoreStatus struct {
+	Backend         string `codec:"backend" json:"backend"`
+	HasStoredSecret bool   `codec:"hasStoredSecret" json:"hasStoredSecret"`
+}
+
+type SecretStoreStatusArg struct {
+	SessionID int `codec:"sessionID" json:"sessionID"`
+}
+
 type AccountInterface interface {
 	// Change the passphrase from old to new. If old isn't set, and force is false,
 	// then prompt at the UI for it. If old isn't set and force is true, then we'll
@@ -49,6 +76,26 @@ type AccountInterface interface {
 	// * Will error if not logged in.
 	HasServerKeys(context.Context, int) (HasServerKeysRes, error)
 	ResetAccount(context.Context, int) error
+	// Starts the autoreset pipeline for the account identified by
+	// usernameOrEmail: after a server-defined countdown elapses, the
+	// account's established keys are reset so it can be reprovisioned as
+	// if it were brand new. This is the safety net for a user who has
+	// lost every device and paper key. Any device that can still log in
+	// to the account may call cancelReset to abort the countdown before
+	// it completes.
+	EnterResetPipeline(context.Context, EnterResetPipelineArg) error
+	// Cancels a previously-started autoreset countdown for the logged-in
+	// user.
+	CancelReset(context.Context, int) error
+	// Returns the state of the logged-in user's autoreset countdown, if
+	// one is active.
+	GetResetStatus(context.Context, int) (AutoresetStatus, error)
+	// Reports which pluggable SecretStore backend (see
+	// NewSecretStoreAll) is in effect on this device, and whether it
+	// currently holds a stored secret for the logged-in user -- e.g.
+	// "keychain" on macOS, or "file" on platforms without a real
+	// OS-backed secure storage integration yet.
+	SecretStoreStatus(context.Context, int) (SecretStoreStatus, error)
 }
 
 func AccountProtocol(i AccountInterface) rpc.Protocol {
@@ -135,6 +182,70 @@ func AccountProtocol(i AccountInterface) rpc.Protocol {
 				},
 				MethodType: rpc.MethodCall,
 			},
+			"enterResetPipeline": {
+				MakeArg: func() interface{} {
+					ret := make([]EnterResetPipelineArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]EnterResetPipelineArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]EnterResetPipelineArg)(nil), args)
+						return
+					}
+					err = i.EnterResetPipeline(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
+			"cancelReset": {
+				MakeArg: func() interface{} {
+					ret := make([]CancelResetArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]CancelResetArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]CancelResetArg)(nil), args)
+						return
+					}
+					err = i.CancelReset(ctx, (*typedArgs)[0].SessionID)
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
+			"getResetStatus": {
+				MakeArg: func() interface{} {
+					ret := make([]GetResetStatusArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]GetResetStatusArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]GetResetStatusArg)(nil), args)
+						return
+					}
+					ret, err = i.GetResetStatus(ctx, (*typedArgs)[0].SessionID)
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
+			"secretStoreStatus": {
+				MakeArg: func() interface{} {
+					ret := make([]SecretStoreStatusArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]SecretStoreStatusArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]SecretStoreStatusArg)(nil), args)
+						return
+					}
+					ret, err = i.SecretStoreStatus(ctx, (*typedArgs)[0].SessionID)
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
 		},
 	}
 }
@@ -175,3 +286,42 @@ func (c AccountClient) ResetAccount(ctx context.Context, sessionID int) (err err
 	err = c.Cli.Call(ctx, "keybase.1.account.resetAccount", []interface{}{__arg}, nil)
 	return
 }
+
+// Starts the autoreset pipeline for the account identified by
+// usernameOrEmail: after a server-defined countdown elapses, the
+// account's established keys are reset so it can be reprovisioned as
+// if it were brand new. This is the safety net for a user who has
+// lost every device and paper key. Any device that can still log in
+// to the account may call cancelReset to abort the countdown before
+// it completes.
+func (c AccountClient) EnterResetPipeline(ctx context.Context, __arg EnterResetPipelineArg) (err error) {
+	err = c.Cli.Call(ctx, "keybase.1.account.enterResetPipeline", []interface{}{__arg}, nil)
+	return
+}
+
+// Cancels a previously-started autoreset countdown for the logged-in
+// user.
+func (c AccountClient) CancelReset(ctx context.Context, sessionID int) (err error) {
+	__arg := CancelResetArg{SessionID: sessionID}
+	err = c.Cli.Call(ctx, "keybase.1.account.cancelReset", []interface{}{__arg}, nil)
+	return
+}
+
+// Returns the state of the logged-in user's autoreset countdown, if
+// one is active.
+func (c AccountClient) GetResetStatus(ctx context.Context, sessionID int) (res AutoresetStatus, err error) {
+	__arg := GetResetStatusArg{SessionID: sessionID}
+	err = c.Cli.Call(ctx, "keybase.1.account.getResetStatus", []interface{}{__arg}, &res)
+	return
+}
+
+// Reports which pluggable SecretStore backend (see
+// NewSecretStoreAll) is in effect on this device, and whether it
+// currently holds a stored secret for the logged-in user -- e.g.
+// "keychain" on macOS, or "file" on platforms without a real
+// OS-backed secure storage integration yet.
+func (c AccountClient) SecretStoreStatus(ctx context.Context, sessionID int) (res SecretStoreStatus, err error) {
+	__arg := SecretStoreStatusArg{SessionID: sessionID}
+	err = c.Cli.Call(ctx, "keybase.1.account.secretStoreStatus", []interface{}{__arg}, &res)
+	return
+}
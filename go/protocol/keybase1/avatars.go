@@ -0,0 +1,133 @@
+// Auto-generated by avdl-compiler v1.3.11 (https://github.com/keybase/node-avdl-compiler)
+//   Input file: avdl/keybase1/avatars.avdl
+
+package keybase1
+
+import (
+	"github.com/keybase/go-framed-msgpack-rpc/rpc"
+	context "golang.org/x/net/context"
+)
+
+type AvatarFormat int
+
+const (
+	AvatarFormat_SQUARE_360 AvatarFormat = 0
+	AvatarFormat_SQUARE_200 AvatarFormat = 1
+	AvatarFormat_SQUARE_40  AvatarFormat = 2
+)
+
+var AvatarFormatMap = map[string]AvatarFormat{
+	"SQUARE_360": 0,
+	"SQUARE_200": 1,
+	"SQUARE_40":  2,
+}
+
+var AvatarFormatRevMap = map[AvatarFormat]string{
+	0: "SQUARE_360",
+	1: "SQUARE_200",
+	2: "SQUARE_40",
+}
+
+func (e AvatarFormat) String() string {
+	if v, ok := AvatarFormatRevMap[e]; ok {
+		return v
+	}
+	return ""
+}
+
+// AvatarFormatPath is the locally-cached path for one (name, format)
+// pair. Path is empty if this name has no avatar, or if the
+// fetch/cache-write failed -- callers should treat an empty path the
+// same as "no avatar".
+type AvatarFormatPath struct {
+	Format AvatarFormat `codec:"format" json:"format"`
+	Path   string       `codec:"path" json:"path"`
+}
+
+type NameWithFormats struct {
+	Name    string             `codec:"name" json:"name"`
+	Formats []AvatarFormatPath `codec:"formats" json:"formats"`
+}
+
+type LoadAvatarsRes struct {
+	Picmap []NameWithFormats `codec:"picmap" json:"picmap"`
+}
+
+type LoadUserAvatarsArg struct {
+	SessionID int            `codec:"sessionID" json:"sessionID"`
+	Names     []string       `codec:"names" json:"names"`
+	Formats   []AvatarFormat `codec:"formats" json:"formats"`
+}
+
+type LoadTeamAvatarsArg struct {
+	SessionID int            `codec:"sessionID" json:"sessionID"`
+	Names     []string       `codec:"names" json:"names"`
+	Formats   []AvatarFormat `codec:"formats" json:"formats"`
+}
+
+type AvatarsInterface interface {
+	// loadUserAvatars looks up avatar URLs for the given usernames in
+	// bulk, downloads and disk-caches whichever ones aren't already
+	// cached, and returns local file paths for each requested format.
+	LoadUserAvatars(context.Context, LoadUserAvatarsArg) (LoadAvatarsRes, error)
+	// loadTeamAvatars is loadUserAvatars for team names.
+	LoadTeamAvatars(context.Context, LoadTeamAvatarsArg) (LoadAvatarsRes, error)
+}
+
+func AvatarsProtocol(i AvatarsInterface) rpc.Protocol {
+	return rpc.Protocol{
+		Name: "keybase.1.avatars",
+		Methods: map[string]rpc.ServeHandlerDescription{
+			"loadUserAvatars": {
+				MakeArg: func() interface{} {
+					ret := make([]LoadUserAvatarsArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]LoadUserAvatarsArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]LoadUserAvatarsArg)(nil), args)
+						return
+					}
+					ret, err = i.LoadUserAvatars(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
+			"loadTeamAvatars": {
+				MakeArg: func() interface{} {
+					ret := make([]LoadTeamAvatarsArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]LoadTeamAvatarsArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]LoadTeamAvatarsArg)(nil), args)
+						return
+					}
+					ret, err = i.LoadTeamAvatars(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
+		},
+	}
+}
+
+type AvatarsClient struct {
+	Cli rpc.GenericClient
+}
+
+// loadUserAvatars looks up avatar URLs for the given usernames in
+// bulk, downloads and disk-caches whichever ones aren't already
+// cached, and returns local file paths for each requested format.
+func (c AvatarsClient) LoadUserAvatars(ctx context.Context, __arg LoadUserAvatarsArg) (res LoadAvatarsRes, err error) {
+	err = c.Cli.Call(ctx, "keybase.1.avatars.loadUserAvatars", []interface{}{__arg}, &res)
+	return
+}
+
+// loadTeamAvatars is loadUserAvatars for team names.
+func (c AvatarsClient) LoadTeamAvatars(ctx context.Context, __arg LoadTeamAvatarsArg) (res LoadAvatarsRes, err error) {
+	err = c.Cli.Call(ctx, "keybase.1.avatars.loadTeamAvatars", []interface{}{__arg}, &res)
+	return
+}
@@ -25,11 +25,12 @@ type SessionStatus struct {
 }
 
 type ClientDetails struct {
-	Pid        int        `codec:"pid" json:"pid"`
-	ClientType ClientType `codec:"clientType" json:"clientType"`
-	Argv       []string   `codec:"argv" json:"argv"`
-	Desc       string     `codec:"desc" json:"desc"`
-	Version    string     `codec:"version" json:"version"`
+	Pid         int        `codec:"pid" json:"pid"`
+	ClientType  ClientType `codec:"clientType" json:"clientType"`
+	Argv        []string   `codec:"argv" json:"argv"`
+	Desc        string     `codec:"desc" json:"desc"`
+	Version     string     `codec:"version" json:"version"`
+	ConnectedAt Time       `codec:"connectedAt" json:"connectedAt"`
 }
 
 type PlatformInfo struct {
@@ -44,25 +45,52 @@ type LoadDeviceErr struct {
 	Desc  string `codec:"desc" json:"desc"`
 }
 
+type PvlDiagnostics struct {
+	MerkleRootSeqno     int64  `codec:"merkleRootSeqno" json:"merkleRootSeqno"`
+	MerkleRootFetchTime Time   `codec:"merkleRootFetchTime" json:"merkleRootFetchTime"`
+	MerkleRootHash      string `codec:"merkleRootHash" json:"merkleRootHash"`
+	ActiveHash          string `codec:"activeHash" json:"activeHash"`
+	CacheSource         string `codec:"cacheSource" json:"cacheSource"`
+	LastFetchError      string `codec:"lastFetchError" json:"lastFetchError"`
+}
+
+type NetworkBreakerStatus struct {
+	Name                string `codec:"name" json:"name"`
+	Open                bool   `codec:"open" json:"open"`
+	ConsecutiveFailures int    `codec:"consecutiveFailures" json:"consecutiveFailures"`
+	TotalFailures       int64  `codec:"totalFailures" json:"totalFailures"`
+	TotalSuccesses      int64  `codec:"totalSuccesses" json:"totalSuccesses"`
+	TotalRateLimited    int64  `codec:"totalRateLimited" json:"totalRateLimited"`
+	OpenedAt            Time   `codec:"openedAt" json:"openedAt"`
+}
+
+type ConnPoolStatus struct {
+	ActiveRequests int   `codec:"activeRequests" json:"activeRequests"`
+	TotalRequests  int64 `codec:"totalRequests" json:"totalRequests"`
+}
+
 type ExtendedStatus struct {
-	Standalone             bool            `codec:"standalone" json:"standalone"`
-	PassphraseStreamCached bool            `codec:"passphraseStreamCached" json:"passphraseStreamCached"`
-	TsecCached             bool            `codec:"tsecCached" json:"tsecCached"`
-	DeviceSigKeyCached     bool            `codec:"deviceSigKeyCached" json:"deviceSigKeyCached"`
-	DeviceEncKeyCached     bool            `codec:"deviceEncKeyCached" json:"deviceEncKeyCached"`
-	PaperSigKeyCached      bool            `codec:"paperSigKeyCached" json:"paperSigKeyCached"`
-	PaperEncKeyCached      bool            `codec:"paperEncKeyCached" json:"paperEncKeyCached"`
-	StoredSecret           bool            `codec:"storedSecret" json:"storedSecret"`
-	SecretPromptSkip       bool            `codec:"secretPromptSkip" json:"secretPromptSkip"`
-	Device                 *Device         `codec:"device,omitempty" json:"device,omitempty"`
-	DeviceErr              *LoadDeviceErr  `codec:"deviceErr,omitempty" json:"deviceErr,omitempty"`
-	LogDir                 string          `codec:"logDir" json:"logDir"`
-	Session                *SessionStatus  `codec:"session,omitempty" json:"session,omitempty"`
-	DefaultUsername        string          `codec:"defaultUsername" json:"defaultUsername"`
-	ProvisionedUsernames   []string        `codec:"provisionedUsernames" json:"provisionedUsernames"`
-	Clients                []ClientDetails `codec:"Clients" json:"Clients"`
-	PlatformInfo           PlatformInfo    `codec:"platformInfo" json:"platformInfo"`
-	DefaultDeviceID        DeviceID        `codec:"defaultDeviceID" json:"defaultDeviceID"`
+	Standalone             bool                   `codec:"standalone" json:"standalone"`
+	PassphraseStreamCached bool                   `codec:"passphraseStreamCached" json:"passphraseStreamCached"`
+	TsecCached             bool                   `codec:"tsecCached" json:"tsecCached"`
+	DeviceSigKeyCached     bool                   `codec:"deviceSigKeyCached" json:"deviceSigKeyCached"`
+	DeviceEncKeyCached     bool                   `codec:"deviceEncKeyCached" json:"deviceEncKeyCached"`
+	PaperSigKeyCached      bool                   `codec:"paperSigKeyCached" json:"paperSigKeyCached"`
+	PaperEncKeyCached      bool                   `codec:"paperEncKeyCached" json:"paperEncKeyCached"`
+	StoredSecret           bool                   `codec:"storedSecret" json:"storedSecret"`
+	SecretPromptSkip       bool                   `codec:"secretPromptSkip" json:"secretPromptSkip"`
+	Device                 *Device                `codec:"device,omitempty" json:"device,omitempty"`
+	DeviceErr              *LoadDeviceErr         `codec:"deviceErr,omitempty" json:"deviceErr,omitempty"`
+	LogDir                 string                 `codec:"logDir" json:"logDir"`
+	Session                *SessionStatus         `codec:"session,omitempty" json:"session,omitempty"`
+	DefaultUsername        string                 `codec:"defaultUsername" json:"defaultUsername"`
+	ProvisionedUsernames   []string               `codec:"provisionedUsernames" json:"provisionedUsernames"`
+	Clients                []ClientDetails        `codec:"Clients" json:"Clients"`
+	PlatformInfo           PlatformInfo           `codec:"platformInfo" json:"platformInfo"`
+	DefaultDeviceID        DeviceID               `codec:"defaultDeviceID" json:"defaultDeviceID"`
+	NetworkBreakers        []NetworkBreakerStatus `codec:"networkBreakers" json:"networkBreakers"`
+	ConnPools              []ConnPoolStatus       `codec:"connPools" json:"connPools"`
+	PvlDiagnostics         *PvlDiagnostics        `codec:"pvlDiagnostics,omitempty" json:"pvlDiagnostics,omitempty"`
 }
 
 type ForkType int
@@ -175,6 +203,20 @@ type WaitForClientArg struct {
 	Timeout    DurationSec `codec:"timeout" json:"timeout"`
 }
 
+type GetPvlMetricsArg struct {
+}
+
+type ProxyCheckResult struct {
+	Component string `codec:"component" json:"component"`
+	Enabled   bool   `codec:"enabled" json:"enabled"`
+	Reachable bool   `codec:"reachable" json:"reachable"`
+	Error     string `codec:"error" json:"error"`
+}
+
+type CheckProxyConnectivityArg struct {
+	SessionID int `codec:"sessionID" json:"sessionID"`
+}
+
 type ConfigInterface interface {
 	GetCurrentStatus(context.Context, int) (GetCurrentStatusRes, error)
 	GetExtendedStatus(context.Context, int) (ExtendedStatus, error)
@@ -192,6 +234,13 @@ type ConfigInterface interface {
 	CheckAPIServerOutOfDateWarning(context.Context) (OutOfDateInfo, error)
 	// Wait for client type to connect to service.
 	WaitForClient(context.Context, WaitForClientArg) (bool, error)
+	// Dump service-side counters and latencies (e.g. pvl fetch cache hits,
+	//    server round trip times) as a JSON blob, for `keybase log metrics`.
+	GetPvlMetrics(context.Context) (string, error)
+	// Dial the configured proxy (if any) for each network component that
+	// honors a ProxyPolicy, and report whether it's reachable, for
+	// `keybase proxy check`.
+	CheckProxyConnectivity(context.Context, int) ([]ProxyCheckResult, error)
 }
 
 func ConfigProtocol(i ConfigInterface) rpc.Protocol {
@@ -369,6 +418,33 @@ func ConfigProtocol(i ConfigInterface) rpc.Protocol {
 				},
 				MethodType: rpc.MethodCall,
 			},
+			"getPvlMetrics": {
+				MakeArg: func() interface{} {
+					ret := make([]GetPvlMetricsArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					ret, err = i.GetPvlMetrics(ctx)
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
+			"checkProxyConnectivity": {
+				MakeArg: func() interface{} {
+					ret := make([]CheckProxyConnectivityArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]CheckProxyConnectivityArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]CheckProxyConnectivityArg)(nil), args)
+						return
+					}
+					ret, err = i.CheckProxyConnectivity(ctx, (*typedArgs)[0].SessionID)
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
 		},
 	}
 }
@@ -442,3 +518,19 @@ func (c ConfigClient) WaitForClient(ctx context.Context, __arg WaitForClientArg)
 	err = c.Cli.Call(ctx, "keybase.1.config.waitForClient", []interface{}{__arg}, &res)
 	return
 }
+
+// Dump service-side counters and latencies (e.g. pvl fetch cache hits,
+//    server round trip times) as a JSON blob, for `keybase log metrics`.
+func (c ConfigClient) GetPvlMetrics(ctx context.Context) (res string, err error) {
+	err = c.Cli.Call(ctx, "keybase.1.config.getPvlMetrics", []interface{}{GetPvlMetricsArg{}}, &res)
+	return
+}
+
+// Dial the configured proxy (if any) for each network component that
+// honors a ProxyPolicy, and report whether it's reachable, for `keybase
+// proxy check`.
+func (c ConfigClient) CheckProxyConnectivity(ctx context.Context, sessionID int) (res []ProxyCheckResult, err error) {
+	__arg := CheckProxyConnectivityArg{SessionID: sessionID}
+	err = c.Cli.Call(ctx, "keybase.1.config.checkProxyConnectivity", []interface{}{__arg}, &res)
+	return
+}
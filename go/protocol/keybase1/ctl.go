@@ -103,6 +103,16 @@ type DbGetArg struct {
 	Key       DbKey `codec:"key" json:"key"`
 }
 
+type DbUsageEntry struct {
+	ObjType int   `codec:"objType" json:"objType"`
+	Bytes   int64 `codec:"bytes" json:"bytes"`
+}
+
+type DbStatsArg struct {
+	SessionID int    `codec:"sessionID" json:"sessionID"`
+	DbType    DbType `codec:"dbType" json:"dbType"`
+}
+
 type CtlInterface interface {
 	Stop(context.Context, StopArg) error
 	LogRotate(context.Context, int) error
@@ -112,6 +122,7 @@ type CtlInterface interface {
 	DbDelete(context.Context, DbDeleteArg) error
 	DbPut(context.Context, DbPutArg) error
 	DbGet(context.Context, DbGetArg) (*DbValue, error)
+	DbStats(context.Context, DbStatsArg) ([]DbUsageEntry, error)
 }
 
 func CtlProtocol(i CtlInterface) rpc.Protocol {
@@ -246,6 +257,22 @@ func CtlProtocol(i CtlInterface) rpc.Protocol {
 				},
 				MethodType: rpc.MethodCall,
 			},
+			"dbStats": {
+				MakeArg: func() interface{} {
+					ret := make([]DbStatsArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]DbStatsArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]DbStatsArg)(nil), args)
+						return
+					}
+					ret, err = i.DbStats(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
 		},
 	}
 }
@@ -297,3 +324,8 @@ func (c CtlClient) DbGet(ctx context.Context, __arg DbGetArg) (res *DbValue, err
 	err = c.Cli.Call(ctx, "keybase.1.ctl.dbGet", []interface{}{__arg}, &res)
 	return
 }
+
+func (c CtlClient) DbStats(ctx context.Context, __arg DbStatsArg) (res []DbUsageEntry, err error) {
+	err = c.Cli.Call(ctx, "keybase.1.ctl.dbStats", []interface{}{__arg}, &res)
+	return
+}
@@ -42,6 +42,12 @@ type CheckDeviceNameForUserArg struct {
 	Devicename string `codec:"devicename" json:"devicename"`
 }
 
+type DeviceRenameArg struct {
+	SessionID int      `codec:"sessionID" json:"sessionID"`
+	DeviceID  DeviceID `codec:"deviceID" json:"deviceID"`
+	NewName   string   `codec:"newName" json:"newName"`
+}
+
 type DeviceInterface interface {
 	// List devices for the user.
 	DeviceList(context.Context, int) ([]Device, error)
@@ -58,6 +64,11 @@ type DeviceInterface interface {
 	// for proper formatting. Return null error on success, and a non-null
 	// error otherwise.
 	CheckDeviceNameForUser(context.Context, CheckDeviceNameForUserArg) error
+	// Renames one of the caller's own devices to newName, posting a
+	// signed sigchain link so other clients pick up the new name on
+	// their next sigchain replay. deviceID defaults to the current
+	// device if left empty.
+	DeviceRename(context.Context, DeviceRenameArg) error
 }
 
 func DeviceProtocol(i DeviceInterface) rpc.Protocol {
@@ -144,6 +155,22 @@ func DeviceProtocol(i DeviceInterface) rpc.Protocol {
 				},
 				MethodType: rpc.MethodCall,
 			},
+			"deviceRename": {
+				MakeArg: func() interface{} {
+					ret := make([]DeviceRenameArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]DeviceRenameArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]DeviceRenameArg)(nil), args)
+						return
+					}
+					err = i.DeviceRename(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
 		},
 	}
 }
@@ -189,3 +216,12 @@ func (c DeviceClient) CheckDeviceNameForUser(ctx context.Context, __arg CheckDev
 	err = c.Cli.Call(ctx, "keybase.1.device.checkDeviceNameForUser", []interface{}{__arg}, nil)
 	return
 }
+
+// Renames one of the caller's own devices to newName, posting a
+// signed sigchain link so other clients pick up the new name on
+// their next sigchain replay. deviceID defaults to the current
+// device if left empty.
+func (c DeviceClient) DeviceRename(ctx context.Context, __arg DeviceRenameArg) (err error) {
+	err = c.Cli.Call(ctx, "keybase.1.device.deviceRename", []interface{}{__arg}, nil)
+	return
+}
@@ -751,7 +751,15 @@ func (t TLFID) ToBytes() []byte {
 
 func (b TLFIdentifyBehavior) AlwaysRunIdentify() bool {
 	return b == TLFIdentifyBehavior_CHAT_GUI || b == TLFIdentifyBehavior_CHAT_CLI ||
-		b == TLFIdentifyBehavior_CHAT_GUI_STRICT
+		b == TLFIdentifyBehavior_CHAT_GUI_STRICT || b == TLFIdentifyBehavior_CHAT_GUI_STRICT_TOFU
+}
+
+// RequiresTrackBeforeSend is true for identify behaviors that refuse to
+// complete (and so refuse to let a chat/KBFS send go through) until the
+// sender has an existing track of the recipient, prompting them to make one
+// inline via the identify UI if they don't already have one.
+func (b TLFIdentifyBehavior) RequiresTrackBeforeSend() bool {
+	return b == TLFIdentifyBehavior_CHAT_GUI_STRICT_TOFU
 }
 
 func (b TLFIdentifyBehavior) CanUseUntrackedFastPath() bool {
@@ -0,0 +1,169 @@
+// Auto-generated by avdl-compiler v1.3.11 (https://github.com/keybase/node-avdl-compiler)
+//   Input file: avdl/keybase1/kvstore.avdl
+
+package keybase1
+
+import (
+	"github.com/keybase/go-framed-msgpack-rpc/rpc"
+	context "golang.org/x/net/context"
+)
+
+type KVStoreGetRes struct {
+	Value string `codec:"value" json:"value"`
+	Found bool   `codec:"found" json:"found"`
+}
+
+type KVStorePutArg struct {
+	SessionID    int    `codec:"sessionID" json:"sessionID"`
+	Namespace    string `codec:"namespace" json:"namespace"`
+	Key          string `codec:"key" json:"key"`
+	Value        string `codec:"value" json:"value"`
+	SyncToServer bool   `codec:"syncToServer" json:"syncToServer"`
+}
+
+type KVStoreGetArg struct {
+	SessionID int    `codec:"sessionID" json:"sessionID"`
+	Namespace string `codec:"namespace" json:"namespace"`
+	Key       string `codec:"key" json:"key"`
+}
+
+type KVStoreDeleteArg struct {
+	SessionID int    `codec:"sessionID" json:"sessionID"`
+	Namespace string `codec:"namespace" json:"namespace"`
+	Key       string `codec:"key" json:"key"`
+}
+
+type KVStoreListNamespacesArg struct {
+	SessionID int `codec:"sessionID" json:"sessionID"`
+}
+
+type KVStoreListKeysArg struct {
+	SessionID int    `codec:"sessionID" json:"sessionID"`
+	Namespace string `codec:"namespace" json:"namespace"`
+}
+
+type KVStoreInterface interface {
+	KVStorePut(context.Context, KVStorePutArg) error
+	KVStoreGet(context.Context, KVStoreGetArg) (KVStoreGetRes, error)
+	KVStoreDelete(context.Context, KVStoreDeleteArg) error
+	KVStoreListNamespaces(context.Context, int) ([]string, error)
+	KVStoreListKeys(context.Context, KVStoreListKeysArg) ([]string, error)
+}
+
+func KVStoreProtocol(i KVStoreInterface) rpc.Protocol {
+	return rpc.Protocol{
+		Name: "keybase.1.kvstore",
+		Methods: map[string]rpc.ServeHandlerDescription{
+			"kvStorePut": {
+				MakeArg: func() interface{} {
+					ret := make([]KVStorePutArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]KVStorePutArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]KVStorePutArg)(nil), args)
+						return
+					}
+					err = i.KVStorePut(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
+			"kvStoreGet": {
+				MakeArg: func() interface{} {
+					ret := make([]KVStoreGetArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]KVStoreGetArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]KVStoreGetArg)(nil), args)
+						return
+					}
+					ret, err = i.KVStoreGet(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
+			"kvStoreDelete": {
+				MakeArg: func() interface{} {
+					ret := make([]KVStoreDeleteArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]KVStoreDeleteArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]KVStoreDeleteArg)(nil), args)
+						return
+					}
+					err = i.KVStoreDelete(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
+			"kvStoreListNamespaces": {
+				MakeArg: func() interface{} {
+					ret := make([]KVStoreListNamespacesArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]KVStoreListNamespacesArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]KVStoreListNamespacesArg)(nil), args)
+						return
+					}
+					ret, err = i.KVStoreListNamespaces(ctx, (*typedArgs)[0].SessionID)
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
+			"kvStoreListKeys": {
+				MakeArg: func() interface{} {
+					ret := make([]KVStoreListKeysArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]KVStoreListKeysArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]KVStoreListKeysArg)(nil), args)
+						return
+					}
+					ret, err = i.KVStoreListKeys(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
+		},
+	}
+}
+
+type KVStoreClient struct {
+	Cli rpc.GenericClient
+}
+
+func (c KVStoreClient) KVStorePut(ctx context.Context, __arg KVStorePutArg) (err error) {
+	err = c.Cli.Call(ctx, "keybase.1.kvstore.kvStorePut", []interface{}{__arg}, nil)
+	return
+}
+
+func (c KVStoreClient) KVStoreGet(ctx context.Context, __arg KVStoreGetArg) (res KVStoreGetRes, err error) {
+	err = c.Cli.Call(ctx, "keybase.1.kvstore.kvStoreGet", []interface{}{__arg}, &res)
+	return
+}
+
+func (c KVStoreClient) KVStoreDelete(ctx context.Context, __arg KVStoreDeleteArg) (err error) {
+	err = c.Cli.Call(ctx, "keybase.1.kvstore.kvStoreDelete", []interface{}{__arg}, nil)
+	return
+}
+
+func (c KVStoreClient) KVStoreListNamespaces(ctx context.Context, sessionID int) (res []string, err error) {
+	__arg := KVStoreListNamespacesArg{SessionID: sessionID}
+	err = c.Cli.Call(ctx, "keybase.1.kvstore.kvStoreListNamespaces", []interface{}{__arg}, &res)
+	return
+}
+
+func (c KVStoreClient) KVStoreListKeys(ctx context.Context, __arg KVStoreListKeysArg) (res []string, err error) {
+	err = c.Cli.Call(ctx, "keybase.1.kvstore.kvStoreListKeys", []interface{}{__arg}, &res)
+	return
+}
@@ -54,7 +54,9 @@ type RecoverAccountFromEmailAddressArg struct {
 }
 
 type PaperKeyArg struct {
-	SessionID int `codec:"sessionID" json:"sessionID"`
+	SessionID int    `codec:"sessionID" json:"sessionID"`
+	Label     string `codec:"label" json:"label"`
+	Strong    bool   `codec:"strong" json:"strong"`
 }
 
 type PaperKeySubmitArg struct {
@@ -112,7 +114,7 @@ type LoginInterface interface {
 	RecoverAccountFromEmailAddress(context.Context, string) error
 	// PaperKey generates paper backup keys for restoring an account.
 	// It calls login_ui.displayPaperKeyPhrase with the phrase.
-	PaperKey(context.Context, int) error
+	PaperKey(context.Context, PaperKeyArg) error
 	// paperKeySubmit checks that paperPhrase is a valid paper key
 	// for the logged in user, caches the keys, and sends a notification.
 	PaperKeySubmit(context.Context, PaperKeySubmitArg) error
@@ -269,7 +271,7 @@ func LoginProtocol(i LoginInterface) rpc.Protocol {
 						err = rpc.NewTypeError((*[]PaperKeyArg)(nil), args)
 						return
 					}
-					err = i.PaperKey(ctx, (*typedArgs)[0].SessionID)
+					err = i.PaperKey(ctx, (*typedArgs)[0])
 					return
 				},
 				MethodType: rpc.MethodCall,
@@ -427,8 +429,7 @@ func (c LoginClient) RecoverAccountFromEmailAddress(ctx context.Context, email s
 
 // PaperKey generates paper backup keys for restoring an account.
 // It calls login_ui.displayPaperKeyPhrase with the phrase.
-func (c LoginClient) PaperKey(ctx context.Context, sessionID int) (err error) {
-	__arg := PaperKeyArg{SessionID: sessionID}
+func (c LoginClient) PaperKey(ctx context.Context, __arg PaperKeyArg) (err error) {
 	err = c.Cli.Call(ctx, "keybase.1.login.paperKey", []interface{}{__arg}, nil)
 	return
 }
@@ -0,0 +1,67 @@
+// Auto-generated by avdl-compiler v1.3.11 (https://github.com/keybase/node-avdl-compiler)
+//   Input file: avdl/keybase1/merkle.avdl
+
+package keybase1
+
+import (
+	"github.com/keybase/go-framed-msgpack-rpc/rpc"
+	context "golang.org/x/net/context"
+)
+
+type MerkleAuditResult struct {
+	Ok           bool     `codec:"ok" json:"ok"`
+	RootsAudited int      `codec:"rootsAudited" json:"rootsAudited"`
+	Problems     []string `codec:"problems" json:"problems"`
+}
+
+type AuditArg struct {
+	Low  int64 `codec:"low" json:"low"`
+	High int64 `codec:"high" json:"high"`
+}
+
+type MerkleInterface interface {
+	// audit re-verifies the skip-pointer chain between every merkle root this
+	// client already has cached locally with a seqno in [low, high], and
+	// reports any discrepancy it finds. It does not fetch new roots from the
+	// server or an external checkpoint service; it only re-checks roots this
+	// client has already fetched and stored.
+	Audit(context.Context, AuditArg) (MerkleAuditResult, error)
+}
+
+func MerkleProtocol(i MerkleInterface) rpc.Protocol {
+	return rpc.Protocol{
+		Name: "keybase.1.merkle",
+		Methods: map[string]rpc.ServeHandlerDescription{
+			"audit": {
+				MakeArg: func() interface{} {
+					ret := make([]AuditArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]AuditArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]AuditArg)(nil), args)
+						return
+					}
+					ret, err = i.Audit(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
+		},
+	}
+}
+
+type MerkleClient struct {
+	Cli rpc.GenericClient
+}
+
+// audit re-verifies the skip-pointer chain between every merkle root this
+// client already has cached locally with a seqno in [low, high], and
+// reports any discrepancy it finds. It does not fetch new roots from the
+// server or an external checkpoint service; it only re-checks roots this
+// client has already fetched and stored.
+func (c MerkleClient) Audit(ctx context.Context, arg AuditArg) (res MerkleAuditResult, err error) {
+	err = c.Cli.Call(ctx, "keybase.1.merkle.audit", []interface{}{arg}, &res)
+	return
+}
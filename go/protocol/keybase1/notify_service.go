@@ -11,8 +11,19 @@ import (
 type ShutdownArg struct {
 }
 
+type MerkleRootRolledBackArg struct {
+	RolledBackFrom int `codec:"rolledBackFrom" json:"rolledBackFrom"`
+	RolledBackTo   int `codec:"rolledBackTo" json:"rolledBackTo"`
+}
+
+type MerkleAuditErrorArg struct {
+	Problems []string `codec:"problems" json:"problems"`
+}
+
 type NotifyServiceInterface interface {
 	Shutdown(context.Context) error
+	MerkleRootRolledBack(context.Context, MerkleRootRolledBackArg) error
+	MerkleAuditError(context.Context, MerkleAuditErrorArg) error
 }
 
 func NotifyServiceProtocol(i NotifyServiceInterface) rpc.Protocol {
@@ -30,6 +41,38 @@ func NotifyServiceProtocol(i NotifyServiceInterface) rpc.Protocol {
 				},
 				MethodType: rpc.MethodCall,
 			},
+			"merkleRootRolledBack": {
+				MakeArg: func() interface{} {
+					ret := make([]MerkleRootRolledBackArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]MerkleRootRolledBackArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]MerkleRootRolledBackArg)(nil), args)
+						return
+					}
+					err = i.MerkleRootRolledBack(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
+			"merkleAuditError": {
+				MakeArg: func() interface{} {
+					ret := make([]MerkleAuditErrorArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]MerkleAuditErrorArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]MerkleAuditErrorArg)(nil), args)
+						return
+					}
+					err = i.MerkleAuditError(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
 		},
 	}
 }
@@ -42,3 +85,13 @@ func (c NotifyServiceClient) Shutdown(ctx context.Context) (err error) {
 	err = c.Cli.Call(ctx, "keybase.1.NotifyService.shutdown", []interface{}{ShutdownArg{}}, nil)
 	return
 }
+
+func (c NotifyServiceClient) MerkleRootRolledBack(ctx context.Context, arg MerkleRootRolledBackArg) (err error) {
+	err = c.Cli.Call(ctx, "keybase.1.NotifyService.merkleRootRolledBack", []interface{}{arg}, nil)
+	return
+}
+
+func (c NotifyServiceClient) MerkleAuditError(ctx context.Context, arg MerkleAuditErrorArg) (err error) {
+	err = c.Cli.Call(ctx, "keybase.1.NotifyService.merkleAuditError", []interface{}{arg}, nil)
+	return
+}
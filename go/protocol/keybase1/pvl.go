@@ -0,0 +1,68 @@
+// Auto-generated by avdl-compiler v1.3.11 (https://github.com/keybase/node-avdl-compiler)
+//   Input file: avdl/keybase1/pvl.avdl
+
+package keybase1
+
+import (
+	"github.com/keybase/go-framed-msgpack-rpc/rpc"
+	context "golang.org/x/net/context"
+)
+
+type PvlTestResult struct {
+	Success      bool     `codec:"success" json:"success"`
+	ErrorMessage string   `codec:"errorMessage" json:"errorMessage"`
+	Steps        []string `codec:"steps" json:"steps"`
+}
+
+type PvlTestArg struct {
+	Username    string `codec:"username" json:"username"`
+	Service     string `codec:"service" json:"service"`
+	KitFilename string `codec:"kitFilename" json:"kitFilename"`
+}
+
+type PvlInterface interface {
+	// Run the PVL interpreter against a user's live proof for a service, in
+	// verbose mode, and return the step-by-step trace. Backs `keybase pvl
+	// test` -- a debugging aid for developing new proof integrations.
+	// kitFilename, if non-empty, checks against a local kit file instead of
+	// the currently cached/fetched one.
+	PvlTest(context.Context, PvlTestArg) (PvlTestResult, error)
+}
+
+func PvlProtocol(i PvlInterface) rpc.Protocol {
+	return rpc.Protocol{
+		Name: "keybase.1.pvl",
+		Methods: map[string]rpc.ServeHandlerDescription{
+			"pvlTest": {
+				MakeArg: func() interface{} {
+					ret := make([]PvlTestArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]PvlTestArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]PvlTestArg)(nil), args)
+						return
+					}
+					ret, err = i.PvlTest(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
+		},
+	}
+}
+
+type PvlClient struct {
+	Cli rpc.GenericClient
+}
+
+// Run the PVL interpreter against a user's live proof for a service, in
+// verbose mode, and return the step-by-step trace. Backs `keybase pvl
+// test` -- a debugging aid for developing new proof integrations.
+// kitFilename, if non-empty, checks against a local kit file instead of
+// the currently cached/fetched one.
+func (c PvlClient) PvlTest(ctx context.Context, arg PvlTestArg) (res PvlTestResult, err error) {
+	err = c.Cli.Call(ctx, "keybase.1.pvl.pvlTest", []interface{}{arg}, &res)
+	return
+}
@@ -37,6 +37,12 @@ type SigListArgs struct {
 	Revoked   bool      `codec:"revoked" json:"revoked"`
 }
 
+type SigChainSelfCheckResult struct {
+	Consistent bool   `codec:"consistent" json:"consistent"`
+	NumLinks   int    `codec:"numLinks" json:"numLinks"`
+	Message    string `codec:"message" json:"message"`
+}
+
 type SigListArg struct {
 	SessionID int         `codec:"sessionID" json:"sessionID"`
 	Arg       SigListArgs `codec:"arg" json:"arg"`
@@ -47,9 +53,20 @@ type SigListJSONArg struct {
 	Arg       SigListArgs `codec:"arg" json:"arg"`
 }
 
+type SigChainSelfCheckArg struct {
+	SessionID int    `codec:"sessionID" json:"sessionID"`
+	Username  string `codec:"username" json:"username"`
+}
+
 type SigsInterface interface {
 	SigList(context.Context, SigListArg) ([]Sig, error)
 	SigListJSON(context.Context, SigListJSONArg) (string, error)
+	// sigChainSelfCheck re-verifies the given user's sigchain from scratch,
+	// bypassing the local incremental verification cache, and compares the
+	// result to what's currently cached. It's a diagnostic for catching
+	// drift in the sigchain verification cache, not a replacement for
+	// normal loading/verification.
+	SigChainSelfCheck(context.Context, SigChainSelfCheckArg) (SigChainSelfCheckResult, error)
 }
 
 func SigsProtocol(i SigsInterface) rpc.Protocol {
@@ -88,6 +105,22 @@ func SigsProtocol(i SigsInterface) rpc.Protocol {
 				},
 				MethodType: rpc.MethodCall,
 			},
+			"sigChainSelfCheck": {
+				MakeArg: func() interface{} {
+					ret := make([]SigChainSelfCheckArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]SigChainSelfCheckArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]SigChainSelfCheckArg)(nil), args)
+						return
+					}
+					ret, err = i.SigChainSelfCheck(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
 		},
 	}
 }
@@ -105,3 +138,8 @@ func (c SigsClient) SigListJSON(ctx context.Context, __arg SigListJSONArg) (res
 	err = c.Cli.Call(ctx, "keybase.1.sigs.sigListJSON", []interface{}{__arg}, &res)
 	return
 }
+
+func (c SigsClient) SigChainSelfCheck(ctx context.Context, __arg SigChainSelfCheckArg) (res SigChainSelfCheckResult, err error) {
+	err = c.Cli.Call(ctx, "keybase.1.sigs.sigChainSelfCheck", []interface{}{__arg}, &res)
+	return
+}
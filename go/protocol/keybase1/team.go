@@ -0,0 +1,418 @@
+// Auto-generated by avdl-compiler v1.3.11 (https://github.com/keybase/node-avdl-compiler)
+//   Input file: avdl/keybase1/team.avdl
+
+package keybase1
+
+import (
+	"github.com/keybase/go-framed-msgpack-rpc/rpc"
+	context "golang.org/x/net/context"
+)
+
+type TeamRole int
+
+const (
+	TeamRole_NONE   TeamRole = 0
+	TeamRole_READER TeamRole = 1
+	TeamRole_WRITER TeamRole = 2
+	TeamRole_ADMIN  TeamRole = 3
+	TeamRole_OWNER  TeamRole = 4
+)
+
+var TeamRoleMap = map[string]TeamRole{
+	"NONE":   0,
+	"READER": 1,
+	"WRITER": 2,
+	"ADMIN":  3,
+	"OWNER":  4,
+}
+
+var TeamRoleRevMap = map[TeamRole]string{
+	0: "NONE",
+	1: "READER",
+	2: "WRITER",
+	3: "ADMIN",
+	4: "OWNER",
+}
+
+func (e TeamRole) String() string {
+	if v, ok := TeamRoleRevMap[e]; ok {
+		return v
+	}
+	return ""
+}
+
+type TeamID string
+
+func (t TeamID) String() string {
+	return string(t)
+}
+
+type TeamMember struct {
+	Username string   `codec:"username" json:"username"`
+	Role     TeamRole `codec:"role" json:"role"`
+}
+
+type AddTeamMemberArg struct {
+	SessionID int      `codec:"sessionID" json:"sessionID"`
+	TeamID    TeamID   `codec:"teamID" json:"teamID"`
+	Username  string   `codec:"username" json:"username"`
+	Role      TeamRole `codec:"role" json:"role"`
+}
+
+type RemoveTeamMemberArg struct {
+	SessionID int    `codec:"sessionID" json:"sessionID"`
+	TeamID    TeamID `codec:"teamID" json:"teamID"`
+	Username  string `codec:"username" json:"username"`
+}
+
+type EditTeamMemberRoleArg struct {
+	SessionID int      `codec:"sessionID" json:"sessionID"`
+	TeamID    TeamID   `codec:"teamID" json:"teamID"`
+	Username  string   `codec:"username" json:"username"`
+	Role      TeamRole `codec:"role" json:"role"`
+}
+
+type ListTeamMembersArg struct {
+	SessionID int    `codec:"sessionID" json:"sessionID"`
+	TeamID    TeamID `codec:"teamID" json:"teamID"`
+}
+
+type TeamName string
+
+func (t TeamName) String() string {
+	return string(t)
+}
+
+type CreateSubteamArg struct {
+	SessionID   int      `codec:"sessionID" json:"sessionID"`
+	ParentName  TeamName `codec:"parentName" json:"parentName"`
+	SubteamName string   `codec:"subteamName" json:"subteamName"`
+}
+
+type RenameSubteamArg struct {
+	SessionID      int      `codec:"sessionID" json:"sessionID"`
+	CurrentName    TeamName `codec:"currentName" json:"currentName"`
+	NewParentName  TeamName `codec:"newParentName" json:"newParentName"`
+	NewSubteamName string   `codec:"newSubteamName" json:"newSubteamName"`
+}
+
+type LookupTeamByNameArg struct {
+	SessionID int      `codec:"sessionID" json:"sessionID"`
+	TeamName  TeamName `codec:"teamName" json:"teamName"`
+}
+
+type TeamInvite struct {
+	InviteID  string   `codec:"inviteID" json:"inviteID"`
+	Role      TeamRole `codec:"role" json:"role"`
+	Contact   string   `codec:"contact" json:"contact"`
+	ExpiresAt Time     `codec:"expiresAt" json:"expiresAt"`
+	Revoked   bool     `codec:"revoked" json:"revoked"`
+}
+
+type CreateTeamInviteArg struct {
+	SessionID int      `codec:"sessionID" json:"sessionID"`
+	TeamID    TeamID   `codec:"teamID" json:"teamID"`
+	Role      TeamRole `codec:"role" json:"role"`
+	Contact   string   `codec:"contact" json:"contact"`
+	TtlSec    int      `codec:"ttlSec" json:"ttlSec"`
+}
+
+type AcceptTeamInviteArg struct {
+	SessionID   int    `codec:"sessionID" json:"sessionID"`
+	InviteToken string `codec:"inviteToken" json:"inviteToken"`
+}
+
+type ListTeamInvitesArg struct {
+	SessionID int    `codec:"sessionID" json:"sessionID"`
+	TeamID    TeamID `codec:"teamID" json:"teamID"`
+}
+
+type RevokeTeamInviteArg struct {
+	SessionID int    `codec:"sessionID" json:"sessionID"`
+	TeamID    TeamID `codec:"teamID" json:"teamID"`
+	InviteID  string `codec:"inviteID" json:"inviteID"`
+}
+
+type AdminChannelTlfNameArg struct {
+	SessionID int    `codec:"sessionID" json:"sessionID"`
+	TeamID    TeamID `codec:"teamID" json:"teamID"`
+}
+
+type TeamInterface interface {
+	AddTeamMember(context.Context, AddTeamMemberArg) error
+	RemoveTeamMember(context.Context, RemoveTeamMemberArg) error
+	EditTeamMemberRole(context.Context, EditTeamMemberRoleArg) error
+	ListTeamMembers(context.Context, ListTeamMembersArg) ([]TeamMember, error)
+	CreateSubteam(context.Context, CreateSubteamArg) (TeamID, error)
+	RenameSubteam(context.Context, RenameSubteamArg) error
+	LookupTeamByName(context.Context, LookupTeamByNameArg) (TeamID, error)
+	CreateTeamInvite(context.Context, CreateTeamInviteArg) (string, error)
+	AcceptTeamInvite(context.Context, AcceptTeamInviteArg) error
+	ListTeamInvites(context.Context, ListTeamInvitesArg) ([]TeamInvite, error)
+	RevokeTeamInvite(context.Context, RevokeTeamInviteArg) error
+	AdminChannelTlfName(context.Context, AdminChannelTlfNameArg) (string, error)
+}
+
+func TeamProtocol(i TeamInterface) rpc.Protocol {
+	return rpc.Protocol{
+		Name: "keybase.1.team",
+		Methods: map[string]rpc.ServeHandlerDescription{
+			"addTeamMember": {
+				MakeArg: func() interface{} {
+					ret := make([]AddTeamMemberArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]AddTeamMemberArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]AddTeamMemberArg)(nil), args)
+						return
+					}
+					err = i.AddTeamMember(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
+			"removeTeamMember": {
+				MakeArg: func() interface{} {
+					ret := make([]RemoveTeamMemberArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]RemoveTeamMemberArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]RemoveTeamMemberArg)(nil), args)
+						return
+					}
+					err = i.RemoveTeamMember(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
+			"editTeamMemberRole": {
+				MakeArg: func() interface{} {
+					ret := make([]EditTeamMemberRoleArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]EditTeamMemberRoleArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]EditTeamMemberRoleArg)(nil), args)
+						return
+					}
+					err = i.EditTeamMemberRole(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
+			"listTeamMembers": {
+				MakeArg: func() interface{} {
+					ret := make([]ListTeamMembersArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]ListTeamMembersArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]ListTeamMembersArg)(nil), args)
+						return
+					}
+					ret, err = i.ListTeamMembers(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
+			"createSubteam": {
+				MakeArg: func() interface{} {
+					ret := make([]CreateSubteamArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]CreateSubteamArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]CreateSubteamArg)(nil), args)
+						return
+					}
+					ret, err = i.CreateSubteam(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
+			"renameSubteam": {
+				MakeArg: func() interface{} {
+					ret := make([]RenameSubteamArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]RenameSubteamArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]RenameSubteamArg)(nil), args)
+						return
+					}
+					err = i.RenameSubteam(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
+			"lookupTeamByName": {
+				MakeArg: func() interface{} {
+					ret := make([]LookupTeamByNameArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]LookupTeamByNameArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]LookupTeamByNameArg)(nil), args)
+						return
+					}
+					ret, err = i.LookupTeamByName(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
+			"createTeamInvite": {
+				MakeArg: func() interface{} {
+					ret := make([]CreateTeamInviteArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]CreateTeamInviteArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]CreateTeamInviteArg)(nil), args)
+						return
+					}
+					ret, err = i.CreateTeamInvite(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
+			"acceptTeamInvite": {
+				MakeArg: func() interface{} {
+					ret := make([]AcceptTeamInviteArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]AcceptTeamInviteArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]AcceptTeamInviteArg)(nil), args)
+						return
+					}
+					err = i.AcceptTeamInvite(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
+			"listTeamInvites": {
+				MakeArg: func() interface{} {
+					ret := make([]ListTeamInvitesArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]ListTeamInvitesArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]ListTeamInvitesArg)(nil), args)
+						return
+					}
+					ret, err = i.ListTeamInvites(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
+			"revokeTeamInvite": {
+				MakeArg: func() interface{} {
+					ret := make([]RevokeTeamInviteArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]RevokeTeamInviteArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]RevokeTeamInviteArg)(nil), args)
+						return
+					}
+					err = i.RevokeTeamInvite(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
+			"adminChannelTlfName": {
+				MakeArg: func() interface{} {
+					ret := make([]AdminChannelTlfNameArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]AdminChannelTlfNameArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]AdminChannelTlfNameArg)(nil), args)
+						return
+					}
+					ret, err = i.AdminChannelTlfName(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
+		},
+	}
+}
+
+type TeamClient struct {
+	Cli rpc.GenericClient
+}
+
+func (c TeamClient) AddTeamMember(ctx context.Context, __arg AddTeamMemberArg) (err error) {
+	err = c.Cli.Call(ctx, "keybase.1.team.addTeamMember", []interface{}{__arg}, nil)
+	return
+}
+
+func (c TeamClient) RemoveTeamMember(ctx context.Context, __arg RemoveTeamMemberArg) (err error) {
+	err = c.Cli.Call(ctx, "keybase.1.team.removeTeamMember", []interface{}{__arg}, nil)
+	return
+}
+
+func (c TeamClient) EditTeamMemberRole(ctx context.Context, __arg EditTeamMemberRoleArg) (err error) {
+	err = c.Cli.Call(ctx, "keybase.1.team.editTeamMemberRole", []interface{}{__arg}, nil)
+	return
+}
+
+func (c TeamClient) ListTeamMembers(ctx context.Context, __arg ListTeamMembersArg) (res []TeamMember, err error) {
+	err = c.Cli.Call(ctx, "keybase.1.team.listTeamMembers", []interface{}{__arg}, &res)
+	return
+}
+
+func (c TeamClient) CreateSubteam(ctx context.Context, __arg CreateSubteamArg) (res TeamID, err error) {
+	err = c.Cli.Call(ctx, "keybase.1.team.createSubteam", []interface{}{__arg}, &res)
+	return
+}
+
+func (c TeamClient) RenameSubteam(ctx context.Context, __arg RenameSubteamArg) (err error) {
+	err = c.Cli.Call(ctx, "keybase.1.team.renameSubteam", []interface{}{__arg}, nil)
+	return
+}
+
+func (c TeamClient) LookupTeamByName(ctx context.Context, __arg LookupTeamByNameArg) (res TeamID, err error) {
+	err = c.Cli.Call(ctx, "keybase.1.team.lookupTeamByName", []interface{}{__arg}, &res)
+	return
+}
+
+func (c TeamClient) CreateTeamInvite(ctx context.Context, __arg CreateTeamInviteArg) (res string, err error) {
+	err = c.Cli.Call(ctx, "keybase.1.team.createTeamInvite", []interface{}{__arg}, &res)
+	return
+}
+
+func (c TeamClient) AcceptTeamInvite(ctx context.Context, __arg AcceptTeamInviteArg) (err error) {
+	err = c.Cli.Call(ctx, "keybase.1.team.acceptTeamInvite", []interface{}{__arg}, nil)
+	return
+}
+
+func (c TeamClient) ListTeamInvites(ctx context.Context, __arg ListTeamInvitesArg) (res []TeamInvite, err error) {
+	err = c.Cli.Call(ctx, "keybase.1.team.listTeamInvites", []interface{}{__arg}, &res)
+	return
+}
+
+func (c TeamClient) RevokeTeamInvite(ctx context.Context, __arg RevokeTeamInviteArg) (err error) {
+	err = c.Cli.Call(ctx, "keybase.1.team.revokeTeamInvite", []interface{}{__arg}, nil)
+	return
+}
+
+func (c TeamClient) AdminChannelTlfName(ctx context.Context, __arg AdminChannelTlfNameArg) (res string, err error) {
+	err = c.Cli.Call(ctx, "keybase.1.team.adminChannelTlfName", []interface{}{__arg}, &res)
+	return
+}
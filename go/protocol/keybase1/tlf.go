@@ -20,6 +20,35 @@ type CompleteAndCanonicalizePrivateTlfNameArg struct {
 	Query TLFQuery `codec:"query" json:"query"`
 }
 
+type TLFQueryBatch struct {
+	TlfNames         []string            `codec:"tlfNames" json:"tlfNames"`
+	IdentifyBehavior TLFIdentifyBehavior `codec:"identifyBehavior" json:"identifyBehavior"`
+	BypassCache      bool                `codec:"bypassCache" json:"bypassCache"`
+}
+
+type CryptKeysBatchItem struct {
+	TlfName string             `codec:"tlfName" json:"tlfName"`
+	Res     GetTLFCryptKeysRes `codec:"res" json:"res"`
+	Err     string             `codec:"err" json:"err"`
+}
+
+type CryptKeysBatchArg struct {
+	Query TLFQueryBatch `codec:"query" json:"query"`
+}
+
+type SetTlfIdentifyBehaviorArg struct {
+	TlfName  string              `codec:"tlfName" json:"tlfName"`
+	Behavior TLFIdentifyBehavior `codec:"behavior" json:"behavior"`
+}
+
+type GetTlfIdentifyBehaviorArg struct {
+	TlfName string `codec:"tlfName" json:"tlfName"`
+}
+
+type ClearTlfIdentifyBehaviorArg struct {
+	TlfName string `codec:"tlfName" json:"tlfName"`
+}
+
 type TlfInterface interface {
 	// CryptKeys returns TLF crypt keys from all generations.
 	CryptKeys(context.Context, TLFQuery) (GetTLFCryptKeysRes, error)
@@ -27,6 +56,24 @@ type TlfInterface interface {
 	// * TLFID should not be cached or stored persistently.
 	PublicCanonicalTLFNameAndID(context.Context, TLFQuery) (CanonicalTLFNameAndIDWithBreaks, error)
 	CompleteAndCanonicalizePrivateTlfName(context.Context, TLFQuery) (CanonicalTLFNameAndIDWithBreaks, error)
+	// CryptKeysBatch resolves crypt keys for many TLF names in a single round
+	// trip, identifying each name concurrently (see tlfValidateParallelism in
+	// the service for the concurrency bound). Intended for callers, like chat
+	// at startup, that would otherwise pay a full CryptKeys round trip per
+	// conversation.
+	CryptKeysBatch(context.Context, TLFQueryBatch) ([]CryptKeysBatchItem, error)
+	// setTlfIdentifyBehavior overrides the identify behavior used for tlfName
+	// in CryptKeys and publicCanonicalTLFNameAndID, until cleared with
+	// clearTlfIdentifyBehavior. Intended for a client to require stricter
+	// identify (e.g. CHAT_GUI_STRICT) on a conversation it considers
+	// sensitive, independent of whatever behavior other callers request.
+	SetTlfIdentifyBehavior(context.Context, SetTlfIdentifyBehaviorArg) error
+	// getTlfIdentifyBehavior returns the override installed by
+	// setTlfIdentifyBehavior for tlfName, if any.
+	GetTlfIdentifyBehavior(context.Context, string) (*TLFIdentifyBehavior, error)
+	// clearTlfIdentifyBehavior removes any override installed by
+	// setTlfIdentifyBehavior for tlfName.
+	ClearTlfIdentifyBehavior(context.Context, string) error
 }
 
 func TlfProtocol(i TlfInterface) rpc.Protocol {
@@ -81,6 +128,70 @@ func TlfProtocol(i TlfInterface) rpc.Protocol {
 				},
 				MethodType: rpc.MethodCall,
 			},
+			"CryptKeysBatch": {
+				MakeArg: func() interface{} {
+					ret := make([]CryptKeysBatchArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]CryptKeysBatchArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]CryptKeysBatchArg)(nil), args)
+						return
+					}
+					ret, err = i.CryptKeysBatch(ctx, (*typedArgs)[0].Query)
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
+			"setTlfIdentifyBehavior": {
+				MakeArg: func() interface{} {
+					ret := make([]SetTlfIdentifyBehaviorArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]SetTlfIdentifyBehaviorArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]SetTlfIdentifyBehaviorArg)(nil), args)
+						return
+					}
+					err = i.SetTlfIdentifyBehavior(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
+			"getTlfIdentifyBehavior": {
+				MakeArg: func() interface{} {
+					ret := make([]GetTlfIdentifyBehaviorArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]GetTlfIdentifyBehaviorArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]GetTlfIdentifyBehaviorArg)(nil), args)
+						return
+					}
+					ret, err = i.GetTlfIdentifyBehavior(ctx, (*typedArgs)[0].TlfName)
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
+			"clearTlfIdentifyBehavior": {
+				MakeArg: func() interface{} {
+					ret := make([]ClearTlfIdentifyBehaviorArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]ClearTlfIdentifyBehaviorArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]ClearTlfIdentifyBehaviorArg)(nil), args)
+						return
+					}
+					err = i.ClearTlfIdentifyBehavior(ctx, (*typedArgs)[0].TlfName)
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
 		},
 	}
 }
@@ -109,3 +220,40 @@ func (c TlfClient) CompleteAndCanonicalizePrivateTlfName(ctx context.Context, qu
 	err = c.Cli.Call(ctx, "keybase.1.tlf.completeAndCanonicalizePrivateTlfName", []interface{}{__arg}, &res)
 	return
 }
+
+// CryptKeysBatch resolves crypt keys for many TLF names in a single round
+// trip, identifying each name concurrently (see tlfValidateParallelism in
+// the service for the concurrency bound). Intended for callers, like chat
+// at startup, that would otherwise pay a full CryptKeys round trip per
+// conversation.
+func (c TlfClient) CryptKeysBatch(ctx context.Context, query TLFQueryBatch) (res []CryptKeysBatchItem, err error) {
+	__arg := CryptKeysBatchArg{Query: query}
+	err = c.Cli.Call(ctx, "keybase.1.tlf.CryptKeysBatch", []interface{}{__arg}, &res)
+	return
+}
+
+// setTlfIdentifyBehavior overrides the identify behavior used for tlfName
+// in CryptKeys and publicCanonicalTLFNameAndID, until cleared with
+// clearTlfIdentifyBehavior. Intended for a client to require stricter
+// identify (e.g. CHAT_GUI_STRICT) on a conversation it considers
+// sensitive, independent of whatever behavior other callers request.
+func (c TlfClient) SetTlfIdentifyBehavior(ctx context.Context, __arg SetTlfIdentifyBehaviorArg) (err error) {
+	err = c.Cli.Call(ctx, "keybase.1.tlf.setTlfIdentifyBehavior", []interface{}{__arg}, nil)
+	return
+}
+
+// getTlfIdentifyBehavior returns the override installed by
+// setTlfIdentifyBehavior for tlfName, if any.
+func (c TlfClient) GetTlfIdentifyBehavior(ctx context.Context, tlfName string) (res *TLFIdentifyBehavior, err error) {
+	__arg := GetTlfIdentifyBehaviorArg{TlfName: tlfName}
+	err = c.Cli.Call(ctx, "keybase.1.tlf.getTlfIdentifyBehavior", []interface{}{__arg}, &res)
+	return
+}
+
+// clearTlfIdentifyBehavior removes any override installed by
+// setTlfIdentifyBehavior for tlfName.
+func (c TlfClient) ClearTlfIdentifyBehavior(ctx context.Context, tlfName string) (err error) {
+	__arg := ClearTlfIdentifyBehaviorArg{TlfName: tlfName}
+	err = c.Cli.Call(ctx, "keybase.1.tlf.clearTlfIdentifyBehavior", []interface{}{__arg}, nil)
+	return
+}
@@ -11,21 +11,23 @@ import (
 type TLFIdentifyBehavior int
 
 const (
-	TLFIdentifyBehavior_DEFAULT_KBFS    TLFIdentifyBehavior = 0
-	TLFIdentifyBehavior_CHAT_CLI        TLFIdentifyBehavior = 1
-	TLFIdentifyBehavior_CHAT_GUI        TLFIdentifyBehavior = 2
-	TLFIdentifyBehavior_CHAT_GUI_STRICT TLFIdentifyBehavior = 3
-	TLFIdentifyBehavior_KBFS_REKEY      TLFIdentifyBehavior = 4
-	TLFIdentifyBehavior_KBFS_QR         TLFIdentifyBehavior = 5
+	TLFIdentifyBehavior_DEFAULT_KBFS         TLFIdentifyBehavior = 0
+	TLFIdentifyBehavior_CHAT_CLI             TLFIdentifyBehavior = 1
+	TLFIdentifyBehavior_CHAT_GUI             TLFIdentifyBehavior = 2
+	TLFIdentifyBehavior_CHAT_GUI_STRICT      TLFIdentifyBehavior = 3
+	TLFIdentifyBehavior_KBFS_REKEY           TLFIdentifyBehavior = 4
+	TLFIdentifyBehavior_KBFS_QR              TLFIdentifyBehavior = 5
+	TLFIdentifyBehavior_CHAT_GUI_STRICT_TOFU TLFIdentifyBehavior = 6
 )
 
 var TLFIdentifyBehaviorMap = map[string]TLFIdentifyBehavior{
-	"DEFAULT_KBFS":    0,
-	"CHAT_CLI":        1,
-	"CHAT_GUI":        2,
-	"CHAT_GUI_STRICT": 3,
-	"KBFS_REKEY":      4,
-	"KBFS_QR":         5,
+	"DEFAULT_KBFS":         0,
+	"CHAT_CLI":             1,
+	"CHAT_GUI":             2,
+	"CHAT_GUI_STRICT":      3,
+	"KBFS_REKEY":           4,
+	"KBFS_QR":              5,
+	"CHAT_GUI_STRICT_TOFU": 6,
 }
 
 var TLFIdentifyBehaviorRevMap = map[TLFIdentifyBehavior]string{
@@ -35,6 +37,7 @@ var TLFIdentifyBehaviorRevMap = map[TLFIdentifyBehavior]string{
 	3: "CHAT_GUI_STRICT",
 	4: "KBFS_REKEY",
 	5: "KBFS_QR",
+	6: "CHAT_GUI_STRICT_TOFU",
 }
 
 func (e TLFIdentifyBehavior) String() string {
@@ -44,6 +47,36 @@ func (e TLFIdentifyBehavior) String() string {
 	return ""
 }
 
+type TLFIdentifyFailureSeverity int
+
+const (
+	TLFIdentifyFailureSeverity_NONE          TLFIdentifyFailureSeverity = 0
+	TLFIdentifyFailureSeverity_KEY_CHANGED   TLFIdentifyFailureSeverity = 1
+	TLFIdentifyFailureSeverity_DELETED_PROOF TLFIdentifyFailureSeverity = 2
+	TLFIdentifyFailureSeverity_REVOKED_PROOF TLFIdentifyFailureSeverity = 3
+)
+
+var TLFIdentifyFailureSeverityMap = map[string]TLFIdentifyFailureSeverity{
+	"NONE":          0,
+	"KEY_CHANGED":   1,
+	"DELETED_PROOF": 2,
+	"REVOKED_PROOF": 3,
+}
+
+var TLFIdentifyFailureSeverityRevMap = map[TLFIdentifyFailureSeverity]string{
+	0: "NONE",
+	1: "KEY_CHANGED",
+	2: "DELETED_PROOF",
+	3: "REVOKED_PROOF",
+}
+
+func (e TLFIdentifyFailureSeverity) String() string {
+	if v, ok := TLFIdentifyFailureSeverityRevMap[e]; ok {
+		return v
+	}
+	return ""
+}
+
 type CanonicalTlfName string
 type CryptKey struct {
 	KeyGeneration int     `codec:"KeyGeneration" json:"KeyGeneration"`
@@ -57,6 +90,16 @@ type TLFBreak struct {
 type TLFIdentifyFailure struct {
 	User   User                 `codec:"user" json:"user"`
 	Breaks *IdentifyTrackBreaks `codec:"breaks,omitempty" json:"breaks,omitempty"`
+
+	// Severity is the most severe failure type found in Breaks, so a
+	// caller merging failures for the same user from multiple sources can
+	// pick the worse of the two without re-inspecting Breaks itself.
+	Severity TLFIdentifyFailureSeverity `codec:"severity" json:"severity"`
+
+	// Source records which caller merged this failure in, e.g. "chat" for
+	// failures already accumulated by a chat identify pass, or "kbfs" for
+	// ones freshly reported by a KBFS RPC response.
+	Source string `codec:"source" json:"source"`
 }
 
 type CanonicalTLFNameAndIDWithBreaks struct {
@@ -73,12 +116,23 @@ type GetTLFCryptKeysRes struct {
 type TLFQuery struct {
 	TlfName          string              `codec:"tlfName" json:"tlfName"`
 	IdentifyBehavior TLFIdentifyBehavior `codec:"identifyBehavior" json:"identifyBehavior"`
+	BypassCache      bool                `codec:"bypassCache" json:"bypassCache"`
+}
+
+type TLFQueryWithID struct {
+	TlfID            TLFID               `codec:"tlfID" json:"tlfID"`
+	IdentifyBehavior TLFIdentifyBehavior `codec:"identifyBehavior" json:"identifyBehavior"`
+	BypassCache      bool                `codec:"bypassCache" json:"bypassCache"`
 }
 
 type GetTLFCryptKeysArg struct {
 	Query TLFQuery `codec:"query" json:"query"`
 }
 
+type GetTLFCryptKeysByIDArg struct {
+	Query TLFQueryWithID `codec:"query" json:"query"`
+}
+
 type GetPublicCanonicalTLFNameAndIDArg struct {
 	Query TLFQuery `codec:"query" json:"query"`
 }
@@ -87,6 +141,9 @@ type TlfKeysInterface interface {
 	// getTLFCryptKeys returns TLF crypt keys from all generations and the TLF ID.
 	// TLF ID should not be cached or stored persistently.
 	GetTLFCryptKeys(context.Context, TLFQuery) (GetTLFCryptKeysRes, error)
+	// getTLFCryptKeysById returns TLF crypt keys from all generations, resolved
+	// directly from a TLF ID instead of a name.
+	GetTLFCryptKeysByID(context.Context, TLFQueryWithID) (GetTLFCryptKeysRes, error)
 	// getPublicCanonicalTLFNameAndID return the canonical name and TLFID for tlfName.
 	// TLF ID should not be cached or stored persistently.
 	GetPublicCanonicalTLFNameAndID(context.Context, TLFQuery) (CanonicalTLFNameAndIDWithBreaks, error)
@@ -112,6 +169,22 @@ func TlfKeysProtocol(i TlfKeysInterface) rpc.Protocol {
 				},
 				MethodType: rpc.MethodCall,
 			},
+			"getTLFCryptKeysById": {
+				MakeArg: func() interface{} {
+					ret := make([]GetTLFCryptKeysByIDArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]GetTLFCryptKeysByIDArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]GetTLFCryptKeysByIDArg)(nil), args)
+						return
+					}
+					ret, err = i.GetTLFCryptKeysByID(ctx, (*typedArgs)[0].Query)
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
 			"getPublicCanonicalTLFNameAndID": {
 				MakeArg: func() interface{} {
 					ret := make([]GetPublicCanonicalTLFNameAndIDArg, 1)
@@ -144,6 +217,14 @@ func (c TlfKeysClient) GetTLFCryptKeys(ctx context.Context, query TLFQuery) (res
 	return
 }
 
+// getTLFCryptKeysById returns TLF crypt keys from all generations, resolved
+// directly from a TLF ID instead of a name.
+func (c TlfKeysClient) GetTLFCryptKeysByID(ctx context.Context, query TLFQueryWithID) (res GetTLFCryptKeysRes, err error) {
+	__arg := GetTLFCryptKeysByIDArg{Query: query}
+	err = c.Cli.Call(ctx, "keybase.1.tlfKeys.getTLFCryptKeysById", []interface{}{__arg}, &res)
+	return
+}
+
 // getPublicCanonicalTLFNameAndID return the canonical name and TLFID for tlfName.
 // TLF ID should not be cached or stored persistently.
 func (c TlfKeysClient) GetPublicCanonicalTLFNameAndID(ctx context.Context, query TLFQuery) (res CanonicalTLFNameAndIDWithBreaks, err error) {
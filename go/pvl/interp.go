@@ -6,7 +6,6 @@ package pvl
 import (
 	"bytes"
 	b64 "encoding/base64"
-	"net"
 	"net/url"
 	"regexp"
 	"strings"
@@ -449,13 +448,8 @@ func runDNS(g proofContextExt, userdomain string, scripts []scriptT, mknewstate
 // Run each script on each TXT record of the domain.
 func runDNSOne(g proofContextExt, domain string, scripts []scriptT, mknewstate stateMaker, sigIDMedium string) libkb.ProofError {
 	// Fetch TXT records
-	var txts []string
-	var err error
-	if g.getStubDNS() == nil {
-		txts, err = net.LookupTXT(domain)
-	} else {
-		txts, err = g.getStubDNS().LookupTXT(domain)
-	}
+	txts, backend, err := lookupTXT(g, domain)
+	debug(g, "DNS lookup for %s answered by backend: %s", domain, backend)
 
 	if err != nil {
 		return libkb.NewProofError(keybase1.ProofStatus_DNS_ERROR,
@@ -11,6 +11,20 @@ import (
 	"github.com/keybase/client/go/protocol/keybase1"
 )
 
+// ValidateStructure checks that in is a well-formed pvl document: it
+// parses, declares pvl_version and revision, and every per-service
+// script's instructions each fill exactly one variant. It does not run
+// any instruction, and does not check pvl_version against
+// SupportedVersion, since callers validating a freshly-fetched kit want
+// to catch a broken document regardless of which version it targets.
+// It's exported for callers like pvlsource that want to reject a
+// malformed pvl document up front, rather than have it surface later as
+// an obscure error deep in CheckProof.
+func ValidateStructure(in string) error {
+	_, err := parse(in)
+	return err
+}
+
 func parse(in string) (pvlT, error) {
 	b := []byte(in)
 	p := pvlT{}
@@ -37,6 +51,13 @@ type pvlT struct {
 	// services is a map from service to a list of scripts.
 	// each script is a list of instructions.
 	Services servicesT `json:"services"`
+	// service_metadata is an optional map from service to display
+	// information for that service. It lets the server introduce a proof
+	// service (display name, prompt, assertion keys) that this pvl already
+	// knows how to check, without a client release beyond the one that
+	// first taught the client the service's keybase1.ProofType. Absent for
+	// every service handled by hardcoded client code.
+	ServiceMetadata serviceMetadataT `json:"service_metadata"`
 }
 
 type servicesT struct {
@@ -63,6 +84,50 @@ func (x *servicesT) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// ServiceMetadata is the display information for a service that this pvl
+// document knows how to verify, keyed by keybase1.ProofType. It's meant
+// for services the server wants to light up without shipping new
+// PreProofCheck/instruction-formatting Go code first: DisplayName, Prompt
+// and Keys are enough to register a generic externals.ServiceType.
+type ServiceMetadata struct {
+	DisplayName string   `json:"display_name"`
+	Prompt      string   `json:"prompt"`
+	Keys        []string `json:"keys"`
+}
+
+type serviceMetadataT struct {
+	Map map[keybase1.ProofType]ServiceMetadata
+}
+
+func (x *serviceMetadataT) UnmarshalJSON(b []byte) error {
+	m := make(map[string]ServiceMetadata)
+	err := json.Unmarshal(b, &m)
+	if err != nil {
+		return err
+	}
+	x.Map = make(map[keybase1.ProofType]ServiceMetadata)
+	for k, v := range m {
+		t, ok := keybase1.ProofTypeMap[strings.ToUpper(k)]
+		if ok {
+			x.Map[t] = v
+		}
+		// Unrecognized proof types are discarded silently, same as services above.
+	}
+	return nil
+}
+
+// ExtractServiceMetadata parses a pvl document and returns its optional
+// service_metadata table. It's exported for externals.RegisterFromKit,
+// which uses it to register client-side ServiceTypes for services that
+// don't have hardcoded Go support yet.
+func ExtractServiceMetadata(pvlString string) (map[keybase1.ProofType]ServiceMetadata, error) {
+	p, err := parse(pvlString)
+	if err != nil {
+		return nil, err
+	}
+	return p.ServiceMetadata.Map, nil
+}
+
 type scriptT struct {
 	Instructions []instructionT
 }
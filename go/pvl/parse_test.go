@@ -20,6 +20,21 @@ func TestParse(t *testing.T) {
 	}
 }
 
+// TestValidateStructure checks that ValidateStructure accepts a
+// well-formed document and rejects both unparseable JSON and a document
+// missing required top-level fields.
+func TestValidateStructure(t *testing.T) {
+	if err := ValidateStructure(testPvlString); err != nil {
+		t.Fatalf("expected a well-formed document to validate, got: %s", err)
+	}
+	if err := ValidateStructure("not json"); err == nil {
+		t.Fatal("expected an error for unparseable JSON")
+	}
+	if err := ValidateStructure(`{"revision":1,"services":{}}`); err == nil {
+		t.Fatal("expected an error for a missing pvl_version")
+	}
+}
+
 // TestParse2 checks a few of the parse output's details.
 func TestParse2(t *testing.T) {
 	p, err := parse(testPvlString)
@@ -48,6 +63,45 @@ func TestParse2(t *testing.T) {
 	}
 }
 
+// TestExtractServiceMetadata checks that an optional service_metadata
+// table is parsed into the right ProofType keys, and that a document
+// without one at all (like testPvlString) returns an empty map rather
+// than an error.
+func TestExtractServiceMetadata(t *testing.T) {
+	metadata, err := ExtractServiceMetadata(testPvlString)
+	if err != nil {
+		t.Fatalf("ExtractServiceMetadata failed: %v", err)
+	}
+	if len(metadata) != 0 {
+		t.Fatalf("expected no service_metadata, got %v", metadata)
+	}
+
+	const withMetadata = `
+	{
+	  "pvl_version": 1,
+	  "revision": 1,
+	  "services": {},
+	  "service_metadata": {
+	    "rooter": {
+	      "display_name": "Rooter",
+	      "prompt": "Your username on Rooter",
+	      "keys": ["rooter"]
+	    }
+	  }
+	}`
+	metadata, err = ExtractServiceMetadata(withMetadata)
+	if err != nil {
+		t.Fatalf("ExtractServiceMetadata failed: %v", err)
+	}
+	meta, ok := metadata[keybase1.ProofType_ROOTER]
+	if !ok {
+		t.Fatalf("expected a rooter entry")
+	}
+	if meta.DisplayName != "Rooter" || len(meta.Keys) != 1 || meta.Keys[0] != "rooter" {
+		t.Fatalf("unexpected metadata: %+v", meta)
+	}
+}
+
 var testPvlString = `
   {
   "pvl_version": 1,
@@ -0,0 +1,19 @@
+// Copyright 2017 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+// +build gofuzz
+
+package pvlsource
+
+// Fuzz is the go-fuzz entry point for parsePvlKit. Build and run with:
+//
+//   go-fuzz-build github.com/keybase/client/go/pvlsource
+//   go-fuzz -bin=./pvlsource-fuzz.zip -workdir=fuzz-workdir
+//
+// parsePvlKit must never panic regardless of input; a crash here is a bug.
+func Fuzz(data []byte) int {
+	if _, err := parsePvlKit(string(data)); err != nil {
+		return 0
+	}
+	return 1
+}
@@ -4,26 +4,77 @@
 package pvlsource
 
 import (
+	"bytes"
 	"crypto/sha512"
 	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
+	mathrand "math/rand"
+	"os"
 	"sync"
 	"time"
 
 	"golang.org/x/net/context"
 
+	"github.com/golang/groupcache/singleflight"
 	"github.com/keybase/client/go/libkb"
+	"github.com/keybase/client/go/pvl"
 )
 
-// Older than this will try to refresh merkle root.
+// How often StartBackgroundRefresh re-fetches the kit, to keep a
+// long-lived service (e.g. the keybase service itself, or KBFS) from
+// ever serving a pvl that's on the verge of tRequireRefresh.
+const tBackgroundRefreshInterval time.Duration = 30 * time.Minute
+
+// Older than this will try to refresh merkle root. Default for
+// Env.GetPvlShouldRefreshInterval; see refreshThresholds.
 // Measures time since merkle root fetched, not time since published.
 const tShouldRefresh time.Duration = 1 * time.Hour
 
-// Older than this is too old to use. All identifies will fail.
+// Older than this is too old to use. All identifies will fail, unless
+// degraded mode is enabled (see Env.GetPvlAllowDegraded) and the root is
+// still within tDegradedGraceWindow of tRequireRefresh. Default for
+// Env.GetPvlRequireRefreshInterval; see refreshThresholds.
 // Measures time since merkle root fetched, not time since published.
 const tRequireRefresh time.Duration = 24 * time.Hour
 
+// In degraded mode, how much further past tRequireRefresh a cached kit
+// may still be served. Beyond this, even degraded mode gives up: a kit
+// that old is more likely to be simply wrong than merely stale.
+const tDegradedGraceWindow time.Duration = 7 * 24 * time.Hour
+
+// How far into the future a kit's ctime may be before validateKit
+// considers it bogus rather than just clock skew between this client
+// and whatever stamped the kit.
+const tCtimeFutureSlop time.Duration = 1 * time.Hour
+
+// Bound on the whole GetKitString operation (merkle refresh + cache
+// lookups + server fetch), so a hung merkle or API call can't wedge a
+// caller forever.
+const tGetKitStringTimeout time.Duration = 30 * time.Second
+
+// Bound on a single merkle/pvl fetch attempt, distinct from
+// tGetKitStringTimeout above which bounds the whole GetKitString call
+// (merkle refresh + cache lookups + fetch, including any retries here).
+const tFetchAttemptTimeout time.Duration = 10 * time.Second
+
+// How many times fetch will try the server before giving up and
+// returning the last error to its caller. Retries are spaced out with
+// libkb.BackoffDefault, the same jittered backoff used by the S3
+// attachment uploader.
+const tFetchMaxAttempts = 3
+
+// Bound on how long StopBackgroundRefresh's shutdown will wait for
+// queued-but-not-yet-written db writes to flush, so a wedged db can't
+// hang shutdown forever.
+const tDBWriteDrainTimeout time.Duration = 5 * time.Second
+
+// How many db writes GetKitString/SwapKitIfMatching may have queued up
+// but not yet flushed to disk. pvl updates are infrequent, so this only
+// needs enough headroom to smooth over a burst, not to buffer forever.
+const dbWriteQueueSize = 8
+
 var dbKey = libkb.DbKey{
 	Typ: libkb.DBPvl,
 	Key: "active",
@@ -45,7 +96,56 @@ type PvlSourceImpl struct {
 	libkb.Contextified
 	sync.Mutex
 
-	mem *entry
+	mem       *entry
+	listeners []libkb.PvlUpdateListener
+	stopCh    chan struct{}
+	dbWriteCh chan dbWriteRequest
+	ageStats  PvlAgeStats
+	degraded  bool
+
+	// sfGroup dedups concurrent server fetches for the same hash; see
+	// fetchDeduped. Its zero value is ready to use.
+	sfGroup singleflight.Group
+
+	// lastSource and lastFetchErr back Diagnostics; lastSource is the
+	// cache tier (mem/db/server) that most recently served a kit, and
+	// lastFetchErr is the error from the most recent failed server
+	// fetch, cleared on the next successful one.
+	lastSource   string
+	lastFetchErr error
+
+	fileCacheMu sync.Mutex
+	fileCache   *fileKitCache
+}
+
+// fileKitCache holds the last kit read (and successfully validated) from
+// a local kit file override, so a hot-reloading caller that polls
+// GetKitString frequently doesn't re-read and re-parse the file on every
+// call, only when its mtime actually changes.
+type fileKitCache struct {
+	path    string
+	modTime time.Time
+	kitJSON string
+}
+
+// PvlAgeStats tracks the age (kit.Ctime to time served), in seconds, of
+// the pvl kits this source has actually handed to a caller. Useful as a
+// coarse telemetry signal for noticing that callers are stuck being
+// served an ever-staler kit.
+type PvlAgeStats struct {
+	Count      int
+	MinAgeSecs int64
+	MaxAgeSecs int64
+	SumAgeSecs int64
+}
+
+// AverageAgeSecs returns the mean served-kit age, or 0 if nothing has
+// been served yet.
+func (a PvlAgeStats) AverageAgeSecs() float64 {
+	if a.Count == 0 {
+		return 0
+	}
+	return float64(a.SumAgeSecs) / float64(a.Count)
 }
 
 var _ libkb.PvlSource = (*PvlSourceImpl)(nil)
@@ -56,28 +156,381 @@ func NewPvlSourceAndInstall(g *libkb.GlobalContext) libkb.PvlSource {
 		Contextified: libkb.NewContextified(g),
 	}
 	g.SetPvlSource(s)
+	s.StartBackgroundRefresh()
+	g.PushShutdownHook(func() error {
+		s.StopBackgroundRefresh()
+		return nil
+	})
 	return s
 }
 
+// jitteredInterval returns base plus or minus up to 20%, so that many
+// instances of a long-lived service started around the same time don't
+// all hit the merkle/pvl endpoints in lockstep.
+func jitteredInterval(base time.Duration) time.Duration {
+	jitter := time.Duration(mathrand.Int63n(int64(base) / 5)) // up to 20% of base
+	if mathrand.Intn(2) == 0 {
+		return base - jitter
+	}
+	return base + jitter
+}
+
+// StartBackgroundRefresh starts a goroutine that periodically checks the
+// merkle root and prefetches a fresh pvl kit before it goes stale, so a
+// long-lived service always has a warm cache and never runs the risk of
+// hitting tRequireRefresh cold, on the critical path of an identify.
+// Intervals are jittered to avoid a thundering herd across instances.
+// It's a no-op if already started. Stop with StopBackgroundRefresh.
+func (s *PvlSourceImpl) StartBackgroundRefresh() {
+	s.Lock()
+	if s.stopCh != nil {
+		s.Unlock()
+		return
+	}
+	stopCh := make(chan struct{})
+	dbWriteCh := make(chan dbWriteRequest, dbWriteQueueSize)
+	s.stopCh = stopCh
+	s.dbWriteCh = dbWriteCh
+	s.Unlock()
+
+	go s.runDBWriteQueue(dbWriteCh, stopCh)
+
+	go func() {
+		timer := time.NewTimer(jitteredInterval(tBackgroundRefreshInterval))
+		defer timer.Stop()
+		for {
+			select {
+			case <-timer.C:
+				ctx := libkb.WithLogTag(context.Background(), "PVL")
+				if _, err := s.GetKitString(ctx); err != nil {
+					s.G().Log.CWarningf(ctx, "PvlSource: background refresh failed: %s", err)
+				}
+				timer.Reset(jitteredInterval(tBackgroundRefreshInterval))
+			case <-stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// StopBackgroundRefresh stops the goroutine started by
+// StartBackgroundRefresh, and gives the db write queue up to
+// tDBWriteDrainTimeout to flush whatever's still pending. It's a no-op if
+// not running.
+func (s *PvlSourceImpl) StopBackgroundRefresh() {
+	s.Lock()
+	if s.stopCh == nil {
+		s.Unlock()
+		return
+	}
+	stopCh := s.stopCh
+	s.stopCh = nil
+	s.dbWriteCh = nil
+	s.Unlock()
+	close(stopCh)
+}
+
+// dbWriteRequest is one pending write to the on-disk pvl cache, queued by
+// queueDBWrite and flushed by runDBWriteQueue.
+type dbWriteRequest struct {
+	hash string
+	pvl  string
+}
+
+// queueDBWrite schedules an async write of hash/pvl to the on-disk cache.
+// While the background-refresh worker is running, the write is handed to
+// its shutdown-aware queue, so a write in flight when the service shuts
+// down gets a chance to flush instead of being abandoned mid-write via an
+// untracked context.Background() goroutine. If the queue is full (pvl
+// updates are rare, so this would mean something is very wrong) or the
+// worker isn't running at all (e.g. a PvlSourceImpl used directly in
+// tests, without StartBackgroundRefresh), it falls back to firing off the
+// write in its own goroutine, same as before.
+func (s *PvlSourceImpl) queueDBWrite(hash string, pvl string) {
+	s.Lock()
+	dbWriteCh := s.dbWriteCh
+	s.Unlock()
+
+	if dbWriteCh != nil {
+		select {
+		case dbWriteCh <- dbWriteRequest{hash: hash, pvl: pvl}:
+			return
+		default:
+			s.G().Log.Warning("PvlSource: db write queue full, falling back to unqueued write for hash %s", hash)
+		}
+	}
+	go s.dbSet(context.Background(), hash, pvl)
+}
+
+// runDBWriteQueue flushes queued db writes until stopCh closes, then
+// drains whatever's left, up to tDBWriteDrainTimeout, before returning.
+func (s *PvlSourceImpl) runDBWriteQueue(reqs <-chan dbWriteRequest, stopCh <-chan struct{}) {
+	for {
+		select {
+		case req := <-reqs:
+			s.dbSet(context.Background(), req.hash, req.pvl)
+		case <-stopCh:
+			s.drainDBWriteQueue(reqs)
+			return
+		}
+	}
+}
+
+// drainDBWriteQueue flushes whatever's left in reqs, up to
+// tDBWriteDrainTimeout, so a shutdown doesn't silently lose a just-queued
+// write but also can't hang forever on a wedged db.
+func (s *PvlSourceImpl) drainDBWriteQueue(reqs <-chan dbWriteRequest) {
+	deadline := time.Now().Add(tDBWriteDrainTimeout)
+	for time.Now().Before(deadline) {
+		select {
+		case req := <-reqs:
+			s.dbSet(context.Background(), req.hash, req.pvl)
+		default:
+			return
+		}
+	}
+}
+
 type pvlKitT struct {
 	KitVersion int                     `json:"kit_version"`
 	Ctime      int                     `json:"ctime"`
 	Tab        map[int]json.RawMessage `json:"tab"`
 }
 
+// pvlKitMaxSize bounds how large a pvl kit we are willing to even attempt
+// to parse. The kit comes from the network (hash-checked against the
+// merkle root, but the root itself could in principle point at something
+// huge), so this is a defense-in-depth cap, not the primary trust check.
+const pvlKitMaxSize = 1 << 20 // 1MB
+
+// parsePvlKit is the single hardened entry point for turning a pvl kit's
+// raw JSON into a pvlKitT. Since the kit is untrusted input (its only
+// validation is a hash match against the merkle root, which says nothing
+// about its shape), this recovers from any panic in the standard decoder
+// and enforces a size limit, so a single malformed-but-hash-matching kit
+// can't crash the service.
+func parsePvlKit(kitJSON string) (kit pvlKitT, err error) {
+	if len(kitJSON) > pvlKitMaxSize {
+		return pvlKitT{}, libkb.NewPvlParseError("kit too large: %d bytes", len(kitJSON))
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			kit = pvlKitT{}
+			err = libkb.NewPvlParseError("panic while unmarshalling kit: %v", r)
+		}
+	}()
+
+	if jsonErr := json.Unmarshal([]byte(kitJSON), &kit); jsonErr != nil {
+		return pvlKitT{}, libkb.NewPvlParseError("unmarshalling kit: %s", describeJSONError(kitJSON, jsonErr))
+	}
+	return kit, nil
+}
+
+// describeJSONError adds a line/column locator to the errors json returns
+// with a byte offset, since those offsets aren't very actionable on
+// their own for someone debugging a hand-edited local kit file.
+func describeJSONError(data string, err error) string {
+	var offset int64
+	switch e := err.(type) {
+	case *json.SyntaxError:
+		offset = e.Offset
+	case *json.UnmarshalTypeError:
+		offset = e.Offset
+	default:
+		return err.Error()
+	}
+	line, col := 1, 1
+	for i, ch := range data {
+		if int64(i) >= offset {
+			break
+		}
+		if ch == '\n' {
+			line++
+			col = 1
+		} else {
+			col++
+		}
+	}
+	return fmt.Sprintf("%s (line %d, col %d)", err, line, col)
+}
+
 // Get PVL to use.
 func (s *PvlSourceImpl) GetPVL(ctx context.Context, pvlVersion int) (string, error) {
-	kitJSON, err := s.GetKitString(ctx)
+	kit, err := s.getValidatedKit(ctx)
 	if err != nil {
 		return "", err
 	}
+	return pvlForVersion(kit, pvlVersion)
+}
 
-	var kit pvlKitT
-	err = json.Unmarshal([]byte(kitJSON), &kit)
+// GetPVLInRange is like GetPVL but, instead of requiring an exact version
+// match, picks the highest version available in the kit within
+// [minVersion, maxVersion] inclusive. This lets a caller written against
+// an older protocol tolerate a kit that has already dropped its exact
+// preferred version, as long as some compatible version remains.
+func (s *PvlSourceImpl) GetPVLInRange(ctx context.Context, minVersion int, maxVersion int) (string, error) {
+	kit, err := s.getValidatedKit(ctx)
 	if err != nil {
-		return "", libkb.NewPvlSourceError("unmarshalling kit: %s", err)
+		return "", err
+	}
+
+	best := -1
+	for v := range kit.Tab {
+		if v < minVersion || v > maxVersion {
+			continue
+		}
+		if v > best {
+			best = v
+		}
+	}
+	if best == -1 {
+		return "", libkb.NewPvlSourceError("no pvl available in range [%d, %d]", minVersion, maxVersion)
+	}
+	return pvlForVersion(kit, best)
+}
+
+// getValidatedKit fetches and parses the active pvl kit, running the usual
+// tab-key and schema validation, shared by GetPVL and GetPVLInRange.
+func (s *PvlSourceImpl) getValidatedKit(ctx context.Context) (pvlKitT, error) {
+	ctx = libkb.WithLogTag(ctx, "PVL")
+	kitJSON, err := s.GetKitString(ctx)
+	if err != nil {
+		return pvlKitT{}, err
+	}
+
+	if err := s.validateKitSignature(kitJSON); err != nil {
+		return pvlKitT{}, err
+	}
+
+	kit, err := parsePvlKit(kitJSON)
+	if err != nil {
+		return pvlKitT{}, err
+	}
+
+	strict := s.G().Env.GetPvlKitStrict()
+	if err := s.validateKitTab(ctx, kit, strict); err != nil {
+		return pvlKitT{}, err
+	}
+	if err := s.validateKit(ctx, kit, strict); err != nil {
+		return pvlKitT{}, err
+	}
+	s.recordServedAge(kit)
+	return kit, nil
+}
+
+// PvlKitSnapshot is a read-only, already-parsed copy of the active pvl
+// kit. Unlike calling GetPVL repeatedly, looking up a version on a
+// snapshot never touches PvlSourceImpl's lock or cache, so it's cheap
+// to hand the same snapshot to many concurrent identify evaluators that
+// only need a consistent view for the duration of one identify.
+type PvlKitSnapshot struct {
+	hash string
+	kit  pvlKitT
+}
+
+// Hash is the hash of the kit this snapshot was taken from.
+func (p PvlKitSnapshot) Hash() string {
+	return p.hash
+}
+
+// GetPVL extracts a single version's pvl out of the snapshot.
+func (p PvlKitSnapshot) GetPVL(pvlVersion int) (string, error) {
+	return pvlForVersion(p.kit, pvlVersion)
+}
+
+// Snapshot fetches and validates the active pvl kit, same as GetPVL,
+// but returns it as a PvlKitSnapshot for a caller that wants to look up
+// several versions, or share one lookup across concurrent evaluators,
+// without re-entering the source for each one.
+func (s *PvlSourceImpl) Snapshot(ctx context.Context) (PvlKitSnapshot, error) {
+	kit, err := s.getValidatedKit(ctx)
+	if err != nil {
+		return PvlKitSnapshot{}, err
+	}
+
+	s.Lock()
+	var hash string
+	if s.mem != nil {
+		hash = s.mem.Hash
+	}
+	s.Unlock()
+
+	return PvlKitSnapshot{hash: hash, kit: kit}, nil
+}
+
+// recordServedAge folds a just-served kit's age into s.ageStats.
+func (s *PvlSourceImpl) recordServedAge(kit pvlKitT) {
+	age := time.Now().Unix() - int64(kit.Ctime)
+	if age < 0 {
+		age = 0
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	if s.ageStats.Count == 0 || age < s.ageStats.MinAgeSecs {
+		s.ageStats.MinAgeSecs = age
+	}
+	if age > s.ageStats.MaxAgeSecs {
+		s.ageStats.MaxAgeSecs = age
+	}
+	s.ageStats.SumAgeSecs += age
+	s.ageStats.Count++
+}
+
+// AgeStats returns a snapshot of the age distribution of pvl kits served
+// so far by this source.
+func (s *PvlSourceImpl) AgeStats() PvlAgeStats {
+	s.Lock()
+	defer s.Unlock()
+	return s.ageStats
+}
+
+// IsDegraded reports whether the most recent GetKitString call had to
+// serve a kit pinned to a merkle root older than tRequireRefresh, because
+// degraded mode let it through rather than failing outright. Callers
+// that finish an identify should check this and warn the user that the
+// result may be based on stale proof-checking rules.
+func (s *PvlSourceImpl) IsDegraded(ctx context.Context) bool {
+	s.Lock()
+	defer s.Unlock()
+	return s.degraded
+}
+
+// Diagnostics returns a snapshot of pvlsource's current state -- the
+// merkle root it's pinned to, the pvl hash currently active, which cache
+// tier last served a kit, and the most recent fetch error, if any --
+// meant to be surfaced in `keybase status`/`keybase log send` so support
+// can triage identify failures without asking a user to run anything
+// extra.
+func (s *PvlSourceImpl) Diagnostics(ctx context.Context) libkb.PvlDiagnostics {
+	s.Lock()
+	var activeHash string
+	if s.mem != nil {
+		activeHash = s.mem.Hash
+	}
+	diag := libkb.PvlDiagnostics{
+		ActiveHash:  activeHash,
+		CacheSource: s.lastSource,
+	}
+	if s.lastFetchErr != nil {
+		diag.LastFetchError = s.lastFetchErr.Error()
+	}
+	s.Unlock()
+
+	if mc := s.G().GetMerkleClient(); mc != nil {
+		if root := mc.LastRoot(); root != nil {
+			diag.MerkleRootSeqno = seqnoWrap(root.Seqno())
+			diag.MerkleRootFetchTime = root.Fetched()
+			diag.MerkleRootHash = root.ShortHash().String()
+		}
 	}
+	return diag
+}
 
+// pvlForVersion extracts a single version's pvl out of an already-parsed
+// and validated kit.
+func pvlForVersion(kit pvlKitT, pvlVersion int) (string, error) {
 	sub, ok := kit.Tab[pvlVersion]
 	if !ok {
 		return "", libkb.NewPvlSourceError("missing pvl for version: %d", pvlVersion)
@@ -94,25 +547,31 @@ func (s *PvlSourceImpl) GetPVL(ctx context.Context, pvlVersion int) (string, err
 // Using the pvl hash from that, it fetches from in-memory falling back to db
 // falling back to server.
 func (s *PvlSourceImpl) GetKitString(ctx context.Context) (string, error) {
+	ctx = libkb.WithLogTag(ctx, "PVL")
 
 	// Use a file instead if specified.
-	if len(s.G().Env.GetPvlKitFilename()) > 0 {
-		return s.readFile(s.G().Env.GetPvlKitFilename())
+	if filename := s.G().Env.GetPvlKitFilename(); len(filename) > 0 {
+		s.G().Log.CDebugf(ctx, "PvlSource: using kit file: %s", filename)
+		return s.readFile(filename)
 	}
 
+	ctx, cancel := context.WithTimeout(ctx, tGetKitStringTimeout)
+	defer cancel()
+
 	mc := s.G().GetMerkleClient()
 	if mc == nil {
 		return "", libkb.NewPvlSourceError("no MerkleClient available")
 	}
 
 	s.Lock()
-	defer s.Unlock()
+
+	shouldRefresh, requireRefresh := s.refreshThresholds(ctx)
 
 	root := mc.LastRoot()
 	// The time that the root was fetched is used rather than when the
 	// root was published so that we can continue to operate even if
 	// the root has not been published in a long time.
-	if (root == nil) || s.pastDue(ctx, root.Fetched(), tShouldRefresh) {
+	if (root == nil) || s.pastDue(ctx, root.Fetched(), shouldRefresh) {
 		s.G().Log.CDebugf(ctx, "PvlSource: merkle root should refresh")
 
 		// Attempt a refresh if the root is old or nil.
@@ -125,20 +584,37 @@ func (s *PvlSourceImpl) GetKitString(ctx context.Context) (string, error) {
 	}
 
 	if root == nil {
+		s.G().MetricsRegistry.IncrCounter("pvlsource.stale_root")
+		s.Unlock()
 		return "", libkb.NewPvlSourceError("no merkle root")
 	}
 
-	if s.pastDue(ctx, root.Fetched(), tRequireRefresh) {
+	if s.pastDue(ctx, root.Fetched(), requireRefresh) {
 		// The root is still too old, even after an attempted refresh.
 		s.G().Log.CDebugf(ctx, "PvlSource: merkle root too old")
-		return "", libkb.NewPvlSourceError("merkle root too old: %v %s", seqnoWrap(root.Seqno()), root.Fetched())
+		s.G().MetricsRegistry.IncrCounter("pvlsource.stale_root")
+
+		if !s.G().Env.GetPvlAllowDegraded() || s.pastDue(ctx, root.Fetched(), requireRefresh+tDegradedGraceWindow) {
+			s.Unlock()
+			return "", libkb.NewPvlSourceError("merkle root too old: %v %s", seqnoWrap(root.Seqno()), root.Fetched())
+		}
+
+		s.G().Log.CWarningf(ctx, "PvlSource: serving degraded (stale root): %v %s", seqnoWrap(root.Seqno()), root.Fetched())
+		s.G().MetricsRegistry.IncrCounter("pvlsource.degraded_serve")
+		s.degraded = true
+	} else {
+		s.degraded = false
 	}
 
-	// This is the hash we are being instructed to use.
-	hash := root.PvlHash()
+	// This is the hash we are being instructed to use. Normally the
+	// stable channel, but a developer or beta tester can opt into a
+	// different one via Env.GetPvlChannel.
+	channel := s.G().Env.GetPvlChannel()
+	hash := root.PvlHashForChannel(channel)
 
 	if hash == "" {
-		return "", libkb.NewPvlSourceError("merkle root has empty pvl hash: %v", seqnoWrap(root.Seqno()))
+		s.Unlock()
+		return "", libkb.NewPvlSourceError("merkle root has empty pvl hash for channel %q: %v", channel, seqnoWrap(root.Seqno()))
 	}
 
 	// If multiple Get's occur, these mem/db gets and sets may race.
@@ -148,40 +624,192 @@ func (s *PvlSourceImpl) GetKitString(ctx context.Context) (string, error) {
 	// Use in-memory cache if it matches
 	fromMem := s.memGet(hash)
 	if fromMem != nil {
+		s.G().MetricsRegistry.IncrCounter("pvlsource.mem_hit")
 		s.G().Log.CDebugf(ctx, "PvlSource: mem cache hit")
 		s.G().Log.CDebugf(ctx, "PvlSource: using hash: %s", hash)
+		s.lastSource = "mem"
+		s.Unlock()
 		return *fromMem, nil
 	}
 
 	// Use db cache if it matches
 	fromDB := s.dbGet(ctx, hash)
 	if fromDB != nil {
+		s.G().MetricsRegistry.IncrCounter("pvlsource.db_hit")
 		s.G().Log.CDebugf(ctx, "PvlSource: db cache hit")
 
 		// Store to memory
 		s.memSet(hash, *fromDB)
 
 		s.G().Log.CDebugf(ctx, "PvlSource: using hash: %s", hash)
+		s.lastSource = "db"
+		s.Unlock()
 		return *fromDB, nil
 	}
 
-	// Fetch from the server
-	// This validates the hash
-	pvl, err := s.fetch(ctx, hash)
+	// Release the lock before hitting the network: fetchDeduped's
+	// singleflight group (not this mutex) is what dedups concurrent
+	// fetches for the same hash, and holding this lock across a slow or
+	// retried fetch would otherwise block unrelated callers (a different
+	// hash, or just a cache hit) for no reason.
+	s.Unlock()
+
+	// Fetch from the server, deduped by hash. This validates the hash.
+	pvl, err := s.fetchDeduped(ctx, hash)
 	if err != nil {
+		s.Lock()
+		s.lastFetchErr = err
+		s.Unlock()
 		return "", err
 	}
 
+	s.Lock()
+	s.lastFetchErr = nil
+	s.lastSource = "server"
+
 	// Store to memory
 	s.memSet(hash, pvl)
+	s.Unlock()
 
 	// Schedule a db write
-	go s.dbSet(context.Background(), hash, pvl)
+	s.queueDBWrite(hash, pvl)
 
 	s.G().Log.CDebugf(ctx, "PvlSource: using hash: %s", hash)
 	return pvl, nil
 }
 
+// validateKitTab checks kit.Tab's integer keys for signs of a broken
+// server packaging job: negative versions, or a gap below the highest
+// version present. In non-strict mode violations are only logged; in
+// strict mode they are returned as an error so a bad kit is never used.
+func (s *PvlSourceImpl) validateKitTab(ctx context.Context, kit pvlKitT, strict bool) error {
+	var max int
+	for version := range kit.Tab {
+		if version > max {
+			max = version
+		}
+		if version < 0 {
+			msg := fmt.Sprintf("negative pvl version in kit: %d", version)
+			if strict {
+				return libkb.NewPvlSourceError(msg)
+			}
+			s.G().Log.CWarningf(ctx, "PvlSource: %s", msg)
+		}
+	}
+	for version := 0; version <= max; version++ {
+		if _, ok := kit.Tab[version]; !ok {
+			msg := fmt.Sprintf("gap in pvl kit versions: missing %d (max %d)", version, max)
+			if strict {
+				return libkb.NewPvlSourceError(msg)
+			}
+			s.G().Log.CWarningf(ctx, "PvlSource: %s", msg)
+		}
+	}
+	return nil
+}
+
+// validateKit checks a freshly-parsed kit's structure beyond bare JSON
+// parseability: a sane kit_version, a sane ctime, and that every tab
+// entry is itself a well-formed pvl document (required top-level
+// fields, structurally valid per-service instruction lists), without
+// executing any instruction. Like validateKitTab, violations are only
+// logged unless strict mode is on, since this is meant to catch a
+// broken server packaging job at fetch time, not to reject anything a
+// real interpreter run wouldn't also reject.
+func (s *PvlSourceImpl) validateKit(ctx context.Context, kit pvlKitT, strict bool) error {
+	reject := func(msg string) error {
+		if strict {
+			return libkb.NewPvlSourceError(msg)
+		}
+		s.G().Log.CWarningf(ctx, "PvlSource: %s", msg)
+		return nil
+	}
+
+	if kit.KitVersion < 0 {
+		if err := reject(fmt.Sprintf("negative kit_version: %d", kit.KitVersion)); err != nil {
+			return err
+		}
+	}
+
+	if kit.Ctime < 0 {
+		if err := reject(fmt.Sprintf("negative ctime: %d", kit.Ctime)); err != nil {
+			return err
+		}
+	} else if kit.Ctime > 0 {
+		latest := s.G().Clock().Now().Add(tCtimeFutureSlop).Unix()
+		if int64(kit.Ctime) > latest {
+			if err := reject(fmt.Sprintf("ctime is implausibly far in the future: %d", kit.Ctime)); err != nil {
+				return err
+			}
+		}
+	}
+
+	for version, sub := range kit.Tab {
+		if err := s.validateKitTabEntry(sub); err != nil {
+			if err := reject(fmt.Sprintf("pvl version %d failed structural validation: %s", version, err)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateKitTabEntry runs pvl.ValidateStructure on a single tab entry,
+// recovering from any panic the way parsePvlKit does, since a tab entry
+// is just as untrusted as the kit envelope around it.
+func (s *PvlSourceImpl) validateKitTabEntry(sub json.RawMessage) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = libkb.NewPvlParseError("panic while validating pvl document: %v", r)
+		}
+	}()
+	return pvl.ValidateStructure(string(sub))
+}
+
+// validateKitSignature checks a kit's embedded "sig" field, if kit
+// signing is required by config. The signature covers the kit JSON with
+// the "sig" field itself removed, re-marshalled canonically (Go's
+// encoding/json sorts object keys when marshalling a map), so it
+// authenticates a kit independent of whatever channel it arrived
+// through (server fetch, local file, or otherwise) rather than relying
+// solely on the merkle hash check.
+func (s *PvlSourceImpl) validateKitSignature(kitJSON string) error {
+	pinnedKID := s.G().Env.GetPvlSigningKID()
+	if len(pinnedKID) == 0 {
+		return nil
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(kitJSON), &raw); err != nil {
+		return libkb.NewPvlParseError("unmarshalling kit for signature check: %s", err)
+	}
+	sigRaw, ok := raw["sig"]
+	if !ok {
+		return libkb.NewPvlSourceError("kit signing is required but the kit has no sig field")
+	}
+	var sig string
+	if err := json.Unmarshal(sigRaw, &sig); err != nil {
+		return libkb.NewPvlParseError("unmarshalling kit sig: %s", err)
+	}
+	delete(raw, "sig")
+	canonical, err := json.Marshal(raw)
+	if err != nil {
+		return libkb.NewPvlParseError("re-marshalling kit for signature check: %s", err)
+	}
+
+	key, payload, _, err := libkb.NaclVerifyAndExtract(sig)
+	if err != nil {
+		return libkb.NewPvlSourceError("kit signature did not verify: %s", err)
+	}
+	if !bytes.Equal(payload, canonical) {
+		return libkb.NewPvlSourceError("kit signature covers different content than the kit body")
+	}
+	if key.GetKID().String() != pinnedKID {
+		return libkb.NewPvlSourceError("kit signed by unexpected KID: %s", key.GetKID())
+	}
+	return nil
+}
+
 type pvlServerRes struct {
 	Status  libkb.AppStatus `json:"status"`
 	KitJSON string          `json:"kit_json"`
@@ -191,49 +819,152 @@ func (r *pvlServerRes) GetAppStatus() *libkb.AppStatus {
 	return &r.Status
 }
 
-// Fetch pvl and check the hash.
+// pvlFetchBreakerName names the shared NetworkBreaker guarding
+// merkle/pvl fetches, so a flapping pvl endpoint can't retry-storm the
+// server independent of whatever else is hitting it.
+const pvlFetchBreakerName = "merkle/pvl"
+
+// pvlFetchBreakerConfig trips after 5 consecutive failures and holds the
+// breaker open for a minute before letting a probe back through; that's
+// short enough not to wedge a real recovery, but long enough that a
+// flapping server doesn't get hit again every GetKitString call.
+var pvlFetchBreakerConfig = libkb.NetworkBreakerConfig{
+	MaxConsecutiveFailures: 5,
+	Cooldown:               1 * time.Minute,
+}
+
+// fetch retrieves the pvl kit for hash from the server and checks the
+// hash, retrying a failed request up to tFetchMaxAttempts times with
+// jittered backoff between tries (same as attachment_s3's uploader), each
+// attempt individually bounded by tFetchAttemptTimeout. Retries stop
+// immediately if ctx is cancelled or its deadline (set by the caller, or
+// by GetKitString's tGetKitStringTimeout) passes.
 func (s *PvlSourceImpl) fetch(ctx context.Context, hash string) (string, error) {
 	s.G().Log.CDebugf(ctx, "PvlSource: fetching from server: %s", hash)
+	s.G().MetricsRegistry.IncrCounter("pvlsource.server_fetch")
+	start := s.G().Clock().Now()
+	defer func() {
+		s.G().MetricsRegistry.RecordLatency("pvlsource.server_fetch", s.G().Clock().Now().Sub(start))
+	}()
+
+	breaker := s.G().NetworkBreakers.Get(pvlFetchBreakerName, pvlFetchBreakerConfig)
+	if err := breaker.Allow(); err != nil {
+		s.G().MetricsRegistry.IncrCounter("pvlsource.breaker_rejected")
+		return "", err
+	}
+
 	var res pvlServerRes
-	err := s.G().API.GetDecode(libkb.APIArg{
-		Endpoint:    "merkle/pvl",
-		NeedSession: false,
-		NetContext:  ctx,
-		Args: libkb.HTTPArgs{
-			"hash": libkb.S{Val: hash},
-		},
-	}, &res)
+	var err error
+	for attempt := 0; attempt < tFetchMaxAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				breaker.Failure()
+				return "", ctx.Err()
+			case <-time.After(libkb.BackoffDefault.Duration(attempt)):
+			}
+		}
+
+		attemptCtx, cancel := context.WithTimeout(ctx, tFetchAttemptTimeout)
+		err = s.G().API.GetDecode(libkb.APIArg{
+			Endpoint:    "merkle/pvl",
+			NeedSession: false,
+			NetContext:  attemptCtx,
+			Args: libkb.HTTPArgs{
+				"hash": libkb.S{Val: hash},
+			},
+		}, &res)
+		cancel()
+		if err == nil {
+			break
+		}
+		s.G().Log.CDebugf(ctx, "PvlSource: fetch attempt %d/%d failed: %s", attempt+1, tFetchMaxAttempts, err)
+	}
 	if err != nil {
+		breaker.Failure()
 		return "", libkb.NewPvlSourceError(err.Error())
 	}
+	breaker.Success()
 	if res.KitJSON == "" {
 		return "", libkb.NewPvlSourceError("server returned empty pvl")
 	}
 	if s.hash(res.KitJSON) != hash {
+		s.G().MetricsRegistry.IncrCounter("pvlsource.hash_mismatch")
 		s.G().Log.CWarningf(ctx, "pvl hash mismatch: got:%s expected:%s", s.hash(res.KitJSON), hash)
 		return "", libkb.NewPvlSourceError("server returned wrong pvl")
 	}
 	return res.KitJSON, nil
 }
 
+// fetchDeduped wraps fetch in s.sfGroup, so that if several GetKitString
+// calls race after a cache miss for the same hash (e.g. a burst of
+// identifies right after a pvl rollout), only one of them actually hits
+// the server; the rest block and share its result.
+func (s *PvlSourceImpl) fetchDeduped(ctx context.Context, hash string) (string, error) {
+	v, err := s.sfGroup.Do(hash, func() (interface{}, error) {
+		return s.fetch(ctx, hash)
+	})
+	if err != nil {
+		return "", err
+	}
+	return v.(string), nil
+}
+
 // updateRoot kicks MerkleClient to update its merkle root
 // by doing a LookupUser on some arbitrary user.
 func (s *PvlSourceImpl) refreshRoot(ctx context.Context) error {
-	q := libkb.NewHTTPArgs()
-	// The user lookup here is unecessary. It is done because that is what
-	// is easy with MerkleClient.
-	// The user lookuped up is you if known, otherwise arbitrarily t_alice.
-	// If t_alice is removed, this path will break.
-	uid := s.G().GetMyUID()
-	if len(uid) == 0 {
-		// Use t_alice's uid.
-		uid = libkb.TAliceUID
-	}
-	q.Add("uid", libkb.UIDArg(uid))
-	_, err := s.G().MerkleClient.LookupUser(ctx, q, nil)
+	_, err := s.G().MerkleClient.FetchRootFromServer(ctx)
 	return err
 }
 
+// Invalidate clears the in-memory and on-disk pvl cache and immediately
+// refetches, so a server-side pvl rollout (announced out of band, e.g.
+// via a "pvl.update" gregor message) takes effect within seconds instead
+// of waiting for the usual tShouldRefresh window.
+func (s *PvlSourceImpl) Invalidate(ctx context.Context) error {
+	ctx = libkb.WithLogTag(ctx, "PVL")
+	s.G().Log.CDebugf(ctx, "PvlSource: invalidating cache by request")
+
+	s.Lock()
+	s.mem = nil
+	s.Unlock()
+
+	if db := s.G().LocalDb; db != nil {
+		if err := db.Delete(dbKey); err != nil {
+			s.G().Log.CWarningf(ctx, "PvlSource: error clearing db cache: %s", err)
+		}
+	}
+
+	_, err := s.GetKitString(ctx)
+	return err
+}
+
+// SwapKitIfMatching atomically installs newKit as the active kit, but only
+// if its hash matches expectedHash, and expectedHash matches the pvl hash
+// currently published in the merkle root (when a merkle client and root
+// are available to check against). Returns whether the swap happened.
+// Useful for callers (e.g. a hot-reload path) that obtain a new kit out
+// of band from the normal fetch path but still want the usual safety
+// checks before it becomes active.
+func (s *PvlSourceImpl) SwapKitIfMatching(ctx context.Context, expectedHash string, newKit string) bool {
+	if expectedHash == "" || s.hash(newKit) != expectedHash {
+		return false
+	}
+
+	if mc := s.G().GetMerkleClient(); mc != nil {
+		if root := mc.LastRoot(); root != nil && root.PvlHash() != "" && root.PvlHash() != expectedHash {
+			s.G().Log.CWarningf(ctx, "PvlSource: refusing kit swap, hash doesn't match merkle root")
+			return false
+		}
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	s.memSet(expectedHash, newKit)
+	s.queueDBWrite(expectedHash, newKit)
+	return true
+}
+
 func (s *PvlSourceImpl) memGet(hash string) *string {
 	if s.mem != nil {
 		if s.mem.Hash == hash {
@@ -245,11 +976,52 @@ func (s *PvlSourceImpl) memGet(hash string) *string {
 }
 
 func (s *PvlSourceImpl) memSet(hash string, pvl string) {
+	var oldHash string
+	if s.mem != nil {
+		oldHash = s.mem.Hash
+	}
 	s.mem = &entry{
 		DBVersion: dbVersion,
 		Hash:      hash,
 		PvlKit:    pvl,
 	}
+	if oldHash != hash {
+		s.notifyPvlUpdate(oldHash, hash)
+	}
+}
+
+// OnPvlUpdate registers a listener that fires whenever GetKitString
+// promotes a new pvl hash into the in-memory cache. Listeners run outside
+// of PvlSourceImpl's lock, on their own goroutine, so a slow or panicking
+// listener can't deadlock or crash callers.
+func (s *PvlSourceImpl) OnPvlUpdate(f libkb.PvlUpdateListener) {
+	s.Lock()
+	defer s.Unlock()
+	s.listeners = append(s.listeners, f)
+}
+
+// notifyPvlUpdate fires all registered listeners. Called with the lock
+// held, but the listeners themselves are run after it's released.
+func (s *PvlSourceImpl) notifyPvlUpdate(oldHash, newHash string) {
+	if len(s.listeners) == 0 {
+		return
+	}
+	listeners := make([]libkb.PvlUpdateListener, len(s.listeners))
+	copy(listeners, s.listeners)
+	go func() {
+		for _, listener := range listeners {
+			s.runListener(listener, oldHash, newHash)
+		}
+	}()
+}
+
+func (s *PvlSourceImpl) runListener(listener libkb.PvlUpdateListener, oldHash, newHash string) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.G().Log.CWarningf(context.Background(), "PvlSource: pvl-update listener panicked: %v", r)
+		}
+	}()
+	listener(oldHash, newHash)
 }
 
 // Get from local db. Can return nil.
@@ -295,6 +1067,43 @@ func (s *PvlSourceImpl) dbSet(ctx context.Context, hash string, pvl string) {
 	}
 }
 
+// CacheConsistencyReport is the result of CheckCacheConsistency.
+type CacheConsistencyReport struct {
+	MemHash    string
+	DBHash     string
+	Consistent bool
+}
+
+// CheckCacheConsistency compares the hash of the in-memory pvl cache
+// against the hash stored in the on-disk cache. A brief mismatch is
+// expected right after a fetch, since dbSet writes to disk in the
+// background; a persistent mismatch usually means the db write is
+// failing. Either cache being empty is not considered a mismatch, since
+// that's the normal state before the first fetch completes on one side
+// or the other.
+func (s *PvlSourceImpl) CheckCacheConsistency(ctx context.Context) CacheConsistencyReport {
+	s.Lock()
+	var memHash string
+	if s.mem != nil {
+		memHash = s.mem.Hash
+	}
+	s.Unlock()
+
+	var dbHash string
+	if db := s.G().LocalDb; db != nil {
+		var ent entry
+		if found, err := db.GetInto(&ent, dbKey); err == nil && found {
+			dbHash = ent.Hash
+		}
+	}
+
+	return CacheConsistencyReport{
+		MemHash:    memHash,
+		DBHash:     dbHash,
+		Consistent: memHash == "" || dbHash == "" || memHash == dbHash,
+	}
+}
+
 // hex of sha512
 func (s *PvlSourceImpl) hash(in string) string {
 	buf := sha512.Sum512([]byte(in))
@@ -302,6 +1111,21 @@ func (s *PvlSourceImpl) hash(in string) string {
 	return out
 }
 
+// refreshThresholds returns the merkle-root-age thresholds GetKitString
+// uses to decide whether to refresh (should) or give up (require),
+// pulling any operator overrides from Env. An override pair where
+// require is shorter than should doesn't make sense, so it's ignored in
+// favor of the compiled-in defaults.
+func (s *PvlSourceImpl) refreshThresholds(ctx context.Context) (should, require time.Duration) {
+	should = s.G().Env.GetPvlShouldRefreshInterval()
+	require = s.G().Env.GetPvlRequireRefreshInterval()
+	if require < should {
+		s.G().Log.CWarningf(ctx, "PvlSource: pvl-require-refresh-interval (%s) is shorter than pvl-should-refresh-interval (%s); ignoring overrides", require, should)
+		return tShouldRefresh, tRequireRefresh
+	}
+	return should, require
+}
+
 func (s *PvlSourceImpl) pastDue(ctx context.Context, event time.Time, limit time.Duration) bool {
 	diff := s.G().Clock().Now().Sub(event)
 	overdue := diff > limit
@@ -311,9 +1135,39 @@ func (s *PvlSourceImpl) pastDue(ctx context.Context, event time.Time, limit time
 	return overdue
 }
 
+// readFile reads and validates a local kit file override, caching the
+// parsed result keyed on the file's mtime so a caller hot-reloading via
+// frequent GetKitString calls only pays for a re-read and re-parse when
+// the file on disk actually changes.
 func (s *PvlSourceImpl) readFile(path string) (string, error) {
+	info, statErr := os.Stat(path)
+	if statErr != nil {
+		return "", statErr
+	}
+
+	s.fileCacheMu.Lock()
+	if s.fileCache != nil && s.fileCache.path == path && s.fileCache.modTime.Equal(info.ModTime()) {
+		cached := s.fileCache.kitJSON
+		s.fileCacheMu.Unlock()
+		return cached, nil
+	}
+	s.fileCacheMu.Unlock()
+
 	buf, err := ioutil.ReadFile(path)
-	return string(buf), err
+	if err != nil {
+		return "", err
+	}
+	kitJSON := string(buf)
+
+	if _, err := parsePvlKit(kitJSON); err != nil {
+		return "", libkb.NewPvlParseError("invalid pvl kit file %s: %s", path, err)
+	}
+
+	s.fileCacheMu.Lock()
+	s.fileCache = &fileKitCache{path: path, modTime: info.ModTime(), kitJSON: kitJSON}
+	s.fileCacheMu.Unlock()
+
+	return kitJSON, nil
 }
 
 func seqnoWrap(x *libkb.Seqno) int64 {
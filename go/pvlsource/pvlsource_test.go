@@ -0,0 +1,1060 @@
+// Copyright 2017 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package pvlsource
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/keybase/client/go/libkb"
+	"github.com/keybase/client/go/logger"
+)
+
+// recordingBackend is a logger.TestLogBackend that keeps every logged
+// line around so tests can inspect it.
+type recordingBackend struct {
+	t     *testing.T
+	lines []string
+}
+
+func (r *recordingBackend) Error(args ...interface{})                 { r.t.Log(args...) }
+func (r *recordingBackend) Errorf(format string, args ...interface{}) { r.t.Logf(format, args...) }
+func (r *recordingBackend) Fatal(args ...interface{})                 { r.t.Fatal(args...) }
+func (r *recordingBackend) Fatalf(format string, args ...interface{}) { r.t.Fatalf(format, args...) }
+func (r *recordingBackend) Log(args ...interface{})                   { r.t.Log(args...) }
+func (r *recordingBackend) Logf(format string, args ...interface{}) {
+	r.lines = append(r.lines, format)
+	r.t.Logf(format, args...)
+}
+
+func setupTest(t *testing.T) (libkb.TestContext, *recordingBackend) {
+	tc := libkb.SetupTest(t, "pvlsource", 1)
+	backend := &recordingBackend{t: t}
+	tc.G.Log = logger.NewTestLogger(backend)
+	return tc, backend
+}
+
+// TestGetKitStringLogsRequestID checks that a request ID already present
+// in the context (per libkb's logging-context convention) is carried
+// through into every log line GetKitString emits.
+func TestGetKitStringLogsRequestID(t *testing.T) {
+	tc, backend := setupTest(t)
+	defer tc.Cleanup()
+
+	kitFile, err := ioutil.TempFile("", "pvlkit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(kitFile.Name())
+	if _, err := kitFile.WriteString(`{"kit_version":1,"ctime":0,"tab":{}}`); err != nil {
+		t.Fatal(err)
+	}
+	kitFile.Close()
+
+	os.Setenv("KEYBASE_PVL_KIT_FILE", kitFile.Name())
+	defer os.Unsetenv("KEYBASE_PVL_KIT_FILE")
+
+	s := NewPvlSourceAndInstall(tc.G).(*PvlSourceImpl)
+
+	ctx := libkb.WithLogTag(context.Background(), "PVL")
+	wantTag := libkb.LogTagsToString(ctx)
+	if wantTag == "" {
+		t.Fatal("expected a request ID to be tagged onto the context")
+	}
+
+	if _, err := s.GetKitString(ctx); err != nil {
+		t.Fatalf("GetKitString failed: %s", err)
+	}
+
+	var found bool
+	for _, line := range backend.lines {
+		if strings.Contains(line, "PvlSource:") && strings.Contains(line, wantTag) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Fatalf("expected a PvlSource log line tagged with %q, got: %v", wantTag, backend.lines)
+	}
+}
+
+// TestGetKitStringNoRequestID checks that GetKitString still works, and
+// still logs, when the caller supplies no request ID at all.
+func TestGetKitStringNoRequestID(t *testing.T) {
+	tc, backend := setupTest(t)
+	defer tc.Cleanup()
+
+	kitFile, err := ioutil.TempFile("", "pvlkit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(kitFile.Name())
+	if _, err := kitFile.WriteString(`{"kit_version":1,"ctime":0,"tab":{}}`); err != nil {
+		t.Fatal(err)
+	}
+	kitFile.Close()
+
+	os.Setenv("KEYBASE_PVL_KIT_FILE", kitFile.Name())
+	defer os.Unsetenv("KEYBASE_PVL_KIT_FILE")
+
+	s := NewPvlSourceAndInstall(tc.G).(*PvlSourceImpl)
+
+	if _, err := s.GetKitString(context.Background()); err != nil {
+		t.Fatalf("GetKitString failed: %s", err)
+	}
+
+	var found bool
+	for _, line := range backend.lines {
+		if strings.Contains(line, "PvlSource: using kit file") {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Fatalf("expected a PvlSource log line, got: %v", backend.lines)
+	}
+}
+
+func TestValidateKitTabNegativeVersion(t *testing.T) {
+	tc, backend := setupTest(t)
+	defer tc.Cleanup()
+
+	s := NewPvlSourceAndInstall(tc.G).(*PvlSourceImpl)
+	kit := pvlKitT{Tab: map[int]json.RawMessage{
+		-1: json.RawMessage(`"x"`),
+		0:  json.RawMessage(`"y"`),
+	}}
+
+	if err := s.validateKitTab(context.Background(), kit, false); err != nil {
+		t.Fatalf("expected warn-only mode not to error, got: %s", err)
+	}
+	var warned bool
+	for _, line := range backend.lines {
+		if strings.Contains(line, "negative pvl version") {
+			warned = true
+		}
+	}
+	if !warned {
+		t.Fatalf("expected a warning about the negative version, got: %v", backend.lines)
+	}
+
+	if err := s.validateKitTab(context.Background(), kit, true); err == nil {
+		t.Fatal("expected strict mode to error on negative version")
+	}
+}
+
+func TestValidateKitTabGap(t *testing.T) {
+	tc, backend := setupTest(t)
+	defer tc.Cleanup()
+
+	s := NewPvlSourceAndInstall(tc.G).(*PvlSourceImpl)
+	kit := pvlKitT{Tab: map[int]json.RawMessage{
+		0: json.RawMessage(`"x"`),
+		2: json.RawMessage(`"y"`),
+	}}
+
+	if err := s.validateKitTab(context.Background(), kit, false); err != nil {
+		t.Fatalf("expected warn-only mode not to error, got: %s", err)
+	}
+	var warned bool
+	for _, line := range backend.lines {
+		if strings.Contains(line, "gap in pvl kit versions") {
+			warned = true
+		}
+	}
+	if !warned {
+		t.Fatalf("expected a warning about the gap, got: %v", backend.lines)
+	}
+
+	if err := s.validateKitTab(context.Background(), kit, true); err == nil {
+		t.Fatal("expected strict mode to error on a gap")
+	}
+}
+
+func TestValidateKitRejectsMalformedTabEntry(t *testing.T) {
+	tc, backend := setupTest(t)
+	defer tc.Cleanup()
+
+	s := NewPvlSourceAndInstall(tc.G).(*PvlSourceImpl)
+	kit := pvlKitT{Tab: map[int]json.RawMessage{
+		1: json.RawMessage(`{"pvl_version":1,"revision":1,"services":{}}`),
+	}}
+	if err := s.validateKit(context.Background(), kit, true); err != nil {
+		t.Fatalf("expected a well-formed tab entry to validate, got: %s", err)
+	}
+
+	kit = pvlKitT{Tab: map[int]json.RawMessage{
+		1: json.RawMessage(`{"revision":1,"services":{}}`),
+	}}
+	if err := s.validateKit(context.Background(), kit, false); err != nil {
+		t.Fatalf("expected warn-only mode not to error, got: %s", err)
+	}
+	var warned bool
+	for _, line := range backend.lines {
+		if strings.Contains(line, "failed structural validation") {
+			warned = true
+		}
+	}
+	if !warned {
+		t.Fatalf("expected a warning about the malformed tab entry, got: %v", backend.lines)
+	}
+
+	if err := s.validateKit(context.Background(), kit, true); err == nil {
+		t.Fatal("expected strict mode to error on a malformed tab entry")
+	}
+}
+
+func TestValidateKitCtime(t *testing.T) {
+	tc, _ := setupTest(t)
+	defer tc.Cleanup()
+
+	s := NewPvlSourceAndInstall(tc.G).(*PvlSourceImpl)
+
+	// Zero is a pervasive sentinel elsewhere in this package's tests, so
+	// it must not be treated as bogus.
+	if err := s.validateKit(context.Background(), pvlKitT{Ctime: 0}, true); err != nil {
+		t.Fatalf("expected ctime 0 to be accepted, got: %s", err)
+	}
+
+	if err := s.validateKit(context.Background(), pvlKitT{Ctime: -1}, true); err == nil {
+		t.Fatal("expected strict mode to error on a negative ctime")
+	}
+
+	future := int(s.G().Clock().Now().Add(2 * tCtimeFutureSlop).Unix())
+	if err := s.validateKit(context.Background(), pvlKitT{Ctime: future}, true); err == nil {
+		t.Fatal("expected strict mode to error on an implausibly future ctime")
+	}
+}
+
+func TestParsePvlKitAdversarial(t *testing.T) {
+	cases := []string{
+		"not json",
+		strings.Repeat("[", 1000000) + strings.Repeat("]", 1000000),
+		`{"kit_version":1,"ctime":0,"tab":{"99999999999999999999999999999999":"x"}}`,
+		strings.Repeat("a", pvlKitMaxSize+1),
+	}
+	for i, c := range cases {
+		if _, err := parsePvlKit(c); err == nil {
+			t.Errorf("case %d: expected an error, got none", i)
+		}
+	}
+}
+
+func TestOnPvlUpdateFiresOnHashChange(t *testing.T) {
+	tc, _ := setupTest(t)
+	defer tc.Cleanup()
+
+	s := NewPvlSourceAndInstall(tc.G).(*PvlSourceImpl)
+
+	var calls int32
+	fired := make(chan struct{}, 10)
+	s.OnPvlUpdate(func(oldHash, newHash string) {
+		atomic.AddInt32(&calls, 1)
+		fired <- struct{}{}
+	})
+
+	// First write: no prior hash, still counts as a change.
+	s.memSet("hash1", "kit1")
+	select {
+	case <-fired:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for listener on first write")
+	}
+
+	// Same hash again: must not fire.
+	s.memSet("hash1", "kit1")
+
+	// New hash: must fire again.
+	s.memSet("hash2", "kit2")
+	select {
+	case <-fired:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for listener on hash change")
+	}
+
+	// Give a possible spurious extra callback a moment to arrive.
+	select {
+	case <-fired:
+		t.Fatal("listener fired more times than expected")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Fatalf("expected exactly 2 calls, got %d", got)
+	}
+}
+
+func TestParsePvlKitValid(t *testing.T) {
+	kit, err := parsePvlKit(`{"kit_version":1,"ctime":0,"tab":{"1":"x"}}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if kit.KitVersion != 1 {
+		t.Fatalf("expected kit_version 1, got %d", kit.KitVersion)
+	}
+}
+
+func TestGetPVLInRange(t *testing.T) {
+	tc, _ := setupTest(t)
+	defer tc.Cleanup()
+
+	dir, err := ioutil.TempDir("", "pvlsource")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := dir + "/pvl.json"
+	if err := ioutil.WriteFile(path, []byte(`{"kit_version":3,"ctime":0,"tab":{"1":"v1","2":"v2","4":"v4"}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("KEYBASE_PVL_KIT_FILE", path)
+	defer os.Unsetenv("KEYBASE_PVL_KIT_FILE")
+
+	s := &PvlSourceImpl{Contextified: libkb.NewContextified(tc.G)}
+
+	pvl, err := s.GetPVLInRange(context.Background(), 1, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if pvl != `"v2"` {
+		t.Fatalf("expected the highest version in range (v2), got: %s", pvl)
+	}
+
+	pvl, err = s.GetPVLInRange(context.Background(), 1, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if pvl != `"v4"` {
+		t.Fatalf("expected the highest available version (v4), got: %s", pvl)
+	}
+
+	if _, err := s.GetPVLInRange(context.Background(), 5, 10); err == nil {
+		t.Fatal("expected an error when nothing is available in range")
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	tc, _ := setupTest(t)
+	defer tc.Cleanup()
+
+	dir, err := ioutil.TempDir("", "pvlsource")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := dir + "/pvl.json"
+	if err := ioutil.WriteFile(path, []byte(`{"kit_version":1,"ctime":0,"tab":{"1":"v1"}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("KEYBASE_PVL_KIT_FILE", path)
+	defer os.Unsetenv("KEYBASE_PVL_KIT_FILE")
+
+	s := &PvlSourceImpl{Contextified: libkb.NewContextified(tc.G)}
+
+	snap, err := s.Snapshot(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	pvl, err := snap.GetPVL(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if pvl != `"v1"` {
+		t.Fatalf("expected v1, got: %s", pvl)
+	}
+	if _, err := snap.GetPVL(2); err == nil {
+		t.Fatal("expected an error for a missing version")
+	}
+}
+
+func TestAgeStats(t *testing.T) {
+	tc, _ := setupTest(t)
+	defer tc.Cleanup()
+
+	s := &PvlSourceImpl{Contextified: libkb.NewContextified(tc.G)}
+	if stats := s.AgeStats(); stats.Count != 0 || stats.AverageAgeSecs() != 0 {
+		t.Fatalf("expected zero-value stats before any kit served: %+v", stats)
+	}
+
+	now := time.Now().Unix()
+	s.recordServedAge(pvlKitT{Ctime: int(now - 10)})
+	s.recordServedAge(pvlKitT{Ctime: int(now - 30)})
+
+	stats := s.AgeStats()
+	if stats.Count != 2 {
+		t.Fatalf("expected 2 served kits, got %d", stats.Count)
+	}
+	if stats.MinAgeSecs != 10 || stats.MaxAgeSecs != 30 {
+		t.Fatalf("expected min/max ages of 10/30, got %d/%d", stats.MinAgeSecs, stats.MaxAgeSecs)
+	}
+	if avg := stats.AverageAgeSecs(); avg != 20 {
+		t.Fatalf("expected average age of 20, got %v", avg)
+	}
+}
+
+func TestReadFileCachesUntilMtimeChanges(t *testing.T) {
+	tc, _ := setupTest(t)
+	defer tc.Cleanup()
+
+	dir, err := ioutil.TempDir("", "pvlsource")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := dir + "/pvl.json"
+	write := func(contents string, mtime time.Time) {
+		if err := ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := &PvlSourceImpl{Contextified: libkb.NewContextified(tc.G)}
+	base := time.Now().Truncate(time.Second)
+
+	write(`{"kit_version":1,"ctime":0,"tab":{"1":"v1"}}`, base)
+	got, err := s.readFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got != `{"kit_version":1,"ctime":0,"tab":{"1":"v1"}}` {
+		t.Fatalf("unexpected content: %s", got)
+	}
+
+	// Rewrite with different content but the SAME mtime: should still
+	// serve the cached copy.
+	if err := ioutil.WriteFile(path, []byte(`{"kit_version":2,"ctime":0,"tab":{"1":"v2"}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, base, base); err != nil {
+		t.Fatal(err)
+	}
+	got, err = s.readFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got != `{"kit_version":1,"ctime":0,"tab":{"1":"v1"}}` {
+		t.Fatalf("expected the cached copy to still be served, got: %s", got)
+	}
+
+	// A new mtime should invalidate the cache and pick up the new content.
+	write(`{"kit_version":2,"ctime":0,"tab":{"1":"v2"}}`, base.Add(time.Second))
+	got, err = s.readFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got != `{"kit_version":2,"ctime":0,"tab":{"1":"v2"}}` {
+		t.Fatalf("expected the updated content after mtime change, got: %s", got)
+	}
+}
+
+func TestReadFileRejectsInvalidJSON(t *testing.T) {
+	tc, _ := setupTest(t)
+	defer tc.Cleanup()
+
+	dir, err := ioutil.TempDir("", "pvlsource")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := dir + "/pvl.json"
+	if err := ioutil.WriteFile(path, []byte("{not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &PvlSourceImpl{Contextified: libkb.NewContextified(tc.G)}
+	_, err = s.readFile(path)
+	if err == nil {
+		t.Fatal("expected an error for invalid JSON")
+	}
+	if !strings.Contains(err.Error(), "line") || !strings.Contains(err.Error(), "col") {
+		t.Fatalf("expected a line/col locator in the error, got: %s", err)
+	}
+}
+
+func TestJitteredInterval(t *testing.T) {
+	base := 30 * time.Minute
+	for i := 0; i < 100; i++ {
+		got := jitteredInterval(base)
+		if got < base-base/5 || got > base+base/5 {
+			t.Fatalf("jittered interval %v out of expected +/-20%% band around %v", got, base)
+		}
+	}
+}
+
+func TestStartStopBackgroundRefresh(t *testing.T) {
+	tc, _ := setupTest(t)
+	defer tc.Cleanup()
+
+	s := &PvlSourceImpl{Contextified: libkb.NewContextified(tc.G)}
+
+	s.StartBackgroundRefresh()
+	if s.stopCh == nil {
+		t.Fatal("expected stopCh to be set after starting")
+	}
+
+	// Starting again should be a no-op, not replace the channel.
+	stopCh := s.stopCh
+	s.StartBackgroundRefresh()
+	if s.stopCh != stopCh {
+		t.Fatal("expected a second start to be a no-op")
+	}
+
+	s.StopBackgroundRefresh()
+	if s.stopCh != nil {
+		t.Fatal("expected stopCh to be cleared after stopping")
+	}
+
+	// Stopping again should be a no-op, not panic on a closed channel.
+	s.StopBackgroundRefresh()
+}
+
+func TestCheckCacheConsistency(t *testing.T) {
+	tc, _ := setupTest(t)
+	defer tc.Cleanup()
+
+	s := &PvlSourceImpl{Contextified: libkb.NewContextified(tc.G)}
+
+	if r := s.CheckCacheConsistency(context.Background()); !r.Consistent {
+		t.Fatalf("expected empty caches to be reported consistent: %+v", r)
+	}
+
+	s.memSet("aaa", "kit-a")
+	if r := s.CheckCacheConsistency(context.Background()); !r.Consistent {
+		t.Fatalf("expected mem-only cache to be reported consistent: %+v", r)
+	}
+
+	s.dbSet(context.Background(), "aaa", "kit-a")
+	if r := s.CheckCacheConsistency(context.Background()); !r.Consistent || r.MemHash != r.DBHash {
+		t.Fatalf("expected matching caches to be reported consistent: %+v", r)
+	}
+
+	s.dbSet(context.Background(), "bbb", "kit-b")
+	if r := s.CheckCacheConsistency(context.Background()); r.Consistent {
+		t.Fatalf("expected mismatched caches to be reported inconsistent: %+v", r)
+	}
+}
+
+// fakeFetchAPI is a minimal libkb.API that answers every GetDecode with a
+// fixed kit_json body, so fetch()'s hash check and metrics can be tested
+// without a real server.
+type fakeFetchAPI struct {
+	libkb.API
+	kitJSON string
+}
+
+func (f *fakeFetchAPI) GetDecode(arg libkb.APIArg, v libkb.APIResponseWrapper) error {
+	res, ok := v.(*pvlServerRes)
+	if !ok {
+		return fmt.Errorf("unexpected response type: %T", v)
+	}
+	res.KitJSON = f.kitJSON
+	return nil
+}
+
+func TestFetchRecordsMetrics(t *testing.T) {
+	tc, _ := setupTest(t)
+	defer tc.Cleanup()
+
+	s := &PvlSourceImpl{Contextified: libkb.NewContextified(tc.G)}
+	kit := `{"kit_version":1,"ctime":0,"tab":{"1":"x"}}`
+	tc.G.API = &fakeFetchAPI{kitJSON: kit}
+
+	if _, err := s.fetch(context.Background(), s.hash(kit)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	snap := tc.G.MetricsRegistry.Snapshot()
+	if snap.Counters["pvlsource.server_fetch"] != 1 {
+		t.Fatalf("expected one server_fetch counted, got: %+v", snap.Counters)
+	}
+	if snap.Latencies["pvlsource.server_fetch"].Count != 1 {
+		t.Fatalf("expected one server_fetch latency sample, got: %+v", snap.Latencies)
+	}
+
+	if _, err := s.fetch(context.Background(), "wrong-hash"); err == nil {
+		t.Fatal("expected an error for a hash mismatch")
+	}
+	snap = tc.G.MetricsRegistry.Snapshot()
+	if snap.Counters["pvlsource.hash_mismatch"] != 1 {
+		t.Fatalf("expected one hash_mismatch counted, got: %+v", snap.Counters)
+	}
+}
+
+// slowFetchAPI is a libkb.API that counts its GetDecode calls and blocks
+// each one on release, so a test can hold several concurrent fetches open
+// at once before letting any of them complete.
+type slowFetchAPI struct {
+	libkb.API
+	kitJSON string
+	calls   int32
+	release chan struct{}
+}
+
+func (f *slowFetchAPI) GetDecode(arg libkb.APIArg, v libkb.APIResponseWrapper) error {
+	atomic.AddInt32(&f.calls, 1)
+	<-f.release
+	res, ok := v.(*pvlServerRes)
+	if !ok {
+		return fmt.Errorf("unexpected response type: %T", v)
+	}
+	res.KitJSON = f.kitJSON
+	return nil
+}
+
+func TestFetchDedupedConcurrentCallsShareOneRequest(t *testing.T) {
+	tc, _ := setupTest(t)
+	defer tc.Cleanup()
+
+	s := &PvlSourceImpl{Contextified: libkb.NewContextified(tc.G)}
+	kit := `{"kit_version":1,"ctime":0,"tab":{"1":"x"}}`
+	hash := s.hash(kit)
+	api := &slowFetchAPI{kitJSON: kit, release: make(chan struct{})}
+	tc.G.API = api
+
+	const numCallers = 10
+	results := make(chan string, numCallers)
+	for i := 0; i < numCallers; i++ {
+		go func() {
+			got, err := s.fetchDeduped(context.Background(), hash)
+			if err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+			results <- got
+		}()
+	}
+
+	// Give every goroutine a chance to reach the (blocked) API call before
+	// releasing it, so they're genuinely racing rather than serialized.
+	for i := 0; i < 200 && atomic.LoadInt32(&api.calls) < 1; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	close(api.release)
+
+	for i := 0; i < numCallers; i++ {
+		if got := <-results; got != kit {
+			t.Fatalf("expected kit %q, got %q", kit, got)
+		}
+	}
+	if api.calls != 1 {
+		t.Fatalf("expected exactly one network request for %d concurrent callers, got %d", numCallers, api.calls)
+	}
+}
+
+// flakyFetchAPI is a libkb.API that fails the first failCount GetDecode
+// calls before answering with kitJSON, so retry/backoff logic can be
+// exercised without a real server.
+type flakyFetchAPI struct {
+	libkb.API
+	failCount int32
+	kitJSON   string
+	calls     int32
+}
+
+func (f *flakyFetchAPI) GetDecode(arg libkb.APIArg, v libkb.APIResponseWrapper) error {
+	atomic.AddInt32(&f.calls, 1)
+	if atomic.AddInt32(&f.failCount, -1) >= 0 {
+		return fmt.Errorf("simulated transient failure")
+	}
+	res, ok := v.(*pvlServerRes)
+	if !ok {
+		return fmt.Errorf("unexpected response type: %T", v)
+	}
+	res.KitJSON = f.kitJSON
+	return nil
+}
+
+func TestFetchRetriesTransientFailures(t *testing.T) {
+	tc, _ := setupTest(t)
+	defer tc.Cleanup()
+
+	s := &PvlSourceImpl{Contextified: libkb.NewContextified(tc.G)}
+	kit := `{"kit_version":1,"ctime":0,"tab":{"1":"x"}}`
+	api := &flakyFetchAPI{failCount: tFetchMaxAttempts - 1, kitJSON: kit}
+	tc.G.API = api
+
+	got, err := s.fetch(context.Background(), s.hash(kit))
+	if err != nil {
+		t.Fatalf("expected fetch to eventually succeed, got: %s", err)
+	}
+	if got != kit {
+		t.Fatalf("expected kit %q, got %q", kit, got)
+	}
+	if int(api.calls) != tFetchMaxAttempts {
+		t.Fatalf("expected %d attempts, got %d", tFetchMaxAttempts, api.calls)
+	}
+}
+
+func TestFetchGivesUpAfterMaxAttempts(t *testing.T) {
+	tc, _ := setupTest(t)
+	defer tc.Cleanup()
+
+	s := &PvlSourceImpl{Contextified: libkb.NewContextified(tc.G)}
+	kit := `{"kit_version":1,"ctime":0,"tab":{"1":"x"}}`
+	api := &flakyFetchAPI{failCount: tFetchMaxAttempts, kitJSON: kit}
+	tc.G.API = api
+
+	if _, err := s.fetch(context.Background(), s.hash(kit)); err == nil {
+		t.Fatal("expected fetch to give up after exhausting retries")
+	}
+	if int(api.calls) != tFetchMaxAttempts {
+		t.Fatalf("expected exactly %d attempts, got %d", tFetchMaxAttempts, api.calls)
+	}
+}
+
+func TestFetchStopsRetryingOnCancellation(t *testing.T) {
+	tc, _ := setupTest(t)
+	defer tc.Cleanup()
+
+	s := &PvlSourceImpl{Contextified: libkb.NewContextified(tc.G)}
+	kit := `{"kit_version":1,"ctime":0,"tab":{"1":"x"}}`
+	api := &flakyFetchAPI{failCount: tFetchMaxAttempts, kitJSON: kit}
+	tc.G.API = api
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := s.fetch(ctx, s.hash(kit)); err == nil {
+		t.Fatal("expected fetch to fail once ctx is cancelled")
+	}
+	if api.calls != 1 {
+		t.Fatalf("expected cancellation to stop retries after the first attempt, got %d calls", api.calls)
+	}
+}
+
+func TestQueueDBWriteFallsBackWithoutBackgroundRefresh(t *testing.T) {
+	tc, _ := setupTest(t)
+	defer tc.Cleanup()
+
+	s := &PvlSourceImpl{Contextified: libkb.NewContextified(tc.G)}
+	s.queueDBWrite("aaa", "kit-a")
+
+	// queueDBWrite falls back to an unqueued goroutine when no
+	// background-refresh worker is running to drain a queue, so give it a
+	// moment to land before checking the db.
+	for i := 0; i < 100; i++ {
+		if got := s.dbGet(context.Background(), "aaa"); got != nil {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("expected queueDBWrite's fallback write to eventually land in the db")
+}
+
+func TestQueueDBWriteDrainsOnShutdown(t *testing.T) {
+	tc, _ := setupTest(t)
+	defer tc.Cleanup()
+
+	s := &PvlSourceImpl{Contextified: libkb.NewContextified(tc.G)}
+	s.StartBackgroundRefresh()
+
+	s.queueDBWrite("bbb", "kit-b")
+	s.StopBackgroundRefresh()
+
+	if got := s.dbGet(context.Background(), "bbb"); got == nil || *got != "kit-b" {
+		t.Fatalf("expected shutdown to drain the queued write, got: %v", got)
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	tc, _ := setupTest(t)
+	defer tc.Cleanup()
+
+	s := &PvlSourceImpl{Contextified: libkb.NewContextified(tc.G)}
+
+	diag := s.Diagnostics(context.Background())
+	if diag.CacheSource != "" || diag.ActiveHash != "" || diag.LastFetchError != "" {
+		t.Fatalf("expected an empty diagnostics snapshot before any fetch, got: %+v", diag)
+	}
+
+	kit := `{"kit_version":1,"ctime":0,"tab":{"1":"x"}}`
+	tc.G.API = &fakeFetchAPI{kitJSON: kit}
+	hash := s.hash(kit)
+
+	if _, err := s.fetch(context.Background(), hash); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	s.Lock()
+	s.lastSource = "server"
+	s.memSet(hash, kit)
+	s.Unlock()
+
+	diag = s.Diagnostics(context.Background())
+	if diag.CacheSource != "server" {
+		t.Fatalf("expected cache source \"server\", got: %q", diag.CacheSource)
+	}
+	if diag.ActiveHash != hash {
+		t.Fatalf("expected active hash %q, got: %q", hash, diag.ActiveHash)
+	}
+	if diag.LastFetchError != "" {
+		t.Fatalf("expected no fetch error after a successful fetch, got: %q", diag.LastFetchError)
+	}
+
+	s.Lock()
+	s.lastFetchErr = fmt.Errorf("boom")
+	s.Unlock()
+	diag = s.Diagnostics(context.Background())
+	if diag.LastFetchError != "boom" {
+		t.Fatalf("expected last fetch error to surface, got: %q", diag.LastFetchError)
+	}
+}
+
+func TestIsDegraded(t *testing.T) {
+	tc, _ := setupTest(t)
+	defer tc.Cleanup()
+
+	s := &PvlSourceImpl{Contextified: libkb.NewContextified(tc.G)}
+	if s.IsDegraded(context.Background()) {
+		t.Fatal("expected not degraded before any fetch")
+	}
+
+	s.Lock()
+	s.degraded = true
+	s.Unlock()
+	if !s.IsDegraded(context.Background()) {
+		t.Fatal("expected degraded to be reported after being set")
+	}
+}
+
+func TestGetPvlAllowDegradedDefaultsFalse(t *testing.T) {
+	tc, _ := setupTest(t)
+	defer tc.Cleanup()
+
+	if tc.G.Env.GetPvlAllowDegraded() {
+		t.Fatal("expected degraded mode to default to off")
+	}
+
+	os.Setenv("KEYBASE_PVL_ALLOW_DEGRADED", "1")
+	defer os.Unsetenv("KEYBASE_PVL_ALLOW_DEGRADED")
+	if !tc.G.Env.GetPvlAllowDegraded() {
+		t.Fatal("expected degraded mode to be enabled by the env var")
+	}
+}
+
+func TestRefreshThresholdsDefaults(t *testing.T) {
+	tc, _ := setupTest(t)
+	defer tc.Cleanup()
+
+	s := &PvlSourceImpl{Contextified: libkb.NewContextified(tc.G)}
+	should, require := s.refreshThresholds(context.Background())
+	if should != tShouldRefresh || require != tRequireRefresh {
+		t.Fatalf("expected compiled-in defaults, got should:%s require:%s", should, require)
+	}
+}
+
+func TestRefreshThresholdsHonorsOverrides(t *testing.T) {
+	tc, _ := setupTest(t)
+	defer tc.Cleanup()
+
+	os.Setenv("KEYBASE_PVL_SHOULD_REFRESH_INTERVAL", "2m")
+	defer os.Unsetenv("KEYBASE_PVL_SHOULD_REFRESH_INTERVAL")
+	os.Setenv("KEYBASE_PVL_REQUIRE_REFRESH_INTERVAL", "10m")
+	defer os.Unsetenv("KEYBASE_PVL_REQUIRE_REFRESH_INTERVAL")
+
+	s := &PvlSourceImpl{Contextified: libkb.NewContextified(tc.G)}
+	should, require := s.refreshThresholds(context.Background())
+	if should != 2*time.Minute || require != 10*time.Minute {
+		t.Fatalf("expected overridden thresholds, got should:%s require:%s", should, require)
+	}
+}
+
+func TestRefreshThresholdsRejectsInvertedOverrides(t *testing.T) {
+	tc, _ := setupTest(t)
+	defer tc.Cleanup()
+
+	os.Setenv("KEYBASE_PVL_SHOULD_REFRESH_INTERVAL", "10m")
+	defer os.Unsetenv("KEYBASE_PVL_SHOULD_REFRESH_INTERVAL")
+	os.Setenv("KEYBASE_PVL_REQUIRE_REFRESH_INTERVAL", "2m")
+	defer os.Unsetenv("KEYBASE_PVL_REQUIRE_REFRESH_INTERVAL")
+
+	s := &PvlSourceImpl{Contextified: libkb.NewContextified(tc.G)}
+	should, require := s.refreshThresholds(context.Background())
+	if should != tShouldRefresh || require != tRequireRefresh {
+		t.Fatalf("expected a fall back to compiled-in defaults when require < should, got should:%s require:%s", should, require)
+	}
+}
+
+func TestSwapKitIfMatching(t *testing.T) {
+	tc, _ := setupTest(t)
+	defer tc.Cleanup()
+
+	s := &PvlSourceImpl{Contextified: libkb.NewContextified(tc.G)}
+	kit := `{"kit_version":1,"ctime":0,"tab":{"1":"x"}}`
+	wrongHash := s.hash(kit) + "f"
+
+	if s.SwapKitIfMatching(context.Background(), wrongHash, kit) {
+		t.Fatal("swap should not happen with a mismatched hash")
+	}
+	if s.mem != nil {
+		t.Fatal("mem should not be set after a rejected swap")
+	}
+
+	rightHash := s.hash(kit)
+	if !s.SwapKitIfMatching(context.Background(), rightHash, kit) {
+		t.Fatal("swap should happen when the hash matches")
+	}
+	if s.mem == nil || s.mem.Hash != rightHash {
+		t.Fatal("mem should hold the swapped-in kit")
+	}
+}
+
+// TestInvalidate checks that Invalidate drops whatever is cached and
+// forces a fresh GetKitString call, rather than serving the stale mem
+// entry.
+func TestInvalidate(t *testing.T) {
+	tc, _ := setupTest(t)
+	defer tc.Cleanup()
+
+	kitFile, err := ioutil.TempFile("", "pvlkit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(kitFile.Name())
+	if _, err := kitFile.WriteString(`{"kit_version":1,"ctime":0,"tab":{}}`); err != nil {
+		t.Fatal(err)
+	}
+	kitFile.Close()
+
+	os.Setenv("KEYBASE_PVL_KIT_FILE", kitFile.Name())
+	defer os.Unsetenv("KEYBASE_PVL_KIT_FILE")
+
+	s := &PvlSourceImpl{Contextified: libkb.NewContextified(tc.G)}
+	s.memSet("stale-hash", "stale-kit")
+	s.dbSet(context.Background(), "stale-hash", "stale-kit")
+
+	if err := s.Invalidate(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if s.mem != nil {
+		t.Fatalf("expected the stale mem entry to be dropped, got: %+v", s.mem)
+	}
+	if kit := s.dbGet(context.Background(), "stale-hash"); kit != nil {
+		t.Fatalf("expected the stale db entry to be dropped, got: %s", *kit)
+	}
+}
+
+// signKit signs the given unsigned kit JSON with key and returns a copy
+// with a "sig" field added, matching what validateKitSignature expects.
+func signKit(t *testing.T, key libkb.NaclSigningKeyPair, unsigned string) string {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(unsigned), &raw); err != nil {
+		t.Fatal(err)
+	}
+	canonical, err := json.Marshal(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, _, err := key.SignToString(canonical)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigJSON, err := json.Marshal(sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw["sig"] = sigJSON
+	signed, err := json.Marshal(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(signed)
+}
+
+func TestValidateKitSignatureNoopWhenUnconfigured(t *testing.T) {
+	tc, _ := setupTest(t)
+	defer tc.Cleanup()
+
+	s := &PvlSourceImpl{Contextified: libkb.NewContextified(tc.G)}
+	if err := s.validateKitSignature(`{"kit_version":1,"ctime":0,"tab":{"1":"x"}}`); err != nil {
+		t.Fatalf("unexpected error with no pinned KID: %s", err)
+	}
+}
+
+func TestValidateKitSignatureAcceptsMatchingKID(t *testing.T) {
+	tc, _ := setupTest(t)
+	defer tc.Cleanup()
+
+	key, err := libkb.GenerateNaclSigningKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed := signKit(t, key, `{"kit_version":1,"ctime":0,"tab":{"1":"x"}}`)
+
+	os.Setenv("KEYBASE_PVL_SIGNING_KID", key.GetKID().String())
+	defer os.Unsetenv("KEYBASE_PVL_SIGNING_KID")
+
+	s := &PvlSourceImpl{Contextified: libkb.NewContextified(tc.G)}
+	if err := s.validateKitSignature(signed); err != nil {
+		t.Fatalf("unexpected error with matching KID: %s", err)
+	}
+}
+
+func TestValidateKitSignatureRejectsMissingSig(t *testing.T) {
+	tc, _ := setupTest(t)
+	defer tc.Cleanup()
+
+	key, err := libkb.GenerateNaclSigningKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("KEYBASE_PVL_SIGNING_KID", key.GetKID().String())
+	defer os.Unsetenv("KEYBASE_PVL_SIGNING_KID")
+
+	s := &PvlSourceImpl{Contextified: libkb.NewContextified(tc.G)}
+	if err := s.validateKitSignature(`{"kit_version":1,"ctime":0,"tab":{"1":"x"}}`); err == nil {
+		t.Fatal("expected an error for a kit with no sig field")
+	}
+}
+
+func TestValidateKitSignatureRejectsWrongKID(t *testing.T) {
+	tc, _ := setupTest(t)
+	defer tc.Cleanup()
+
+	signingKey, err := libkb.GenerateNaclSigningKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pinnedKey, err := libkb.GenerateNaclSigningKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed := signKit(t, signingKey, `{"kit_version":1,"ctime":0,"tab":{"1":"x"}}`)
+
+	os.Setenv("KEYBASE_PVL_SIGNING_KID", pinnedKey.GetKID().String())
+	defer os.Unsetenv("KEYBASE_PVL_SIGNING_KID")
+
+	s := &PvlSourceImpl{Contextified: libkb.NewContextified(tc.G)}
+	if err := s.validateKitSignature(signed); err == nil {
+		t.Fatal("expected an error when the kit is signed by an unpinned KID")
+	}
+}
+
+func TestValidateKitSignatureRejectsTamperedBody(t *testing.T) {
+	tc, _ := setupTest(t)
+	defer tc.Cleanup()
+
+	key, err := libkb.GenerateNaclSigningKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed := signKit(t, key, `{"kit_version":1,"ctime":0,"tab":{"1":"x"}}`)
+	tampered := strings.Replace(signed, `"x"`, `"y"`, 1)
+
+	os.Setenv("KEYBASE_PVL_SIGNING_KID", key.GetKID().String())
+	defer os.Unsetenv("KEYBASE_PVL_SIGNING_KID")
+
+	s := &PvlSourceImpl{Contextified: libkb.NewContextified(tc.G)}
+	if err := s.validateKitSignature(tampered); err == nil {
+		t.Fatal("expected an error when the signed body was tampered with")
+	}
+}
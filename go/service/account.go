@@ -90,3 +90,37 @@ func (h *AccountHandler) ResetAccount(ctx context.Context, sessionID int) error
 
 	return h.G().Logout()
 }
+
+func (h *AccountHandler) EnterResetPipeline(_ context.Context, arg keybase1.EnterResetPipelineArg) error {
+	return h.G().LoginState().EnterResetPipeline(arg.UsernameOrEmail)
+}
+
+func (h *AccountHandler) CancelReset(_ context.Context, sessionID int) error {
+	return h.G().LoginState().CancelReset()
+}
+
+func (h *AccountHandler) GetResetStatus(_ context.Context, sessionID int) (keybase1.AutoresetStatus, error) {
+	return h.G().LoginState().GetResetStatus()
+}
+
+func (h *AccountHandler) SecretStoreStatus(_ context.Context, sessionID int) (keybase1.SecretStoreStatus, error) {
+	backend := h.G().SecretStoreAll.GetBackendType()
+	username := h.G().Env.GetUsername()
+
+	var hasStoredSecret bool
+	users, err := h.G().SecretStoreAll.GetUsersWithStoredSecrets()
+	if err != nil {
+		return keybase1.SecretStoreStatus{}, err
+	}
+	for _, u := range users {
+		if libkb.NewNormalizedUsername(u).Eq(username) {
+			hasStoredSecret = true
+			break
+		}
+	}
+
+	return keybase1.SecretStoreStatus{
+		Backend:         string(backend),
+		HasStoredSecret: hasStoredSecret,
+	}, nil
+}
@@ -0,0 +1,33 @@
+// Copyright 2017 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package service
+
+import (
+	"github.com/keybase/client/go/libkb"
+	keybase1 "github.com/keybase/client/go/protocol/keybase1"
+	"github.com/keybase/go-framed-msgpack-rpc/rpc"
+	"golang.org/x/net/context"
+)
+
+// AvatarsHandler is the RPC handler for the avatars interface.
+type AvatarsHandler struct {
+	*BaseHandler
+	libkb.Contextified
+}
+
+// NewAvatarsHandler creates an AvatarsHandler for the xp transport.
+func NewAvatarsHandler(xp rpc.Transporter, g *libkb.GlobalContext) *AvatarsHandler {
+	return &AvatarsHandler{
+		BaseHandler:  NewBaseHandler(xp),
+		Contextified: libkb.NewContextified(g),
+	}
+}
+
+func (h *AvatarsHandler) LoadUserAvatars(ctx context.Context, arg keybase1.LoadUserAvatarsArg) (keybase1.LoadAvatarsRes, error) {
+	return h.G().GetAvatarLoader().LoadUsers(ctx, arg.Names, arg.Formats)
+}
+
+func (h *AvatarsHandler) LoadTeamAvatars(ctx context.Context, arg keybase1.LoadTeamAvatarsArg) (keybase1.LoadAvatarsRes, error) {
+	return h.G().GetAvatarLoader().LoadTeams(ctx, arg.Names, arg.Formats)
+}
@@ -159,6 +159,30 @@ func (h *chatLocalHandler) MarkAsReadLocal(ctx context.Context, arg chat1.MarkAs
 	})
 }
 
+// StartTyping implements keybase.chatLocal.startTyping protocol.
+func (h *chatLocalHandler) StartTyping(ctx context.Context, conversationID chat1.ConversationID) (err error) {
+	defer h.Trace(ctx, func() error { return err }, "StartTyping")()
+	if err = h.assertLoggedIn(ctx); err != nil {
+		return err
+	}
+	return h.remoteClient().UpdateTypingRemote(ctx, chat1.UpdateTypingRemoteArg{
+		ConvID: conversationID,
+		Typing: true,
+	})
+}
+
+// StopTyping implements keybase.chatLocal.stopTyping protocol.
+func (h *chatLocalHandler) StopTyping(ctx context.Context, conversationID chat1.ConversationID) (err error) {
+	defer h.Trace(ctx, func() error { return err }, "StopTyping")()
+	if err = h.assertLoggedIn(ctx); err != nil {
+		return err
+	}
+	return h.remoteClient().UpdateTypingRemote(ctx, chat1.UpdateTypingRemoteArg{
+		ConvID: conversationID,
+		Typing: false,
+	})
+}
+
 // GetInboxAndUnboxLocal implements keybase.chatLocal.getInboxAndUnboxLocal protocol.
 func (h *chatLocalHandler) GetInboxAndUnboxLocal(ctx context.Context, arg chat1.GetInboxAndUnboxLocalArg) (res chat1.GetInboxAndUnboxLocalRes, err error) {
 	var identBreaks []keybase1.TLFIdentifyFailure
@@ -595,6 +619,105 @@ func (h *chatLocalHandler) SetConversationStatusLocal(ctx context.Context, arg c
 	}, nil
 }
 
+// SetConversationRetentionLocal implements keybase.chatLocal.setConversationRetentionLocal
+// protocol. This repo predates team-based conversations, so arg.WholeTLF --
+// apply the policy to every other conversation sharing this one's TLF that
+// this device has cached in its inbox -- stands in for a team-level policy.
+// Setting a policy only tells the server and other devices what it is; each
+// device's own BackgroundRetentionSweeper is what actually purges its local
+// cache to match it.
+func (h *chatLocalHandler) SetConversationRetentionLocal(ctx context.Context, arg chat1.SetConversationRetentionLocalArg) (res chat1.SetConversationRetentionLocalRes, err error) {
+	var identBreaks []keybase1.TLFIdentifyFailure
+	ctx = chat.Context(ctx, arg.IdentifyBehavior, &identBreaks, h.identNotifier)
+	defer h.Trace(ctx, func() error { return err }, "SetConversationRetentionLocal")()
+	if err = h.assertLoggedIn(ctx); err != nil {
+		return res, err
+	}
+
+	convIDs := []chat1.ConversationID{arg.ConversationID}
+	if arg.WholeTLF {
+		convIDs = append(convIDs, h.siblingConvIDsInTLF(ctx, arg.ConversationID)...)
+	}
+
+	var rateLimits []*chat1.RateLimit
+	for _, convID := range convIDs {
+		scrres, err := h.remoteClient().SetConversationRetention(ctx, chat1.SetConversationRetentionArg{
+			ConversationID: convID,
+			Policy:         arg.Policy,
+		})
+		if err != nil {
+			return res, err
+		}
+		rateLimits = append(rateLimits, scrres.RateLimit)
+	}
+
+	return chat1.SetConversationRetentionLocalRes{
+		RateLimits:       utils.AggRateLimitsP(rateLimits),
+		IdentifyFailures: identBreaks,
+	}, nil
+}
+
+// ReportConversationLocal implements keybase.chatLocal.ReportConversationLocal
+// protocol. It only flags the conversation for the server's abuse team --
+// callers that also want to stop seeing it should follow up with
+// SetConversationStatusLocal(BLOCKED).
+func (h *chatLocalHandler) ReportConversationLocal(ctx context.Context, arg chat1.ReportConversationLocalArg) (res chat1.ReportConversationLocalRes, err error) {
+	var identBreaks []keybase1.TLFIdentifyFailure
+	ctx = chat.Context(ctx, arg.IdentifyBehavior, &identBreaks, h.identNotifier)
+	defer h.Trace(ctx, func() error { return err }, "ReportConversationLocal")()
+	if err = h.assertLoggedIn(ctx); err != nil {
+		return chat1.ReportConversationLocalRes{}, err
+	}
+
+	rcres, err := h.remoteClient().ReportConversation(ctx, chat1.ReportConversationArg{
+		ConversationID: arg.ConversationID,
+		Reason:         arg.Reason,
+		Note:           arg.Note,
+	})
+	if err != nil {
+		return chat1.ReportConversationLocalRes{}, err
+	}
+
+	return chat1.ReportConversationLocalRes{
+		RateLimits:       utils.AggRateLimitsP([]*chat1.RateLimit{rcres.RateLimit}),
+		IdentifyFailures: identBreaks,
+	}, nil
+}
+
+// siblingConvIDsInTLF returns the convIDs of every locally cached
+// conversation that shares convID's TLF, not including convID itself. Used
+// as the TLF-wide stand-in for a team-level retention policy.
+func (h *chatLocalHandler) siblingConvIDsInTLF(ctx context.Context, convID chat1.ConversationID) []chat1.ConversationID {
+	uid := h.G().Env.GetUID()
+	_, convs, err := storage.NewInbox(h.G(), uid.ToBytes(), h.getSecretUI).ReadAll(ctx)
+	if err != nil {
+		h.Debug(ctx, "siblingConvIDsInTLF: unable to read inbox: %s", err.Error())
+		return nil
+	}
+
+	var tlfID chat1.TLFID
+	for _, conv := range convs {
+		if conv.GetConvID().Eq(convID) {
+			tlfID = conv.Metadata.IdTriple.Tlfid
+			break
+		}
+	}
+	if len(tlfID) == 0 {
+		return nil
+	}
+
+	var siblings []chat1.ConversationID
+	for _, conv := range convs {
+		if conv.GetConvID().Eq(convID) {
+			continue
+		}
+		if conv.Metadata.IdTriple.Tlfid.Eq(tlfID) {
+			siblings = append(siblings, conv.GetConvID())
+		}
+	}
+	return siblings
+}
+
 // PostLocal implements keybase.chatLocal.postLocal protocol.
 func (h *chatLocalHandler) PostLocal(ctx context.Context, arg chat1.PostLocalArg) (res chat1.PostLocalRes, err error) {
 	var identBreaks []keybase1.TLFIdentifyFailure
@@ -669,6 +792,25 @@ func (h *chatLocalHandler) PostEditNonblock(ctx context.Context, arg chat1.PostE
 	return h.PostLocalNonblock(ctx, parg)
 }
 
+func (h *chatLocalHandler) PostReactionNonblock(ctx context.Context, arg chat1.PostReactionNonblockArg) (chat1.PostLocalNonblockRes, error) {
+
+	var parg chat1.PostLocalNonblockArg
+	parg.ClientPrev = arg.ClientPrev
+	parg.ConversationID = arg.ConversationID
+	parg.IdentifyBehavior = arg.IdentifyBehavior
+	parg.Msg.ClientHeader.Conv = arg.Conv
+	parg.Msg.ClientHeader.MessageType = chat1.MessageType_REACTION
+	parg.Msg.ClientHeader.Supersedes = arg.Supersedes
+	parg.Msg.ClientHeader.TlfName = arg.TlfName
+	parg.Msg.ClientHeader.TlfPublic = arg.TlfPublic
+	parg.Msg.MessageBody = chat1.NewMessageBodyWithReaction(chat1.MessageReaction{
+		MessageID: arg.Supersedes,
+		Body:      arg.Body,
+	})
+
+	return h.PostLocalNonblock(ctx, parg)
+}
+
 func (h *chatLocalHandler) PostTextNonblock(ctx context.Context, arg chat1.PostTextNonblockArg) (chat1.PostLocalNonblockRes, error) {
 
 	var parg chat1.PostLocalNonblockArg
@@ -1167,6 +1309,91 @@ func (h *chatLocalHandler) downloadAttachmentLocal(ctx context.Context, arg down
 	}, nil
 }
 
+func (h *chatLocalHandler) ListOutbox(ctx context.Context) (res chat1.ListOutboxRes, err error) {
+	defer h.Trace(ctx, func() error { return err }, "ListOutbox")()
+	if err = h.assertLoggedIn(ctx); err != nil {
+		return res, err
+	}
+
+	uid := h.G().Env.GetUID()
+	outbox := storage.NewOutbox(h.G(), uid.ToBytes(), h.getSecretUI)
+	records, err := outbox.PullAllConversations(ctx, true, false)
+	if err != nil {
+		if _, ok := err.(storage.MissError); ok {
+			return res, nil
+		}
+		return res, err
+	}
+
+	res.Records = records
+	return res, nil
+}
+
+func (h *chatLocalHandler) SearchInbox(ctx context.Context, arg chat1.SearchInboxArg) (res chat1.SearchInboxRes, err error) {
+	defer h.Trace(ctx, func() error { return err }, "SearchInbox")()
+	if err = h.assertLoggedIn(ctx); err != nil {
+		return res, err
+	}
+
+	uid := gregor1.UID(h.G().Env.GetUID().ToBytes())
+	searchHits, err := chat.NewSearcher(h.G()).SearchInbox(ctx, uid, arg.Query, arg.ConvID,
+		arg.MaxHits, arg.BeforeContext, arg.AfterContext)
+	if err != nil {
+		return res, err
+	}
+
+	for _, convHits := range searchHits {
+		hit := chat1.ChatSearchInboxHit{
+			ConvID:  convHits.ConvID,
+			TlfName: convHits.TlfName,
+		}
+		for _, searchHit := range convHits.Hits {
+			hit.Hits = append(hit.Hits, chat1.ChatSearchHit{
+				HitMessage:    searchHit.HitMessage,
+				BeforeContext: searchHit.BeforeContext,
+				AfterContext:  searchHit.AfterContext,
+			})
+		}
+		res.Conversations = append(res.Conversations, hit)
+	}
+
+	return res, nil
+}
+
+// UnboxMobilePushNotification implements keybase.chatLocal.unboxMobilePushNotification
+// protocol. An APNS/FCM push notification's data payload is too small to carry a
+// boxed message, so it only carries convID/msgID; this fetches and unboxes just
+// that one message and reduces it to a short sender/snippet preview.
+func (h *chatLocalHandler) UnboxMobilePushNotification(ctx context.Context, arg chat1.UnboxMobilePushNotificationArg) (res chat1.MobilePushNotificationPreview, err error) {
+	var identBreaks []keybase1.TLFIdentifyFailure
+	ctx = chat.Context(ctx, arg.IdentifyBehavior, &identBreaks, h.identNotifier)
+	defer h.Trace(ctx, func() error { return err }, "UnboxMobilePushNotification")()
+	if err = h.assertLoggedIn(ctx); err != nil {
+		return res, err
+	}
+
+	rres, err := h.remoteClient().GetMessagesRemote(ctx, chat1.GetMessagesRemoteArg{
+		ConversationID: arg.ConvID,
+		MessageIDs:     []chat1.MessageID{arg.MsgID},
+	})
+	if err != nil {
+		return res, err
+	}
+	if len(rres.Msgs) == 0 {
+		return res, libkb.NotFoundError{Msg: "no such message"}
+	}
+
+	unboxed, err := h.boxer.UnboxMessage(ctx, rres.Msgs[0], arg.ConvID, nil /* finalizeInfo */)
+	if err != nil {
+		return res, err
+	}
+	if !unboxed.IsValid() {
+		return res, fmt.Errorf("UnboxMobilePushNotification: message did not unbox validly")
+	}
+
+	return chat.BuildMobilePushNotificationPreview(unboxed.Valid())
+}
+
 func (h *chatLocalHandler) CancelPost(ctx context.Context, outboxID chat1.OutboxID) (err error) {
 	defer h.Trace(ctx, func() error { return err }, "CancelPost")()
 	if err = h.assertLoggedIn(ctx); err != nil {
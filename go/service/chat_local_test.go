@@ -808,6 +808,40 @@ func TestGetOutbox(t *testing.T) {
 
 }
 
+func TestListOutbox(t *testing.T) {
+	ctc := makeChatTestContext(t, "ListOutbox", 2)
+	defer ctc.cleanup()
+	users := ctc.users()
+
+	created := mustCreateConversationForTest(t, ctc, users[0], chat1.TopicType_CHAT, ctc.as(t, users[1]).user().Username)
+
+	u := users[0]
+	h := ctc.as(t, users[0]).h
+	tc := ctc.world.Tcs[ctc.as(t, users[0]).user().Username]
+	outbox := storage.NewOutbox(tc.G, users[0].User.GetUID().ToBytes(), h.getSecretUI)
+
+	res, err := h.ListOutbox(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 0, len(res.Records), "expected an empty outbox")
+
+	obr, err := outbox.PushMessage(context.TODO(), created.Id, chat1.MessagePlaintext{
+		ClientHeader: chat1.MessageClientHeader{
+			Sender:    u.User.GetUID().ToBytes(),
+			TlfName:   u.Username,
+			TlfPublic: false,
+			OutboxInfo: &chat1.OutboxInfo{
+				Prev: 10,
+			},
+		},
+	}, keybase1.TLFIdentifyBehavior_CHAT_CLI)
+	require.NoError(t, err)
+
+	res, err = h.ListOutbox(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, len(res.Records), "wrong size outbox")
+	require.Equal(t, obr.OutboxID, res.Records[0].OutboxID, "wrong outbox ID")
+}
+
 func TestChatGap(t *testing.T) {
 	ctc := makeChatTestContext(t, "GetOutbox", 2)
 	defer ctc.cleanup()
@@ -906,12 +940,15 @@ func (n *chatListener) PGPKeyInSecretStoreFile()
 func (n *chatListener) BadgeState(badgeState keybase1.BadgeState)                           {}
 func (n *chatListener) ReachabilityChanged(r keybase1.Reachability)                         {}
 func (n *chatListener) ChatIdentifyUpdate(update keybase1.CanonicalTLFNameAndIDWithBreaks)  {}
+func (n *chatListener) ChatTLFIdentifyProgress(progress chat1.TLFIdentifyProgress)          {}
 func (n *chatListener) ChatTLFFinalize(uid keybase1.UID, convID chat1.ConversationID, info chat1.ConversationFinalizeInfo) {
 }
 func (n *chatListener) ChatTLFResolve(uid keybase1.UID, convID chat1.ConversationID, info chat1.ConversationResolveInfo) {
 }
 func (n *chatListener) ChatInboxStale(uid keybase1.UID)                                {}
 func (n *chatListener) ChatThreadsStale(uid keybase1.UID, cids []chat1.ConversationID) {}
+func (n *chatListener) ChatTypingUpdate(typingUpdates []chat1.ConvTypingUpdate)         {}
+func (n *chatListener) ChatAttentionUpdate(uid keybase1.UID, convID chat1.ConversationID, msgID chat1.MessageID) {}
 func (n *chatListener) NewChatActivity(uid keybase1.UID, activity chat1.ChatActivity) {
 	typ, _ := activity.ActivityType()
 	if typ == chat1.ChatActivityType_INCOMING_MESSAGE {
@@ -6,10 +6,12 @@ package service
 import (
 	"encoding/json"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"golang.org/x/net/context"
 
@@ -194,6 +196,19 @@ func (h ConfigHandler) GetExtendedStatus(ctx context.Context, sessionID int) (re
 	res.ProvisionedUsernames = p
 	res.PlatformInfo = getPlatformInfo()
 	res.DefaultDeviceID = h.G().Env.GetDeviceID()
+	res.NetworkBreakers = exportNetworkBreakerStatus(h.G().NetworkBreakers.Snapshot())
+	res.ConnPools = exportConnPoolStatus(h.G().API.ConnStats())
+	if pvlSource := h.G().GetPvlSource(); pvlSource != nil {
+		diag := pvlSource.Diagnostics(ctx)
+		res.PvlDiagnostics = &keybase1.PvlDiagnostics{
+			MerkleRootSeqno:     diag.MerkleRootSeqno,
+			MerkleRootFetchTime: keybase1.ToTime(diag.MerkleRootFetchTime),
+			MerkleRootHash:      diag.MerkleRootHash,
+			ActiveHash:          diag.ActiveHash,
+			CacheSource:         diag.CacheSource,
+			LastFetchError:      diag.LastFetchError,
+		}
+	}
 
 	return res, nil
 }
@@ -305,3 +320,77 @@ func (h ConfigHandler) CheckAPIServerOutOfDateWarning(_ context.Context) (keybas
 func (h ConfigHandler) WaitForClient(_ context.Context, arg keybase1.WaitForClientArg) (bool, error) {
 	return h.G().ConnectionManager.WaitForClientType(arg.ClientType, arg.Timeout.Duration()), nil
 }
+
+func (h ConfigHandler) GetPvlMetrics(_ context.Context) (string, error) {
+	return h.G().MetricsRegistry.Snapshot().JSON()
+}
+
+// proxyCheckComponents are the network paths configurable via
+// libkb.Env.GetProxyPolicy, checked by CheckProxyConnectivity. The API
+// client and proof checker have their own longer-standing Tor-based proxy
+// knobs (GetTorMode/GetProofProxyMode) and aren't part of this newer
+// per-component ProxyPolicy, so they're not checked here.
+var proxyCheckComponents = []string{"gregor", "attachment"}
+
+// CheckProxyConnectivity dials the configured proxy (if any) for each
+// component in proxyCheckComponents and reports whether it's reachable, so
+// a misconfigured proxy address shows up immediately instead of as a
+// mysterious connection timeout later. It only checks that the proxy
+// itself is reachable, not the component's actual destination -- that's
+// already covered by each component's own retry/reconnect logic.
+func (h ConfigHandler) CheckProxyConnectivity(ctx context.Context, sessionID int) (res []keybase1.ProxyCheckResult, err error) {
+	defer h.G().Trace("ConfigHandler::CheckProxyConnectivity", func() error { return err })()
+	for _, component := range proxyCheckComponents {
+		res = append(res, checkProxyConnectivity(h.G(), component))
+	}
+	return res, nil
+}
+
+func checkProxyConnectivity(g *libkb.GlobalContext, component string) keybase1.ProxyCheckResult {
+	policy := g.Env.GetProxyPolicy(component)
+	result := keybase1.ProxyCheckResult{Component: component, Enabled: policy.Enabled()}
+	if !result.Enabled {
+		return result
+	}
+	conn, err := net.DialTimeout("tcp", policy.Address, 5*time.Second)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	conn.Close()
+	result.Reachable = true
+	return result
+}
+
+// exportNetworkBreakerStatus converts a NetworkBreakerRegistry snapshot to
+// its RPC representation, for surfacing in GetExtendedStatus (`keybase
+// status`).
+func exportNetworkBreakerStatus(states []libkb.NetworkBreakerState) []keybase1.NetworkBreakerStatus {
+	exported := make([]keybase1.NetworkBreakerStatus, len(states))
+	for i, s := range states {
+		exported[i] = keybase1.NetworkBreakerStatus{
+			Name:                s.Name,
+			Open:                s.Open,
+			ConsecutiveFailures: s.ConsecutiveFailures,
+			TotalFailures:       s.TotalFailures,
+			TotalSuccesses:      s.TotalSuccesses,
+			TotalRateLimited:    s.TotalRateLimited,
+			OpenedAt:            keybase1.ToTime(s.OpenedAt),
+		}
+	}
+	return exported
+}
+
+// exportConnPoolStatus converts a Client connection-reuse snapshot to its
+// RPC representation, for surfacing in GetExtendedStatus (`keybase
+// status`).
+func exportConnPoolStatus(stats []libkb.ConnPoolStats) []keybase1.ConnPoolStatus {
+	exported := make([]keybase1.ConnPoolStatus, len(stats))
+	for i, s := range stats {
+		exported[i] = keybase1.ConnPoolStatus{
+			ActiveRequests: s.ActiveRequests,
+			TotalRequests:  s.TotalRequests,
+		}
+	}
+	return exported
+}
@@ -117,6 +117,25 @@ func (c *CtlHandler) DbGet(_ context.Context, arg keybase1.DbGetArg) (*keybase1.
 	return &val, nil
 }
 
+func (c *CtlHandler) DbStats(_ context.Context, arg keybase1.DbStatsArg) ([]keybase1.DbUsageEntry, error) {
+	var db *libkb.JSONLocalDb
+	switch arg.DbType {
+	case keybase1.DbType_MAIN:
+		db = c.G().LocalDb
+	case keybase1.DbType_CHAT:
+		db = c.G().LocalChatDb
+	default:
+		return nil, libkb.NewDBError("no such DB type")
+	}
+
+	usage := db.UsageStats()
+	ret := make([]keybase1.DbUsageEntry, 0, len(usage))
+	for typ, bytes := range usage {
+		ret = append(ret, keybase1.DbUsageEntry{ObjType: int(typ), Bytes: bytes})
+	}
+	return ret, nil
+}
+
 func (c *CtlHandler) DbPut(_ context.Context, arg keybase1.DbPutArg) (err error) {
 	key := libkb.ImportDbKey(arg.Key)
 
@@ -103,3 +103,14 @@ func (h *DeviceHandler) CheckDeviceNameForUser(_ context.Context, arg keybase1.C
 
 	return err
 }
+
+// DeviceRename renames one of the caller's own devices via a signed
+// sigchain link, the same signature-based approach EmailChange uses to
+// update account settings.
+func (h *DeviceHandler) DeviceRename(_ context.Context, arg keybase1.DeviceRenameArg) error {
+	ctx := &engine.Context{
+		SecretUI: h.getSecretUI(arg.SessionID, h.G()),
+	}
+	eng := engine.NewDeviceRename(&arg, h.G())
+	return engine.RunEngine(eng, ctx)
+}
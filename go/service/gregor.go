@@ -527,6 +527,7 @@ func (g *gregorHandler) OnConnect(ctx context.Context, conn *rpc.Connection,
 
 	// Sync badge state in the background
 	if g.badger != nil {
+		g.badger.Restore(ctx)
 		go func(badger *badges.Badger) {
 			badger.Resync(context.Background(), &chat1.RemoteClient{Cli: g.cli})
 		}(g.badger)
@@ -905,9 +906,15 @@ func (g *gregorHandler) handleOutOfBandMessage(ctx context.Context, obm gregor.O
 		return g.chatHandler.TlfFinalize(ctx, obm)
 	case "chat.tlfresolve":
 		return g.chatHandler.TlfResolve(ctx, obm)
+	case "chat.typing":
+		return g.chatHandler.Typing(ctx, obm)
 	case "internal.reconnect":
 		g.G().Log.Debug("reconnected to push server")
 		return nil
+	case "pvl.update":
+		return g.pvlUpdate(ctx)
+	case "avatars.changed":
+		return g.avatarsChanged(ctx, obm)
 	default:
 		return fmt.Errorf("unhandled system: %s", obm.System())
 	}
@@ -918,6 +925,15 @@ func (g *gregorHandler) Shutdown() {
 	g.connMutex.Lock()
 	defer g.connMutex.Unlock()
 
+	// Flush local state to disk now instead of waiting for the next save
+	// timer tick, so a restart immediately after shutdown restores from a
+	// state that includes whatever was consumed since the last tick.
+	if g.gregorCli != nil {
+		if err := g.gregorCli.Save(); err != nil {
+			g.Debug(context.Background(), "shutdown: failed to save gregor state: %s", err)
+		}
+	}
+
 	if g.conn == nil {
 		return
 	}
@@ -953,6 +969,43 @@ func (g *gregorHandler) kbfsFavorites(ctx context.Context, m gregor.OutOfBandMes
 	}
 }
 
+// pvlUpdate is fired when the server pushes a "pvl.update" out-of-band
+// message, announcing that a new pvl kit has gone live. It invalidates
+// the local pvl cache so the new kit is picked up immediately, rather
+// than waiting on the client to notice on its own.
+func (g *gregorHandler) pvlUpdate(ctx context.Context) error {
+	pvlSource := g.G().GetPvlSource()
+	if pvlSource == nil {
+		return errors.New("gregor handler for pvl.update: no pvl source configured")
+	}
+	return pvlSource.Invalidate(ctx)
+}
+
+// avatarsChanged is fired when the server pushes an "avatars.changed"
+// out-of-band message, announcing that a user's or team's avatar has
+// been updated. It drops the cached URL and image for that name so the
+// next load re-fetches it instead of serving a stale picture.
+func (g *gregorHandler) avatarsChanged(ctx context.Context, m gregor.OutOfBandMessage) error {
+	if m.Body() == nil {
+		return errors.New("gregor handler for avatars.changed: nil message body")
+	}
+	body, err := jsonw.Unmarshal(m.Body().Bytes())
+	if err != nil {
+		return err
+	}
+
+	name, err := body.AtPath("name").GetString()
+	if err != nil {
+		return err
+	}
+
+	avatarLoader := g.G().GetAvatarLoader()
+	if avatarLoader == nil {
+		return errors.New("gregor handler for avatars.changed: no avatar loader configured")
+	}
+	return avatarLoader.OnCacheInvalidate(ctx, name)
+}
+
 func (g *gregorHandler) notifyFavoritesChanged(ctx context.Context, uid gregor.UID) error {
 	kbUID, err := keybase1.UIDFromString(hex.EncodeToString(uid.Bytes()))
 	if err != nil {
@@ -1090,7 +1143,15 @@ func (g *gregorHandler) connectTLS() error {
 		WrapErrorFunc:    libkb.WrapError,
 		ReconnectBackoff: func() backoff.BackOff { return constBackoff },
 	}
-	g.conn = rpc.NewTLSConnection(uri.HostPort, []byte(rawCA), libkb.ErrorUnwrapper{}, g, libkb.NewRPCLogFactory(g.G()), g.G().Log, opts)
+	if g.G().Env.GetProxyPolicy("gregor").Enabled() {
+		// The vendored TLS transport dials directly with no proxy hook, so
+		// route through our own transport instead when a gregor proxy is
+		// configured.
+		t := newConnTransportTLS(g.G(), uri.HostPort, []byte(rawCA))
+		g.conn = rpc.NewConnectionWithTransport(g, t, libkb.ErrorUnwrapper{}, g.G().Log, opts)
+	} else {
+		g.conn = rpc.NewTLSConnection(uri.HostPort, []byte(rawCA), libkb.ErrorUnwrapper{}, g, libkb.NewRPCLogFactory(g.G()), g.G().Log, opts)
+	}
 
 	// The client we get here will reconnect to gregord on disconnect if necessary.
 	// We should grab it here instead of in OnConnect, since the connection is not
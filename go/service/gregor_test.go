@@ -101,6 +101,7 @@ func (n *nlistener) FavoritesChanged(uid keybase1.UID) {
 }
 func (n *nlistener) NewChatActivity(uid keybase1.UID, activity chat1.ChatActivity)      {}
 func (n *nlistener) ChatIdentifyUpdate(update keybase1.CanonicalTLFNameAndIDWithBreaks) {}
+func (n *nlistener) ChatTLFIdentifyProgress(progress chat1.TLFIdentifyProgress)         {}
 func (n *nlistener) KeyfamilyChanged(uid keybase1.UID)                                  {}
 func (n *nlistener) PGPKeyInSecretStoreFile()                                           {}
 func (n *nlistener) FSSyncStatusResponse(arg keybase1.FSSyncStatusArg)                  {}
@@ -118,6 +119,8 @@ func (n *nlistener) ChatThreadsStale(uid keybase1.UID, cids []chat1.Conversation
 		require.Fail(n.t, "thread send timeout")
 	}
 }
+func (n *nlistener) ChatTypingUpdate([]chat1.ConvTypingUpdate) {}
+func (n *nlistener) ChatAttentionUpdate(keybase1.UID, chat1.ConversationID, chat1.MessageID) {}
 func (n *nlistener) BadgeState(badgeState keybase1.BadgeState) {
 	select {
 	case n.badgeState <- badgeState:
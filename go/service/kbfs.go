@@ -66,6 +66,10 @@ func (h *KBFSHandler) checkConversationRekey(arg keybase1.FSNotification) {
 		return
 	}
 
+	if invalidator, ok := h.G().GetTlfResolver().(libkb.TlfCacheInvalidator); ok {
+		invalidator.InvalidateTLF(filepath.Base(arg.Filename))
+	}
+
 	uid := h.G().Env.GetUID()
 	if uid.IsNil() {
 		h.G().Log.Debug("received rekey finished notification for %s, but have no UID", arg.Filename)
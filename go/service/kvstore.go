@@ -0,0 +1,61 @@
+// Copyright 2016 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package service
+
+import (
+	"github.com/keybase/client/go/kvstore"
+	"github.com/keybase/client/go/libkb"
+	keybase1 "github.com/keybase/client/go/protocol/keybase1"
+	"github.com/keybase/go-framed-msgpack-rpc/rpc"
+	"golang.org/x/net/context"
+)
+
+type KVStoreHandler struct {
+	*BaseHandler
+	libkb.Contextified
+
+	store *kvstore.KVStore
+}
+
+func NewKVStoreHandler(xp rpc.Transporter, g *libkb.GlobalContext) *KVStoreHandler {
+	h := &KVStoreHandler{
+		BaseHandler:  NewBaseHandler(xp),
+		Contextified: libkb.NewContextified(g),
+	}
+	h.store = kvstore.New(g, h.getSecretUI)
+	return h
+}
+
+func (h *KVStoreHandler) getSecretUI() libkb.SecretUI {
+	return h.BaseHandler.getSecretUI(0, h.G())
+}
+
+func (h *KVStoreHandler) KVStorePut(ctx context.Context, arg keybase1.KVStorePutArg) error {
+	uid := h.G().Env.GetUID()
+	return h.store.Put(ctx, uid, arg.Namespace, arg.Key, arg.Value, arg.SyncToServer)
+}
+
+func (h *KVStoreHandler) KVStoreGet(ctx context.Context, arg keybase1.KVStoreGetArg) (keybase1.KVStoreGetRes, error) {
+	uid := h.G().Env.GetUID()
+	value, found, err := h.store.Get(ctx, uid, arg.Namespace, arg.Key)
+	if err != nil {
+		return keybase1.KVStoreGetRes{}, err
+	}
+	return keybase1.KVStoreGetRes{Value: value, Found: found}, nil
+}
+
+func (h *KVStoreHandler) KVStoreDelete(_ context.Context, arg keybase1.KVStoreDeleteArg) error {
+	uid := h.G().Env.GetUID()
+	return h.store.Delete(uid, arg.Namespace, arg.Key)
+}
+
+func (h *KVStoreHandler) KVStoreListNamespaces(_ context.Context, sessionID int) ([]string, error) {
+	uid := h.G().Env.GetUID()
+	return h.store.ListNamespaces(uid)
+}
+
+func (h *KVStoreHandler) KVStoreListKeys(_ context.Context, arg keybase1.KVStoreListKeysArg) ([]string, error) {
+	uid := h.G().Env.GetUID()
+	return h.store.ListKeys(uid, arg.Namespace)
+}
@@ -0,0 +1,93 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package service
+
+import (
+	"github.com/keybase/client/go/libkb"
+	keybase1 "github.com/keybase/client/go/protocol/keybase1"
+)
+
+// logIdentifyUI is a headless libkb.IdentifyUI used when no client has
+// registered a delegate identify UI. Without it, identifies triggered in
+// the background (e.g. by an incoming chat message) would fail outright
+// the moment they needed a UI, instead of just proceeding unattended like
+// they would if a UI were present but auto-answering. It logs everything
+// it would otherwise have shown a user and answers prompts the same way a
+// non-strict RemoteIdentifyUI with skipPrompt does.
+type logIdentifyUI struct {
+	libkb.Contextified
+}
+
+func newLogIdentifyUI(g *libkb.GlobalContext) *logIdentifyUI {
+	return &logIdentifyUI{Contextified: libkb.NewContextified(g)}
+}
+
+func (u *logIdentifyUI) Start(username string, reason keybase1.IdentifyReason, force bool) error {
+	u.G().Log.Debug("logIdentifyUI: Start(%s, %v, %v): no delegate UI registered, identifying headlessly", username, reason, force)
+	return nil
+}
+
+func (u *logIdentifyUI) FinishWebProofCheck(p keybase1.RemoteProof, lcr keybase1.LinkCheckResult) error {
+	if lcr.BreaksTracking {
+		u.G().Log.Warning("logIdentifyUI: web proof check failed and breaks tracking: %+v", lcr)
+	}
+	return nil
+}
+
+func (u *logIdentifyUI) FinishSocialProofCheck(p keybase1.RemoteProof, lcr keybase1.LinkCheckResult) error {
+	if lcr.BreaksTracking {
+		u.G().Log.Warning("logIdentifyUI: social proof check failed and breaks tracking: %+v", lcr)
+	}
+	return nil
+}
+
+func (u *logIdentifyUI) Confirm(outcome *keybase1.IdentifyOutcome) (keybase1.ConfirmResult, error) {
+	u.G().Log.Debug("logIdentifyUI: auto-confirming identify of %q", outcome.Username)
+	return keybase1.ConfirmResult{IdentityConfirmed: true}, nil
+}
+
+func (u *logIdentifyUI) DisplayCryptocurrency(c keybase1.Cryptocurrency) error {
+	return nil
+}
+
+func (u *logIdentifyUI) DisplayKey(k keybase1.IdentifyKey) error {
+	return nil
+}
+
+func (u *logIdentifyUI) ReportLastTrack(s *keybase1.TrackSummary) error {
+	return nil
+}
+
+func (u *logIdentifyUI) LaunchNetworkChecks(i *keybase1.Identity, user *keybase1.User) error {
+	return nil
+}
+
+func (u *logIdentifyUI) DisplayTrackStatement(s string) error {
+	return nil
+}
+
+func (u *logIdentifyUI) DisplayUserCard(c keybase1.UserCard) error {
+	return nil
+}
+
+func (u *logIdentifyUI) ReportTrackToken(t keybase1.TrackToken) error {
+	return nil
+}
+
+func (u *logIdentifyUI) Cancel() error {
+	return nil
+}
+
+func (u *logIdentifyUI) Finish() error {
+	return nil
+}
+
+func (u *logIdentifyUI) DisplayTLFCreateWithInvite(d keybase1.DisplayTLFCreateWithInviteArg) error {
+	return nil
+}
+
+func (u *logIdentifyUI) Dismiss(s string, r keybase1.DismissReason) error {
+	u.G().Log.Debug("logIdentifyUI: Dismiss(%s, %v)", s, r)
+	return nil
+}
@@ -66,14 +66,14 @@ func (h *LoginHandler) ClearStoredSecret(_ context.Context, arg keybase1.ClearSt
 	return libkb.ClearStoredSecret(h.G(), libkb.NewNormalizedUsername(arg.Username))
 }
 
-func (h *LoginHandler) PaperKey(_ context.Context, sessionID int) error {
+func (h *LoginHandler) PaperKey(_ context.Context, arg keybase1.PaperKeyArg) error {
 	ctx := &engine.Context{
-		LogUI:     h.getLogUI(sessionID),
-		LoginUI:   h.getLoginUI(sessionID),
-		SecretUI:  h.getSecretUI(sessionID, h.G()),
-		SessionID: sessionID,
+		LogUI:     h.getLogUI(arg.SessionID),
+		LoginUI:   h.getLoginUI(arg.SessionID),
+		SecretUI:  h.getSecretUI(arg.SessionID, h.G()),
+		SessionID: arg.SessionID,
 	}
-	eng := engine.NewPaperKey(h.G())
+	eng := engine.NewPaperKeyWithArg(h.G(), &engine.PaperKeyArg{Label: arg.Label, Strong: arg.Strong})
 	return engine.RunEngine(eng, ctx)
 }
 
@@ -40,6 +40,8 @@ type Service struct {
 	rekeyMaster          *rekeyMaster
 	attachmentstore      *chat.AttachmentStore
 	messageDeliverer     *chat.Deliverer
+	ephemeralPurger      *chat.BackgroundEphemeralPurger
+	retentionSweeper     *chat.BackgroundRetentionSweeper
 	badger               *badges.Badger
 	reachability         *reachability
 	backgroundIdentifier *BackgroundIdentifier
@@ -50,6 +52,10 @@ type Shutdowner interface {
 }
 
 func NewService(g *libkb.GlobalContext, isDaemon bool) *Service {
+	attachmentHTTPClient, err := g.Env.GetProxyPolicy("attachment").HTTPClient()
+	if err != nil {
+		g.Log.Warning("failed to build proxy client for attachment transfers, using default: %s", err)
+	}
 	return &Service{
 		Contextified:    libkb.NewContextified(g),
 		isDaemon:        isDaemon,
@@ -57,7 +63,7 @@ func NewService(g *libkb.GlobalContext, isDaemon bool) *Service {
 		stopCh:          make(chan keybase1.ExitCode),
 		logForwarder:    newLogFwd(),
 		rekeyMaster:     newRekeyMaster(g),
-		attachmentstore: chat.NewAttachmentStore(g.Log, g.Env.GetRuntimeDir()),
+		attachmentstore: chat.NewAttachmentStore(g.Log, g.Env.GetRuntimeDir(), int64(g.Env.GetChatAttachmentCacheQuotaBytes()), attachmentHTTPClient),
 		badger:          badges.NewBadger(g),
 		reachability:    newReachability(g),
 	}
@@ -70,6 +76,7 @@ func (d *Service) GetStartChannel() <-chan struct{} {
 func (d *Service) RegisterProtocols(srv *rpc.Server, xp rpc.Transporter, connID libkb.ConnectionID, logReg *logRegister, g *libkb.GlobalContext) (shutdowners []Shutdowner, err error) {
 	protocols := []rpc.Protocol{
 		keybase1.AccountProtocol(NewAccountHandler(xp, g)),
+		keybase1.AvatarsProtocol(NewAvatarsHandler(xp, g)),
 		keybase1.BTCProtocol(NewCryptocurrencyHandler(xp, g)),
 		keybase1.CryptocurrencyProtocol(NewCryptocurrencyHandler(xp, g)),
 		keybase1.ConfigProtocol(NewConfigHandler(xp, connID, g, d)),
@@ -83,10 +90,13 @@ func (d *Service) RegisterProtocols(srv *rpc.Server, xp rpc.Transporter, connID
 		keybase1.IdentifyProtocol(NewIdentifyHandler(xp, g)),
 		keybase1.KbfsProtocol(NewKBFSHandler(xp, g)),
 		keybase1.KbfsMountProtocol(NewKBFSMountHandler(xp, g)),
+		keybase1.KVStoreProtocol(NewKVStoreHandler(xp, g)),
 		keybase1.LogProtocol(NewLogHandler(xp, logReg, g)),
 		keybase1.LoginProtocol(NewLoginHandler(xp, g)),
+		keybase1.MerkleProtocol(NewMerkleHandler(xp, g)),
 		keybase1.NotifyCtlProtocol(NewNotifyCtlHandler(xp, connID, g)),
 		keybase1.PGPProtocol(NewPGPHandler(xp, g)),
+		keybase1.PvlProtocol(NewPvlHandler(xp, g)),
 		keybase1.ReachabilityProtocol(newReachabilityHandler(xp, g, d.reachability)),
 		keybase1.RevokeProtocol(NewRevokeHandler(xp, g)),
 		keybase1.ProveProtocol(NewProveHandler(xp, g)),
@@ -96,6 +106,7 @@ func (d *Service) RegisterProtocols(srv *rpc.Server, xp rpc.Transporter, connID
 		keybase1.SessionProtocol(NewSessionHandler(xp, g)),
 		keybase1.SignupProtocol(NewSignupHandler(xp, g)),
 		keybase1.SigsProtocol(NewSigsHandler(xp, g)),
+		keybase1.TeamProtocol(NewTeamHandler(xp, g)),
 		keybase1.TestProtocol(NewTestHandler(xp, g)),
 		keybase1.TrackProtocol(NewTrackHandler(xp, g)),
 		keybase1.UserProtocol(NewUserHandler(xp, g)),
@@ -123,6 +134,7 @@ func (d *Service) Handle(c net.Conn) {
 
 	cl := make(chan error, 1)
 	connID := d.G().NotifyRouter.AddConnection(xp, cl)
+	defer d.G().NotifyRouter.RemoveConnection(connID)
 
 	var logReg *logRegister
 	if d.isDaemon {
@@ -241,6 +253,8 @@ func (d *Service) RunBackgroundOperations(uir *UIRouter) {
 	d.createMessageDeliverer()
 	d.startupGregor()
 	d.startMessageDeliverer()
+	d.createAndStartEphemeralPurger()
+	d.createAndStartRetentionSweeper()
 	d.addGlobalHooks()
 	d.configurePath()
 	d.configureRekey(uir)
@@ -265,6 +279,30 @@ func (d *Service) startMessageDeliverer() {
 	}
 }
 
+func (d *Service) createAndStartEphemeralPurger() {
+	uid := d.G().Env.GetUID()
+	if uid.IsNil() {
+		return
+	}
+	if d.ephemeralPurger != nil {
+		d.ephemeralPurger.Stop(context.Background())
+	}
+	d.ephemeralPurger = chat.NewBackgroundEphemeralPurger(d.G())
+	d.ephemeralPurger.Start(context.Background(), uid.ToBytes())
+}
+
+func (d *Service) createAndStartRetentionSweeper() {
+	uid := d.G().Env.GetUID()
+	if uid.IsNil() {
+		return
+	}
+	if d.retentionSweeper != nil {
+		d.retentionSweeper.Stop(context.Background())
+	}
+	d.retentionSweeper = chat.NewBackgroundRetentionSweeper(d.G())
+	d.retentionSweeper.Start(context.Background(), uid.ToBytes())
+}
+
 func (d *Service) createChatSources() {
 	tlf := newTlfHandler(nil, d.G())
 	ri := d.chatRemoteClient
@@ -459,6 +497,8 @@ func (d *Service) OnLogin() error {
 	uid := d.G().Env.GetUID()
 	if !uid.IsNil() {
 		d.G().MessageDeliverer.Start(context.Background(), d.G().Env.GetUID().ToBytes())
+		d.createAndStartEphemeralPurger()
+		d.createAndStartRetentionSweeper()
 		d.runBackgroundIdentifierWithUID(uid)
 	}
 	return nil
@@ -481,6 +521,16 @@ func (d *Service) OnLogout() (err error) {
 		d.messageDeliverer.Stop(context.Background())
 	}
 
+	log("shutting down ephemeral purger")
+	if d.ephemeralPurger != nil {
+		d.ephemeralPurger.Stop(context.Background())
+	}
+
+	log("shutting down retention sweeper")
+	if d.retentionSweeper != nil {
+		d.retentionSweeper.Stop(context.Background())
+	}
+
 	log("shutting down rekeyMaster")
 	d.rekeyMaster.Logout()
 
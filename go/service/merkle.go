@@ -0,0 +1,40 @@
+// Copyright 2016 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package service
+
+import (
+	"github.com/keybase/client/go/libkb"
+	keybase1 "github.com/keybase/client/go/protocol/keybase1"
+	"github.com/keybase/go-framed-msgpack-rpc/rpc"
+	"golang.org/x/net/context"
+)
+
+type MerkleHandler struct {
+	*BaseHandler
+	libkb.Contextified
+}
+
+func NewMerkleHandler(xp rpc.Transporter, g *libkb.GlobalContext) *MerkleHandler {
+	return &MerkleHandler{
+		BaseHandler:  NewBaseHandler(xp),
+		Contextified: libkb.NewContextified(g),
+	}
+}
+
+// Audit re-verifies the skip-pointer chain between the merkle roots this
+// client already has cached locally in the given seqno range. It backs
+// `keybase merkle audit`.
+func (h *MerkleHandler) Audit(ctx context.Context, arg keybase1.AuditArg) (res keybase1.MerkleAuditResult, err error) {
+	defer h.G().CTrace(ctx, "MerkleHandler#Audit", func() error { return err })()
+
+	result, err := h.G().GetMerkleClient().AuditRange(ctx, libkb.Seqno(arg.Low), libkb.Seqno(arg.High))
+	if err != nil {
+		return res, err
+	}
+
+	res.Ok = result.OK()
+	res.RootsAudited = result.RootsAudited
+	res.Problems = result.Problems
+	return res, nil
+}
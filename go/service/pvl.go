@@ -0,0 +1,120 @@
+// Copyright 2016 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package service
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/keybase/client/go/libkb"
+	"github.com/keybase/client/go/logger"
+	keybase1 "github.com/keybase/client/go/protocol/keybase1"
+	"github.com/keybase/go-framed-msgpack-rpc/rpc"
+	"golang.org/x/net/context"
+)
+
+type PvlHandler struct {
+	*BaseHandler
+	libkb.Contextified
+}
+
+func NewPvlHandler(xp rpc.Transporter, g *libkb.GlobalContext) *PvlHandler {
+	return &PvlHandler{
+		BaseHandler:  NewBaseHandler(xp),
+		Contextified: libkb.NewContextified(g),
+	}
+}
+
+// pvlKitFileMu serializes concurrent PvlTest calls, since overriding the
+// local kit file is done through the process-wide KEYBASE_PVL_KIT_FILE
+// environment variable that PvlSource already reads.
+var pvlKitFileMu sync.Mutex
+
+// pvlStepCollector is a logger.ExternalHandler that captures the "PVL ..."
+// debug trace emitted by the interpreter (see pvl/debug.go) into a slice of
+// lines, instead of sending it to the usual service log.
+type pvlStepCollector struct {
+	steps []string
+}
+
+func (c *pvlStepCollector) Log(level keybase1.LogLevel, format string, args []interface{}) {
+	c.steps = append(c.steps, fmt.Sprintf(format, args...))
+}
+
+// PvlTest runs the PVL interpreter against a user's live proof for a
+// service in verbose mode, returning the interpreter's step-by-step trace.
+// It backs `keybase pvl test`, a debugging aid for developing new proof
+// integrations.
+func (h *PvlHandler) PvlTest(ctx context.Context, arg keybase1.PvlTestArg) (res keybase1.PvlTestResult, err error) {
+	defer h.G().CTrace(ctx, "PvlHandler#PvlTest", func() error { return err })()
+
+	if arg.KitFilename != "" {
+		pvlKitFileMu.Lock()
+		defer pvlKitFileMu.Unlock()
+		prev := os.Getenv("KEYBASE_PVL_KIT_FILE")
+		if err := os.Setenv("KEYBASE_PVL_KIT_FILE", arg.KitFilename); err != nil {
+			return res, err
+		}
+		defer os.Setenv("KEYBASE_PVL_KIT_FILE", prev)
+	}
+
+	serviceType := h.G().Services.GetServiceType(arg.Service)
+	if serviceType == nil {
+		return res, fmt.Errorf("unknown proof service: %s", arg.Service)
+	}
+	proofType, ok := libkb.RemoteServiceTypes[arg.Service]
+	if !ok {
+		return res, fmt.Errorf("no remote proof type for service: %s", arg.Service)
+	}
+
+	user, err := libkb.LoadUser(libkb.NewLoadUserByNameArg(h.G(), arg.Username))
+	if err != nil {
+		return res, err
+	}
+
+	links := user.IDTable().GetActiveProofsFor(serviceType)
+	var link libkb.RemoteProofChainLink
+	for _, l := range links {
+		if l.GetProofType() == proofType {
+			link = l
+			break
+		}
+	}
+	if link == nil {
+		return res, fmt.Errorf("no active %s proof found for %s", arg.Service, arg.Username)
+	}
+
+	hints, err := libkb.LoadAndRefreshSigHints(ctx, user.GetUID(), h.G())
+	if err != nil {
+		return res, err
+	}
+	hint := hints.Lookup(link.GetSigID())
+	if hint == nil {
+		return res, fmt.Errorf("no sig hint found for proof %s", link.GetSigID())
+	}
+
+	pc, err := libkb.MakeProofChecker(h.G().Services, link)
+	if err != nil {
+		return res, err
+	}
+
+	collector := &pvlStepCollector{}
+	verboseLog := logger.New("pvl-test")
+	verboseLog.Configure("plain", true /* debug */, "")
+	verboseLog.SetExternalHandler(collector)
+	pvlCtx := h.G().CloneWithNetContext(ctx)
+	pvlCtx.Log = verboseLog
+
+	perr := pc.CheckStatus(pvlCtx, *hint, libkb.ProofCheckerModeActive)
+
+	res.Steps = collector.steps
+	if perr != nil {
+		res.Success = false
+		res.ErrorMessage = perr.GetDesc()
+	} else {
+		res.Success = true
+	}
+	return res, nil
+}
@@ -51,9 +51,21 @@ type reachability struct {
 }
 
 func newReachability(g *libkb.GlobalContext) *reachability {
-	return &reachability{
+	r := &reachability{
 		Contextified: libkb.NewContextified(g),
 	}
+	g.ConnectivityMonitor = r
+	return r
+}
+
+// ForceCheck runs an immediate reachability check, bypassing the periodic
+// poll interval in start's loop. This is what OS-level network-change
+// callbacks (see startRouteChangeMonitor and the mobile bind layer's
+// NetworkChanged) call so gregor/chat/the API client find out about a
+// network change immediately instead of up to reachabilityPollInterval
+// later.
+func (h *reachability) ForceCheck() {
+	h.check()
 }
 
 func (h *reachability) setReachability(r keybase1.Reachability) {
@@ -63,10 +75,33 @@ func (h *reachability) setReachability(r keybase1.Reachability) {
 	if h.lastReachability.Reachable != r.Reachable {
 		h.G().Log.Debug("Reachability changed: %#v", r)
 		h.G().NotifyRouter.HandleReachability(r)
+		if r.Reachable == keybase1.Reachable_YES {
+			go h.G().DeferredAPIQueue.Flush(h.postDeferred)
+		}
 	}
 	h.lastReachability = r
 }
 
+// postDeferred re-issues a request that DeferredAPIQueue.Enqueue queued
+// earlier, using the same InternalAPIEngine every other internal API
+// call goes through. HTTPArgs is single-valued per key, so only the
+// first value of each queued key survives the round trip.
+func (h *reachability) postDeferred(endpoint string, args url.Values) error {
+	httpArgs := libkb.NewHTTPArgs()
+	for k, vs := range args {
+		if len(vs) > 0 {
+			httpArgs.Add(k, libkb.S{Val: vs[0]})
+		}
+	}
+	_, err := h.G().API.Post(libkb.APIArg{Endpoint: endpoint, Args: httpArgs})
+	return err
+}
+
+// reachabilityPollInterval bounds how long a network change can go
+// unnoticed when there's no OS-level route-change signal to rely on (see
+// startRouteChangeMonitor).
+const reachabilityPollInterval = 30 * time.Second
+
 func (h *reachability) start() keybase1.Reachability {
 	h.startMutex.Lock()
 	defer h.startMutex.Unlock()
@@ -78,11 +113,12 @@ func (h *reachability) start() keybase1.Reachability {
 			h.check()
 			for {
 				select {
-				case <-h.G().Clock().After(time.Second * 30):
+				case <-h.G().Clock().After(reachabilityPollInterval):
 					h.check()
 				}
 			}
 		}()
+		startRouteChangeMonitor(h.G(), h.ForceCheck)
 	}
 	return h.lastReachability
 }
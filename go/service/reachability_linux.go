@@ -0,0 +1,57 @@
+// Copyright 2018 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package service
+
+import (
+	"syscall"
+
+	"github.com/keybase/client/go/libkb"
+)
+
+// startRouteChangeMonitor opens a NETLINK_ROUTE socket subscribed to link
+// and address change multicast groups and calls onChange whenever the
+// kernel reports one, so a network change (Wi-Fi to cellular, VPN up/down,
+// etc.) triggers an immediate reachability recheck instead of waiting for
+// reachabilityPollInterval. It doesn't bother parsing the netlink message
+// bodies -- any message on these groups means "something about routing
+// changed", which is all onChange needs to know before it does its own
+// check.
+func startRouteChangeMonitor(g *libkb.GlobalContext, onChange func()) {
+	go func() {
+		if err := watchRouteChanges(onChange); err != nil {
+			g.Log.Debug("reachability: route change monitor not available: %s", err)
+		}
+	}()
+}
+
+func watchRouteChanges(onChange func()) error {
+	const rtmgrpLink = 0x1
+	const rtmgrpIPv4Ifaddr = 0x10
+	const rtmgrpIPv6Ifaddr = 0x100
+
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_RAW, syscall.NETLINK_ROUTE)
+	if err != nil {
+		return err
+	}
+	defer syscall.Close(fd)
+
+	addr := &syscall.SockaddrNetlink{
+		Family: syscall.AF_NETLINK,
+		Groups: rtmgrpLink | rtmgrpIPv4Ifaddr | rtmgrpIPv6Ifaddr,
+	}
+	if err := syscall.Bind(fd, addr); err != nil {
+		return err
+	}
+
+	buf := make([]byte, 4096)
+	for {
+		n, _, err := syscall.Recvfrom(fd, buf, 0)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			onChange()
+		}
+	}
+}
@@ -0,0 +1,15 @@
+// Copyright 2018 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+// +build !linux
+
+package service
+
+import "github.com/keybase/client/go/libkb"
+
+// startRouteChangeMonitor is a no-op on platforms without a route-change
+// socket to watch (or where the OS instead pushes network changes to us
+// via the mobile bind layer's NetworkChanged -- see reachability.go's
+// ConnectivityMonitor). reachability still falls back to
+// reachabilityPollInterval on these platforms.
+func startRouteChangeMonitor(g *libkb.GlobalContext, onChange func()) {}
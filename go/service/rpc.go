@@ -1,10 +1,14 @@
 package service
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+
 	"github.com/keybase/client/go/libkb"
 	"github.com/keybase/go-framed-msgpack-rpc/rpc"
 	"golang.org/x/net/context"
-	"net"
 )
 
 // connTransport implements rpc.ConnectionTransport
@@ -26,8 +30,15 @@ func newConnTransport(g *libkb.GlobalContext, host string) *connTransport {
 }
 
 func (t *connTransport) Dial(context.Context) (rpc.Transporter, error) {
+	dial := net.Dial
+	if dialer, err := t.G().Env.GetProxyPolicy("gregor").Dialer(); err != nil {
+		return nil, err
+	} else if dialer != nil {
+		dial = dialer
+	}
+
 	var err error
-	t.conn, err = net.Dial("tcp", t.host)
+	t.conn, err = dial("tcp", t.host)
 	if err != nil {
 		return nil, err
 	}
@@ -51,3 +62,87 @@ func (t *connTransport) Close() {
 func (t *connTransport) Reset() {
 	t.transport = nil
 }
+
+// connTransportTLS implements rpc.ConnectionTransport like
+// rpc.ConnectionTransportTLS (see vendor/.../connection.go), but dials
+// through the "gregor" ProxyPolicy first when one is configured. Vendored
+// ConnectionTransportTLS hardcodes tls.DialWithDialer with no dialer hook,
+// so this exists as a separate transport rather than a patch to it; used by
+// gregorHandler.connectTLS only when GetProxyPolicy("gregor") is enabled,
+// leaving the vendored path as the default otherwise.
+type connTransportTLS struct {
+	libkb.Contextified
+	host            string
+	rawCA           []byte
+	conn            net.Conn
+	transport       rpc.Transporter
+	stagedTransport rpc.Transporter
+}
+
+var _ rpc.ConnectionTransport = (*connTransportTLS)(nil)
+
+func newConnTransportTLS(g *libkb.GlobalContext, host string, rawCA []byte) *connTransportTLS {
+	return &connTransportTLS{
+		Contextified: libkb.NewContextified(g),
+		host:         host,
+		rawCA:        rawCA,
+	}
+}
+
+func (t *connTransportTLS) Dial(context.Context) (rpc.Transporter, error) {
+	dial := net.Dial
+	if dialer, err := t.G().Env.GetProxyPolicy("gregor").Dialer(); err != nil {
+		return nil, err
+	} else if dialer != nil {
+		dial = dialer
+	}
+
+	rawConn, err := dial("tcp", t.host)
+	if err != nil {
+		return nil, err
+	}
+
+	serverName := t.host
+	if host, _, err := net.SplitHostPort(t.host); err == nil {
+		serverName = host
+	}
+
+	config := &tls.Config{
+		ServerName: serverName,
+	}
+	if len(t.rawCA) > 0 {
+		certs := x509.NewCertPool()
+		if !certs.AppendCertsFromPEM(t.rawCA) {
+			rawConn.Close()
+			return nil, errors.New("connTransportTLS: unable to load root certificates")
+		}
+		config.RootCAs = certs
+	}
+
+	tlsConn := tls.Client(rawConn, config)
+	if err := tlsConn.Handshake(); err != nil {
+		rawConn.Close()
+		return nil, err
+	}
+
+	t.conn = tlsConn
+	t.stagedTransport = rpc.NewTransport(t.conn, libkb.NewRPCLogFactory(t.G()), libkb.WrapError)
+	return t.stagedTransport, nil
+}
+
+func (t *connTransportTLS) IsConnected() bool {
+	return t.transport != nil && t.transport.IsConnected()
+}
+
+func (t *connTransportTLS) Finalize() {
+	t.transport = t.stagedTransport
+	t.stagedTransport = nil
+}
+
+func (t *connTransportTLS) Close() {
+	t.conn.Close()
+}
+
+func (t *connTransportTLS) Reset() {
+	t.transport = nil
+}
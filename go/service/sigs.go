@@ -41,6 +41,19 @@ func (h *SigsHandler) SigListJSON(_ context.Context, arg keybase1.SigListJSONArg
 	return eng.JSON()
 }
 
+func (h *SigsHandler) SigChainSelfCheck(ctx context.Context, arg keybase1.SigChainSelfCheckArg) (keybase1.SigChainSelfCheckResult, error) {
+	eng := engine.NewSigChainSelfCheck(h.G(), arg.Username)
+	if err := engine.RunEngine(eng, &engine.Context{NetContext: ctx}); err != nil {
+		return keybase1.SigChainSelfCheckResult{}, err
+	}
+	res := eng.Result()
+	return keybase1.SigChainSelfCheckResult{
+		Consistent: res.Consistent,
+		NumLinks:   res.NumLinks,
+		Message:    res.Message,
+	}, nil
+}
+
 func (h *SigsHandler) run(args keybase1.SigListArgs) (*engine.SigsList, error) {
 	ctx := &engine.Context{}
 
@@ -0,0 +1,785 @@
+// Copyright 2018 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/keybase/client/go/libkb"
+	keybase1 "github.com/keybase/client/go/protocol/keybase1"
+	"github.com/keybase/go-framed-msgpack-rpc/rpc"
+)
+
+// teamHandler implements keybase1.TeamInterface.
+//
+// SCOPING NOTE: this client has no team sigchain. There's no
+// server-signed chain of membership-change links to verify, the way a
+// real Keybase team is -- that would require server-side team support
+// this codebase doesn't have. Instead, a "team" here *is* an implicit
+// TLF (see libkb.ParseImplicitTeamTlfName and
+// tlfHandler.ResolveImplicitTeam): its ID is the underlying TLF's stable
+// keybase1.TLFID (constant across renames and rekeys), and membership
+// changes are made the same way private-folder membership always has
+// been in this codebase -- by renaming the TLF to move a participant
+// into or out of the writer/reader lists and letting KBFS's existing
+// rekey machinery pick it up. That's a real rekey, not a stub, but it's
+// also the only "verification" involved: a name resolving at all
+// already goes through the usual identify pipeline, and there's no
+// separate team-chain signature on top of that.
+//
+// TeamRole distinguishes ADMIN and OWNER from WRITER, which KBFS itself
+// doesn't -- KBFS only knows writer vs. reader. Those finer roles are
+// purely local metadata cached in LocalDb under libkb.DBTeamMembers, not
+// synced to KBFS or any other client, and carry no cryptographic weight.
+//
+// Key rotation on membership change is handled entirely by the rename
+// in applyMembershipEdit below: KBFS already treats a TLF name change as
+// a rekey trigger, generating fresh key material and reboxing it for the
+// resulting writer/reader set as part of that existing, real rekey path
+// -- the same one go/service/rekey_master.go drives for every other TLF,
+// via the TLFRekeyGregorCategory gregor category the server sends
+// whenever a TLF has a stale key generation. There's no separate "team
+// key" to rotate or rotation link to post here -- those are team
+// sigchain concepts, and there is no team sigchain in this client.
+//
+// A membership edit is a read-modify-write against the shared TLF name
+// with no server-side lock, so two admins racing to edit the same team
+// can clobber each other; applyMembershipEdit retries a losing edit
+// against the winner's freshly resolved name rather than silently
+// dropping it.
+//
+// Dotted team names (see CreateSubteam/RenameSubteam/LookupTeamByName)
+// are likewise a purely local convenience: there's no server-side team
+// name registry to check a name against, so the parent-chain registry
+// cached under libkb.DBTeamNames is just this device's own record of
+// which TLF it decided "acme.eng.frontend" refers to. It isn't
+// synchronized with any other device.
+//
+// Invite tokens (CreateTeamInvite/AcceptTeamInvite) are "signed" in a
+// narrower sense than a real Keybase invite link: each token is signed
+// with a fresh, single-use NaCl keypair generated just for that token,
+// whose private half is discarded immediately afterward (see
+// libkb.GenerateNaclSigningKeyPair/SignToString). That's real Ed25519
+// signing, and it does what a signature can honestly do here -- prove
+// the token's contents (team, role, expiry) weren't altered after
+// CreateTeamInvite produced them -- but it does NOT bind the token to
+// the inviting admin's own identity key, since there's no
+// team-sigchain-registered admin key to sign with. The actual
+// authorization check happens once, synchronously, inside
+// CreateTeamInvite (requireAdminOf); the LocalDb record it writes under
+// libkb.DBTeamInvites, not the token itself, is the source of truth
+// AcceptTeamInvite consults for revocation and expiry.
+//
+// AdminChannelTlfName follows the same "team is a TLF" logic one step
+// further: an admin-only channel is just another implicit TLF, this one
+// scoped to the ADMIN/OWNER subset of teamID's writers instead of the
+// full membership. That reuses KBFS's real per-TLF key generation for
+// both the confidentiality guarantee (only that TLF's writers ever get
+// key material for it) and the inbox-visibility guarantee (a
+// conversation only shows up for TLFs you're a participant of), rather
+// than inventing a new per-message, per-recipient boxing rule inside a
+// single shared conversation.
+type teamHandler struct {
+	*BaseHandler
+	libkb.Contextified
+	tlf *tlfHandler
+}
+
+func NewTeamHandler(xp rpc.Transporter, g *libkb.GlobalContext) *teamHandler {
+	return &teamHandler{
+		BaseHandler:  NewBaseHandler(xp),
+		Contextified: libkb.NewContextified(g),
+		tlf:          newTlfHandler(nil, g),
+	}
+}
+
+// teamRoleOverrideDbKey namespaces the LocalDb entry holding teamID's
+// cached ADMIN/OWNER overrides (see roleOverrides).
+func teamRoleOverrideDbKey(teamID keybase1.TeamID) libkb.DbKey {
+	return libkb.DbKey{Typ: libkb.DBTeamMembers, Key: string(teamID)}
+}
+
+// roleOverrides returns the local role cache for teamID, keyed by
+// username, for members whose role is finer-grained than KBFS's plain
+// writer/reader distinction can express (i.e. ADMIN or OWNER). A member
+// with no entry here is a plain WRITER or READER, per their KBFS
+// membership.
+func (h *teamHandler) roleOverrides(teamID keybase1.TeamID) (map[string]keybase1.TeamRole, error) {
+	var overrides map[string]keybase1.TeamRole
+	found, err := h.G().LocalDb.GetInto(&overrides, teamRoleOverrideDbKey(teamID))
+	if err != nil || !found {
+		return nil, err
+	}
+	return overrides, nil
+}
+
+// setRoleOverride records role as username's cached role in teamID,
+// clearing any cached entry instead if role is the KBFS-default WRITER
+// (there's nothing to override).
+func (h *teamHandler) setRoleOverride(teamID keybase1.TeamID, username string, role keybase1.TeamRole) error {
+	overrides, err := h.roleOverrides(teamID)
+	if err != nil {
+		return err
+	}
+	if role != keybase1.TeamRole_ADMIN && role != keybase1.TeamRole_OWNER {
+		if overrides == nil {
+			return nil
+		}
+		delete(overrides, username)
+		return h.G().LocalDb.PutObj(teamRoleOverrideDbKey(teamID), nil, overrides)
+	}
+	if overrides == nil {
+		overrides = make(map[string]keybase1.TeamRole)
+	}
+	overrides[username] = role
+	return h.G().LocalDb.PutObj(teamRoleOverrideDbKey(teamID), nil, overrides)
+}
+
+// clearRoleOverride removes any cached role override for username in
+// teamID, e.g. after they've been removed from the team entirely.
+func (h *teamHandler) clearRoleOverride(teamID keybase1.TeamID, username string) error {
+	overrides, err := h.roleOverrides(teamID)
+	if err != nil || len(overrides) == 0 {
+		return err
+	}
+	if _, ok := overrides[username]; !ok {
+		return nil
+	}
+	delete(overrides, username)
+	return h.G().LocalDb.PutObj(teamRoleOverrideDbKey(teamID), nil, overrides)
+}
+
+// currentName resolves teamID's current implicit team name by its
+// stable TLF ID, rather than trusting a previously cached name, so a
+// rename made from another device is always picked up before this
+// handler makes its own membership edit on top of it.
+func (h *teamHandler) currentName(ctx context.Context, teamID keybase1.TeamID) (libkb.ImplicitTeamName, error) {
+	res, err := h.tlf.CryptKeysByID(ctx, keybase1.TLFQueryWithID{TlfID: keybase1.TLFID(teamID)})
+	if err != nil {
+		return libkb.ImplicitTeamName{}, err
+	}
+	return libkb.ParseImplicitTeamTlfName(string(res.NameIDBreaks.CanonicalName), false), nil
+}
+
+// rename resolves newName, triggering a KBFS rekey if it isn't already
+// the TLF's current name, and confirms the rekey landed on the same TLF
+// ID. teamID is supposed to be stable across membership changes, so a
+// mismatch here means the edit is about to be recorded against the
+// wrong folder -- surfacing that as an error beats silently continuing.
+func (h *teamHandler) rename(ctx context.Context, teamID keybase1.TeamID, newName libkb.ImplicitTeamName) error {
+	resolvedID, _, err := h.tlf.ResolveImplicitTeam(ctx, newName.String(), newName.Public)
+	if err != nil {
+		return err
+	}
+	if resolvedID != keybase1.TLFID(teamID) {
+		return fmt.Errorf("team %s: membership change unexpectedly resolved to a different TLF (%s)", teamID, resolvedID)
+	}
+	return nil
+}
+
+// teamMembershipMaxAttempts bounds how many times applyMembershipEdit
+// re-reads and retries a membership edit that lost a race against
+// another admin's concurrent edit of the same team.
+const teamMembershipMaxAttempts = 3
+
+// applyMembershipEdit resolves teamID's current name, applies edit to
+// produce the new name, and renames the team to it. If edit itself
+// reports an error (e.g. the requested change doesn't make sense against
+// the current membership), that error is returned immediately with no
+// retry. If the rename fails -- most likely because another admin's own
+// edit landed between our read and our rename -- it retries against a
+// freshly re-resolved name, up to teamMembershipMaxAttempts times, so a
+// losing edit gets reapplied on top of the winner's change instead of
+// silently dropped.
+func (h *teamHandler) applyMembershipEdit(ctx context.Context, teamID keybase1.TeamID, edit func(*libkb.ImplicitTeamName) error) (err error) {
+	for attempt := 0; attempt < teamMembershipMaxAttempts; attempt++ {
+		var name libkb.ImplicitTeamName
+		name, err = h.currentName(ctx, teamID)
+		if err != nil {
+			return err
+		}
+		if err = edit(&name); err != nil {
+			return err
+		}
+		if err = h.rename(ctx, teamID, name); err == nil {
+			return nil
+		}
+		h.G().Log.CDebugf(ctx, "teamHandler: retrying membership edit on team %s after conflict (attempt %d): %s", teamID, attempt+1, err)
+	}
+	return err
+}
+
+func (h *teamHandler) AddTeamMember(ctx context.Context, arg keybase1.AddTeamMemberArg) error {
+	err := h.applyMembershipEdit(ctx, arg.TeamID, func(name *libkb.ImplicitTeamName) error {
+		if name.HasMember(arg.Username) {
+			return fmt.Errorf("%s is already a member of this team", arg.Username)
+		}
+		if arg.Role == keybase1.TeamRole_READER {
+			name.Readers = append(name.Readers, arg.Username)
+		} else {
+			name.Writers = append(name.Writers, arg.Username)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return h.setRoleOverride(arg.TeamID, arg.Username, arg.Role)
+}
+
+func (h *teamHandler) RemoveTeamMember(ctx context.Context, arg keybase1.RemoveTeamMemberArg) error {
+	err := h.applyMembershipEdit(ctx, arg.TeamID, func(name *libkb.ImplicitTeamName) error {
+		if !name.RemoveMember(arg.Username) {
+			return fmt.Errorf("%s is not a member of this team", arg.Username)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return h.clearRoleOverride(arg.TeamID, arg.Username)
+}
+
+// EditTeamMemberRole moves arg.Username between the writer and reader
+// buckets (triggering the same rekey-on-rename as AddTeamMember/
+// RemoveTeamMember) only if the change actually crosses that boundary.
+// ADMIN, OWNER, and WRITER are all writers as far as KBFS is concerned,
+// so moving between those three is purely a local role-override update.
+func (h *teamHandler) EditTeamMemberRole(ctx context.Context, arg keybase1.EditTeamMemberRoleArg) error {
+	nowReader := arg.Role == keybase1.TeamRole_READER
+	err := h.applyMembershipEdit(ctx, arg.TeamID, func(name *libkb.ImplicitTeamName) error {
+		wasReader := name.IsReader(arg.Username)
+		if !wasReader && !name.IsWriter(arg.Username) {
+			return fmt.Errorf("%s is not a member of this team", arg.Username)
+		}
+		if wasReader != nowReader {
+			name.RemoveMember(arg.Username)
+			if nowReader {
+				name.Readers = append(name.Readers, arg.Username)
+			} else {
+				name.Writers = append(name.Writers, arg.Username)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return h.setRoleOverride(arg.TeamID, arg.Username, arg.Role)
+}
+
+func (h *teamHandler) ListTeamMembers(ctx context.Context, arg keybase1.ListTeamMembersArg) ([]keybase1.TeamMember, error) {
+	name, err := h.currentName(ctx, arg.TeamID)
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := h.roleOverrides(arg.TeamID)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]keybase1.TeamMember, 0, len(name.Writers)+len(name.Readers))
+	for _, username := range name.Readers {
+		members = append(members, keybase1.TeamMember{Username: username, Role: keybase1.TeamRole_READER})
+	}
+	for _, username := range name.Writers {
+		role := keybase1.TeamRole_WRITER
+		if override, ok := overrides[username]; ok {
+			role = override
+		}
+		members = append(members, keybase1.TeamMember{Username: username, Role: role})
+	}
+	return members, nil
+}
+
+// teamNameEntry is the local registry record for one level of a dotted
+// team name. Children is kept on the parent (rather than discovered by
+// scanning the registry, which libkb.LocalDbOps has no way to do) so
+// RenameSubteam can cheaply refuse to move a team that still has
+// subteams of its own.
+type teamNameEntry struct {
+	TeamID     keybase1.TeamID `json:"team_id"`
+	ParentName string          `json:"parent_name"`
+	Children   []string        `json:"children,omitempty"`
+}
+
+func teamNameDbKey(fullName string) libkb.DbKey {
+	return libkb.DbKey{Typ: libkb.DBTeamNames, Key: fullName}
+}
+
+func (h *teamHandler) teamNameEntry(fullName string) (entry teamNameEntry, found bool, err error) {
+	found, err = h.G().LocalDb.GetInto(&entry, teamNameDbKey(fullName))
+	return entry, found, err
+}
+
+func (h *teamHandler) putTeamNameEntry(fullName string, entry teamNameEntry) error {
+	return h.G().LocalDb.PutObj(teamNameDbKey(fullName), nil, entry)
+}
+
+// addChild records childFullName as one of parentFullName's children,
+// so a later rename of parentFullName's subteam can be refused. A
+// missing parent entry is a caller bug (the parent was already required
+// to exist), so it's reported as an error rather than silently ignored.
+func (h *teamHandler) addChild(parentFullName, childFullName string) error {
+	parent, found, err := h.teamNameEntry(parentFullName)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("unknown parent team %q", parentFullName)
+	}
+	parent.Children = append(parent.Children, childFullName)
+	return h.putTeamNameEntry(parentFullName, parent)
+}
+
+// removeChild removes childFullName from parentFullName's recorded
+// children. A missing parent entry is tolerated (e.g. the parent was
+// itself deleted out from under this call), since there's nothing left
+// to fix up in that case.
+func (h *teamHandler) removeChild(parentFullName, childFullName string) error {
+	parent, found, err := h.teamNameEntry(parentFullName)
+	if err != nil || !found {
+		return err
+	}
+	for i, c := range parent.Children {
+		if c == childFullName {
+			parent.Children = append(parent.Children[:i], parent.Children[i+1:]...)
+			break
+		}
+	}
+	return h.putTeamNameEntry(parentFullName, parent)
+}
+
+// requireAdminOf checks that the calling user is recorded as an ADMIN or
+// OWNER of teamID before letting a subteam operation proceed. This is
+// enforced only here, client-side, against the local role-override
+// cache -- there's no team sigchain to check it against server-side, so
+// it's advisory, not a security boundary: nothing stops another admin
+// (or another one of the same user's devices) from making the
+// equivalent KBFS-level change directly, without ever consulting this
+// check.
+func (h *teamHandler) requireAdminOf(teamID keybase1.TeamID) error {
+	username := h.G().Env.GetUsername()
+	if len(username) == 0 {
+		return libkb.LoginRequiredError{}
+	}
+	overrides, err := h.roleOverrides(teamID)
+	if err != nil {
+		return err
+	}
+	switch overrides[string(username)] {
+	case keybase1.TeamRole_ADMIN, keybase1.TeamRole_OWNER:
+		return nil
+	default:
+		return fmt.Errorf("%s is not an admin of this team", username)
+	}
+}
+
+// resolveTeamName resolves a dotted team name to a TeamID by walking
+// the parent chain from the root down, one level at a time, checking at
+// each level both that the level is registered and that its recorded
+// parent actually matches where the name says it should be. That's
+// slower than a single lookup keyed by the full name, but it means a
+// registry inconsistency (e.g. a leaf whose ParentName is stale because
+// an ancestor got renamed without every descendant being walked and
+// fixed up) is reported as an error instead of silently resolving to
+// the wrong team.
+func (h *teamHandler) resolveTeamName(teamName string) (keybase1.TeamID, error) {
+	if len(teamName) == 0 {
+		return "", fmt.Errorf("empty team name")
+	}
+	segments := strings.Split(teamName, ".")
+	var parent string
+	for i, seg := range segments {
+		full := seg
+		if parent != "" {
+			full = parent + "." + seg
+		}
+		entry, found, err := h.teamNameEntry(full)
+		if err != nil {
+			return "", err
+		}
+		if !found {
+			return "", fmt.Errorf("unknown team %q", full)
+		}
+		if entry.ParentName != parent {
+			return "", fmt.Errorf("team registry inconsistency: %q has recorded parent %q, expected %q", full, entry.ParentName, parent)
+		}
+		if i == len(segments)-1 {
+			return entry.TeamID, nil
+		}
+		parent = full
+	}
+	return "", fmt.Errorf("invalid team name %q", teamName)
+}
+
+// CreateSubteam creates a brand new team (a fresh implicit TLF, owned
+// solely by the caller to start) and registers it as
+// "arg.ParentName.arg.SubteamName". arg.ParentName must already be a
+// registered team, and the caller must be one of its admins.
+func (h *teamHandler) CreateSubteam(ctx context.Context, arg keybase1.CreateSubteamArg) (keybase1.TeamID, error) {
+	parentName := string(arg.ParentName)
+	parentTeamID, err := h.resolveTeamName(parentName)
+	if err != nil {
+		return "", err
+	}
+	if err := h.requireAdminOf(parentTeamID); err != nil {
+		return "", err
+	}
+
+	fullName := parentName + "." + arg.SubteamName
+	if _, found, err := h.teamNameEntry(fullName); err != nil {
+		return "", err
+	} else if found {
+		return "", fmt.Errorf("a team named %q already exists", fullName)
+	}
+
+	username := h.G().Env.GetUsername()
+	if len(username) == 0 {
+		return "", libkb.LoginRequiredError{}
+	}
+	newName := libkb.ImplicitTeamName{Writers: []string{string(username)}}
+	teamID, _, err := h.tlf.ResolveImplicitTeam(ctx, newName.String(), newName.Public)
+	if err != nil {
+		return "", err
+	}
+	newTeamID := keybase1.TeamID(teamID)
+
+	if err := h.setRoleOverride(newTeamID, string(username), keybase1.TeamRole_OWNER); err != nil {
+		return "", err
+	}
+	if err := h.putTeamNameEntry(fullName, teamNameEntry{TeamID: newTeamID, ParentName: parentName}); err != nil {
+		return "", err
+	}
+	if err := h.addChild(parentName, fullName); err != nil {
+		return "", err
+	}
+	return newTeamID, nil
+}
+
+// RenameSubteam moves or renames the subteam registered at
+// arg.CurrentName to "arg.NewParentName.arg.NewSubteamName". The
+// subteam must have no children of its own (they'd otherwise be left
+// pointing at a parent name that no longer exists), and the caller must
+// be an admin of the subteam, and of the new parent too if it's
+// actually changing.
+func (h *teamHandler) RenameSubteam(ctx context.Context, arg keybase1.RenameSubteamArg) error {
+	currentName := string(arg.CurrentName)
+	newParentName := string(arg.NewParentName)
+
+	entry, found, err := h.teamNameEntry(currentName)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("unknown team %q", currentName)
+	}
+	if len(entry.Children) > 0 {
+		return fmt.Errorf("cannot rename %q: it has subteams of its own", currentName)
+	}
+	if err := h.requireAdminOf(entry.TeamID); err != nil {
+		return err
+	}
+
+	if _, found, err := h.teamNameEntry(newParentName); err != nil {
+		return err
+	} else if !found {
+		return fmt.Errorf("unknown parent team %q", newParentName)
+	}
+
+	newFullName := newParentName + "." + arg.NewSubteamName
+	if newFullName != currentName {
+		if _, found, err := h.teamNameEntry(newFullName); err != nil {
+			return err
+		} else if found {
+			return fmt.Errorf("a team named %q already exists", newFullName)
+		}
+	}
+
+	if newParentName != entry.ParentName {
+		newParentTeamID, err := h.resolveTeamName(newParentName)
+		if err != nil {
+			return err
+		}
+		if err := h.requireAdminOf(newParentTeamID); err != nil {
+			return err
+		}
+	}
+
+	if err := h.removeChild(entry.ParentName, currentName); err != nil {
+		return err
+	}
+	if err := h.G().LocalDb.Delete(teamNameDbKey(currentName)); err != nil {
+		return err
+	}
+	if err := h.putTeamNameEntry(newFullName, teamNameEntry{TeamID: entry.TeamID, ParentName: newParentName}); err != nil {
+		return err
+	}
+	return h.addChild(newParentName, newFullName)
+}
+
+// LookupTeamByName resolves a dotted team name to its TeamID.
+func (h *teamHandler) LookupTeamByName(ctx context.Context, arg keybase1.LookupTeamByNameArg) (keybase1.TeamID, error) {
+	return h.resolveTeamName(string(arg.TeamName))
+}
+
+// teamInvitePayload is the JSON body signed inside an invite token. It's
+// self-contained: everything AcceptTeamInvite needs to know about the
+// invite (other than its current revoked/expired status, which lives in
+// the LocalDb record keyed by TeamID+InviteID) round-trips through the
+// token itself.
+type teamInvitePayload struct {
+	InviteID  string            `json:"invite_id"`
+	TeamID    keybase1.TeamID   `json:"team_id"`
+	Role      keybase1.TeamRole `json:"role"`
+	ExpiresAt int64             `json:"expires_at"`
+}
+
+// teamInviteRecord is the LocalDb-side record of an invite, indexed by
+// TeamID+InviteID. Contact is stored here (and only here, never in the
+// token) since it's the actual destination this invite is meant for,
+// not something AcceptTeamInvite needs to verify.
+type teamInviteRecord struct {
+	InviteID  string            `json:"invite_id"`
+	TeamID    keybase1.TeamID   `json:"team_id"`
+	Role      keybase1.TeamRole `json:"role"`
+	Contact   string            `json:"contact"`
+	ExpiresAt time.Time         `json:"expires_at"`
+	Revoked   bool              `json:"revoked"`
+}
+
+func teamInviteDbKey(teamID keybase1.TeamID, inviteID string) libkb.DbKey {
+	return libkb.DbKey{Typ: libkb.DBTeamInvites, Key: "invite:" + string(teamID) + ":" + inviteID}
+}
+
+func teamInviteListDbKey(teamID keybase1.TeamID) libkb.DbKey {
+	return libkb.DbKey{Typ: libkb.DBTeamInvites, Key: "list:" + string(teamID)}
+}
+
+func (h *teamHandler) teamInviteRecord(teamID keybase1.TeamID, inviteID string) (record teamInviteRecord, found bool, err error) {
+	found, err = h.G().LocalDb.GetInto(&record, teamInviteDbKey(teamID, inviteID))
+	return record, found, err
+}
+
+func (h *teamHandler) putTeamInviteRecord(record teamInviteRecord) error {
+	return h.G().LocalDb.PutObj(teamInviteDbKey(record.TeamID, record.InviteID), nil, record)
+}
+
+// teamInviteIDs returns the IDs of every invite ever created for teamID
+// (including revoked and expired ones), so ListTeamInvites has
+// something to enumerate without libkb.LocalDbOps supporting a scan.
+func (h *teamHandler) teamInviteIDs(teamID keybase1.TeamID) ([]string, error) {
+	var ids []string
+	found, err := h.G().LocalDb.GetInto(&ids, teamInviteListDbKey(teamID))
+	if err != nil || !found {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (h *teamHandler) addTeamInviteID(teamID keybase1.TeamID, inviteID string) error {
+	ids, err := h.teamInviteIDs(teamID)
+	if err != nil {
+		return err
+	}
+	ids = append(ids, inviteID)
+	return h.G().LocalDb.PutObj(teamInviteListDbKey(teamID), nil, ids)
+}
+
+// CreateTeamInvite mints a signed, self-contained invite token for
+// arg.Contact (an email address or phone number this client has no way
+// to actually deliver to -- that's left to the caller) to join teamID
+// with arg.Role, valid for arg.TtlSec seconds. See teamHandler's doc
+// comment for what the signature does and doesn't prove.
+func (h *teamHandler) CreateTeamInvite(ctx context.Context, arg keybase1.CreateTeamInviteArg) (string, error) {
+	if err := h.requireAdminOf(arg.TeamID); err != nil {
+		return "", err
+	}
+	if arg.TtlSec <= 0 {
+		return "", fmt.Errorf("invalid invite ttlSec %d", arg.TtlSec)
+	}
+
+	inviteID, err := libkb.RandString("", 16)
+	if err != nil {
+		return "", err
+	}
+	expiresAt := h.G().Clock().Now().Add(time.Duration(arg.TtlSec) * time.Second)
+
+	payloadBytes, err := json.Marshal(teamInvitePayload{
+		InviteID:  inviteID,
+		TeamID:    arg.TeamID,
+		Role:      arg.Role,
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signingKey, err := libkb.GenerateNaclSigningKeyPair()
+	if err != nil {
+		return "", err
+	}
+	token, _, err := signingKey.SignToString(payloadBytes)
+	if err != nil {
+		return "", err
+	}
+
+	if err := h.putTeamInviteRecord(teamInviteRecord{
+		InviteID:  inviteID,
+		TeamID:    arg.TeamID,
+		Role:      arg.Role,
+		Contact:   arg.Contact,
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return "", err
+	}
+	if err := h.addTeamInviteID(arg.TeamID, inviteID); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// AcceptTeamInvite verifies inviteToken's signature and payload, checks
+// the invite hasn't been revoked or expired, and adds the currently
+// logged-in user to its team with its role. A successful accept marks
+// the invite revoked so the same token can't be replayed to join twice.
+func (h *teamHandler) AcceptTeamInvite(ctx context.Context, arg keybase1.AcceptTeamInviteArg) error {
+	_, payloadBytes, _, err := libkb.NaclVerifyAndExtract(arg.InviteToken)
+	if err != nil {
+		return fmt.Errorf("invalid invite token: %s", err)
+	}
+	var payload teamInvitePayload
+	if err := json.Unmarshal(payloadBytes, &payload); err != nil {
+		return fmt.Errorf("invalid invite token: %s", err)
+	}
+
+	record, found, err := h.teamInviteRecord(payload.TeamID, payload.InviteID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("unknown invite")
+	}
+	if record.Revoked {
+		return fmt.Errorf("this invite has already been used or revoked")
+	}
+	if h.G().Clock().Now().After(record.ExpiresAt) {
+		return fmt.Errorf("this invite has expired")
+	}
+
+	username := h.G().Env.GetUsername()
+	if len(username) == 0 {
+		return libkb.LoginRequiredError{}
+	}
+
+	err = h.applyMembershipEdit(ctx, payload.TeamID, func(name *libkb.ImplicitTeamName) error {
+		if name.HasMember(string(username)) {
+			return fmt.Errorf("%s is already a member of this team", username)
+		}
+		if payload.Role == keybase1.TeamRole_READER {
+			name.Readers = append(name.Readers, string(username))
+		} else {
+			name.Writers = append(name.Writers, string(username))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := h.setRoleOverride(payload.TeamID, string(username), payload.Role); err != nil {
+		return err
+	}
+
+	record.Revoked = true
+	return h.putTeamInviteRecord(record)
+}
+
+func (h *teamHandler) ListTeamInvites(ctx context.Context, arg keybase1.ListTeamInvitesArg) ([]keybase1.TeamInvite, error) {
+	ids, err := h.teamInviteIDs(arg.TeamID)
+	if err != nil {
+		return nil, err
+	}
+	invites := make([]keybase1.TeamInvite, 0, len(ids))
+	for _, id := range ids {
+		record, found, err := h.teamInviteRecord(arg.TeamID, id)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+		invites = append(invites, keybase1.TeamInvite{
+			InviteID:  record.InviteID,
+			Role:      record.Role,
+			Contact:   record.Contact,
+			ExpiresAt: keybase1.ToTime(record.ExpiresAt),
+			Revoked:   record.Revoked,
+		})
+	}
+	return invites, nil
+}
+
+// RevokeTeamInvite marks an outstanding invite as revoked so a future
+// AcceptTeamInvite for it is rejected, even though the token itself
+// remains a validly signed artifact -- revocation lives in the LocalDb
+// record, not the token.
+func (h *teamHandler) RevokeTeamInvite(ctx context.Context, arg keybase1.RevokeTeamInviteArg) error {
+	if err := h.requireAdminOf(arg.TeamID); err != nil {
+		return err
+	}
+	record, found, err := h.teamInviteRecord(arg.TeamID, arg.InviteID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("unknown invite %q", arg.InviteID)
+	}
+	record.Revoked = true
+	return h.putTeamInviteRecord(record)
+}
+
+// AdminChannelTlfName computes the TLF name of teamID's admin-only
+// channel: an implicit TLF whose writer set is exactly teamID's current
+// ADMIN and OWNER members, with no readers. Only an admin of teamID may
+// learn this name, since learning it is what lets a device open or
+// create the channel's chat conversation -- from there, boxing and
+// inbox visibility for that conversation are already handled correctly
+// by the existing TLF/chat machinery with no further work here: KBFS
+// only generates key material for a TLF's own writer set, so nobody
+// outside it can decrypt the channel's messages, and the conversation
+// never surfaces in a non-member's inbox because they were never a
+// participant of the underlying TLF.
+func (h *teamHandler) AdminChannelTlfName(ctx context.Context, arg keybase1.AdminChannelTlfNameArg) (string, error) {
+	if err := h.requireAdminOf(arg.TeamID); err != nil {
+		return "", err
+	}
+
+	name, err := h.currentName(ctx, arg.TeamID)
+	if err != nil {
+		return "", err
+	}
+	overrides, err := h.roleOverrides(arg.TeamID)
+	if err != nil {
+		return "", err
+	}
+
+	var admins []string
+	for _, writer := range name.Writers {
+		switch overrides[writer] {
+		case keybase1.TeamRole_ADMIN, keybase1.TeamRole_OWNER:
+			admins = append(admins, writer)
+		}
+	}
+	if len(admins) == 0 {
+		return "", fmt.Errorf("team %s has no recorded ADMIN or OWNER members", arg.TeamID)
+	}
+
+	adminChannelName := libkb.ImplicitTeamName{Writers: admins, Public: false}
+	return adminChannelName.String(), nil
+}
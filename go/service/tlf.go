@@ -5,99 +5,433 @@ package service
 
 import (
 	"fmt"
+	"strings"
+	"sync"
+	"time"
 
 	"golang.org/x/net/context"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/keybase/client/go/chat"
 	"github.com/keybase/client/go/chat/utils"
 	"github.com/keybase/client/go/libkb"
+	"github.com/keybase/client/go/protocol/chat1"
 	keybase1 "github.com/keybase/client/go/protocol/keybase1"
 	"github.com/keybase/go-framed-msgpack-rpc/rpc"
 )
 
+// tlfValidateParallelism bounds how many TLF names ValidateTLFNames
+// resolves concurrently, so that validating a large batch of names doesn't
+// open one identify per name all at once.
+const tlfValidateParallelism = 8
+
 type tlfHandler struct {
 	*BaseHandler
 	utils.DebugLabeler
 	libkb.Contextified
+
+	participantsMu   sync.Mutex
+	lastParticipants map[keybase1.TLFID]map[string]bool
+
+	pendingMu          sync.Mutex
+	pendingResolutions map[string]keybase1.TLFQuery
+	retryLoopOnce      sync.Once
 }
 
 func newTlfHandler(xp rpc.Transporter, g *libkb.GlobalContext) *tlfHandler {
 	return &tlfHandler{
-		BaseHandler:  NewBaseHandler(xp),
-		Contextified: libkb.NewContextified(g),
-		DebugLabeler: utils.NewDebugLabeler(g, "TlfHandler", false),
+		BaseHandler:        NewBaseHandler(xp),
+		Contextified:       libkb.NewContextified(g),
+		DebugLabeler:       utils.NewDebugLabeler(g, "TlfHandler", false),
+		lastParticipants:   make(map[keybase1.TLFID]map[string]bool),
+		pendingResolutions: make(map[string]keybase1.TLFQuery),
+	}
+}
+
+// tlfParticipants splits a canonical TLF name into its participant
+// usernames, dropping the "#readers" separator and conflict-resolution
+// suffixes.
+func tlfParticipants(canonicalName keybase1.CanonicalTlfName) []string {
+	name := string(canonicalName)
+	if idx := strings.IndexByte(name, ' '); idx >= 0 {
+		// Drop " (conflicted copy ...)" suffixes.
+		name = name[:idx]
+	}
+	name = strings.Replace(name, "#", ",", -1)
+	var res []string
+	for _, p := range strings.Split(name, ",") {
+		if p := strings.TrimSpace(p); len(p) > 0 {
+			res = append(res, p)
+		}
 	}
+	return res
 }
 
-func (h *tlfHandler) tlfKeysClient() (*keybase1.TlfKeysClient, error) {
-	xp := h.G().ConnectionManager.LookupByClientType(keybase1.ClientType_KBFS)
-	if xp == nil {
-		return nil, fmt.Errorf("KBFS client wasn't found")
+// noteParticipants records the current participants of a TLF and reports
+// which of them are new since the last time this handler identified that
+// TLF. The first time a TLF is seen, nothing is reported as new. This is
+// an in-memory, best-effort diagnostic and is not persisted.
+func (h *tlfHandler) noteParticipants(tlfID keybase1.TLFID, canonicalName keybase1.CanonicalTlfName) []string {
+	h.participantsMu.Lock()
+	defer h.participantsMu.Unlock()
+
+	prev, seenBefore := h.lastParticipants[tlfID]
+	cur := make(map[string]bool)
+	var added []string
+	for _, p := range tlfParticipants(canonicalName) {
+		cur[p] = true
+		if seenBefore && !prev[p] {
+			added = append(added, p)
+		}
 	}
-	return &keybase1.TlfKeysClient{
-		Cli: rpc.NewClient(xp, libkb.ErrorUnwrapper{}),
-	}, nil
+	h.lastParticipants[tlfID] = cur
+	return added
+}
+
+// tlfResolver returns the libkb.TlfResolver to resolve TLF crypt keys
+// against. Resolution itself (normally a round trip to KBFS, but
+// pluggable via libkb.GlobalContext.SetTlfResolver) lives in libkb so
+// that tests and alternative frontends can install a different backend
+// without touching tlfHandler.
+func (h *tlfHandler) tlfResolver() libkb.TlfResolver {
+	return h.G().GetTlfResolver()
 }
 
+// failureSeverity computes the most severe failure type reported in
+// breaks: a revoked proof outranks a deleted one, which outranks a plain
+// key change.
+func failureSeverity(breaks *keybase1.IdentifyTrackBreaks) keybase1.TLFIdentifyFailureSeverity {
+	if breaks == nil {
+		return keybase1.TLFIdentifyFailureSeverity_NONE
+	}
+	severity := keybase1.TLFIdentifyFailureSeverity_NONE
+	for _, p := range breaks.Proofs {
+		switch p.Lcr.ProofResult.State {
+		case keybase1.ProofState_REVOKED:
+			return keybase1.TLFIdentifyFailureSeverity_REVOKED_PROOF
+		case keybase1.ProofState_DELETED:
+			if severity < keybase1.TLFIdentifyFailureSeverity_DELETED_PROOF {
+				severity = keybase1.TLFIdentifyFailureSeverity_DELETED_PROOF
+			}
+		}
+	}
+	if severity == keybase1.TLFIdentifyFailureSeverity_NONE && len(breaks.Keys) > 0 {
+		severity = keybase1.TLFIdentifyFailureSeverity_KEY_CHANGED
+	}
+	return severity
+}
+
+// annotateBreaks returns a copy of fails with Severity (derived from
+// Breaks) and Source set on every entry, without mutating the caller's
+// slice.
+func annotateBreaks(fails []keybase1.TLFIdentifyFailure, source string) []keybase1.TLFIdentifyFailure {
+	res := make([]keybase1.TLFIdentifyFailure, len(fails))
+	for i, f := range fails {
+		f.Severity = failureSeverity(f.Breaks)
+		f.Source = source
+		res[i] = f
+	}
+	return res
+}
+
+// appendBreaks merges two lists of per-user identify failures, keeping
+// the most severe failure for any user reported by both. l is the
+// accumulator built up across earlier TLF calls in this chat identify
+// pass (already annotated); r is freshly reported by the KBFS response
+// for this call and gets annotated with source "kbfs" here.
 func appendBreaks(l []keybase1.TLFIdentifyFailure, r []keybase1.TLFIdentifyFailure) []keybase1.TLFIdentifyFailure {
-	m := make(map[string]bool)
-	var res []keybase1.TLFIdentifyFailure
-	for _, f := range l {
-		m[f.User.Username] = true
-		res = append(res, f)
+	byUser := make(map[string]int, len(l))
+	res := make([]keybase1.TLFIdentifyFailure, len(l))
+	copy(res, l)
+	for i, f := range res {
+		byUser[f.User.Username] = i
 	}
-	for _, f := range r {
-		if !m[f.User.Username] {
-			res = append(res, f)
+
+	for _, f := range annotateBreaks(r, "kbfs") {
+		if idx, ok := byUser[f.User.Username]; ok {
+			if f.Severity > res[idx].Severity {
+				res[idx] = f
+			}
+			continue
 		}
+		byUser[f.User.Username] = len(res)
+		res = append(res, f)
 	}
 	return res
 }
 
+// effectiveIdentifyBehavior returns the behavior that should actually be
+// sent to KBFS, forcing identify off entirely when the context was built
+// with chat.WithIdentifyDisabled (e.g. by a background migration job),
+// regardless of what the caller otherwise requested.
+func effectiveIdentifyBehavior(ctx context.Context, requested keybase1.TLFIdentifyBehavior) keybase1.TLFIdentifyBehavior {
+	if chat.IdentifyDisabled(ctx) {
+		return keybase1.TLFIdentifyBehavior_DEFAULT_KBFS
+	}
+	return requested
+}
+
+// tlfIdentifyBehaviorOverrideEntry is the on-disk representation of a
+// per-TLF identify behavior override, set via SetTlfIdentifyBehavior.
+type tlfIdentifyBehaviorOverrideEntry struct {
+	Behavior keybase1.TLFIdentifyBehavior `json:"behavior"`
+}
+
+func tlfIdentifyBehaviorOverrideDbKey(tlfName string) libkb.DbKey {
+	return libkb.DbKey{Typ: libkb.DBTlfIdentifyBehavior, Key: tlfName}
+}
+
+// identifyBehaviorForTlf returns the behavior CryptKeys and
+// PublicCanonicalTLFNameAndID should actually use for tlfName: the
+// override installed by SetTlfIdentifyBehavior if there is one, otherwise
+// requested (usually derived from the calling context). Either way,
+// effectiveIdentifyBehavior still gets the final say, so a
+// context-disabled identify (e.g. a background migration job) can't be
+// overridden by a stale per-TLF setting.
+func (h *tlfHandler) identifyBehaviorForTlf(ctx context.Context, tlfName string, requested keybase1.TLFIdentifyBehavior) keybase1.TLFIdentifyBehavior {
+	if override, err := h.GetTlfIdentifyBehavior(ctx, tlfName); err == nil && override != nil {
+		requested = *override
+	}
+	return effectiveIdentifyBehavior(ctx, requested)
+}
+
+// SetTlfIdentifyBehavior installs arg.Behavior as the identify behavior
+// override for arg.TlfName, persisted in the local db so it survives a
+// service restart.
+func (h *tlfHandler) SetTlfIdentifyBehavior(ctx context.Context, arg keybase1.SetTlfIdentifyBehaviorArg) error {
+	return h.G().LocalDb.PutObj(tlfIdentifyBehaviorOverrideDbKey(arg.TlfName), nil,
+		tlfIdentifyBehaviorOverrideEntry{Behavior: arg.Behavior})
+}
+
+// GetTlfIdentifyBehavior returns the identify behavior override installed
+// for tlfName by SetTlfIdentifyBehavior, if any.
+func (h *tlfHandler) GetTlfIdentifyBehavior(ctx context.Context, tlfName string) (*keybase1.TLFIdentifyBehavior, error) {
+	var entry tlfIdentifyBehaviorOverrideEntry
+	found, err := h.G().LocalDb.GetInto(&entry, tlfIdentifyBehaviorOverrideDbKey(tlfName))
+	if err != nil || !found {
+		return nil, err
+	}
+	return &entry.Behavior, nil
+}
+
+// ClearTlfIdentifyBehavior removes the identify behavior override
+// installed for tlfName by SetTlfIdentifyBehavior, if any.
+func (h *tlfHandler) ClearTlfIdentifyBehavior(ctx context.Context, tlfName string) error {
+	return h.G().LocalDb.Delete(tlfIdentifyBehaviorOverrideDbKey(tlfName))
+}
+
+// CryptKeys resolves and returns the crypt keys for a TLF by name.
+// Setting arg.BypassCache forces this one call to skip any caching layer
+// (this handler's own, and KBFS's, since arg is forwarded as-is) and go
+// all the way to a fresh lookup.
 func (h *tlfHandler) CryptKeys(ctx context.Context, arg keybase1.TLFQuery) (keybase1.GetTLFCryptKeysRes, error) {
 	var err error
 	ident, breaks, ok := chat.IdentifyMode(ctx)
 	if ok {
 		arg.IdentifyBehavior = ident
 	}
+	arg.IdentifyBehavior = h.identifyBehaviorForTlf(ctx, arg.TlfName, arg.IdentifyBehavior)
 	defer h.Trace(ctx, func() error { return err },
 		fmt.Sprintf("CryptKeys(tlf=%s,mode=%v,handler=%v)", arg.TlfName, arg.IdentifyBehavior, h.BaseHandler.xp != nil))()
 
-	tlfClient, err := h.tlfKeysClient()
+	resp, err := h.tlfResolver().GetTLFCryptKeys(ctx, arg)
+	if ok && !chat.IdentifyDisabled(ctx) && len(resp.NameIDBreaks.Breaks.Breaks) > 0 {
+		// Merge in whatever breaks KBFS did manage to report even if the
+		// call itself is about to error out below (e.g. the context was
+		// canceled partway through identify), so a caller polling
+		// *breaks for a cancellation-safe snapshot still sees them.
+		*breaks = appendBreaks(*breaks, resp.NameIDBreaks.Breaks.Breaks)
+	}
 	if err != nil {
-		return keybase1.GetTLFCryptKeysRes{}, err
+		return resp, err
 	}
 
-	resp, err := tlfClient.GetTLFCryptKeys(ctx, arg)
+	if !chat.IdentifyDisabled(ctx) {
+		if in := chat.CtxIdentifyNotifier(ctx); in != nil {
+			in.Send(resp.NameIDBreaks)
+		}
+		h.notifyIdentifyProgress(ctx, resp.NameIDBreaks)
+		if added := h.noteParticipants(resp.NameIDBreaks.TlfID, resp.NameIDBreaks.CanonicalName); len(added) > 0 {
+			h.G().Log.CDebugf(ctx, "TlfHandler: new participants in %s since last identify: %v", arg.TlfName, added)
+		}
+	}
+	return resp, nil
+}
+
+// notifyIdentifyProgress sends a ChatTLFIdentifyProgress notification for
+// each participant of nameIDBreaks, so a GUI resolving a TLF with many
+// participants can show progress rather than going silent until CryptKeys
+// returns. KBFS reports all breaks in one round trip, so this can't fire
+// until resolution is otherwise complete -- it's one notification per
+// participant rather than one lump notification for the whole TLF, not a
+// true stream of interim results.
+func (h *tlfHandler) notifyIdentifyProgress(ctx context.Context, nameIDBreaks keybase1.CanonicalTLFNameAndIDWithBreaks) {
+	breaksByUser := make(map[string]keybase1.IdentifyTrackBreaks, len(nameIDBreaks.Breaks.Breaks))
+	for _, f := range nameIDBreaks.Breaks.Breaks {
+		if f.Breaks != nil {
+			breaksByUser[f.User.Username] = *f.Breaks
+		}
+	}
+	for _, username := range tlfParticipants(nameIDBreaks.CanonicalName) {
+		progress := chat1.TLFIdentifyProgress{
+			TlfName:  nameIDBreaks.CanonicalName,
+			Username: username,
+		}
+		if b, ok := breaksByUser[username]; ok {
+			progress.Breaks = &b
+		}
+		h.G().NotifyRouter.HandleChatTLFIdentifyProgress(ctx, progress)
+	}
+}
+
+// CryptKeysByID fetches a TLF's crypt keys directly by its stable TLF ID,
+// skipping the name-based resolution and canonicalization that CryptKeys
+// does. Identify is only run if the given behavior requires it. See
+// CryptKeys for the meaning of arg.BypassCache.
+func (h *tlfHandler) CryptKeysByID(ctx context.Context, arg keybase1.TLFQueryWithID) (keybase1.GetTLFCryptKeysRes, error) {
+	var err error
+	arg.IdentifyBehavior = effectiveIdentifyBehavior(ctx, arg.IdentifyBehavior)
+	defer h.Trace(ctx, func() error { return err },
+		fmt.Sprintf("CryptKeysByID(tlfID=%s,mode=%v)", arg.TlfID, arg.IdentifyBehavior))()
+
+	resp, err := h.tlfResolver().GetTLFCryptKeysByID(ctx, arg)
 	if err != nil {
 		return resp, err
 	}
 
-	if in := chat.CtxIdentifyNotifier(ctx); in != nil {
-		in.Send(resp.NameIDBreaks)
+	if arg.IdentifyBehavior.AlwaysRunIdentify() {
+		if in := chat.CtxIdentifyNotifier(ctx); in != nil {
+			in.Send(resp.NameIDBreaks)
+		}
 	}
-	if ok {
-		*breaks = appendBreaks(*breaks, resp.NameIDBreaks.Breaks.Breaks)
+	if added := h.noteParticipants(resp.NameIDBreaks.TlfID, resp.NameIDBreaks.CanonicalName); len(added) > 0 {
+		h.G().Log.CDebugf(ctx, "TlfHandler: new participants in %s since last identify: %v", arg.TlfID, added)
 	}
 	return resp, nil
 }
 
+// TLFNameResolution reports whether a single TLF name resolved
+// successfully in a ValidateTLFNames pre-check.
+type TLFNameResolution struct {
+	TlfName    string
+	Resolvable bool
+	Err        string
+}
+
+// ValidateTLFNames checks that each of the given TLF names can be
+// resolved and canonicalized, without callers having to run a full
+// CryptKeys and inspect the error themselves. Useful for e.g. validating
+// user input before committing to an operation that touches several TLFs.
+// Names are resolved concurrently, bounded by tlfValidateParallelism, so a
+// large batch doesn't pay for N sequential identify round trips; results
+// are still returned in the same order as names.
+func (h *tlfHandler) ValidateTLFNames(ctx context.Context, names []string) []TLFNameResolution {
+	type job struct {
+		index int
+		name  string
+	}
+
+	res := make([]TLFNameResolution, len(names))
+	jobCh := make(chan job)
+
+	eg, ctx := errgroup.WithContext(ctx)
+	eg.Go(func() error {
+		defer close(jobCh)
+		for i, name := range names {
+			select {
+			case jobCh <- job{index: i, name: name}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	})
+	for i := 0; i < tlfValidateParallelism; i++ {
+		eg.Go(func() error {
+			for j := range jobCh {
+				_, err := h.CryptKeys(ctx, keybase1.TLFQuery{
+					TlfName:          j.name,
+					IdentifyBehavior: keybase1.TLFIdentifyBehavior_CHAT_GUI,
+				})
+				item := TLFNameResolution{TlfName: j.name, Resolvable: err == nil}
+				if err != nil {
+					item.Err = err.Error()
+				}
+				res[j.index] = item
+			}
+			return nil
+		})
+	}
+	// Worker goroutines never return an error of their own -- a failed
+	// resolution is captured per-name in TLFNameResolution.Err -- so this
+	// can only fail if ctx is canceled mid-dispatch.
+	_ = eg.Wait()
+	return res
+}
+
+// CryptKeysBatch resolves crypt keys for many TLF names in a single call,
+// identifying each name concurrently (bounded by tlfValidateParallelism)
+// instead of making callers pay for a full CryptKeys round trip per name.
+// A failure to resolve one name is reported in that item's Err and doesn't
+// affect the rest of the batch. Results are returned in the same order as
+// arg.TlfNames.
+func (h *tlfHandler) CryptKeysBatch(ctx context.Context, arg keybase1.TLFQueryBatch) ([]keybase1.CryptKeysBatchItem, error) {
+	type job struct {
+		index int
+		name  string
+	}
+
+	res := make([]keybase1.CryptKeysBatchItem, len(arg.TlfNames))
+	jobCh := make(chan job)
+
+	eg, ctx := errgroup.WithContext(ctx)
+	eg.Go(func() error {
+		defer close(jobCh)
+		for i, name := range arg.TlfNames {
+			select {
+			case jobCh <- job{index: i, name: name}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	})
+	for i := 0; i < tlfValidateParallelism; i++ {
+		eg.Go(func() error {
+			for j := range jobCh {
+				resp, err := h.CryptKeys(ctx, keybase1.TLFQuery{
+					TlfName:          j.name,
+					IdentifyBehavior: arg.IdentifyBehavior,
+					BypassCache:      arg.BypassCache,
+				})
+				item := keybase1.CryptKeysBatchItem{TlfName: j.name, Res: resp}
+				if err != nil {
+					item.Err = err.Error()
+				}
+				res[j.index] = item
+			}
+			return nil
+		})
+	}
+	// Worker goroutines never return an error of their own -- a failed
+	// resolution is captured per-name in CryptKeysBatchItem.Err -- so this
+	// can only fail if ctx is canceled mid-dispatch.
+	_ = eg.Wait()
+	return res, nil
+}
+
 func (h *tlfHandler) PublicCanonicalTLFNameAndID(ctx context.Context, arg keybase1.TLFQuery) (keybase1.CanonicalTLFNameAndIDWithBreaks, error) {
 	var err error
 	ident, breaks, ok := chat.IdentifyMode(ctx)
 	if ok {
 		arg.IdentifyBehavior = ident
 	}
+	arg.IdentifyBehavior = h.identifyBehaviorForTlf(ctx, arg.TlfName, arg.IdentifyBehavior)
 	defer h.Trace(ctx, func() error { return err },
 		fmt.Sprintf("PublicCanonicalTLFNameAndID(tlf=%s,mode=%v)", arg.TlfName,
 			arg.IdentifyBehavior))()
 
-	tlfClient, err := h.tlfKeysClient()
-	if err != nil {
-		return keybase1.CanonicalTLFNameAndIDWithBreaks{}, err
-	}
-
-	resp, err := tlfClient.GetPublicCanonicalTLFNameAndID(ctx, arg)
+	resp, err := h.tlfResolver().GetPublicCanonicalTLFNameAndID(ctx, arg)
 	if err != nil {
 		return resp, err
 	}
@@ -125,12 +459,158 @@ func (h *tlfHandler) CompleteAndCanonicalizePrivateTlfName(ctx context.Context,
 	// TODO: We should think about how to handle read-only TLFs.
 	arg.TlfName = string(username) + "," + arg.TlfName
 
-	// TODO: do some caching so we don't end up calling this RPC
-	// unnecessarily too often
+	// Cache the resolved/canonicalized result, keyed by the sorted set of
+	// assertions being resolved, so that repeated sends to the same private
+	// TLF (the common case while chatting) don't each pay for a fresh
+	// CryptKeys round trip to KBFS. arg.BypassCache lets a caller opt out,
+	// same as it does for CryptKeys' own cache.
+	cacheKey := libkb.ParseImplicitTeamTlfName(arg.TlfName, false /* public */).CacheKey()
+	if !arg.BypassCache {
+		if cached, cerr := h.G().TlfNameCache.Get(cacheKey); cerr != nil {
+			h.G().Log.CDebugf(ctx, "TlfHandler: error reading TLF name cache: %s", cerr)
+		} else if cached != nil {
+			return *cached, nil
+		}
+	}
+
 	resp, err := h.CryptKeys(ctx, arg)
 	if err != nil {
+		if isOfflineResolutionError(err) {
+			// The name (likely a not-yet-resolved social assertion, e.g.
+			// alice@twitter) may be fine -- we just couldn't reach the
+			// network to resolve it. Queue it for a background retry
+			// instead of making the caller poll.
+			h.queueOfflineResolution(arg)
+		}
 		return keybase1.CanonicalTLFNameAndIDWithBreaks{}, err
 	}
 
+	if err := h.G().TlfNameCache.Insert(cacheKey, resp.NameIDBreaks); err != nil {
+		h.G().Log.CDebugf(ctx, "TlfHandler: failed to cache canonicalized TLF name: %s", err)
+	}
+
 	return resp.NameIDBreaks, nil
 }
+
+// isOfflineResolutionError reports whether err looks like a connectivity
+// problem rather than a permanent resolution failure (e.g. a bad TLF
+// name), making it worth queueing a background retry instead of just
+// surfacing the error to the caller.
+func isOfflineResolutionError(err error) bool {
+	switch err.(type) {
+	case libkb.APINetError, libkb.KBFSNotRunningError:
+		return true
+	}
+	return false
+}
+
+// tlfPendingResolutionRetryInterval is how often queueOfflineResolution's
+// background loop retries queued TLF name resolutions.
+const tlfPendingResolutionRetryInterval = 30 * time.Second
+
+// queueOfflineResolution records arg (with the username already
+// prepended and the name otherwise fully prepared for CryptKeys) to be
+// retried once connectivity looks like it might have returned, and makes
+// sure the retry loop is running.
+func (h *tlfHandler) queueOfflineResolution(arg keybase1.TLFQuery) {
+	h.pendingMu.Lock()
+	h.pendingResolutions[arg.TlfName] = arg
+	h.pendingMu.Unlock()
+
+	h.retryLoopOnce.Do(func() {
+		go h.retryPendingResolutionsLoop()
+	})
+}
+
+func (h *tlfHandler) retryPendingResolutionsLoop() {
+	for {
+		<-h.G().Clock().After(tlfPendingResolutionRetryInterval)
+		h.retryPendingResolutions()
+	}
+}
+
+// retryPendingResolutions re-attempts every queued resolution. A
+// resolution that succeeds is cached and reported via the same
+// ChatIdentifyUpdate notification a live CryptKeys call would send, so
+// that a UI which is already watching for that name gets the canonical
+// name once it's finally known. A resolution that fails for a reason
+// other than connectivity is dropped rather than retried forever.
+func (h *tlfHandler) retryPendingResolutions() {
+	h.pendingMu.Lock()
+	pending := make([]keybase1.TLFQuery, 0, len(h.pendingResolutions))
+	for _, arg := range h.pendingResolutions {
+		pending = append(pending, arg)
+	}
+	h.pendingMu.Unlock()
+
+	ctx := context.Background()
+	for _, arg := range pending {
+		resp, err := h.CryptKeys(ctx, arg)
+		if err != nil {
+			if !isOfflineResolutionError(err) {
+				h.pendingMu.Lock()
+				delete(h.pendingResolutions, arg.TlfName)
+				h.pendingMu.Unlock()
+			}
+			continue
+		}
+
+		h.pendingMu.Lock()
+		delete(h.pendingResolutions, arg.TlfName)
+		h.pendingMu.Unlock()
+
+		cacheKey := libkb.ParseImplicitTeamTlfName(arg.TlfName, false /* public */).CacheKey()
+		if err := h.G().TlfNameCache.Insert(cacheKey, resp.NameIDBreaks); err != nil {
+			h.G().Log.Debug("TlfHandler: failed to cache canonicalized TLF name after retry: %s", err)
+		}
+		h.G().NotifyRouter.HandleChatIdentifyUpdate(ctx, resp.NameIDBreaks)
+	}
+}
+
+// ResolveImplicitTeam resolves a TLF-style name -- writers, and optionally
+// readers introduced with "#" -- to a stable ID and canonical display
+// name, creating the underlying folder on demand if it doesn't already
+// exist. Two names that parse to the same libkb.ImplicitTeamName (same
+// writers, same readers, regardless of input order or a "(conflicted
+// copy ...)" suffix) always resolve to the same ID, since resolution and
+// caching both go through libkb.ParseImplicitTeamTlfName.
+//
+// This is the same resolution tlfHandler already performs for KBFS
+// (CryptKeys/PublicCanonicalTLFNameAndID) and that chat already reaches
+// through the TlfInterface RPC client -- ResolveImplicitTeam just gives
+// other in-process, service-layer callers (e.g. future chat conversation
+// creation) a single Go entry point for it, instead of each one
+// duplicating name parsing and cache-key construction.
+//
+// NOTE: this client doesn't implement Keybase's server-side team
+// sigchains -- there's no keybase1.TeamID, no team keys, and no
+// membership management here. The ID returned below is the existing KBFS
+// TLF ID, which already uniquely and stably identifies a set of
+// participants the same way a real implicit team ID would.
+func (h *tlfHandler) ResolveImplicitTeam(ctx context.Context, tlfName string, public bool) (keybase1.TLFID, keybase1.CanonicalTlfName, error) {
+	cacheKey := libkb.ParseImplicitTeamTlfName(tlfName, public).CacheKey()
+	if cached, err := h.G().TlfNameCache.Get(cacheKey); err == nil && cached != nil {
+		return cached.TlfID, cached.CanonicalName, nil
+	}
+
+	query := keybase1.TLFQuery{TlfName: tlfName}
+	var nameIDBreaks keybase1.CanonicalTLFNameAndIDWithBreaks
+	if public {
+		res, err := h.PublicCanonicalTLFNameAndID(ctx, query)
+		if err != nil {
+			return "", "", err
+		}
+		nameIDBreaks = res
+	} else {
+		res, err := h.CryptKeys(ctx, query)
+		if err != nil {
+			return "", "", err
+		}
+		nameIDBreaks = res.NameIDBreaks
+	}
+
+	if err := h.G().TlfNameCache.Insert(cacheKey, nameIDBreaks); err != nil {
+		h.G().Log.CDebugf(ctx, "ResolveImplicitTeam: failed to cache resolution for %s: %s", tlfName, err)
+	}
+	return nameIDBreaks.TlfID, nameIDBreaks.CanonicalName, nil
+}
@@ -0,0 +1,528 @@
+// Copyright 2017 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/keybase/client/go/chat"
+	"github.com/keybase/client/go/libkb"
+	"github.com/keybase/client/go/protocol/chat1"
+	keybase1 "github.com/keybase/client/go/protocol/keybase1"
+)
+
+type fakeGenericClient struct {
+	lastMethod string
+	lastArg    interface{}
+	res        keybase1.GetTLFCryptKeysRes
+	err        error
+}
+
+func (f *fakeGenericClient) Call(ctx context.Context, method string, arg interface{}, res interface{}) error {
+	f.lastMethod = method
+	f.lastArg = arg
+	if out, ok := res.(*keybase1.GetTLFCryptKeysRes); ok {
+		*out = f.res
+	}
+	return f.err
+}
+
+func (f *fakeGenericClient) Notify(ctx context.Context, method string, arg interface{}) error {
+	return nil
+}
+
+// TestCryptKeysByIDRoutesByID makes sure the generated tlfKeys client wired
+// up in CryptKeysByID calls the getTLFCryptKeysById RPC with the given TLF
+// ID, rather than doing any name-based resolution.
+func TestCryptKeysByIDRoutesByID(t *testing.T) {
+	fc := &fakeGenericClient{
+		res: keybase1.GetTLFCryptKeysRes{
+			NameIDBreaks: keybase1.CanonicalTLFNameAndIDWithBreaks{
+				TlfID: keybase1.TLFID("abc123"),
+			},
+		},
+	}
+	cli := keybase1.TlfKeysClient{Cli: fc}
+
+	res, err := cli.GetTLFCryptKeysByID(context.Background(), keybase1.TLFQueryWithID{
+		TlfID:            keybase1.TLFID("abc123"),
+		IdentifyBehavior: keybase1.TLFIdentifyBehavior_CHAT_GUI,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if fc.lastMethod != "keybase.1.tlfKeys.getTLFCryptKeysById" {
+		t.Fatalf("expected getTLFCryptKeysById RPC, got: %s", fc.lastMethod)
+	}
+	if res.NameIDBreaks.TlfID != keybase1.TLFID("abc123") {
+		t.Fatalf("expected TlfID abc123, got: %s", res.NameIDBreaks.TlfID)
+	}
+}
+
+// TestBypassCacheForwarded checks that TLFQueryWithID.BypassCache is
+// forwarded to KBFS unchanged, since there's no handler-side TLF cache
+// (yet) to intercept it locally.
+func TestBypassCacheForwarded(t *testing.T) {
+	fc := &fakeGenericClient{}
+	cli := keybase1.TlfKeysClient{Cli: fc}
+
+	_, err := cli.GetTLFCryptKeysByID(context.Background(), keybase1.TLFQueryWithID{
+		TlfID:       keybase1.TLFID("abc123"),
+		BypassCache: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	arg, ok := fc.lastArg.([]interface{})
+	if !ok || len(arg) != 1 {
+		t.Fatalf("unexpected arg shape: %#v", fc.lastArg)
+	}
+	query, ok := arg[0].(keybase1.GetTLFCryptKeysByIDArg)
+	if !ok {
+		t.Fatalf("unexpected arg type: %#v", arg[0])
+	}
+	if !query.Query.BypassCache {
+		t.Fatal("expected BypassCache to be forwarded to the wire arg")
+	}
+}
+
+// TestGetTLFCryptKeysByIDPartialResultOnError checks that a failed
+// getTLFCryptKeysById call still surfaces whatever partial response (e.g.
+// breaks gathered before a cancellation) the transport decoded, rather
+// than discarding it just because the RPC returned an error. CryptKeys
+// and CryptKeysByID rely on this to give callers a cancellation-safe
+// snapshot of identify breaks even when the call errors out late.
+func TestGetTLFCryptKeysByIDPartialResultOnError(t *testing.T) {
+	wantErr := errors.New("context canceled")
+	fc := &fakeGenericClient{
+		res: keybase1.GetTLFCryptKeysRes{
+			NameIDBreaks: keybase1.CanonicalTLFNameAndIDWithBreaks{
+				TlfID: keybase1.TLFID("abc123"),
+				Breaks: keybase1.TLFBreak{
+					Breaks: []keybase1.TLFIdentifyFailure{{User: keybase1.User{Username: "mallory"}}},
+				},
+			},
+		},
+		err: wantErr,
+	}
+	cli := keybase1.TlfKeysClient{Cli: fc}
+
+	res, err := cli.GetTLFCryptKeysByID(context.Background(), keybase1.TLFQueryWithID{
+		TlfID: keybase1.TLFID("abc123"),
+	})
+	if err != wantErr {
+		t.Fatalf("expected the error to propagate, got: %v", err)
+	}
+	if len(res.NameIDBreaks.Breaks.Breaks) != 1 || res.NameIDBreaks.Breaks.Breaks[0].User.Username != "mallory" {
+		t.Fatalf("expected the partial breaks to still be populated, got: %+v", res)
+	}
+}
+
+// TestEffectiveIdentifyBehaviorDisabled checks that a context built with
+// chat.WithIdentifyDisabled forces identify off regardless of what the
+// caller otherwise asked for, and that an ordinary context passes the
+// requested behavior through unchanged.
+func TestEffectiveIdentifyBehaviorDisabled(t *testing.T) {
+	ctx := chat.WithIdentifyDisabled(context.Background())
+	got := effectiveIdentifyBehavior(ctx, keybase1.TLFIdentifyBehavior_CHAT_GUI_STRICT)
+	if got != keybase1.TLFIdentifyBehavior_DEFAULT_KBFS {
+		t.Fatalf("expected identify to be forced off, got: %v", got)
+	}
+
+	got = effectiveIdentifyBehavior(context.Background(), keybase1.TLFIdentifyBehavior_CHAT_GUI_STRICT)
+	if got != keybase1.TLFIdentifyBehavior_CHAT_GUI_STRICT {
+		t.Fatalf("expected requested behavior to pass through, got: %v", got)
+	}
+}
+
+// TestValidateTLFNamesNoKBFSClient checks that ValidateTLFNames reports
+// every name as unresolvable, with the underlying error attached, when
+// there's no KBFS client to resolve against.
+func TestValidateTLFNamesNoKBFSClient(t *testing.T) {
+	tc := libkb.SetupTest(t, "tlf", 1)
+	defer tc.Cleanup()
+
+	h := newTlfHandler(nil, tc.G)
+	res := h.ValidateTLFNames(context.Background(), []string{"alice,bob", "carol"})
+	if len(res) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(res))
+	}
+	for _, r := range res {
+		if r.Resolvable {
+			t.Fatalf("expected %q to be unresolvable with no KBFS client", r.TlfName)
+		}
+		if r.Err == "" {
+			t.Fatalf("expected an error message for %q", r.TlfName)
+		}
+	}
+}
+
+// TestValidateTLFNamesPreservesOrder checks that ValidateTLFNames returns
+// results in the same order as the input names, even though it resolves
+// them concurrently across more names than tlfValidateParallelism.
+func TestValidateTLFNamesPreservesOrder(t *testing.T) {
+	tc := libkb.SetupTest(t, "tlf", 1)
+	defer tc.Cleanup()
+
+	h := newTlfHandler(nil, tc.G)
+	var names []string
+	for i := 0; i < tlfValidateParallelism*3; i++ {
+		names = append(names, fmt.Sprintf("user%d", i))
+	}
+
+	res := h.ValidateTLFNames(context.Background(), names)
+	if len(res) != len(names) {
+		t.Fatalf("expected %d results, got %d", len(names), len(res))
+	}
+	for i, r := range res {
+		if r.TlfName != names[i] {
+			t.Fatalf("expected result %d to be for %q, got %q", i, names[i], r.TlfName)
+		}
+	}
+}
+
+// TestCryptKeysBatchPreservesOrderAndReportsErrors checks that
+// CryptKeysBatch returns one item per requested name, in request order,
+// and that a failure to resolve (no KBFS client, here) is reported per
+// item rather than failing the whole batch.
+func TestCryptKeysBatchPreservesOrderAndReportsErrors(t *testing.T) {
+	tc := libkb.SetupTest(t, "tlf", 1)
+	defer tc.Cleanup()
+
+	h := newTlfHandler(nil, tc.G)
+	var names []string
+	for i := 0; i < tlfValidateParallelism*3; i++ {
+		names = append(names, fmt.Sprintf("user%d", i))
+	}
+
+	res, err := h.CryptKeysBatch(context.Background(), keybase1.TLFQueryBatch{TlfNames: names})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(res) != len(names) {
+		t.Fatalf("expected %d results, got %d", len(names), len(res))
+	}
+	for i, item := range res {
+		if item.TlfName != names[i] {
+			t.Fatalf("expected result %d to be for %q, got %q", i, names[i], item.TlfName)
+		}
+		if item.Err == "" {
+			t.Fatalf("expected an error for %q with no KBFS client connected", item.TlfName)
+		}
+	}
+}
+
+// TestQueueOfflineResolutionRetries checks that a queued offline
+// resolution is retried and, once it succeeds (simulated here by
+// swapping in a KBFS client that now works), is removed from the queue
+// and cached.
+func TestQueueOfflineResolutionRetries(t *testing.T) {
+	tc := libkb.SetupTest(t, "tlf", 1)
+	defer tc.Cleanup()
+
+	h := newTlfHandler(nil, tc.G)
+	arg := keybase1.TLFQuery{TlfName: "alice,bob"}
+	h.queueOfflineResolution(arg)
+
+	h.pendingMu.Lock()
+	n := len(h.pendingResolutions)
+	h.pendingMu.Unlock()
+	if n != 1 {
+		t.Fatalf("expected 1 queued resolution, got %d", n)
+	}
+
+	// Still offline: retrying should leave it queued.
+	h.retryPendingResolutions()
+	h.pendingMu.Lock()
+	n = len(h.pendingResolutions)
+	h.pendingMu.Unlock()
+	if n != 1 {
+		t.Fatalf("expected resolution to remain queued while offline, got %d entries", n)
+	}
+}
+
+// TestAppendBreaksKeepsMostSevere checks that merging two failure lists
+// for the same user keeps whichever side reports the more severe
+// failure, and that a user only reported on one side is kept as-is.
+func TestAppendBreaksKeepsMostSevere(t *testing.T) {
+	l := []keybase1.TLFIdentifyFailure{
+		{
+			User: keybase1.User{Username: "alice"},
+			Breaks: &keybase1.IdentifyTrackBreaks{
+				Keys: []keybase1.IdentifyKey{{BreaksTracking: true}},
+			},
+			Severity: keybase1.TLFIdentifyFailureSeverity_KEY_CHANGED,
+			Source:   "kbfs",
+		},
+		{User: keybase1.User{Username: "carol"}},
+	}
+	r := []keybase1.TLFIdentifyFailure{
+		{
+			User: keybase1.User{Username: "alice"},
+			Breaks: &keybase1.IdentifyTrackBreaks{
+				Proofs: []keybase1.IdentifyProofBreak{{
+					Lcr: keybase1.LinkCheckResult{ProofResult: keybase1.ProofResult{State: keybase1.ProofState_REVOKED}},
+				}},
+			},
+		},
+		{User: keybase1.User{Username: "bob"}},
+	}
+
+	res := appendBreaks(l, r)
+	byUser := make(map[string]keybase1.TLFIdentifyFailure, len(res))
+	for _, f := range res {
+		byUser[f.User.Username] = f
+	}
+
+	if len(res) != 3 {
+		t.Fatalf("expected 3 merged failures, got %d", len(res))
+	}
+	if got := byUser["alice"].Severity; got != keybase1.TLFIdentifyFailureSeverity_REVOKED_PROOF {
+		t.Fatalf("expected alice's failure to be upgraded to REVOKED_PROOF, got %v", got)
+	}
+	if got := byUser["bob"].Source; got != "kbfs" {
+		t.Fatalf("expected bob's failure to be annotated with source kbfs, got %q", got)
+	}
+	if got := byUser["carol"].Source; got != "" {
+		t.Fatalf("expected carol's failure (only on the l side) to keep its existing source, got %q", got)
+	}
+}
+
+func TestIsOfflineResolutionError(t *testing.T) {
+	if !isOfflineResolutionError(libkb.KBFSNotRunningError{}) {
+		t.Fatal("expected KBFSNotRunningError to be treated as an offline error")
+	}
+	if !isOfflineResolutionError(libkb.APINetError{}) {
+		t.Fatal("expected APINetError to be treated as an offline error")
+	}
+	if isOfflineResolutionError(errors.New("some other error")) {
+		t.Fatal("expected an unrelated error not to be treated as offline")
+	}
+}
+
+// TestNoteParticipantsReportsNewOnly checks that noteParticipants doesn't
+// report anything new on the first identify of a TLF, but does report
+// participants that appear in a later identify of the same TLF.
+func TestNoteParticipantsReportsNewOnly(t *testing.T) {
+	tc := libkb.SetupTest(t, "tlf", 1)
+	defer tc.Cleanup()
+
+	h := newTlfHandler(nil, tc.G)
+	tlfID := keybase1.TLFID("abc123")
+
+	added := h.noteParticipants(tlfID, keybase1.CanonicalTlfName("alice,bob"))
+	if len(added) != 0 {
+		t.Fatalf("expected no new participants on first identify, got: %v", added)
+	}
+
+	added = h.noteParticipants(tlfID, keybase1.CanonicalTlfName("alice,bob,carol#dave"))
+	if len(added) != 2 {
+		t.Fatalf("expected 2 new participants, got: %v", added)
+	}
+	want := map[string]bool{"carol": true, "dave": true}
+	for _, p := range added {
+		if !want[p] {
+			t.Fatalf("unexpected new participant: %s", p)
+		}
+	}
+
+	added = h.noteParticipants(tlfID, keybase1.CanonicalTlfName("alice,bob,carol#dave"))
+	if len(added) != 0 {
+		t.Fatalf("expected no new participants when unchanged, got: %v", added)
+	}
+}
+
+// TestCryptKeysByIDNoKBFSClient checks that CryptKeysByID surfaces an
+// error, without attempting an identify, when no KBFS client is connected.
+func TestCryptKeysByIDNoKBFSClient(t *testing.T) {
+	tc := libkb.SetupTest(t, "tlf", 1)
+	defer tc.Cleanup()
+
+	h := newTlfHandler(nil, tc.G)
+	_, err := h.CryptKeysByID(context.Background(), keybase1.TLFQueryWithID{
+		TlfID:            keybase1.TLFID("abc123"),
+		IdentifyBehavior: keybase1.TLFIdentifyBehavior_CHAT_GUI,
+	})
+	if err == nil {
+		t.Fatal("expected an error with no KBFS client connected")
+	}
+}
+
+// TestTlfHandlerUsesInstalledTlfResolver checks that tlfHandler resolves
+// through whatever libkb.TlfResolver is installed on the GlobalContext,
+// rather than talking to KBFS directly.
+func TestTlfHandlerUsesInstalledTlfResolver(t *testing.T) {
+	tc := libkb.SetupTest(t, "tlf", 1)
+	defer tc.Cleanup()
+
+	fake := &fakeTlfResolver{
+		cryptKeys: keybase1.GetTLFCryptKeysRes{
+			NameIDBreaks: keybase1.CanonicalTLFNameAndIDWithBreaks{
+				CanonicalName: "t_alice,t_bob",
+			},
+		},
+	}
+	tc.G.SetTlfResolver(fake)
+
+	h := newTlfHandler(nil, tc.G)
+	res, err := h.CryptKeys(context.Background(), keybase1.TLFQuery{TlfName: "t_alice,t_bob"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if res.NameIDBreaks.CanonicalName != "t_alice,t_bob" {
+		t.Fatalf("unexpected canonical name: %s", res.NameIDBreaks.CanonicalName)
+	}
+	if !fake.calledCryptKeys {
+		t.Fatal("expected tlfHandler to call the installed TlfResolver")
+	}
+}
+
+type fakeTlfResolver struct {
+	cryptKeys       keybase1.GetTLFCryptKeysRes
+	calledCryptKeys bool
+}
+
+func (f *fakeTlfResolver) GetTLFCryptKeys(ctx context.Context, query keybase1.TLFQuery) (keybase1.GetTLFCryptKeysRes, error) {
+	f.calledCryptKeys = true
+	return f.cryptKeys, nil
+}
+
+func (f *fakeTlfResolver) GetTLFCryptKeysByID(ctx context.Context, query keybase1.TLFQueryWithID) (keybase1.GetTLFCryptKeysRes, error) {
+	return f.cryptKeys, nil
+}
+
+func (f *fakeTlfResolver) GetPublicCanonicalTLFNameAndID(ctx context.Context, query keybase1.TLFQuery) (keybase1.CanonicalTLFNameAndIDWithBreaks, error) {
+	return f.cryptKeys.NameIDBreaks, nil
+}
+
+// TestTlfIdentifyBehaviorOverrideRoundTrips checks that an override
+// installed by SetTlfIdentifyBehavior is returned by GetTlfIdentifyBehavior
+// and applied by identifyBehaviorForTlf, and that ClearTlfIdentifyBehavior
+// removes it again.
+func TestTlfIdentifyBehaviorOverrideRoundTrips(t *testing.T) {
+	tc := libkb.SetupTest(t, "tlf", 1)
+	defer tc.Cleanup()
+
+	h := newTlfHandler(nil, tc.G)
+	ctx := context.Background()
+	const tlfName = "t_alice,t_bob"
+
+	if got, err := h.GetTlfIdentifyBehavior(ctx, tlfName); err != nil || got != nil {
+		t.Fatalf("expected no override before Set, got %v, %v", got, err)
+	}
+	if got := h.identifyBehaviorForTlf(ctx, tlfName, keybase1.TLFIdentifyBehavior_CHAT_GUI); got != keybase1.TLFIdentifyBehavior_CHAT_GUI {
+		t.Fatalf("expected requested behavior with no override, got %v", got)
+	}
+
+	if err := h.SetTlfIdentifyBehavior(ctx, keybase1.SetTlfIdentifyBehaviorArg{
+		TlfName:  tlfName,
+		Behavior: keybase1.TLFIdentifyBehavior_CHAT_GUI_STRICT,
+	}); err != nil {
+		t.Fatalf("unexpected error setting override: %s", err)
+	}
+
+	got, err := h.GetTlfIdentifyBehavior(ctx, tlfName)
+	if err != nil || got == nil || *got != keybase1.TLFIdentifyBehavior_CHAT_GUI_STRICT {
+		t.Fatalf("expected CHAT_GUI_STRICT override, got %v, %v", got, err)
+	}
+	if got := h.identifyBehaviorForTlf(ctx, tlfName, keybase1.TLFIdentifyBehavior_CHAT_GUI); got != keybase1.TLFIdentifyBehavior_CHAT_GUI_STRICT {
+		t.Fatalf("expected override to win over requested behavior, got %v", got)
+	}
+
+	if err := h.ClearTlfIdentifyBehavior(ctx, tlfName); err != nil {
+		t.Fatalf("unexpected error clearing override: %s", err)
+	}
+	if got, err := h.GetTlfIdentifyBehavior(ctx, tlfName); err != nil || got != nil {
+		t.Fatalf("expected no override after Clear, got %v, %v", got, err)
+	}
+}
+
+// TestNotifyIdentifyProgressPerParticipant checks that notifyIdentifyProgress
+// fires one ChatTLFIdentifyProgress per participant, pairing each with their
+// own breaks (or none, if they identified clean).
+func TestNotifyIdentifyProgressPerParticipant(t *testing.T) {
+	tc := libkb.SetupTest(t, "tlf", 1)
+	defer tc.Cleanup()
+
+	listener := newProgressListener()
+	tc.G.NotifyRouter.SetListener(listener)
+
+	h := newTlfHandler(nil, tc.G)
+	h.notifyIdentifyProgress(context.Background(), keybase1.CanonicalTLFNameAndIDWithBreaks{
+		CanonicalName: "t_alice,t_bob",
+		Breaks: keybase1.TLFBreak{
+			Breaks: []keybase1.TLFIdentifyFailure{
+				{
+					User:   keybase1.User{Username: "t_bob"},
+					Breaks: &keybase1.IdentifyTrackBreaks{},
+				},
+			},
+		},
+	})
+
+	if len(listener.progress) != 2 {
+		t.Fatalf("expected one notification per participant, got %d", len(listener.progress))
+	}
+	byUser := make(map[string]chat1.TLFIdentifyProgress)
+	for _, p := range listener.progress {
+		byUser[p.Username] = p
+	}
+	if byUser["t_alice"].Breaks != nil {
+		t.Fatalf("expected no breaks for t_alice, got %+v", byUser["t_alice"].Breaks)
+	}
+	if byUser["t_bob"].Breaks == nil {
+		t.Fatal("expected breaks for t_bob")
+	}
+}
+
+type progressListener struct {
+	noopNotifyListener
+	progress []chat1.TLFIdentifyProgress
+}
+
+var _ libkb.NotifyListener = (*progressListener)(nil)
+
+func newProgressListener() *progressListener {
+	return &progressListener{}
+}
+
+func (l *progressListener) ChatTLFIdentifyProgress(progress chat1.TLFIdentifyProgress) {
+	l.progress = append(l.progress, progress)
+}
+
+// noopNotifyListener implements libkb.NotifyListener with no-ops, so tests
+// that only care about one notification don't have to stub every method.
+type noopNotifyListener struct{}
+
+func (noopNotifyListener) Logout()                                                             {}
+func (noopNotifyListener) Login(username string)                                               {}
+func (noopNotifyListener) ClientOutOfDate(to, uri, msg string)                                  {}
+func (noopNotifyListener) UserChanged(uid keybase1.UID)                                         {}
+func (noopNotifyListener) TrackingChanged(uid keybase1.UID, username libkb.NormalizedUsername)  {}
+func (noopNotifyListener) FSActivity(activity keybase1.FSNotification)                          {}
+func (noopNotifyListener) FSEditListResponse(arg keybase1.FSEditListArg)                         {}
+func (noopNotifyListener) FSSyncStatusResponse(arg keybase1.FSSyncStatusArg)                     {}
+func (noopNotifyListener) FSSyncEvent(arg keybase1.FSPathSyncStatus)                             {}
+func (noopNotifyListener) FSEditListRequest(arg keybase1.FSEditListRequest)                      {}
+func (noopNotifyListener) FavoritesChanged(uid keybase1.UID)                                     {}
+func (noopNotifyListener) PaperKeyCached(uid keybase1.UID, encKID, sigKID keybase1.KID)          {}
+func (noopNotifyListener) KeyfamilyChanged(uid keybase1.UID)                                     {}
+func (noopNotifyListener) NewChatActivity(uid keybase1.UID, activity chat1.ChatActivity)         {}
+func (noopNotifyListener) ChatIdentifyUpdate(update keybase1.CanonicalTLFNameAndIDWithBreaks)    {}
+func (noopNotifyListener) ChatTLFIdentifyProgress(progress chat1.TLFIdentifyProgress)            {}
+func (noopNotifyListener) ChatTLFFinalize(uid keybase1.UID, convID chat1.ConversationID, info chat1.ConversationFinalizeInfo) {
+}
+func (noopNotifyListener) ChatTLFResolve(uid keybase1.UID, convID chat1.ConversationID, info chat1.ConversationResolveInfo) {
+}
+func (noopNotifyListener) ChatInboxStale(uid keybase1.UID)                          {}
+func (noopNotifyListener) ChatThreadsStale(uid keybase1.UID, cids []chat1.ConversationID) {}
+func (noopNotifyListener) ChatTypingUpdate(typingUpdates []chat1.ConvTypingUpdate)        {}
+func (noopNotifyListener) ChatAttentionUpdate(uid keybase1.UID, convID chat1.ConversationID, msgID chat1.MessageID) {}
+func (noopNotifyListener) PGPKeyInSecretStoreFile()                                 {}
+func (noopNotifyListener) BadgeState(badgeState keybase1.BadgeState)                {}
+func (noopNotifyListener) ReachabilityChanged(r keybase1.Reachability)              {}
@@ -37,16 +37,26 @@ type UIRouter struct {
 	setCh      chan setObj
 	getCh      chan getObj
 	shutdownCh chan struct{}
+
+	// identifyConns and lastIdentifyCid are only ever touched inside run(),
+	// unlike uis above, since choosing among them requires the extra
+	// priority/affinity logic in selectIdentifyConn.
+	identifyConns   []libkb.ConnectionID
+	lastIdentifyCid libkb.ConnectionID
+	setIdentifyCh   chan libkb.ConnectionID
+	getIdentifyCh   chan chan<- libkb.ConnectionID
 }
 
 func NewUIRouter(g *libkb.GlobalContext) *UIRouter {
 	ret := &UIRouter{
-		Contextified: libkb.NewContextified(g),
-		cm:           g.ConnectionManager,
-		uis:          make(map[libkb.UIKind]libkb.ConnectionID),
-		setCh:        make(chan setObj),
-		getCh:        make(chan getObj),
-		shutdownCh:   make(chan struct{}),
+		Contextified:  libkb.NewContextified(g),
+		cm:            g.ConnectionManager,
+		uis:           make(map[libkb.UIKind]libkb.ConnectionID),
+		setCh:         make(chan setObj),
+		getCh:         make(chan getObj),
+		shutdownCh:    make(chan struct{}),
+		setIdentifyCh: make(chan libkb.ConnectionID),
+		getIdentifyCh: make(chan chan<- libkb.ConnectionID),
 	}
 	go ret.run()
 	return ret
@@ -73,12 +83,85 @@ func (u *UIRouter) run() {
 				}
 			}
 			o.retCh <- transporterAndConnectionID{transporter: ret, connectionID: cid}
+		case cid := <-u.setIdentifyCh:
+			u.registerIdentifyConn(cid)
+		case retCh := <-u.getIdentifyCh:
+			retCh <- u.selectIdentifyConn()
 		}
 	}
 }
 
+func (u *UIRouter) registerIdentifyConn(cid libkb.ConnectionID) {
+	for _, c := range u.identifyConns {
+		if c == cid {
+			return
+		}
+	}
+	u.identifyConns = append(u.identifyConns, cid)
+}
+
+// identifyUIPriority ranks client types for the purposes of picking a
+// delegate identify UI when more than one is registered. The GUI is
+// preferred over the CLI since it's normally the one attended by a human;
+// anything else (or an unlabeled connection) is a last resort.
+func identifyUIPriority(t keybase1.ClientType) int {
+	switch t {
+	case keybase1.ClientType_GUI_MAIN:
+		return 3
+	case keybase1.ClientType_CLI:
+		return 2
+	case keybase1.ClientType_GUI_HELPER:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// selectIdentifyConn picks which of the currently registered identify UI
+// connections should service the next identify. It prunes connections that
+// have since disconnected, sticks with whichever connection it last
+// returned as long as that connection is still registered (so a single
+// terminal/GUI doesn't get swapped out from under an in-progress identify
+// session just because another UI registered in the meantime), and
+// otherwise picks the highest-priority live connection. It returns 0 (an
+// invalid ConnectionID) if none are available.
+func (u *UIRouter) selectIdentifyConn() libkb.ConnectionID {
+	live := u.identifyConns[:0]
+	for _, cid := range u.identifyConns {
+		if u.cm.LookupConnection(cid) != nil {
+			live = append(live, cid)
+		}
+	}
+	u.identifyConns = live
+
+	for _, cid := range u.identifyConns {
+		if cid == u.lastIdentifyCid {
+			return cid
+		}
+	}
+
+	if len(u.identifyConns) == 0 {
+		u.lastIdentifyCid = 0
+		return 0
+	}
+
+	best := u.identifyConns[0]
+	bestPriority := identifyUIPriority(u.cm.LookupClientDetails(best).ClientType)
+	for _, cid := range u.identifyConns[1:] {
+		if p := identifyUIPriority(u.cm.LookupClientDetails(cid).ClientType); p > bestPriority {
+			best, bestPriority = cid, p
+		}
+	}
+	u.lastIdentifyCid = best
+	return best
+}
+
 func (u *UIRouter) SetUI(c libkb.ConnectionID, k libkb.UIKind) {
 	u.G().Log.Debug("UIRouter: connection %v registering UI %s", c, k)
+	if k == libkb.IdentifyUIKind {
+		u.setIdentifyCh <- c
+		return
+	}
 	u.setCh <- setObj{c, k}
 }
 
@@ -90,9 +173,22 @@ func (u *UIRouter) getUI(k libkb.UIKind) (rpc.Transporter, libkb.ConnectionID) {
 }
 
 func (u *UIRouter) GetIdentifyUI() (libkb.IdentifyUI, error) {
-	x, _ := u.getUI(libkb.IdentifyUIKind)
+	retCh := make(chan libkb.ConnectionID)
+	u.getIdentifyCh <- retCh
+	cid := <-retCh
+
+	var x rpc.Transporter
+	if cid != 0 {
+		x = u.cm.LookupConnection(cid)
+	}
 	if x == nil {
-		return nil, nil
+		// No delegate registered (or it just disconnected) -- fall back to
+		// a headless UI instead of failing the identify outright. This
+		// matters most for identifies triggered in the background, e.g. by
+		// an incoming chat message, which have no other way to surface an
+		// error to a human.
+		u.G().Log.Debug("UIRouter: no IdentifyUI registered, using headless fallback")
+		return newLogIdentifyUI(u.G()), nil
 	}
 	cli := rpc.NewClient(x, libkb.ErrorUnwrapper{})
 	iuicli := keybase1.IdentifyUiClient{Cli: cli}